@@ -0,0 +1,112 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/server"
+	"github.com/google/uuid"
+	"github.com/urfave/cli/v2"
+)
+
+func (application *app) apiKeyCommand() *cli.Command {
+	return &cli.Command{
+		Name: "api-key",
+		Usage: "manage API keys of machine to machine clients",
+		Subcommands: []*cli.Command{
+			{
+				Name: "create",
+				Usage: "create an API key for an user, the key is only printed once",
+				Flags: []cli.Flag{
+					idFlag,
+					&cli.StringFlag{Name: "name", Required: true},
+					&cli.StringSliceFlag{Name: "scope", Usage: "one of " + strings.Join(domain.APIKeyScopes, ", "), Required: true},
+					&cli.DurationFlag{Name: "expires-in", Usage: "how long the key is valid, it never expires when omitted"},
+				},
+				Action: func(c *cli.Context) error {
+					id, err := uuid.Parse(c.String("id"))
+
+					if err != nil {
+						return err
+					}
+
+					key := domain.APIKey{
+						UserId: id,
+						Name: c.String("name"),
+						Scopes: c.StringSlice("scope"),
+					}
+
+					if expiresIn := c.Duration("expires-in"); expiresIn > 0 {
+						key.ExpiresAt = time.Now().Add(expiresIn)
+					}
+
+					result, secret, err := server.NewAPIKeyService(application.db).Create(key)
+
+					if err != nil {
+						return err
+					}
+
+					fmt.Fprintln(c.App.Writer, "API key created successfully:", result.Id)
+					fmt.Fprintln(c.App.Writer, secret)
+
+					return nil
+				},
+			},
+			{
+				Name: "list",
+				Usage: "list the API keys of an user",
+				Flags: []cli.Flag{idFlag},
+				Action: func(c *cli.Context) error {
+					id, err := uuid.Parse(c.String("id"))
+
+					if err != nil {
+						return err
+					}
+
+					keys, err := server.NewAPIKeyService(application.db).List(id)
+
+					if err != nil {
+						return err
+					}
+
+					for _, key := range keys {
+						fmt.Fprintf(c.App.Writer, "%s\t%s\t%s\t%s\tactive=%t\n", key.Id, key.Prefix, key.Name, strings.Join(key.Scopes, ","), key.Active(time.Now()))
+					}
+
+					return nil
+				},
+			},
+			{
+				Name: "revoke",
+				Usage: "revoke an API key",
+				Flags: []cli.Flag{
+					idFlag,
+					&cli.StringFlag{Name: "key-id", Required: true},
+				},
+				Action: func(c *cli.Context) error {
+					id, err := uuid.Parse(c.String("id"))
+
+					if err != nil {
+						return err
+					}
+
+					keyId, err := uuid.Parse(c.String("key-id"))
+
+					if err != nil {
+						return err
+					}
+
+					if err := server.NewAPIKeyService(application.db).Revoke(id, keyId); err != nil {
+						return err
+					}
+
+					fmt.Fprintln(c.App.Writer, "API key revoked successfully:", keyId)
+
+					return nil
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,65 @@
+package command
+
+import (
+	"database/sql"
+
+	"github.com/PedroPereiraN/go-hexagonal/config"
+	"github.com/urfave/cli/v2"
+)
+
+// app keeps what every command needs, the database is only opened when a command runs
+type app struct {
+	cfg config.Config
+	db *sql.DB
+	openDB func(url string) (*sql.DB, error)
+}
+
+// NewApp builds the admin cli, openDB opens the database of the --database-url flag before
+// a command runs and it is closed once the command is over
+func NewApp(cfg config.Config, openDB func(url string) (*sql.DB, error)) *cli.App {
+	application := &app{cfg: cfg, openDB: openDB}
+
+	return &cli.App{
+		Name: "go-hexagonal",
+		Usage: "serve the api and manage users from the command line",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name: "database-url",
+				Usage: "postgres connection string",
+				EnvVars: []string{"DATABASE_URL"},
+				Value: cfg.DatabaseURL,
+			},
+		},
+		Before: application.open,
+		After: application.close,
+		Commands: []*cli.Command{
+			application.serveCommand(),
+			application.migrateCommand(),
+			application.userCommand(),
+			application.tokenCommand(),
+			application.apiKeyCommand(),
+		},
+	}
+}
+
+func (application *app) open(c *cli.Context) error {
+	application.cfg.DatabaseURL = c.String("database-url")
+
+	db, err := application.openDB(application.cfg.DatabaseURL)
+
+	if err != nil {
+		return err
+	}
+
+	application.db = db
+
+	return nil
+}
+
+func (application *app) close(c *cli.Context) error {
+	if application.db == nil {
+		return nil
+	}
+
+	return application.db.Close()
+}
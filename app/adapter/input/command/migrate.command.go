@@ -0,0 +1,77 @@
+package command
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/repository"
+	"github.com/urfave/cli/v2"
+)
+
+func (application *app) migrateCommand() *cli.Command {
+	return &cli.Command{
+		Name: "migrate",
+		Usage: "manage the database schema",
+		Subcommands: []*cli.Command{
+			{
+				Name: "up",
+				Usage: "apply every pending migration",
+				Action: func(c *cli.Context) error {
+					applied, err := repository.NewMigrator(application.db).Up()
+
+					for _, migration := range applied {
+						fmt.Fprintf(c.App.Writer, "applied %d %s\n", migration.Version, migration.Name)
+					}
+
+					if err != nil {
+						return err
+					}
+
+					if len(applied) == 0 {
+						fmt.Fprintln(c.App.Writer, "database is up to date")
+					}
+
+					return nil
+				},
+			},
+			{
+				Name: "down",
+				Usage: "revert the last applied migration",
+				Action: func(c *cli.Context) error {
+					reverted, err := repository.NewMigrator(application.db).Down()
+
+					if err != nil {
+						return err
+					}
+
+					fmt.Fprintf(c.App.Writer, "reverted %d %s\n", reverted.Version, reverted.Name)
+
+					return nil
+				},
+			},
+			{
+				Name: "status",
+				Usage: "list the migrations and whether they were applied",
+				Action: func(c *cli.Context) error {
+					status, err := repository.NewMigrator(application.db).Status()
+
+					if err != nil {
+						return err
+					}
+
+					for _, migration := range status {
+						appliedAt := "pending"
+
+						if migration.Applied {
+							appliedAt = migration.AppliedAt.Format(time.RFC3339)
+						}
+
+						fmt.Fprintf(c.App.Writer, "%4d  %-30s  %s\n", migration.Version, migration.Name, appliedAt)
+					}
+
+					return nil
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,27 @@
+package command
+
+import (
+	"github.com/PedroPereiraN/go-hexagonal/server"
+	"github.com/urfave/cli/v2"
+)
+
+func (application *app) serveCommand() *cli.Command {
+	return &cli.Command{
+		Name: "serve",
+		Usage: "start the http api",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name: "addr",
+				Usage: "address to listen on",
+				Value: ":8080",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if err := application.db.Ping(); err != nil {
+				return err
+			}
+
+			return server.Run(application.db, application.cfg, c.String("addr"))
+		},
+	}
+}
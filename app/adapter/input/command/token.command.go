@@ -0,0 +1,50 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/server"
+	"github.com/PedroPereiraN/go-hexagonal/services"
+	"github.com/google/uuid"
+	"github.com/urfave/cli/v2"
+)
+
+func (application *app) tokenCommand() *cli.Command {
+	return &cli.Command{
+		Name: "token",
+		Usage: "manage access tokens",
+		Subcommands: []*cli.Command{
+			{
+				Name: "issue",
+				Usage: "issue a token for an user without his password",
+				Flags: []cli.Flag{idFlag},
+				Action: func(c *cli.Context) error {
+					id, err := uuid.Parse(c.String("id"))
+
+					if err != nil {
+						return err
+					}
+
+					keyRing, err := server.NewKeyRing(application.db, application.cfg)
+
+					if err != nil {
+						return err
+					}
+
+					service.SetKeyRing(keyRing)
+
+					token, err := server.NewUserService(application.db, application.cfg).IssueToken(id, domain.Client{UserAgent: "admin cli"})
+
+					if err != nil {
+						return err
+					}
+
+					fmt.Fprintln(c.App.Writer, token)
+
+					return nil
+				},
+			},
+		},
+	}
+}
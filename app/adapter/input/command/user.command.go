@@ -0,0 +1,349 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/exporter"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/importer"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/server"
+	"github.com/google/uuid"
+	"github.com/urfave/cli/v2"
+)
+
+var idFlag = &cli.StringFlag{
+	Name: "id",
+	Usage: "user id",
+	Required: true,
+}
+
+func (application *app) userCommand() *cli.Command {
+	return &cli.Command{
+		Name: "user",
+		Usage: "manage users",
+		Subcommands: []*cli.Command{
+			{
+				Name: "create",
+				Usage: "create a new user",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "name", Required: true},
+					&cli.StringFlag{Name: "email", Required: true},
+					&cli.StringFlag{Name: "phone", Required: true},
+					&cli.StringFlag{Name: "password", Required: true},
+				},
+				Action: func(c *cli.Context) error {
+					id, err := server.NewUserService(application.db, application.cfg).Create(domain.UserDomain{
+						Name: c.String("name"),
+						Email: c.String("email"),
+						Phone: c.String("phone"),
+						Password: c.String("password"),
+					})
+
+					if err != nil {
+						return err
+					}
+
+					fmt.Fprintln(c.App.Writer, id)
+
+					return nil
+				},
+			},
+			{
+				Name: "list",
+				Usage: "list the active users",
+				Action: func(c *cli.Context) error {
+					users, err := server.NewUserService(application.db, application.cfg).ListAll()
+
+					if err != nil {
+						return err
+					}
+
+					printUsers(c, users)
+
+					return nil
+				},
+			},
+			{
+				Name: "get",
+				Usage: "show one user",
+				Flags: []cli.Flag{idFlag},
+				Action: func(c *cli.Context) error {
+					id, err := uuid.Parse(c.String("id"))
+
+					if err != nil {
+						return err
+					}
+
+					user, err := server.NewUserService(application.db, application.cfg).List(id)
+
+					if err != nil {
+						return err
+					}
+
+					printUsers(c, []domain.UserDomain{user})
+
+					return nil
+				},
+			},
+			{
+				Name: "delete",
+				Usage: "soft delete an user",
+				Flags: []cli.Flag{idFlag},
+				Action: func(c *cli.Context) error {
+					id, err := uuid.Parse(c.String("id"))
+
+					if err != nil {
+						return err
+					}
+
+					result, err := server.NewUserService(application.db, application.cfg).Delete(id)
+
+					if err != nil {
+						return err
+					}
+
+					fmt.Fprintln(c.App.Writer, "User deleted successfully:", result)
+
+					return nil
+				},
+			},
+			{
+				Name: "restore",
+				Usage: "restore a soft deleted user",
+				Flags: []cli.Flag{idFlag},
+				Action: func(c *cli.Context) error {
+					id, err := uuid.Parse(c.String("id"))
+
+					if err != nil {
+						return err
+					}
+
+					result, err := server.NewUserService(application.db, application.cfg).Restore(id)
+
+					if err != nil {
+						return err
+					}
+
+					fmt.Fprintln(c.App.Writer, "User restored successfully:", result)
+
+					return nil
+				},
+			},
+			{
+				Name: "set-password",
+				Usage: "replace the password of an user",
+				Flags: []cli.Flag{
+					idFlag,
+					&cli.StringFlag{Name: "password", Required: true},
+				},
+				Action: func(c *cli.Context) error {
+					id, err := uuid.Parse(c.String("id"))
+
+					if err != nil {
+						return err
+					}
+
+					result, err := server.NewUserService(application.db, application.cfg).ResetPassword(id, c.String("password"))
+
+					if err != nil {
+						return err
+					}
+
+					fmt.Fprintln(c.App.Writer, "User password updated successfully:", result)
+
+					return nil
+				},
+			},
+			{
+				Name: "set-role",
+				Usage: "change the role of an user, admin passwords expire after PASSWORD_EXPIRY_DAYS",
+				Flags: []cli.Flag{
+					idFlag,
+					&cli.StringFlag{Name: "role", Usage: "user or admin", Required: true},
+				},
+				Action: func(c *cli.Context) error {
+					id, err := uuid.Parse(c.String("id"))
+
+					if err != nil {
+						return err
+					}
+
+					result, err := server.NewUserService(application.db, application.cfg).SetRole(id, c.String("role"))
+
+					if err != nil {
+						return err
+					}
+
+					fmt.Fprintln(c.App.Writer, "User role updated successfully:", result)
+
+					return nil
+				},
+			},
+			{
+				Name: "reset-2fa",
+				Usage: "disable two-factor of an user that lost the authenticator",
+				Flags: []cli.Flag{idFlag},
+				Action: func(c *cli.Context) error {
+					id, err := uuid.Parse(c.String("id"))
+
+					if err != nil {
+						return err
+					}
+
+					tfService, err := server.NewTwoFactorService(application.db, application.cfg)
+
+					if err != nil {
+						return err
+					}
+
+					if err := tfService.Reset(id); err != nil {
+						return err
+					}
+
+					fmt.Fprintln(c.App.Writer, "Two-factor reset successfully:", id)
+
+					return nil
+				},
+			},
+			{
+				Name: "import",
+				Usage: "create users in bulk from a csv or ndjson file",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "file", Usage: "path of the file, - reads from stdin", Required: true},
+					&cli.StringFlag{Name: "format", Usage: "csv or ndjson, detected from the file extension when omitted"},
+					&cli.StringFlag{Name: "mode", Usage: "all-or-nothing or best-effort", Value: domain.ImportBestEffort},
+					&cli.BoolFlag{Name: "dry-run", Usage: "validate the file without creating users"},
+				},
+				Action: func(c *cli.Context) error {
+					source := io.Reader(os.Stdin)
+
+					if c.String("file") != "-" {
+						file, err := os.Open(c.String("file"))
+
+						if err != nil {
+							return err
+						}
+
+						defer file.Close()
+
+						source = file
+					}
+
+					format := c.String("format")
+
+					if format == "" {
+						format = importer.DetectFormat(c.String("file"), "")
+					}
+
+					rows, err := importer.NewUserReader(format, source)
+
+					if err != nil {
+						return err
+					}
+
+					report, err := server.NewUserService(application.db, application.cfg).Import(rows, c.String("mode"), c.Bool("dry-run"))
+
+					if err != nil {
+						return err
+					}
+
+					writer := tabwriter.NewWriter(c.App.Writer, 0, 0, 2, ' ', 0)
+
+					fmt.Fprintln(writer, "ROW\tSTATUS\tID\tERROR")
+
+					for _, row := range report.Rows {
+						id := ""
+
+						if row.Id != uuid.Nil {
+							id = row.Id.String()
+						}
+
+						fmt.Fprintf(writer, "%d\t%s\t%s\t%s\n", row.Row, row.Status, id, row.Error)
+					}
+
+					writer.Flush()
+
+					fmt.Fprintf(c.App.Writer, "total %d, created %d, failed %d (mode %s, dry run %t)\n", report.Total, report.Created, report.Failed, report.Mode, report.DryRun)
+
+					return nil
+				},
+			},
+			{
+				Name: "export",
+				Usage: "write the users as csv, ndjson or json without password hashes",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "format", Usage: "csv, ndjson or json", Value: exporter.FormatCSV},
+					&cli.StringFlag{Name: "output", Usage: "path of the file, - writes to stdout", Value: "-"},
+					&cli.StringFlag{Name: "created-from", Usage: "only users created at or after this date (RFC3339 or YYYY-MM-DD)"},
+					&cli.StringFlag{Name: "created-to", Usage: "only users created before this date (RFC3339 or YYYY-MM-DD)"},
+					&cli.BoolFlag{Name: "include-deleted", Usage: "include soft deleted users"},
+				},
+				Action: func(c *cli.Context) error {
+					filter := domain.UserFilter{IncludeDeleted: c.Bool("include-deleted")}
+
+					var err error
+
+					if filter.CreatedFrom, err = exporter.ParseDate(c.String("created-from")); err != nil {
+						return err
+					}
+
+					if filter.CreatedTo, err = exporter.ParseDate(c.String("created-to")); err != nil {
+						return err
+					}
+
+					destination := c.App.Writer
+
+					if c.String("output") != "-" {
+						file, err := os.Create(c.String("output"))
+
+						if err != nil {
+							return err
+						}
+
+						defer file.Close()
+
+						destination = file
+					}
+
+					writer, err := exporter.NewUserWriter(c.String("format"), destination)
+
+					if err != nil {
+						return err
+					}
+
+					iterator, err := server.NewUserService(application.db, application.cfg).Export(filter)
+
+					if err != nil {
+						return err
+					}
+
+					count, err := exporter.Export(iterator, writer, nil)
+
+					if err != nil {
+						return err
+					}
+
+					fmt.Fprintf(c.App.ErrWriter, "%d users exported\n", count)
+
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func printUsers(c *cli.Context, users []domain.UserDomain) {
+	writer := tabwriter.NewWriter(c.App.Writer, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(writer, "ID\tNAME\tEMAIL\tPHONE\tCREATED AT")
+
+	for _, user := range users {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", user.Id, user.Name, user.Email, user.Phone, user.CreatedAt.Format(time.RFC3339))
+	}
+
+	writer.Flush()
+}
@@ -0,0 +1,266 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/middleware"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/model"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/input"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func NewAPIKeyController(
+	service port.APIKeyService,
+) APIKeyController {
+	return &apiKeyController{
+		service: service,
+	}
+}
+
+type APIKeyController interface {
+	CreateMine(c *gin.Context)
+	ListMine(c *gin.Context)
+	RevokeMine(c *gin.Context)
+	List(c *gin.Context)
+	Revoke(c *gin.Context)
+}
+
+type apiKeyController struct {
+	service port.APIKeyService
+}
+
+// @Summary create API key
+// @Description create an API key for the authenticated user, the key is only returned here
+// @Tags me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param apiKey body model.CreateAPIKeyModel true "API key"
+// @Success 201 {object} model.APIKeyModel
+// @Failure 400 "invalid values"
+// @Failure 401 "Invalid token"
+// @Failure 403 "API keys can't manage API keys"
+// @Failure 500 "Internal server error"
+// @Router /v1/me/api-keys [post]
+func (controller *apiKeyController) CreateMine(c *gin.Context) {
+	identity, ok := userIdentity(c, "API keys can't manage API keys")
+
+	if !ok {
+		return
+	}
+
+	var apiKeyData model.CreateAPIKeyModel
+
+	if err := c.ShouldBindJSON(&apiKeyData); err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	result, secret, err := controller.service.Create(domain.APIKey{
+		UserId: identity.UserId,
+		Name: apiKeyData.Name,
+		Scopes: apiKeyData.Scopes,
+		ExpiresAt: apiKeyData.ExpiresAt,
+	})
+
+	if err != nil {
+		apiKeyError(c, err)
+
+		return
+	}
+
+	response := toAPIKeyModel(result)
+	response.Key = secret
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// @Summary list my API keys
+// @Description list the API keys of the authenticated user, including revoked and expired ones
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.APIKeyModel
+// @Failure 401 "Invalid token"
+// @Failure 403 "API keys can't manage API keys"
+// @Failure 500 "Internal server error"
+// @Router /v1/me/api-keys [get]
+func (controller *apiKeyController) ListMine(c *gin.Context) {
+	identity, ok := userIdentity(c, "API keys can't manage API keys")
+
+	if !ok {
+		return
+	}
+
+	controller.list(c, identity.UserId)
+}
+
+// @Summary revoke my API key
+// @Description revoke one of the API keys of the authenticated user
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "API key id"
+// @Success 200 "API key revoked successfully"
+// @Failure 400 "Invalid id"
+// @Failure 401 "Invalid token"
+// @Failure 403 "API keys can't manage API keys"
+// @Failure 404 "API key not found"
+// @Failure 500 "Internal server error"
+// @Router /v1/me/api-keys/{id} [delete]
+func (controller *apiKeyController) RevokeMine(c *gin.Context) {
+	identity, ok := userIdentity(c, "API keys can't manage API keys")
+
+	if !ok {
+		return
+	}
+
+	keyId, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid id")
+
+		return
+	}
+
+	controller.revoke(c, identity.UserId, keyId)
+}
+
+// @Summary list user API keys
+// @Description list the API keys of any user
+// @Tags admin
+// @Produce json
+// @Param id query string true "user id"
+// @Security BearerAuth
+// @Success 200 {array} model.APIKeyModel
+// @Failure 400 "Invalid id"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 404 "User not found"
+// @Failure 500 "Internal server error"
+// @Router /admin/user/api-keys [get]
+func (controller *apiKeyController) List(c *gin.Context) {
+	userId, ok := queryUserId(c)
+
+	if !ok {
+		return
+	}
+
+	controller.list(c, userId)
+}
+
+// @Summary revoke user API key
+// @Description revoke an API key of any user
+// @Tags admin
+// @Produce json
+// @Param id query string true "user id"
+// @Param keyId query string true "API key id"
+// @Security BearerAuth
+// @Success 200 "API key revoked successfully"
+// @Failure 400 "Invalid id"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 404 "API key not found"
+// @Failure 500 "Internal server error"
+// @Router /admin/user/api-keys [delete]
+func (controller *apiKeyController) Revoke(c *gin.Context) {
+	userId, ok := queryUserId(c)
+
+	if !ok {
+		return
+	}
+
+	keyId, err := uuid.Parse(c.Query("keyId"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid API key id")
+
+		return
+	}
+
+	controller.revoke(c, userId, keyId)
+}
+
+func (controller *apiKeyController) list(c *gin.Context, userId uuid.UUID) {
+	result, err := controller.service.List(userId)
+
+	if err != nil {
+		apiKeyError(c, err)
+
+		return
+	}
+
+	keys := []model.APIKeyModel{}
+
+	for _, key := range result {
+		keys = append(keys, toAPIKeyModel(key))
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+func (controller *apiKeyController) revoke(c *gin.Context, userId uuid.UUID, keyId uuid.UUID) {
+	if err := controller.service.Revoke(userId, keyId); err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			c.JSON(http.StatusNotFound, "API key not found")
+
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	c.JSON(http.StatusOK, "API key revoked successfully: " + keyId.String())
+}
+
+// userIdentity is the caller of the /me routes that only a user token may call, an API key
+// creating more keys would escape its own scopes and expiration, reason is the 403 answered to keys
+func userIdentity(c *gin.Context, reason string) (domain.Identity, bool) {
+	identity, ok := middleware.CurrentIdentity(c)
+
+	if !ok {
+		c.JSON(http.StatusUnauthorized, "Invalid token")
+
+		return domain.Identity{}, false
+	}
+
+	if identity.APIKeyId != uuid.Nil {
+		c.JSON(http.StatusForbidden, reason)
+
+		return domain.Identity{}, false
+	}
+
+	return identity, true
+}
+
+func apiKeyError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "sql: no rows in result set":
+		c.JSON(http.StatusNotFound, "User not found")
+	case err.Error() == "Inform at least one scope", err.Error() == "Expiration must be in the future", strings.HasPrefix(err.Error(), "Invalid scope"):
+		c.JSON(http.StatusBadRequest, err.Error())
+	default:
+		c.JSON(http.StatusInternalServerError, err.Error())
+	}
+}
+
+func toAPIKeyModel(key domain.APIKey) model.APIKeyModel {
+	return model.APIKeyModel{
+		Id: key.Id.String(),
+		Name: key.Name,
+		Prefix: key.Prefix,
+		Scopes: key.Scopes,
+		CreatedAt: key.CreatedAt,
+		ExpiresAt: key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt: key.RevokedAt,
+		Active: key.Active(time.Now()),
+	}
+}
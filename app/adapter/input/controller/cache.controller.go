@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/model"
+	"github.com/PedroPereiraN/go-hexagonal/ports/input"
+	"github.com/gin-gonic/gin"
+)
+
+func NewCacheController(
+	provider port.CacheStatsProvider,
+) CacheController {
+	return &cacheController{
+		provider: provider,
+	}
+}
+
+type CacheController interface {
+	Stats(c *gin.Context)
+}
+
+type cacheController struct {
+	provider port.CacheStatsProvider
+}
+
+// @Summary user cache stats
+// @Description hits, misses and invalidations of the user repository cache since the server started
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.CacheStatsModel
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Router /admin/cache/stats [get]
+func (controller *cacheController) Stats(c *gin.Context) {
+	stats := controller.provider.Stats()
+
+	c.JSON(http.StatusOK, model.CacheStatsModel{
+		Hits: stats.Hits,
+		Misses: stats.Misses,
+		Shared: stats.Shared,
+		Invalidations: stats.Invalidations,
+		HitRatio: stats.HitRatio(),
+	})
+}
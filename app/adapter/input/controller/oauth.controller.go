@@ -0,0 +1,609 @@
+package controller
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/middleware"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/model"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/input"
+	"github.com/gin-gonic/gin"
+)
+
+// authorizePage asks for the credentials and the consent at once, the authorization server
+// keeps no login cookie so the user signs in on every authorization
+var authorizePage = template.Must(template.New("authorize").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Authorize {{.ClientName}}</title>
+</head>
+<body>
+	{{if .Fatal}}
+	<h1>Authorization failed</h1>
+	<p>{{.Error}}</p>
+	{{else}}
+	<h1>{{.ClientName}} wants to access your account</h1>
+	{{if .Scopes}}
+	<ul>
+		{{range .Scopes}}<li>{{.}}</li>{{end}}
+	</ul>
+	{{end}}
+	{{if .Error}}<p role="alert">{{.Error}}</p>{{end}}
+	<form method="post" action="/oauth/authorize">
+		<input type="hidden" name="response_type" value="{{.Request.ResponseType}}">
+		<input type="hidden" name="client_id" value="{{.Request.ClientId}}">
+		<input type="hidden" name="redirect_uri" value="{{.Request.RedirectURI}}">
+		<input type="hidden" name="scope" value="{{.Request.Scope}}">
+		<input type="hidden" name="state" value="{{.Request.State}}">
+		<input type="hidden" name="code_challenge" value="{{.Request.CodeChallenge}}">
+		<input type="hidden" name="code_challenge_method" value="{{.Request.CodeChallengeMethod}}">
+		<input type="hidden" name="nonce" value="{{.Request.Nonce}}">
+		{{if .ChallengeToken}}
+		<input type="hidden" name="challenge_token" value="{{.ChallengeToken}}">
+		<label>Two-factor code <input name="code" autocomplete="one-time-code" required></label>
+		{{else}}
+		<label>Email <input type="email" name="email" autocomplete="username" required></label>
+		<label>Password <input type="password" name="password" autocomplete="current-password" required></label>
+		{{end}}
+		<button type="submit" name="action" value="approve">Approve</button>
+		<button type="submit" name="action" value="deny" formnovalidate>Deny</button>
+	</form>
+	{{end}}
+</body>
+</html>
+`))
+
+type authorizePageData struct {
+	ClientName string
+	Scopes []string
+	Request domain.AuthorizationRequest
+	ChallengeToken string
+	Error string
+	Fatal bool
+}
+
+func NewOAuthController(
+	service port.OAuthService,
+	users port.UserService,
+	twoFactor port.TwoFactorService,
+	apiKeys port.Authenticator,
+) OAuthController {
+	return &oauthController{
+		service: service,
+		users: users,
+		twoFactor: twoFactor,
+		apiKeys: apiKeys,
+	}
+}
+
+type OAuthController interface {
+	AuthorizePage(c *gin.Context)
+	Authorize(c *gin.Context)
+	Token(c *gin.Context)
+	Revoke(c *gin.Context)
+	Introspect(c *gin.Context)
+	CreateClient(c *gin.Context)
+	ListClients(c *gin.Context)
+	DeleteClient(c *gin.Context)
+}
+
+type oauthController struct {
+	service port.OAuthService
+	users port.UserService
+	twoFactor port.TwoFactorService
+	apiKeys port.Authenticator
+}
+
+// @Summary authorization page
+// @Description start the authorization code flow, shows the login and consent page of the client
+// @Tags oauth
+// @Produce html
+// @Param response_type query string true "code"
+// @Param client_id query string true "client id"
+// @Param redirect_uri query string false "one of the registered redirect uris"
+// @Param scope query string false "space separated scopes"
+// @Param state query string false "opaque value sent back to the client"
+// @Param code_challenge query string false "PKCE challenge, required for public clients"
+// @Param code_challenge_method query string false "S256"
+// @Param nonce query string false "OpenID Connect nonce, sent back in the ID token"
+// @Success 200 "login and consent page"
+// @Failure 302 "redirect to the client with the error"
+// @Failure 400 "Unknown client or invalid redirect uri"
+// @Router /oauth/authorize [get]
+func (controller *oauthController) AuthorizePage(c *gin.Context) {
+	request, oauthClient, err := controller.service.ValidateAuthorization(authorizationRequest(c))
+
+	if err != nil {
+		authorizeError(c, request, err)
+
+		return
+	}
+
+	renderAuthorizePage(c, http.StatusOK, oauthClient, request, "", "")
+}
+
+// @Summary authorize
+// @Description log the user in with the page form and redirect back to the client with the code, or with access_denied when the user denied it
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce html
+// @Success 302 "redirect to the client with code and state"
+// @Failure 400 "Unknown client or invalid redirect uri"
+// @Failure 401 "Invalid email, password or two-factor code"
+// @Failure 403 "Password expired or account not active"
+// @Failure 500 "Internal server error"
+// @Router /oauth/authorize [post]
+func (controller *oauthController) Authorize(c *gin.Context) {
+	request, oauthClient, err := controller.service.ValidateAuthorization(authorizationRequest(c))
+
+	if err != nil {
+		authorizeError(c, request, err)
+
+		return
+	}
+
+	if c.PostForm("action") != "approve" {
+		redirectWithError(c, request, domain.NewOAuthError(domain.OAuthAccessDenied, "The user denied the request"))
+
+		return
+	}
+
+	var result domain.LoginResult
+
+	if challenge := c.PostForm("challenge_token"); challenge != "" {
+		result, err = controller.twoFactor.CompleteLogin(challenge, c.PostForm("code"), client(c))
+
+		switch {
+		case err != nil && err.Error() == "Invalid two-factor code":
+			renderAuthorizePage(c, http.StatusUnauthorized, oauthClient, request, challenge, err.Error())
+
+			return
+		case err != nil && err.Error() == "Invalid challenge token":
+			renderAuthorizePage(c, http.StatusUnauthorized, oauthClient, request, "", "The login expired, sign in again")
+
+			return
+		}
+	} else {
+		result, err = controller.users.Login(c.PostForm("email"), c.PostForm("password"), client(c))
+
+		if err != nil && (err.Error() == "Wrong password" || err.Error() == "sql: no rows in result set") {
+			renderAuthorizePage(c, http.StatusUnauthorized, oauthClient, request, "", "Invalid email or password")
+
+			return
+		}
+	}
+
+	if err != nil && isAccountStatusError(err) {
+		renderAuthorizePage(c, http.StatusForbidden, oauthClient, request, "", err.Error())
+
+		return
+	}
+
+	if err != nil {
+		authorizeError(c, request, err)
+
+		return
+	}
+
+	if result.TwoFactorRequired {
+		renderAuthorizePage(c, http.StatusOK, oauthClient, request, result.ChallengeToken, "")
+
+		return
+	}
+
+	// the page has no form to change the password, the user changes it through the login api first
+	if result.PasswordExpired {
+		renderAuthorizePage(c, http.StatusForbidden, oauthClient, request, "", "Your password expired, change it before signing in")
+
+		return
+	}
+
+	code, err := controller.service.Authorize(request, result.UserId)
+
+	if err != nil {
+		authorizeError(c, request, err)
+
+		return
+	}
+
+	redirectTo(c, request, url.Values{"code": {code}})
+}
+
+// @Summary token
+// @Description exchange an authorization code, a refresh token or the client credentials for an access token, clients authenticate with HTTP basic or client_id and client_secret
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "authorization_code, refresh_token or client_credentials"
+// @Param code formData string false "authorization code"
+// @Param redirect_uri formData string false "redirect uri of the authorization"
+// @Param code_verifier formData string false "PKCE verifier"
+// @Param refresh_token formData string false "refresh token"
+// @Param scope formData string false "space separated scopes"
+// @Success 200 {object} model.OAuthTokenModel
+// @Failure 400 {object} model.OAuthErrorModel
+// @Failure 401 {object} model.OAuthErrorModel
+// @Failure 500 {object} model.OAuthErrorModel
+// @Router /oauth/token [post]
+func (controller *oauthController) Token(c *gin.Context) {
+	clientId, clientSecret := clientCredentials(c)
+
+	result, err := controller.service.Token(domain.TokenRequest{
+		GrantType: c.PostForm("grant_type"),
+		ClientId: clientId,
+		ClientSecret: clientSecret,
+		Code: c.PostForm("code"),
+		RedirectURI: c.PostForm("redirect_uri"),
+		CodeVerifier: c.PostForm("code_verifier"),
+		RefreshToken: c.PostForm("refresh_token"),
+		Scope: c.PostForm("scope"),
+	})
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	if err != nil {
+		oauthError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.OAuthTokenModel{
+		AccessToken: result.AccessToken,
+		TokenType: result.TokenType,
+		ExpiresIn: result.ExpiresIn,
+		RefreshToken: result.RefreshToken,
+		Scope: result.Scope,
+		IDToken: result.IDToken,
+	})
+}
+
+// @Summary revoke token
+// @Description revoke an access or refresh token of the client (RFC 7009), revoking a refresh token also revokes the access tokens of its grant
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "access or refresh token"
+// @Success 200 "token revoked or unknown"
+// @Failure 400 {object} model.OAuthErrorModel
+// @Failure 401 {object} model.OAuthErrorModel
+// @Failure 500 {object} model.OAuthErrorModel
+// @Router /oauth/revoke [post]
+func (controller *oauthController) Revoke(c *gin.Context) {
+	token := c.PostForm("token")
+
+	if token == "" {
+		oauthError(c, domain.NewOAuthError(domain.OAuthInvalidRequest, "Inform the token"))
+
+		return
+	}
+
+	clientId, clientSecret := clientCredentials(c)
+
+	if err := controller.service.Revoke(clientId, clientSecret, token); err != nil {
+		oauthError(c, err)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// @Summary introspect token
+// @Description describe an access or refresh token of this server (RFC 7662), the caller authenticates as a confidential client or with an API key with the tokens:introspect scope. Revoked and expired tokens and tokens of deleted users are inactive
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param X-API-Key header string false "API key, instead of the client credentials"
+// @Param token formData string true "access or refresh token"
+// @Param token_type_hint formData string false "access_token or refresh_token, not needed"
+// @Success 200 {object} model.OAuthIntrospectionModel
+// @Failure 400 {object} model.OAuthErrorModel
+// @Failure 401 {object} model.OAuthErrorModel
+// @Failure 403 {object} model.OAuthErrorModel
+// @Failure 500 {object} model.OAuthErrorModel
+// @Router /oauth/introspect [post]
+func (controller *oauthController) Introspect(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	if apiKey := c.GetHeader(middleware.APIKeyHeader); apiKey != "" {
+		identity, err := controller.apiKeys.Authenticate(apiKey)
+
+		if err != nil {
+			if err.Error() != "Invalid API key" {
+				oauthError(c, err)
+
+				return
+			}
+
+			oauthError(c, domain.NewOAuthError(domain.OAuthInvalidClient, err.Error()))
+
+			return
+		}
+
+		if !identity.HasScope(domain.ScopeTokensIntrospect) {
+			c.JSON(http.StatusForbidden, model.OAuthErrorModel{Error: "insufficient_scope", ErrorDescription: "Missing scope: " + domain.ScopeTokensIntrospect})
+
+			return
+		}
+	} else if _, err := controller.service.AuthenticateClient(clientCredentials(c)); err != nil {
+		oauthError(c, err)
+
+		return
+	}
+
+	token := c.PostForm("token")
+
+	if token == "" {
+		oauthError(c, domain.NewOAuthError(domain.OAuthInvalidRequest, "Inform the token"))
+
+		return
+	}
+
+	result, err := controller.service.Introspect(token)
+
+	if err != nil {
+		oauthError(c, err)
+
+		return
+	}
+
+	if !result.Active {
+		c.JSON(http.StatusOK, model.OAuthIntrospectionModel{})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.OAuthIntrospectionModel{
+		Active: true,
+		TokenType: result.TokenType,
+		Scope: result.Scope,
+		ClientId: result.ClientId,
+		Username: result.Username,
+		Subject: result.Subject,
+		Issuer: result.Issuer,
+		TokenId: result.TokenId,
+		IssuedAt: result.IssuedAt.Unix(),
+		ExpiresAt: result.ExpiresAt.Unix(),
+	})
+}
+
+// @Summary create OAuth client
+// @Description register an application that signs users in with this api, the secret of confidential clients is only returned here
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param client body model.CreateOAuthClientModel true "client"
+// @Security BearerAuth
+// @Success 201 {object} model.OAuthClientModel
+// @Failure 400 "invalid values"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 500 "Internal server error"
+// @Router /admin/oauth/client [post]
+func (controller *oauthController) CreateClient(c *gin.Context) {
+	var clientData model.CreateOAuthClientModel
+
+	if err := c.ShouldBindJSON(&clientData); err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	result, secret, err := controller.service.CreateClient(domain.OAuthClient{
+		Name: clientData.Name,
+		RedirectURIs: clientData.RedirectURIs,
+		GrantTypes: clientData.GrantTypes,
+		Scopes: clientData.Scopes,
+	}, !clientData.Public)
+
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "Inform"), strings.HasPrefix(err.Error(), "Invalid"), strings.HasPrefix(err.Error(), "Public clients"):
+			c.JSON(http.StatusBadRequest, err.Error())
+		default:
+			c.JSON(http.StatusInternalServerError, err.Error())
+		}
+
+		return
+	}
+
+	response := toOAuthClientModel(result)
+	response.Secret = secret
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// @Summary list OAuth clients
+// @Description list the registered OAuth clients
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.OAuthClientModel
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 500 "Internal server error"
+// @Router /admin/oauth/client [get]
+func (controller *oauthController) ListClients(c *gin.Context) {
+	result, err := controller.service.ListClients()
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	clients := []model.OAuthClientModel{}
+
+	for _, client := range result {
+		clients = append(clients, toOAuthClientModel(client))
+	}
+
+	c.JSON(http.StatusOK, clients)
+}
+
+// @Summary delete OAuth client
+// @Description delete an OAuth client along with its codes and tokens
+// @Tags admin
+// @Produce json
+// @Param id query string true "client id"
+// @Security BearerAuth
+// @Success 200 "Client deleted successfully"
+// @Failure 400 "Unspecified client"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 404 "Client not found"
+// @Failure 500 "Internal server error"
+// @Router /admin/oauth/client [delete]
+func (controller *oauthController) DeleteClient(c *gin.Context) {
+	clientId := c.Query("id")
+
+	if clientId == "" {
+		c.JSON(http.StatusBadRequest, "Unspecified client")
+
+		return
+	}
+
+	if err := controller.service.DeleteClient(clientId); err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			c.JSON(http.StatusNotFound, "Client not found")
+
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	c.JSON(http.StatusOK, "Client deleted successfully: " + clientId)
+}
+
+// authorizationRequest reads the parameters from the query of the GET and from the form of the POST
+func authorizationRequest(c *gin.Context) domain.AuthorizationRequest {
+	value := c.Query
+
+	if c.Request.Method == http.MethodPost {
+		value = c.PostForm
+	}
+
+	return domain.AuthorizationRequest{
+		ResponseType: value("response_type"),
+		ClientId: value("client_id"),
+		RedirectURI: value("redirect_uri"),
+		Scope: value("scope"),
+		State: value("state"),
+		CodeChallenge: value("code_challenge"),
+		CodeChallengeMethod: value("code_challenge_method"),
+		Nonce: value("nonce"),
+	}
+}
+
+// authorizeError only redirects when the redirect uri was validated, otherwise the page
+// would be an open redirect
+func authorizeError(c *gin.Context, request domain.AuthorizationRequest, err error) {
+	var oauthErr *domain.OAuthError
+
+	if !errors.As(err, &oauthErr) {
+		renderAuthorizePage(c, http.StatusInternalServerError, domain.OAuthClient{}, request, "", "Internal server error")
+
+		return
+	}
+
+	if request.RedirectURI == "" {
+		renderAuthorizePage(c, http.StatusBadRequest, domain.OAuthClient{}, request, "", oauthErr.Description)
+
+		return
+	}
+
+	redirectWithError(c, request, oauthErr)
+}
+
+func redirectWithError(c *gin.Context, request domain.AuthorizationRequest, err *domain.OAuthError) {
+	redirectTo(c, request, url.Values{"error": {err.Code}, "error_description": {err.Description}})
+}
+
+func redirectTo(c *gin.Context, request domain.AuthorizationRequest, params url.Values) {
+	location, _ := url.Parse(request.RedirectURI)
+	query := location.Query()
+
+	for key, values := range params {
+		query[key] = values
+	}
+
+	if request.State != "" {
+		query.Set("state", request.State)
+	}
+
+	location.RawQuery = query.Encode()
+
+	c.Redirect(http.StatusFound, location.String())
+}
+
+func renderAuthorizePage(c *gin.Context, status int, client domain.OAuthClient, request domain.AuthorizationRequest, challenge string, message string) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Frame-Options", "DENY")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(status)
+
+	authorizePage.Execute(c.Writer, authorizePageData{
+		ClientName: client.Name,
+		Scopes: strings.Fields(request.Scope),
+		Request: request,
+		ChallengeToken: challenge,
+		Error: message,
+		Fatal: status == http.StatusBadRequest || status == http.StatusInternalServerError,
+	})
+}
+
+// clientCredentials prefers HTTP basic, whose values are form encoded as RFC 6749 section 2.3.1 asks
+func clientCredentials(c *gin.Context) (string, string) {
+	clientId, clientSecret, ok := c.Request.BasicAuth()
+
+	if !ok {
+		return c.PostForm("client_id"), c.PostForm("client_secret")
+	}
+
+	if unescaped, err := url.QueryUnescape(clientId); err == nil {
+		clientId = unescaped
+	}
+
+	if unescaped, err := url.QueryUnescape(clientSecret); err == nil {
+		clientSecret = unescaped
+	}
+
+	return clientId, clientSecret
+}
+
+func oauthError(c *gin.Context, err error) {
+	var oauthErr *domain.OAuthError
+
+	if !errors.As(err, &oauthErr) {
+		c.JSON(http.StatusInternalServerError, model.OAuthErrorModel{Error: "server_error"})
+
+		return
+	}
+
+	if oauthErr.Status() == http.StatusUnauthorized {
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+
+	c.JSON(oauthErr.Status(), model.OAuthErrorModel{Error: oauthErr.Code, ErrorDescription: oauthErr.Description})
+}
+
+func toOAuthClientModel(client domain.OAuthClient) model.OAuthClientModel {
+	return model.OAuthClientModel{
+		Id: client.Id,
+		Name: client.Name,
+		RedirectURIs: client.RedirectURIs,
+		GrantTypes: client.GrantTypes,
+		Scopes: client.Scopes,
+		Public: !client.Confidential(),
+		CreatedAt: client.CreatedAt,
+	}
+}
@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/model"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/input"
+	"github.com/gin-gonic/gin"
+)
+
+func NewOIDCController(
+	issuer string,
+	service port.OAuthService,
+	keys port.KeySet,
+) OIDCController {
+	return &oidcController{
+		issuer: issuer,
+		service: service,
+		keys: keys,
+	}
+}
+
+type OIDCController interface {
+	Discovery(c *gin.Context)
+	JWKS(c *gin.Context)
+	UserInfo(c *gin.Context)
+}
+
+type oidcController struct {
+	issuer string
+	service port.OAuthService
+	keys port.KeySet
+}
+
+// @Summary OpenID Connect discovery
+// @Description describe the endpoints and the capabilities of the authorization server
+// @Tags oauth
+// @Produce json
+// @Success 200 {object} model.OIDCConfigurationModel
+// @Router /.well-known/openid-configuration [get]
+func (controller *oidcController) Discovery(c *gin.Context) {
+	c.JSON(http.StatusOK, model.OIDCConfigurationModel{
+		Issuer: controller.issuer,
+		AuthorizationEndpoint: controller.issuer + "/oauth/authorize",
+		TokenEndpoint: controller.issuer + "/oauth/token",
+		UserInfoEndpoint: controller.issuer + "/userinfo",
+		RevocationEndpoint: controller.issuer + "/oauth/revoke",
+		IntrospectionEndpoint: controller.issuer + "/oauth/introspect",
+		JWKSURI: controller.issuer + "/.well-known/jwks.json",
+		ResponseTypesSupported: []string{"code"},
+		GrantTypesSupported: domain.OAuthGrantTypes,
+		SubjectTypesSupported: []string{"public"},
+		IDTokenSigningAlgValuesSupported: controller.keys.Algorithms(),
+		ScopesSupported: domain.OIDCScopes,
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported: []string{domain.PKCEMethodS256},
+		ClaimsSupported: []string{"iss", "sub", "aud", "exp", "iat", "nonce", "name", "email", "email_verified", "phone_number", "phone_number_verified"},
+	})
+}
+
+// @Summary JSON Web Key Set
+// @Description list the public keys that verify the tokens, a key is published before it signs and until the tokens it signed expire. It's empty when tokens are signed with a shared secret
+// @Tags oauth
+// @Produce json
+// @Success 200 {object} model.JWKSModel
+// @Router /.well-known/jwks.json [get]
+func (controller *oidcController) JWKS(c *gin.Context) {
+	keys := []model.JWKModel{}
+
+	for _, key := range controller.keys.PublicKeys() {
+		keys = append(keys, model.JWKModel{
+			Kty: key.Kty,
+			Kid: key.Kid,
+			Use: key.Use,
+			Alg: key.Alg,
+			N: key.N,
+			E: key.E,
+			Crv: key.Crv,
+			X: key.X,
+			Y: key.Y,
+		})
+	}
+
+	// verifiers refetch the set when they meet an unknown kid, the next key is published well before it signs
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, model.JWKSModel{Keys: keys})
+}
+
+// @Summary userinfo
+// @Description return the claims of the user released by the scopes of the OAuth access token, which must have the openid scope
+// @Tags oauth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 "claims of the user"
+// @Failure 401 "invalid_token"
+// @Failure 403 "insufficient_scope"
+// @Failure 500 "Internal server error"
+// @Router /userinfo [get]
+func (controller *oidcController) UserInfo(c *gin.Context) {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+	// RFC 6750 section 2.2 also allows the token in the form body
+	if !ok && c.Request.Method == http.MethodPost {
+		token = c.PostForm("access_token")
+	}
+
+	if token == "" {
+		c.Header("WWW-Authenticate", `Bearer`)
+		c.JSON(http.StatusUnauthorized, model.OAuthErrorModel{Error: "invalid_token"})
+
+		return
+	}
+
+	claims, err := controller.service.UserInfo(token)
+
+	if err != nil {
+		switch err.Error() {
+		case "Invalid token":
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.JSON(http.StatusUnauthorized, model.OAuthErrorModel{Error: "invalid_token", ErrorDescription: err.Error()})
+		case "Insufficient scope":
+			c.Header("WWW-Authenticate", `Bearer error="insufficient_scope", scope="openid"`)
+			c.JSON(http.StatusForbidden, model.OAuthErrorModel{Error: "insufficient_scope", ErrorDescription: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, err.Error())
+		}
+
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, claims)
+}
@@ -0,0 +1,286 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/middleware"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/model"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/input"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func NewSessionController(
+	service port.UserService,
+) SessionController {
+	return &sessionController{
+		service: service,
+	}
+}
+
+type SessionController interface {
+	ListMine(c *gin.Context)
+	RevokeMine(c *gin.Context)
+	List(c *gin.Context)
+	Revoke(c *gin.Context)
+	ListMyLogins(c *gin.Context)
+	ListLogins(c *gin.Context)
+}
+
+type sessionController struct {
+	service port.UserService
+}
+
+// @Summary list my sessions
+// @Description list where the authenticated user is logged in
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.SessionModel
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing scope: sessions"
+// @Failure 500 "Internal server error"
+// @Router /v1/me/sessions [get]
+func (controller *sessionController) ListMine(c *gin.Context) {
+	identity, ok := middleware.CurrentIdentity(c)
+
+	if !ok {
+		c.JSON(http.StatusUnauthorized, "Invalid token")
+
+		return
+	}
+
+	controller.list(c, identity.UserId, identity.SessionId)
+}
+
+// @Summary sign out a session
+// @Description revoke one of the sessions of the authenticated user, its tokens stop working right away
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "session id"
+// @Success 200 "Session revoked successfully"
+// @Failure 400 "Invalid id"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing scope: sessions"
+// @Failure 404 "Session not found"
+// @Failure 500 "Internal server error"
+// @Router /v1/me/sessions/{id} [delete]
+func (controller *sessionController) RevokeMine(c *gin.Context) {
+	identity, ok := middleware.CurrentIdentity(c)
+
+	if !ok {
+		c.JSON(http.StatusUnauthorized, "Invalid token")
+
+		return
+	}
+
+	sessionId, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid id")
+
+		return
+	}
+
+	controller.revoke(c, identity.UserId, sessionId)
+}
+
+// @Summary list user sessions
+// @Description list the active sessions of any user
+// @Tags admin
+// @Produce json
+// @Param id query string true "user id"
+// @Security BearerAuth
+// @Success 200 {array} model.SessionModel
+// @Failure 400 "Invalid id"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 404 "User not found"
+// @Failure 500 "Internal server error"
+// @Router /admin/user/sessions [get]
+func (controller *sessionController) List(c *gin.Context) {
+	userId, ok := queryUserId(c)
+
+	if !ok {
+		return
+	}
+
+	controller.list(c, userId, uuid.Nil)
+}
+
+// @Summary sign out an user session
+// @Description revoke a session of any user
+// @Tags admin
+// @Produce json
+// @Param id query string true "user id"
+// @Param sessionId query string true "session id"
+// @Security BearerAuth
+// @Success 200 "Session revoked successfully"
+// @Failure 400 "Invalid id"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 404 "Session not found"
+// @Failure 500 "Internal server error"
+// @Router /admin/user/sessions [delete]
+func (controller *sessionController) Revoke(c *gin.Context) {
+	userId, ok := queryUserId(c)
+
+	if !ok {
+		return
+	}
+
+	sessionId, err := uuid.Parse(c.Query("sessionId"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid session id")
+
+		return
+	}
+
+	controller.revoke(c, userId, sessionId)
+}
+
+// @Summary list my logins
+// @Description list the most recent login attempts of the authenticated user
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "attempts to list, 20 by default and at most 100"
+// @Success 200 {array} model.LoginAttemptModel
+// @Failure 400 "Invalid limit"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing scope: sessions"
+// @Failure 500 "Internal server error"
+// @Router /v1/me/logins [get]
+func (controller *sessionController) ListMyLogins(c *gin.Context) {
+	identity, ok := middleware.CurrentIdentity(c)
+
+	if !ok {
+		c.JSON(http.StatusUnauthorized, "Invalid token")
+
+		return
+	}
+
+	controller.listLogins(c, identity.UserId)
+}
+
+// @Summary list user logins
+// @Description list the most recent login attempts of any user, only for admins
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "user id"
+// @Param limit query int false "attempts to list, 20 by default and at most 100"
+// @Success 200 {array} model.LoginAttemptModel
+// @Failure 400 "Invalid id or limit"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin"
+// @Failure 404 "User not found"
+// @Failure 500 "Internal server error"
+// @Router /v1/users/{id}/logins [get]
+func (controller *sessionController) ListLogins(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid id")
+
+		return
+	}
+
+	controller.listLogins(c, userId)
+}
+
+func (controller *sessionController) list(c *gin.Context, userId uuid.UUID, current uuid.UUID) {
+	result, err := controller.service.ListSessions(userId)
+
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			c.JSON(http.StatusNotFound, "User not found")
+
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	sessions := []model.SessionModel{}
+
+	for _, session := range result {
+		sessions = append(sessions, toSessionModel(session, current))
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+func (controller *sessionController) listLogins(c *gin.Context, userId uuid.UUID) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid limit")
+
+		return
+	}
+
+	result, err := controller.service.ListLogins(userId, limit)
+
+	if err != nil {
+		switch err.Error() {
+		case "Invalid limit":
+			c.JSON(http.StatusBadRequest, err.Error())
+		case "sql: no rows in result set":
+			c.JSON(http.StatusNotFound, "User not found")
+		default:
+			c.JSON(http.StatusInternalServerError, err.Error())
+		}
+
+		return
+	}
+
+	attempts := []model.LoginAttemptModel{}
+
+	for _, attempt := range result {
+		attempts = append(attempts, model.LoginAttemptModel{
+			Id: attempt.Id.String(),
+			Outcome: attempt.Outcome,
+			FailureReason: attempt.FailureReason,
+			Ip: attempt.IP,
+			UserAgent: attempt.UserAgent,
+			CreatedAt: attempt.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, attempts)
+}
+
+func (controller *sessionController) revoke(c *gin.Context, userId uuid.UUID, sessionId uuid.UUID) {
+	if err := controller.service.RevokeSession(userId, sessionId); err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			c.JSON(http.StatusNotFound, "Session not found")
+
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	c.JSON(http.StatusOK, "Session revoked successfully: " + sessionId.String())
+}
+
+func toSessionModel(session domain.Session, current uuid.UUID) model.SessionModel {
+	return model.SessionModel{
+		Id: session.Id.String(),
+		UserAgent: session.UserAgent,
+		Ip: session.IP,
+		CreatedAt: session.CreatedAt,
+		LastSeenAt: session.LastSeenAt,
+		ExpiresAt: session.ExpiresAt,
+		Current: current != uuid.Nil && session.Id == current,
+	}
+}
@@ -0,0 +1,217 @@
+package controller
+
+import (
+	"encoding/base64"
+	"net/http"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/model"
+	"github.com/PedroPereiraN/go-hexagonal/ports/input"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func NewTwoFactorController(
+	service port.TwoFactorService,
+) TwoFactorController {
+	return &twoFactorController{
+		service: service,
+	}
+}
+
+type TwoFactorController interface {
+	Enroll(c *gin.Context)
+	Confirm(c *gin.Context)
+	Login(c *gin.Context)
+	Reset(c *gin.Context)
+}
+
+type twoFactorController struct {
+	service port.TwoFactorService
+}
+
+// @Summary enroll two-factor
+// @Description generate a TOTP secret for the authenticated user, two-factor is only enabled after confirming a first code
+// @Tags me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.TwoFactorEnrollmentModel
+// @Failure 401 "Invalid token"
+// @Failure 403 "API keys can't manage two-factor"
+// @Failure 404 "User not found"
+// @Failure 409 "Two-factor is already enabled"
+// @Failure 500 "Internal server error"
+// @Router /v1/me/2fa/enroll [post]
+func (controller *twoFactorController) Enroll(c *gin.Context) {
+	identity, ok := userIdentity(c, "API keys can't manage two-factor")
+
+	if !ok {
+		return
+	}
+
+	result, err := controller.service.Enroll(identity.UserId)
+
+	if err != nil {
+		switch err.Error() {
+		case "sql: no rows in result set":
+			c.JSON(http.StatusNotFound, "User not found")
+		case "Two-factor is already enabled":
+			c.JSON(http.StatusConflict, err.Error())
+		default:
+			c.JSON(http.StatusInternalServerError, err.Error())
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.TwoFactorEnrollmentModel{
+		Secret: result.Secret,
+		Uri: result.URI,
+		QrCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(result.QRCode),
+	})
+}
+
+// @Summary confirm two-factor
+// @Description enable two-factor for the authenticated user with a first code from the authenticator, the recovery codes are only returned here
+// @Tags me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code body model.TwoFactorCodeModel true "code"
+// @Success 200 {object} model.RecoveryCodesModel
+// @Failure 400 "Invalid two-factor code"
+// @Failure 401 "Invalid token"
+// @Failure 403 "API keys can't manage two-factor"
+// @Failure 404 "Two-factor enrolment not found"
+// @Failure 409 "Two-factor is already enabled"
+// @Failure 500 "Internal server error"
+// @Router /v1/me/2fa/confirm [post]
+func (controller *twoFactorController) Confirm(c *gin.Context) {
+	identity, ok := userIdentity(c, "API keys can't manage two-factor")
+
+	if !ok {
+		return
+	}
+
+	var codeData model.TwoFactorCodeModel
+
+	if err := c.ShouldBindJSON(&codeData); err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	result, err := controller.service.Confirm(identity.UserId, codeData.Code)
+
+	if err != nil {
+		switch err.Error() {
+		case "sql: no rows in result set":
+			c.JSON(http.StatusNotFound, "Two-factor enrolment not found")
+		case "Two-factor is already enabled":
+			c.JSON(http.StatusConflict, err.Error())
+		case "Invalid two-factor code":
+			c.JSON(http.StatusBadRequest, err.Error())
+		default:
+			c.JSON(http.StatusInternalServerError, err.Error())
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, model.RecoveryCodesModel{RecoveryCodes: result})
+}
+
+// @Summary login second step
+// @Description finish the login with the challenge token and a TOTP or recovery code, an expired password gets the password change token instead
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param loginInfo body model.TwoFactorLoginModel true "challenge and code"
+// @Success 200 {object} model.LoginModel
+// @Failure 400 "invalid values"
+// @Failure 401 "Invalid challenge token or code"
+// @Failure 403 "Account is suspended or deactivated"
+// @Failure 500 "Internal server error"
+// @Router /user/login/2fa [post]
+func (controller *twoFactorController) Login(c *gin.Context) {
+	var loginInfo model.TwoFactorLoginModel
+
+	if err := c.ShouldBindJSON(&loginInfo); err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	result, err := controller.service.CompleteLogin(loginInfo.ChallengeToken, loginInfo.Code, client(c))
+
+	if err != nil {
+		switch {
+		case err.Error() == "Invalid challenge token" || err.Error() == "Invalid two-factor code":
+			c.JSON(http.StatusUnauthorized, err.Error())
+		case isAccountStatusError(err):
+			c.JSON(http.StatusForbidden, err.Error())
+		default:
+			c.JSON(http.StatusInternalServerError, err.Error())
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, loginModel(result))
+}
+
+// @Summary reset two-factor
+// @Description disable two-factor and drop the recovery codes of an user that lost the authenticator
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id query string true "user id"
+// @Security BearerAuth
+// @Success 200 "Two-factor reset successfully"
+// @Failure 400 "Invalid id"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 404 "User not found"
+// @Failure 500 "Internal server error"
+// @Router /admin/user/2fa [delete]
+func (controller *twoFactorController) Reset(c *gin.Context) {
+	userId, ok := queryUserId(c)
+
+	if !ok {
+		return
+	}
+
+	if err := controller.service.Reset(userId); err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			c.JSON(http.StatusNotFound, "User not found")
+
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	c.JSON(http.StatusOK, "Two-factor reset successfully: " + userId.String())
+}
+
+func queryUserId(c *gin.Context) (uuid.UUID, bool) {
+	paramsId := c.Query("id")
+
+	if paramsId == "" {
+		c.JSON(http.StatusBadRequest, "Unspecified user")
+
+		return uuid.Nil, false
+	}
+
+	userId, err := uuid.Parse(paramsId)
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid id")
+
+		return uuid.Nil, false
+	}
+
+	return userId, true
+}
@@ -1,8 +1,16 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/exporter"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/importer"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/middleware"
 	"github.com/PedroPereiraN/go-hexagonal/adapter/input/model"
 	"github.com/PedroPereiraN/go-hexagonal/domain"
 	"github.com/PedroPereiraN/go-hexagonal/ports/input"
@@ -24,7 +32,24 @@ type UserController interface {
 	Delete(c *gin.Context)
 	Update(c *gin.Context)
 	UpdatePassword(c *gin.Context)
+	ResetPassword(c *gin.Context)
+	SetRole(c *gin.Context)
+	RequirePasswordChange(c *gin.Context)
+	Suspend(c *gin.Context)
+	Reactivate(c *gin.Context)
 	Login(c *gin.Context)
+	ChangeExpiredPassword(c *gin.Context)
+	ListDeleted(c *gin.Context)
+	ListByStatus(c *gin.Context)
+	Restore(c *gin.Context)
+	Purge(c *gin.Context)
+	Import(c *gin.Context)
+	Export(c *gin.Context)
+	Search(c *gin.Context)
+	Me(c *gin.Context)
+	UpdateMe(c *gin.Context)
+	ChangeMyPassword(c *gin.Context)
+	DeactivateMe(c *gin.Context)
 }
 
 type userController struct {
@@ -38,7 +63,7 @@ type userController struct {
 // @Produce json
 // @Param user body model.CreateUserModel true "user"
 // @Success 200 "User created successfully"
-// @Failure 400 "invalid values"
+// @Failure 400 {object} model.PasswordPolicyErrorModel "invalid values or password policy violations"
 // @Failure 500 "Internal server error"
 // @Router /user [post]
 func (controller *userController) Create(c *gin.Context) {
@@ -52,27 +77,22 @@ func (controller *userController) Create(c *gin.Context) {
 		return
 	}
 
-	uDomain, err := domain.CreateUser(
-		uuid.Nil,
-		userData.Name,
-		userData.Email,
-		userData.Phone,
-		userData.Password,
-		time.Time{},
-		time.Time{},
-		time.Time{},
-	)
-
-	if err != nil {
-		c.JSON(http.StatusBadRequest, err.Error())
+	if err := domain.CurrentPasswordPolicy().Validate(userData.Password, userData.Name, userData.Email); err != nil {
+		badRequest(c, err)
 
 		return
 	}
 
-	result, err := controller.service.Create(uDomain)
+	// the service builds the user, so the password is hashed there and only there
+	result, err := controller.service.Create(domain.UserDomain{
+		Name: userData.Name,
+		Email: userData.Email,
+		Phone: userData.Phone,
+		Password: userData.Password,
+	})
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, err.Error())
+		badRequest(c, err)
 
 		return
 	}
@@ -231,19 +251,21 @@ func (controller *userController) Update(c *gin.Context) {
 
 
 // @Summary update user password
-// @Description update an user password
+// @Description update an user password, the current password must be confirmed and the tokens issued before stop working
 // @Tags user
 // @Accept json
 // @Produce json
 // @Param id query string true "user id"
-// @Param password body model.UpdateUserPasswordModel true "new password"
+// @Param password body model.ChangePasswordModel true "current and new password"
 // @Success 200 "User password edited successfully"
-// @Failure 400 "invalid values"
+// @Failure 400 {object} model.PasswordPolicyErrorModel "invalid values, password policy violations or the current password reused"
+// @Failure 403 "Wrong password"
+// @Failure 404 "User not found"
 // @Failure 500 "Internal server error"
 // @Router /user/update-password [patch]
 func (controller *userController) UpdatePassword(c *gin.Context) {
 	paramsId := c.Query("id")
-	var userData model.UpdateUserPasswordModel
+	var userData model.ChangePasswordModel
 
 	if paramsId == "" {
 		c.JSON(http.StatusBadRequest, "Inform an ID to update user data.")
@@ -265,10 +287,10 @@ func (controller *userController) UpdatePassword(c *gin.Context) {
 		return
 	}
 
-	result, err := controller.service.UpdatePassword(userId, userData.Password)
+	result, err := controller.service.UpdatePassword(userId, userData.CurrentPassword, userData.Password)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, err.Error())
+		passwordError(c, err)
 
 		return
 	}
@@ -276,14 +298,56 @@ func (controller *userController) UpdatePassword(c *gin.Context) {
 	c.JSON(http.StatusOK, "User password updated successfully: " + result.String())
 }
 
+// @Summary reset user password
+// @Description replace the password of any user without the current one, the tokens issued before stop working
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id query string true "user id"
+// @Param password body model.UpdateUserPasswordModel true "new password"
+// @Security BearerAuth
+// @Success 200 "User password reset successfully"
+// @Failure 400 {object} model.PasswordPolicyErrorModel "invalid values, password policy violations or the current password reused"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 404 "User not found"
+// @Failure 500 "Internal server error"
+// @Router /admin/user/password [patch]
+func (controller *userController) ResetPassword(c *gin.Context) {
+	userId, ok := queryUserId(c)
+
+	if !ok {
+		return
+	}
+
+	var userData model.UpdateUserPasswordModel
+
+	if err := c.ShouldBindJSON(&userData); err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	result, err := controller.service.ResetPassword(userId, userData.Password)
+
+	if err != nil {
+		passwordError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, "User password reset successfully: " + result.String())
+}
+
 // @Summary login
 // @Description login with an user
 // @Tags user
 // @Accept json
 // @Produce json
 // @Param loginInfo body model.UserLoginModel true "user"
-// @Success 200 "User updated successfully"
+// @Success 200 {object} model.LoginModel "the token, the challenge token when the user has two-factor enabled or the password change token when the password expired"
 // @Failure 400 "invalid values"
+// @Failure 403 "Account is pending activation, suspended or deactivated"
 // @Failure 500 "Internal server error"
 // @Router /user/login [post]
 func (controller *userController) Login(c *gin.Context) {
@@ -297,15 +361,1000 @@ func (controller *userController) Login(c *gin.Context) {
 		return
 	}
 
-	result, err := controller.service.Login(loginInfo.Email, loginInfo.Password)
+	result, err := controller.service.Login(loginInfo.Email, loginInfo.Password, client(c))
 
 	if err != nil {
+		if isAccountStatusError(err) {
+			c.JSON(http.StatusForbidden, err.Error())
+
+			return
+		}
+
 		c.JSON(http.StatusBadRequest, err.Error())
 
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"token": result,
-	})
+	c.JSON(http.StatusOK, loginModel(result))
+}
+
+// @Summary change expired password
+// @Description finish a login whose password expired with the password change token and a new password, the password rules and history apply
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param loginInfo body model.ExpiredPasswordModel true "password change token and new password"
+// @Success 200 {object} model.LoginModel
+// @Failure 400 {object} model.PasswordPolicyErrorModel "invalid values, password policy violations or a recently used password"
+// @Failure 401 "Invalid password change token"
+// @Failure 403 "Account is pending activation, suspended or deactivated"
+// @Failure 500 "Internal server error"
+// @Router /user/login/password [post]
+func (controller *userController) ChangeExpiredPassword(c *gin.Context) {
+	var loginInfo model.ExpiredPasswordModel
+
+	if err := c.ShouldBindJSON(&loginInfo); err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	result, err := controller.service.ChangeExpiredPassword(loginInfo.PasswordChangeToken, loginInfo.Password, client(c))
+
+	if err != nil {
+		if err.Error() == "Invalid password change token" {
+			c.JSON(http.StatusUnauthorized, err.Error())
+
+			return
+		}
+
+		if isAccountStatusError(err) {
+			c.JSON(http.StatusForbidden, err.Error())
+
+			return
+		}
+
+		badRequest(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, loginModel(result))
+}
+
+// @Summary set user role
+// @Description change the role of an user, the password expiry follows the role
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id query string true "user id"
+// @Param role body model.UserRoleModel true "user or admin"
+// @Security BearerAuth
+// @Success 200 "User role updated successfully"
+// @Failure 400 "Invalid id or role"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 404 "User not found"
+// @Failure 500 "Internal server error"
+// @Router /admin/user/role [patch]
+func (controller *userController) SetRole(c *gin.Context) {
+	userId, ok := queryUserId(c)
+
+	if !ok {
+		return
+	}
+
+	var roleData model.UserRoleModel
+
+	if err := c.ShouldBindJSON(&roleData); err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	result, err := controller.service.SetRole(userId, roleData.Role)
+
+	if err != nil {
+		switch err.Error() {
+		case "sql: no rows in result set":
+			c.JSON(http.StatusNotFound, "User not found")
+		case "Invalid role":
+			c.JSON(http.StatusBadRequest, err.Error())
+		default:
+			c.JSON(http.StatusInternalServerError, err.Error())
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, "User role updated successfully: " + result.String())
+}
+
+// @Summary suspend user
+// @Description keep the user from signing in until he is reactivated or until the suspension ends, his sessions are revoked and his tokens and API keys are refused
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id query string true "user id"
+// @Param suspension body model.SuspendUserModel true "reason and optional end"
+// @Security BearerAuth
+// @Success 200 "User suspended successfully"
+// @Failure 400 "Invalid id, reason or end of the suspension"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 404 "User not found"
+// @Failure 409 "Invalid status transition"
+// @Failure 500 "Internal server error"
+// @Router /admin/user/suspend [patch]
+func (controller *userController) Suspend(c *gin.Context) {
+	userId, ok := queryUserId(c)
+
+	if !ok {
+		return
+	}
+
+	actor, ok := currentActor(c)
+
+	if !ok {
+		return
+	}
+
+	var suspension model.SuspendUserModel
+
+	if err := c.ShouldBindJSON(&suspension); err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	var until time.Time
+
+	if suspension.Until != nil {
+		until = *suspension.Until
+	}
+
+	result, err := controller.service.Suspend(userId, suspension.Reason, actor, until)
+
+	if err != nil {
+		statusError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, "User suspended successfully: " + result.String())
+}
+
+// @Summary reactivate user
+// @Description end the suspension of the user or activate a pending account, deactivated accounts are restored instead
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id query string true "user id"
+// @Param reactivation body model.ReactivateUserModel true "reason"
+// @Security BearerAuth
+// @Success 200 "User reactivated successfully"
+// @Failure 400 "Invalid id"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 404 "User not found"
+// @Failure 409 "Invalid status transition"
+// @Failure 500 "Internal server error"
+// @Router /admin/user/reactivate [patch]
+func (controller *userController) Reactivate(c *gin.Context) {
+	userId, ok := queryUserId(c)
+
+	if !ok {
+		return
+	}
+
+	actor, ok := currentActor(c)
+
+	if !ok {
+		return
+	}
+
+	var reactivation model.ReactivateUserModel
+
+	if err := c.ShouldBindJSON(&reactivation); err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	result, err := controller.service.Reactivate(userId, reactivation.Reason, actor)
+
+	if err != nil {
+		statusError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, "User reactivated successfully: " + result.String())
+}
+
+// currentActor names the authenticated admin changing the status, it is kept as who made the change
+func currentActor(c *gin.Context) (string, bool) {
+	identity, ok := middleware.CurrentIdentity(c)
+
+	if !ok {
+		c.JSON(http.StatusUnauthorized, "Invalid token")
+
+		return "", false
+	}
+
+	return identity.Email, true
+}
+
+// statusError answers the errors of the status transitions
+func statusError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "sql: no rows in result set":
+		c.JSON(http.StatusNotFound, "User not found")
+	case "Invalid status transition":
+		c.JSON(http.StatusConflict, err.Error())
+	case "Suspension reason is required", "Suspension must end in the future":
+		c.JSON(http.StatusBadRequest, err.Error())
+	default:
+		c.JSON(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// isAccountStatusError tells if a login was refused because the account isn't active
+func isAccountStatusError(err error) bool {
+	switch err.Error() {
+	case "Account is pending activation", "Account is suspended", "Account is deactivated":
+		return true
+	}
+
+	return false
+}
+
+// @Summary require password change
+// @Description make the next login of the user ask for a new password before handing out a token
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id query string true "user id"
+// @Security BearerAuth
+// @Success 200 "User must change the password at next login"
+// @Failure 400 "Invalid id"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 404 "User not found"
+// @Failure 500 "Internal server error"
+// @Router /admin/user/password/expire [patch]
+func (controller *userController) RequirePasswordChange(c *gin.Context) {
+	userId, ok := queryUserId(c)
+
+	if !ok {
+		return
+	}
+
+	result, err := controller.service.RequirePasswordChange(userId)
+
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			c.JSON(http.StatusNotFound, "User not found")
+
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	c.JSON(http.StatusOK, "User must change the password at next login: " + result.String())
+}
+
+
+// @Summary list deleted users
+// @Description list the users that were soft deleted and were not purged yet
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.DeletedUserModel
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 500 "Internal server error"
+// @Router /admin/user/deleted [get]
+func (controller *userController) ListDeleted(c *gin.Context) {
+	result, err := controller.service.ListDeleted()
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, "Error while fetching deleted users")
+
+		return
+	}
+
+	users := []model.DeletedUserModel{}
+
+	for _, user := range result {
+		users = append(users, model.DeletedUserModel{
+			Id: user.Id.String(),
+			Name: user.Name,
+			Email: user.Email,
+			Phone: user.Phone,
+			CreatedAt: user.CreatedAt,
+			DeletedAt: user.DeletedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// @Summary list users by status
+// @Description list the users in a status with the reason of the last change and who made it
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param status query string true "pending, active, suspended or deactivated"
+// @Security BearerAuth
+// @Success 200 {array} model.StatusUserModel
+// @Failure 400 "Invalid status"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 500 "Internal server error"
+// @Router /admin/user/status [get]
+func (controller *userController) ListByStatus(c *gin.Context) {
+	result, err := controller.service.ListByStatus(c.Query("status"))
+
+	if err != nil {
+		if err.Error() == "Invalid status" {
+			c.JSON(http.StatusBadRequest, err.Error())
+
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, "Error while fetching users")
+
+		return
+	}
+
+	users := []model.StatusUserModel{}
+
+	for _, user := range result {
+		statusUser := model.StatusUserModel{
+			Id: user.Id.String(),
+			Name: user.Name,
+			Email: user.Email,
+			Phone: user.Phone,
+			Role: user.Role,
+			Status: user.Status.State,
+			StatusReason: user.Status.Reason,
+			StatusChangedBy: user.Status.ChangedBy,
+			StatusChangedAt: user.Status.ChangedAt,
+			CreatedAt: user.CreatedAt,
+		}
+
+		if !user.Status.SuspendedUntil.IsZero() {
+			statusUser.SuspendedUntil = &user.Status.SuspendedUntil
+		}
+
+		users = append(users, statusUser)
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// @Summary restore user
+// @Description restore a soft deleted user while the grace period has not expired
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id query string true "user id"
+// @Security BearerAuth
+// @Success 200 "User restored successfully"
+// @Failure 400 "invalid id or grace period expired"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 404 "User not found"
+// @Failure 409 "email or phone already registered"
+// @Failure 500 "Internal server error"
+// @Router /admin/user/restore [patch]
+func (controller *userController) Restore(c *gin.Context) {
+	paramsId := c.Query("id")
+
+	if paramsId == "" {
+		c.JSON(http.StatusBadRequest, "Unspecified user")
+
+		return
+	}
+
+	userId, err := uuid.Parse(paramsId)
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid id")
+
+		return
+	}
+
+	result, err := controller.service.Restore(userId)
+
+	if err != nil {
+		switch err.Error() {
+		case "sql: no rows in result set":
+			c.JSON(http.StatusNotFound, "User not found")
+		case "Email is already registered", "Phone is already registered":
+			c.JSON(http.StatusConflict, err.Error())
+		case "Restore grace period has expired":
+			c.JSON(http.StatusBadRequest, err.Error())
+		default:
+			c.JSON(http.StatusInternalServerError, err.Error())
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, "User restored successfully: " + result.String())
+}
+
+// @Summary purge deleted users
+// @Description permanently remove the users deleted before the retention window, use dryRun to only list them
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param dryRun query bool false "only report the users that would be purged"
+// @Security BearerAuth
+// @Success 200 {object} model.PurgeReportModel
+// @Failure 400 "invalid values"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 500 "Internal server error"
+// @Router /admin/user/purge [delete]
+func (controller *userController) Purge(c *gin.Context) {
+	dryRun := false
+
+	if paramsDryRun := c.Query("dryRun"); paramsDryRun != "" {
+		parsed, err := strconv.ParseBool(paramsDryRun)
+
+		if err != nil {
+			c.JSON(http.StatusBadRequest, "Invalid dryRun")
+
+			return
+		}
+
+		dryRun = parsed
+	}
+
+	result, err := controller.service.Purge(dryRun)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	report := model.PurgeReportModel{
+		DryRun: dryRun,
+		Count: len(result),
+		Users: []model.PurgedUserModel{},
+	}
+
+	for _, user := range result {
+		report.Users = append(report.Users, model.PurgedUserModel{
+			Id: user.Id.String(),
+			Email: user.Email,
+			DeletedAt: user.DeletedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+
+// @Summary import users
+// @Description create users in bulk from a csv (name,email,phone,password header) or ndjson file sent as multipart field "file" or as the raw body
+// @Tags admin
+// @Accept mpfd
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param file formData file false "csv or ndjson file"
+// @Param format query string false "csv or ndjson, detected from the file name or content type when omitted"
+// @Param mode query string false "all-or-nothing or best-effort (default)"
+// @Param dryRun query bool false "validate the file without creating users"
+// @Security BearerAuth
+// @Success 200 {object} model.ImportReportModel
+// @Failure 400 "invalid values"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 500 "Internal server error"
+// @Router /admin/user/import [post]
+func (controller *userController) Import(c *gin.Context) {
+	mode := c.DefaultQuery("mode", domain.ImportBestEffort)
+
+	if !domain.IsValidImportMode(mode) {
+		c.JSON(http.StatusBadRequest, "Invalid mode, use all-or-nothing or best-effort")
+
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dryRun", "false"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid dryRun")
+
+		return
+	}
+
+	format := c.Query("format")
+	var source io.Reader
+
+	// the multipart body is read as a stream instead of being stored by FormFile
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		multipartReader, err := c.Request.MultipartReader()
+
+		if err != nil {
+			c.JSON(http.StatusBadRequest, err.Error())
+
+			return
+		}
+
+		for source == nil {
+			part, err := multipartReader.NextPart()
+
+			if err == io.EOF {
+				c.JSON(http.StatusBadRequest, "Missing file field")
+
+				return
+			}
+
+			if err != nil {
+				c.JSON(http.StatusBadRequest, err.Error())
+
+				return
+			}
+
+			if part.FormName() != "file" {
+				continue
+			}
+
+			source = part
+
+			if format == "" {
+				format = importer.DetectFormat(part.FileName(), part.Header.Get("Content-Type"))
+			}
+		}
+	} else {
+		source = c.Request.Body
+
+		if format == "" {
+			format = importer.DetectFormat("", c.ContentType())
+		}
+	}
+
+	rows, err := importer.NewUserReader(format, source)
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	report, err := controller.service.Import(rows, mode, dryRun)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	result := model.ImportReportModel{
+		Mode: report.Mode,
+		DryRun: report.DryRun,
+		Total: report.Total,
+		Created: report.Created,
+		Failed: report.Failed,
+		Rows: []model.ImportRowModel{},
+	}
+
+	for _, row := range report.Rows {
+		rowModel := model.ImportRowModel{
+			Row: row.Row,
+			Status: row.Status,
+			Error: row.Error,
+		}
+
+		if row.Id != uuid.Nil {
+			rowModel.Id = row.Id.String()
+		}
+
+		result.Rows = append(result.Rows, rowModel)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+
+// @Summary export users
+// @Description stream the users as csv, ndjson or json, password hashes are never included
+// @Tags admin
+// @Produce json
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "csv, ndjson or json (default)"
+// @Param createdFrom query string false "only users created at or after this date (RFC3339 or YYYY-MM-DD)"
+// @Param createdTo query string false "only users created before this date (RFC3339 or YYYY-MM-DD)"
+// @Param includeDeleted query bool false "include soft deleted users"
+// @Security BearerAuth
+// @Success 200 "users file"
+// @Failure 400 "invalid values"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 500 "Internal server error"
+// @Router /admin/user/export [get]
+func (controller *userController) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", exporter.FormatJSON)
+	filter := domain.UserFilter{}
+
+	var err error
+
+	if filter.CreatedFrom, err = exporter.ParseDate(c.Query("createdFrom")); err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid createdFrom")
+
+		return
+	}
+
+	if filter.CreatedTo, err = exporter.ParseDate(c.Query("createdTo")); err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid createdTo")
+
+		return
+	}
+
+	if filter.IncludeDeleted, err = strconv.ParseBool(c.DefaultQuery("includeDeleted", "false")); err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid includeDeleted")
+
+		return
+	}
+
+	writer, err := exporter.NewUserWriter(format, c.Writer)
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	iterator, err := controller.service.Export(filter)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, "Error while fetching users")
+
+		return
+	}
+
+	// without Content-Length the response is sent with chunked transfer encoding
+	c.Header("Content-Type", exporter.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="users-%s.%s"`, time.Now().Format("20060102-150405"), format))
+	c.Status(http.StatusOK)
+
+	// the status was already sent, a failure can only interrupt the stream
+	if _, err := exporter.Export(iterator, writer, c.Writer.Flush); err != nil {
+		c.Error(err)
+	}
+}
+
+// @Summary search users
+// @Description find users by part of their name, email or phone, typos are tolerated and the best matches come first, only for admins
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param q query string true "at least 2 characters"
+// @Param limit query int false "page size, 20 by default and at most 100"
+// @Param offset query int false "results to skip"
+// @Security BearerAuth
+// @Success 200 {object} model.SearchUsersModel
+// @Failure 400 "invalid query"
+// @Failure 401 "Invalid token or API key"
+// @Failure 403 "Missing role: admin or scope: users:read"
+// @Failure 500 "Internal server error"
+// @Router /v1/users/search [get]
+func (controller *userController) Search(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid limit")
+
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid offset")
+
+		return
+	}
+
+	page, err := controller.service.Search(c.Query("q"), limit, offset)
+
+	if err != nil {
+		if err.Error() == "Search query must have at least 2 characters" {
+			c.JSON(http.StatusBadRequest, err.Error())
+
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, "Error while searching users")
+
+		return
+	}
+
+	response := model.SearchUsersModel{
+		Total: page.Total,
+		Limit: page.Limit,
+		Offset: page.Offset,
+		Results: []model.SearchUserModel{},
+	}
+
+	for _, hit := range page.Hits {
+		response.Results = append(response.Results, model.SearchUserModel{
+			Id: hit.User.Id.String(),
+			Name: hit.User.Name,
+			Email: hit.User.Email,
+			Phone: hit.User.Phone,
+			CreatedAt: hit.User.CreatedAt,
+			Score: hit.Score,
+			Field: hit.Field,
+			Highlight: hit.Highlight,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary my profile
+// @Description show the authenticated user
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.UserModel
+// @Failure 401 "Invalid token or API key"
+// @Failure 403 "Missing scope: users:read"
+// @Failure 404 "User not found"
+// @Failure 500 "Internal server error"
+// @Router /v1/me [get]
+func (controller *userController) Me(c *gin.Context) {
+	identity, ok := middleware.CurrentIdentity(c)
+
+	if !ok {
+		c.JSON(http.StatusUnauthorized, "Invalid token")
+
+		return
+	}
+
+	controller.profile(c, identity.UserId)
+}
+
+// @Summary update my profile
+// @Description update the name, email or phone of the authenticated user, the fields left out are kept
+// @Tags me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user body model.UpdateUserModel true "user"
+// @Success 200 {object} model.UserModel
+// @Failure 400 "invalid values"
+// @Failure 401 "Invalid token or API key"
+// @Failure 403 "Missing scope: users:write"
+// @Failure 404 "User not found"
+// @Failure 409 "email or phone already registered"
+// @Failure 500 "Internal server error"
+// @Router /v1/me [patch]
+func (controller *userController) UpdateMe(c *gin.Context) {
+	identity, ok := middleware.CurrentIdentity(c)
+
+	if !ok {
+		c.JSON(http.StatusUnauthorized, "Invalid token")
+
+		return
+	}
+
+	var userData model.UpdateUserModel
+
+	if err := c.ShouldBindJSON(&userData); err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	uDomain, err := domain.CreateUser(
+		uuid.Nil,
+		userData.Name,
+		userData.Email,
+		userData.Phone,
+		"",
+		time.Time{},
+		time.Time{},
+		time.Time{},
+	)
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	if _, err := controller.service.Update(identity.UserId, uDomain); err != nil {
+		switch err.Error() {
+		case "sql: no rows in result set":
+			c.JSON(http.StatusNotFound, "User not found")
+		case "Email is already registered", "Phone is already registered":
+			c.JSON(http.StatusConflict, err.Error())
+		default:
+			c.JSON(http.StatusInternalServerError, err.Error())
+		}
+
+		return
+	}
+
+	controller.profile(c, identity.UserId)
+}
+
+// @Summary change my password
+// @Description change the password of the authenticated user, the current password must be confirmed and the tokens issued before, this one included, stop working
+// @Tags me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param password body model.ChangePasswordModel true "current and new password"
+// @Success 200 "User password updated successfully"
+// @Failure 400 {object} model.PasswordPolicyErrorModel "invalid values, password policy violations or the current password reused"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Wrong password or API keys can't change the password"
+// @Failure 404 "User not found"
+// @Failure 500 "Internal server error"
+// @Router /v1/me/password [post]
+func (controller *userController) ChangeMyPassword(c *gin.Context) {
+	identity, ok := userIdentity(c, "API keys can't change the password")
+
+	if !ok {
+		return
+	}
+
+	var userData model.ChangePasswordModel
+
+	if err := c.ShouldBindJSON(&userData); err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	result, err := controller.service.UpdatePassword(identity.UserId, userData.CurrentPassword, userData.Password)
+
+	if err != nil {
+		passwordError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, "User password updated successfully: " + result.String())
+}
+
+// @Summary deactivate my account
+// @Description soft delete the authenticated user after confirming the password, his sessions are revoked and an admin can restore him during the grace period
+// @Tags me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param password body model.DeactivateUserModel true "password"
+// @Success 200 "User deactivated successfully"
+// @Failure 400 "invalid values"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Wrong password or API keys can't deactivate the account"
+// @Failure 404 "User not found"
+// @Failure 500 "Internal server error"
+// @Router /v1/me [delete]
+func (controller *userController) DeactivateMe(c *gin.Context) {
+	identity, ok := userIdentity(c, "API keys can't deactivate the account")
+
+	if !ok {
+		return
+	}
+
+	var userData model.DeactivateUserModel
+
+	if err := c.ShouldBindJSON(&userData); err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	result, err := controller.service.Deactivate(identity.UserId, userData.Password)
+
+	if err != nil {
+		passwordError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, "User deactivated successfully: " + result.String())
+}
+
+func (controller *userController) profile(c *gin.Context, userId uuid.UUID) {
+	result, err := controller.service.List(userId)
+
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			c.JSON(http.StatusNotFound, "User not found")
+
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, "Error while fetching user")
+
+		return
+	}
+
+	profile := model.UserModel{
+		Id: result.Id.String(),
+		Name: result.Name,
+		Email: result.Email,
+		Phone: result.Phone,
+		CreatedAt: result.CreatedAt,
+		UpdatedAt: result.UpdatedAt,
+	}
+
+	if !result.LastLoginAt.IsZero() {
+		profile.LastLoginAt = &result.LastLoginAt
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// passwordError answers the errors of the routes that take a password
+func passwordError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "sql: no rows in result set":
+		c.JSON(http.StatusNotFound, "User not found")
+	case "Wrong password":
+		c.JSON(http.StatusForbidden, err.Error())
+	default:
+		badRequest(c, err)
+	}
+}
+
+// badRequest answers 400 with the error message, password policy errors carry every failed rule
+func badRequest(c *gin.Context, err error) {
+	var policyErr *domain.PasswordPolicyError
+
+	if !errors.As(err, &policyErr) {
+		c.JSON(http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	response := model.PasswordPolicyErrorModel{
+		Error: policyErr.Error(),
+		Violations: []model.PasswordViolationModel{},
+	}
+
+	for _, violation := range policyErr.Violations {
+		response.Violations = append(response.Violations, model.PasswordViolationModel{
+			Rule: violation.Rule,
+			Message: violation.Message,
+		})
+	}
+
+	c.JSON(http.StatusBadRequest, response)
+}
+
+func loginModel(result domain.LoginResult) model.LoginModel {
+	return model.LoginModel{
+		Token: result.Token,
+		TwoFactorRequired: result.TwoFactorRequired,
+		ChallengeToken: result.ChallengeToken,
+		PasswordExpired: result.PasswordExpired,
+		PasswordChangeToken: result.PasswordChangeToken,
+	}
+}
+
+// client identifies where a login came from, it is stored with the session
+func client(c *gin.Context) domain.Client {
+	return domain.Client{
+		IP: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
 }
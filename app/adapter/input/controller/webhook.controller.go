@@ -0,0 +1,334 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/model"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/input"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func NewWebhookController(
+	service port.WebhookService,
+) WebhookController {
+	return &webhookController{
+		service: service,
+	}
+}
+
+type WebhookController interface {
+	Create(c *gin.Context)
+	List(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+	ListDeliveries(c *gin.Context)
+	Redeliver(c *gin.Context)
+}
+
+type webhookController struct {
+	service port.WebhookService
+}
+
+// @Summary create webhook
+// @Description subscribe an url to user events, the secret used to sign the payloads is only returned here
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param webhook body model.CreateWebhookModel true "webhook"
+// @Security BearerAuth
+// @Success 201 {object} model.WebhookModel
+// @Failure 400 "invalid values"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 500 "Internal server error"
+// @Router /admin/webhook [post]
+func (controller *webhookController) Create(c *gin.Context) {
+	var webhookData model.CreateWebhookModel
+
+	if err := c.ShouldBindJSON(&webhookData); err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	result, err := controller.service.CreateSubscription(domain.WebhookSubscription{
+		Url: webhookData.Url,
+		EventTypes: webhookData.EventTypes,
+		Secret: webhookData.Secret,
+	})
+
+	if err != nil {
+		webhookError(c, err)
+
+		return
+	}
+
+	response := toWebhookModel(result)
+	response.Secret = result.Secret
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// @Summary list webhooks
+// @Description list all webhooks or specify one using its id
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param id query string false "webhook id"
+// @Security BearerAuth
+// @Success 200 {array} model.WebhookModel
+// @Failure 400 "Invalid id"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 404 "Webhook not found"
+// @Failure 500 "Internal server error"
+// @Router /admin/webhook [get]
+func (controller *webhookController) List(c *gin.Context) {
+	if c.Query("id") != "" {
+		id, ok := webhookId(c, "id")
+
+		if !ok {
+			return
+		}
+
+		result, err := controller.service.GetSubscription(id)
+
+		if err != nil {
+			webhookError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, toWebhookModel(result))
+
+		return
+	}
+
+	result, err := controller.service.ListSubscriptions()
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, "Error while fetching webhooks")
+
+		return
+	}
+
+	webhooks := []model.WebhookModel{}
+
+	for _, subscription := range result {
+		webhooks = append(webhooks, toWebhookModel(subscription))
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// @Summary update webhook
+// @Description change the url, event types, secret or disable a webhook
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param id query string true "webhook id"
+// @Param webhook body model.UpdateWebhookModel true "webhook"
+// @Security BearerAuth
+// @Success 200 "Webhook updated successfully"
+// @Failure 400 "invalid values"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 404 "Webhook not found"
+// @Failure 500 "Internal server error"
+// @Router /admin/webhook [put]
+func (controller *webhookController) Update(c *gin.Context) {
+	id, ok := webhookId(c, "id")
+
+	if !ok {
+		return
+	}
+
+	var webhookData model.UpdateWebhookModel
+
+	if err := c.ShouldBindJSON(&webhookData); err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	result, err := controller.service.UpdateSubscription(id, domain.WebhookSubscription{
+		Url: webhookData.Url,
+		EventTypes: webhookData.EventTypes,
+		Secret: webhookData.Secret,
+		Active: *webhookData.Active,
+	})
+
+	if err != nil {
+		webhookError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, "Webhook updated successfully: " + result.String())
+}
+
+// @Summary delete webhook
+// @Description delete a webhook and its delivery log
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param id query string true "webhook id"
+// @Security BearerAuth
+// @Success 200 "Webhook deleted successfully"
+// @Failure 400 "Invalid id"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 404 "Webhook not found"
+// @Failure 500 "Internal server error"
+// @Router /admin/webhook [delete]
+func (controller *webhookController) Delete(c *gin.Context) {
+	id, ok := webhookId(c, "id")
+
+	if !ok {
+		return
+	}
+
+	result, err := controller.service.DeleteSubscription(id)
+
+	if err != nil {
+		webhookError(c, err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, "Webhook deleted successfully: " + result.String())
+}
+
+// @Summary list webhook deliveries
+// @Description list the most recent deliveries of a webhook with the result of their last attempt
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param subscriptionId query string true "webhook id"
+// @Security BearerAuth
+// @Success 200 {array} model.WebhookDeliveryModel
+// @Failure 400 "Invalid id"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 404 "Webhook not found"
+// @Failure 500 "Internal server error"
+// @Router /admin/webhook/deliveries [get]
+func (controller *webhookController) ListDeliveries(c *gin.Context) {
+	id, ok := webhookId(c, "subscriptionId")
+
+	if !ok {
+		return
+	}
+
+	result, err := controller.service.ListDeliveries(id)
+
+	if err != nil {
+		webhookError(c, err)
+
+		return
+	}
+
+	deliveries := []model.WebhookDeliveryModel{}
+
+	for _, delivery := range result {
+		deliveries = append(deliveries, model.WebhookDeliveryModel{
+			Id: delivery.Id.String(),
+			SubscriptionId: delivery.SubscriptionId.String(),
+			EventId: delivery.EventId.String(),
+			EventType: delivery.EventType,
+			Status: delivery.Status,
+			Attempts: delivery.Attempts,
+			NextAttemptAt: delivery.NextAttemptAt,
+			LastStatusCode: delivery.LastStatusCode,
+			LastError: delivery.LastError,
+			CreatedAt: delivery.CreatedAt,
+			DeliveredAt: delivery.DeliveredAt,
+			Payload: delivery.Payload,
+		})
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// @Summary redeliver webhook
+// @Description schedule a delivery to be sent again, dead deliveries included
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param id query string true "delivery id"
+// @Security BearerAuth
+// @Success 200 "Delivery scheduled successfully"
+// @Failure 400 "Invalid id"
+// @Failure 401 "Invalid token"
+// @Failure 403 "Missing role: admin or API key used"
+// @Failure 404 "Delivery not found"
+// @Failure 500 "Internal server error"
+// @Router /admin/webhook/redeliver [post]
+func (controller *webhookController) Redeliver(c *gin.Context) {
+	id, ok := webhookId(c, "id")
+
+	if !ok {
+		return
+	}
+
+	result, err := controller.service.Redeliver(id)
+
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			c.JSON(http.StatusNotFound, "Delivery not found")
+
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	c.JSON(http.StatusOK, "Delivery scheduled successfully: " + result.String())
+}
+
+func webhookId(c *gin.Context, param string) (uuid.UUID, bool) {
+	paramsId := c.Query(param)
+
+	if paramsId == "" {
+		c.JSON(http.StatusBadRequest, "Unspecified webhook")
+
+		return uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(paramsId)
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid id")
+
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
+
+func webhookError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "sql: no rows in result set":
+		c.JSON(http.StatusNotFound, "Webhook not found")
+	case err.Error() == "Inform at least one event type", strings.HasPrefix(err.Error(), "Invalid event type"):
+		c.JSON(http.StatusBadRequest, err.Error())
+	default:
+		c.JSON(http.StatusInternalServerError, err.Error())
+	}
+}
+
+func toWebhookModel(subscription domain.WebhookSubscription) model.WebhookModel {
+	return model.WebhookModel{
+		Id: subscription.Id.String(),
+		Url: subscription.Url,
+		EventTypes: subscription.EventTypes,
+		Active: subscription.Active,
+		CreatedAt: subscription.CreatedAt,
+		UpdatedAt: subscription.UpdatedAt,
+	}
+}
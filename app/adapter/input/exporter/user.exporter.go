@@ -0,0 +1,232 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+)
+
+const (
+	FormatCSV = "csv"
+	FormatNDJSON = "ndjson"
+	FormatJSON = "json"
+)
+
+// UserWriter serializes users one at a time, Close must be called to finish the document
+type UserWriter interface {
+	Write(domain.UserDomain) error
+	Flush() error
+	Close() error
+}
+
+// exportedUser lists the exported fields, the password hash is deliberately absent
+type exportedUser struct {
+	Id string `json:"id"`
+	Name string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
+	DeletedAt string `json:"deletedAt,omitempty"`
+}
+
+var csvHeader = []string{"id", "name", "email", "phone", "createdAt", "updatedAt", "deletedAt"}
+
+func NewUserWriter(format string, destination io.Writer) (UserWriter, error) {
+	switch format {
+	case FormatCSV:
+		writer := csv.NewWriter(destination)
+
+		if err := writer.Write(csvHeader); err != nil {
+			return nil, err
+		}
+
+		return &csvWriter{writer: writer}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{encoder: json.NewEncoder(destination)}, nil
+	case FormatJSON:
+		return &jsonWriter{destination: destination}, nil
+	}
+
+	return nil, errors.New("Invalid export format, use csv, ndjson or json")
+}
+
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	}
+
+	return "application/json; charset=utf-8"
+}
+
+func toExported(user domain.UserDomain) exportedUser {
+	return exportedUser{
+		Id: user.Id.String(),
+		Name: user.Name,
+		Email: user.Email,
+		Phone: user.Phone,
+		CreatedAt: formatTime(user.CreatedAt),
+		UpdatedAt: formatTime(user.UpdatedAt),
+		DeletedAt: formatTime(user.DeletedAt),
+	}
+}
+
+func formatTime(value time.Time) string {
+	if value.IsZero() {
+		return ""
+	}
+
+	return value.Format(time.RFC3339)
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func (writer *csvWriter) Write(user domain.UserDomain) error {
+	exported := toExported(user)
+
+	return writer.writer.Write([]string{
+		exported.Id,
+		exported.Name,
+		exported.Email,
+		exported.Phone,
+		exported.CreatedAt,
+		exported.UpdatedAt,
+		exported.DeletedAt,
+	})
+}
+
+func (writer *csvWriter) Flush() error {
+	writer.writer.Flush()
+
+	return writer.writer.Error()
+}
+
+func (writer *csvWriter) Close() error {
+	writer.writer.Flush()
+
+	return writer.writer.Error()
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (writer *ndjsonWriter) Write(user domain.UserDomain) error {
+	return writer.encoder.Encode(toExported(user))
+}
+
+func (writer *ndjsonWriter) Flush() error {
+	return nil
+}
+
+func (writer *ndjsonWriter) Close() error {
+	return nil
+}
+
+// jsonWriter writes a single array without holding the users in memory
+type jsonWriter struct {
+	destination io.Writer
+	count int
+}
+
+func (writer *jsonWriter) Write(user domain.UserDomain) error {
+	separator := ","
+
+	if writer.count == 0 {
+		separator = "["
+	}
+
+	encoded, err := json.Marshal(toExported(user))
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(writer.destination, separator); err != nil {
+		return err
+	}
+
+	if _, err := writer.destination.Write(encoded); err != nil {
+		return err
+	}
+
+	writer.count++
+
+	return nil
+}
+
+func (writer *jsonWriter) Flush() error {
+	return nil
+}
+
+func (writer *jsonWriter) Close() error {
+	closing := "]"
+
+	if writer.count == 0 {
+		closing = "[]"
+	}
+
+	_, err := io.WriteString(writer.destination, closing)
+
+	return err
+}
+
+// Export copies every user from the iterator to the writer, flush is called
+// periodically so the http response is sent in chunks while rows are read
+func Export(iterator domain.UserIterator, writer UserWriter, flush func()) (int, error) {
+	defer iterator.Close()
+
+	count := 0
+
+	for iterator.Next() {
+		if err := writer.Write(iterator.User()); err != nil {
+			return count, err
+		}
+
+		count++
+
+		if flush != nil && count % 500 == 0 {
+			if err := writer.Flush(); err != nil {
+				return count, err
+			}
+
+			flush()
+		}
+	}
+
+	if err := iterator.Err(); err != nil {
+		return count, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return count, err
+	}
+
+	if flush != nil {
+		flush()
+	}
+
+	return count, nil
+}
+
+// ParseDate accepts RFC3339 or YYYY-MM-DD, an empty value becomes the zero time
+func ParseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+
+	return time.Parse("2006-01-02", value)
+}
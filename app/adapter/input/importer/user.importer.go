@@ -0,0 +1,169 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/model"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	FormatCSV = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// maxLineSize limits a single ndjson line, the file itself is never loaded whole
+const maxLineSize = 1024 * 1024
+
+var csvColumns = []string{"name", "email", "phone", "password"}
+
+// NewUserReader reads users from a csv file with a header line or from newline delimited json objects
+func NewUserReader(format string, source io.Reader) (domain.ImportRowReader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(source)
+	case FormatNDJSON:
+		scanner := bufio.NewScanner(source)
+		scanner.Buffer(make([]byte, 64 * 1024), maxLineSize)
+
+		return &ndjsonReader{scanner: scanner}, nil
+	}
+
+	return nil, errors.New("Invalid import format, use csv or ndjson")
+}
+
+// DetectFormat guesses the format from the file name or the content type, it returns "" when unknown
+func DetectFormat(filename string, contentType string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return FormatCSV
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	}
+
+	switch {
+	case strings.HasPrefix(contentType, "text/csv"):
+		return FormatCSV
+	case strings.HasPrefix(contentType, "application/x-ndjson"), strings.HasPrefix(contentType, "application/jsonl"):
+		return FormatNDJSON
+	}
+
+	return ""
+}
+
+type csvReader struct {
+	reader *csv.Reader
+	columns map[string]int
+}
+
+func newCSVReader(source io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(source)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+
+	if err == io.EOF {
+		return nil, errors.New("The csv file is empty")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+
+	for _, column := range csvColumns {
+		if _, ok := columns[column]; !ok {
+			return nil, errors.New("The csv header must contain the columns " + strings.Join(csvColumns, ", "))
+		}
+	}
+
+	return &csvReader{reader: reader, columns: columns}, nil
+}
+
+func (reader *csvReader) Next() (domain.ImportRow, error) {
+	record, err := reader.reader.Read()
+
+	if err == io.EOF {
+		return domain.ImportRow{}, io.EOF
+	}
+
+	// a malformed line is reported and the next one is still read
+	var parseError *csv.ParseError
+
+	if errors.As(err, &parseError) {
+		return domain.ImportRow{Row: parseError.StartLine, Problem: parseError.Err.Error()}, nil
+	}
+
+	if err != nil {
+		return domain.ImportRow{}, err
+	}
+
+	line, _ := reader.reader.FieldPos(0)
+
+	return validateRow(line, model.CreateUserModel{
+		Name: record[reader.columns["name"]],
+		Email: record[reader.columns["email"]],
+		Phone: record[reader.columns["phone"]],
+		Password: record[reader.columns["password"]],
+	}), nil
+}
+
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	line int
+}
+
+func (reader *ndjsonReader) Next() (domain.ImportRow, error) {
+	for reader.scanner.Scan() {
+		reader.line++
+
+		content := strings.TrimSpace(reader.scanner.Text())
+
+		if content == "" {
+			continue
+		}
+
+		var userData model.CreateUserModel
+
+		if err := json.Unmarshal([]byte(content), &userData); err != nil {
+			return domain.ImportRow{Row: reader.line, Problem: err.Error()}, nil
+		}
+
+		return validateRow(reader.line, userData), nil
+	}
+
+	if err := reader.scanner.Err(); err != nil {
+		return domain.ImportRow{}, err
+	}
+
+	return domain.ImportRow{}, io.EOF
+}
+
+// validateRow applies the same rules POST /user applies to its body
+func validateRow(line int, userData model.CreateUserModel) domain.ImportRow {
+	row := domain.ImportRow{
+		Row: line,
+		Name: userData.Name,
+		Email: userData.Email,
+		Phone: userData.Phone,
+		Password: userData.Password,
+	}
+
+	if err := binding.Validator.ValidateStruct(&userData); err != nil {
+		row.Problem = err.Error()
+	}
+
+	return row
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/input"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	APIKeyHeader = "X-API-Key"
+	identityKey = "identity"
+)
+
+// Authenticate accepts either an "Authorization: Bearer <token>" or an "X-API-Key" header,
+// both resolve to the same identity that the handlers read with CurrentIdentity
+func Authenticate(tokens port.Authenticator, apiKeys port.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authenticator := tokens
+		credential := c.GetHeader(APIKeyHeader)
+
+		if credential != "" {
+			authenticator = apiKeys
+		} else {
+			token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+			if !ok || token == "" {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, "Missing bearer token or API key")
+
+				return
+			}
+
+			credential = token
+		}
+
+		identity, err := authenticator.Authenticate(credential)
+
+		if err != nil {
+			switch err.Error() {
+			case "Invalid token", "Session has been revoked", "Invalid API key":
+				c.AbortWithStatusJSON(http.StatusUnauthorized, err.Error())
+			case "Account is pending activation", "Account is suspended", "Account is deactivated":
+				c.AbortWithStatusJSON(http.StatusForbidden, err.Error())
+			default:
+				c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
+			}
+
+			return
+		}
+
+		c.Set(identityKey, identity)
+
+		c.Next()
+	}
+}
+
+// RequireScope must come after Authenticate, it refuses API keys that weren't granted the scope
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, ok := CurrentIdentity(c)
+
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, "Invalid token")
+
+			return
+		}
+
+		if !identity.HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, "Missing scope: " + scope)
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireRole must come after Authenticate, it refuses the callers whose user doesn't have the role
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, ok := CurrentIdentity(c)
+
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, "Invalid token")
+
+			return
+		}
+
+		if identity.Role != role {
+			c.AbortWithStatusJSON(http.StatusForbidden, "Missing role: " + role)
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireUserToken must come after Authenticate, it refuses API keys whatever their user and scopes are
+func RequireUserToken(reason string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, ok := CurrentIdentity(c)
+
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, "Invalid token")
+
+			return
+		}
+
+		if identity.APIKeyId != uuid.Nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, reason)
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// CurrentIdentity returns the caller set by Authenticate
+func CurrentIdentity(c *gin.Context) (domain.Identity, bool) {
+	value, ok := c.Get(identityKey)
+
+	if !ok {
+		return domain.Identity{}, false
+	}
+
+	identity, ok := value.(domain.Identity)
+
+	return identity, ok
+}
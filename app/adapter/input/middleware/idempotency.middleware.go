@@ -0,0 +1,196 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+	"github.com/gin-gonic/gin"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// only this much of the body is hashed, so an upload isn't held in memory to be fingerprinted
+const fingerprintBodyLimit = 1 << 20
+
+// Idempotency makes POST, PUT, PATCH and DELETE requests carrying an Idempotency-Key safe to retry.
+// The first response for a key is stored and replayed on retries with the same body,
+// a retry with a different body is rejected and a retry that arrives while the first
+// request is still running waits up to wait before getting a conflict. A key only belongs
+// to the caller, method and path that used it, see scopedKey.
+// The response is stored as it is, so it is added per route on the routes creating resources
+// and never on the ones answering with tokens, secrets or recovery codes.
+func Idempotency(repository port.IdempotencyRepository, ttl time.Duration, wait time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+
+		if key == "" || !isMutating(c.Request.Method) {
+			c.Next()
+
+			return
+		}
+
+		if len(key) > 255 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, "Idempotency-Key must have at most 255 characters")
+
+			return
+		}
+
+		fingerprint, err := requestFingerprint(c)
+
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+
+			return
+		}
+
+		key = scopedKey(c, key)
+		now := time.Now()
+
+		record, reserved, err := repository.Reserve(domain.IdempotencyRecord{
+			Key: key,
+			Fingerprint: fingerprint,
+			CreatedAt: now,
+			ExpiresAt: now.Add(ttl),
+		})
+
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
+
+			return
+		}
+
+		if !reserved {
+			replay(c, repository, record, fingerprint, wait)
+
+			return
+		}
+
+		writer := &bodyRecorder{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+
+		defer func() {
+			// server errors and panics free the key so the client can retry
+			if recovered := recover(); recovered != nil {
+				repository.Release(key)
+
+				panic(recovered)
+			}
+
+			if writer.Status() >= http.StatusInternalServerError {
+				repository.Release(key)
+
+				return
+			}
+
+			repository.Complete(key, writer.Status(), writer.Header().Get("Content-Type"), writer.body.Bytes())
+		}()
+
+		c.Next()
+	}
+}
+
+func replay(c *gin.Context, repository port.IdempotencyRepository, record domain.IdempotencyRecord, fingerprint string, wait time.Duration) {
+	if record.Fingerprint != fingerprint {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request")
+
+		return
+	}
+
+	deadline := time.Now().Add(wait)
+
+	for record.Status == domain.IdempotencyPending && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+
+		current, err := repository.Find(record.Key)
+
+		// the first request failed and released the key, the client should retry
+		if err != nil {
+			break
+		}
+
+		record = current
+	}
+
+	if record.Status != domain.IdempotencyCompleted {
+		c.AbortWithStatusJSON(http.StatusConflict, "A request with this Idempotency-Key is still being processed")
+
+		return
+	}
+
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(record.ResponseStatus, record.ResponseContentType, record.ResponseBody)
+	c.Abort()
+}
+
+// scopedKey hashes the key with the caller, the method and the path so two callers using the same
+// key don't see each other's responses. It runs before the credential is verified, so the caller is
+// the bearer token or API key as sent, and the client IP on the routes that take neither.
+func scopedKey(c *gin.Context, key string) string {
+	caller := c.GetHeader(APIKeyHeader)
+
+	if caller == "" {
+		caller = c.GetHeader("Authorization")
+	}
+
+	if caller == "" {
+		caller = "ip:" + c.ClientIP()
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(caller + "\n" + c.Request.Method + " " + c.Request.URL.Path + "\n" + key))
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// requestFingerprint identifies the request by method, path, query, body length and the
+// first fingerprintBodyLimit bytes of the body, the rest is left unread for the handler
+func requestFingerprint(c *gin.Context) (string, error) {
+	prefix, err := io.ReadAll(io.LimitReader(c.Request.Body, fingerprintBodyLimit))
+
+	if err != nil {
+		return "", err
+	}
+
+	c.Request.Body = prefixedBody{Reader: io.MultiReader(bytes.NewReader(prefix), c.Request.Body), Closer: c.Request.Body}
+
+	hash := sha256.New()
+	hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "?" + c.Request.URL.RawQuery + "\n"))
+	hash.Write([]byte(strconv.FormatInt(c.Request.ContentLength, 10) + "\n"))
+	hash.Write(prefix)
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// prefixedBody puts the hashed prefix back in front of the body that wasn't read
+type prefixedBody struct {
+	io.Reader
+	io.Closer
+}
+
+func isMutating(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch || method == http.MethodDelete
+}
+
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (writer *bodyRecorder) Write(data []byte) (int, error) {
+	writer.body.Write(data)
+
+	return writer.ResponseWriter.Write(data)
+}
+
+func (writer *bodyRecorder) WriteString(data string) (int, error) {
+	writer.body.WriteString(data)
+
+	return writer.ResponseWriter.WriteString(data)
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+type CreateAPIKeyModel struct {
+	Name string `json:"name" binding:"required,max=100"`
+	Scopes []string `json:"scopes" binding:"required,min=1"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// APIKeyModel only carries the key in the creation response
+type APIKeyModel struct {
+	Id string `json:"id"`
+	Name string `json:"name"`
+	Key string `json:"key,omitempty"`
+	Prefix string `json:"prefix"`
+	Scopes []string `json:"scopes"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	RevokedAt time.Time `json:"revokedAt"`
+	Active bool `json:"active"`
+}
@@ -0,0 +1,9 @@
+package model
+
+type CacheStatsModel struct {
+	Hits uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	Shared uint64 `json:"shared"`
+	Invalidations uint64 `json:"invalidations"`
+	HitRatio float64 `json:"hitRatio"`
+}
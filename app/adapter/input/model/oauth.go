@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+// CreateOAuthClientModel registers a confidential client unless public is set, public clients
+// (SPAs, mobile apps) have no secret and must use PKCE
+type CreateOAuthClientModel struct {
+	Name string `json:"name" binding:"required,max=100"`
+	RedirectURIs []string `json:"redirectUris"`
+	GrantTypes []string `json:"grantTypes"`
+	Scopes []string `json:"scopes"`
+	Public bool `json:"public"`
+}
+
+// OAuthClientModel only carries the secret in the creation response
+type OAuthClientModel struct {
+	Id string `json:"id"`
+	Name string `json:"name"`
+	Secret string `json:"secret,omitempty"`
+	RedirectURIs []string `json:"redirectUris"`
+	GrantTypes []string `json:"grantTypes"`
+	Scopes []string `json:"scopes"`
+	Public bool `json:"public"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// OAuthTokenModel follows RFC 6749 section 5.1, so it uses snake case like every OAuth client expects
+type OAuthTokenModel struct {
+	AccessToken string `json:"access_token"`
+	TokenType string `json:"token_type"`
+	ExpiresIn int `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope string `json:"scope,omitempty"`
+	IDToken string `json:"id_token,omitempty"`
+}
+
+// OAuthIntrospectionModel follows RFC 7662 section 2.2, token_type is access_token or refresh_token
+// so a refresh token sent as bearer can be told apart
+type OAuthIntrospectionModel struct {
+	Active bool `json:"active"`
+	TokenType string `json:"token_type,omitempty"`
+	Scope string `json:"scope,omitempty"`
+	ClientId string `json:"client_id,omitempty"`
+	Username string `json:"username,omitempty"`
+	Subject string `json:"sub,omitempty"`
+	Issuer string `json:"iss,omitempty"`
+	TokenId string `json:"jti,omitempty"`
+	IssuedAt int64 `json:"iat,omitempty"`
+	ExpiresAt int64 `json:"exp,omitempty"`
+}
+
+type OAuthErrorModel struct {
+	Error string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
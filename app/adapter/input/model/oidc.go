@@ -0,0 +1,37 @@
+package model
+
+// OIDCConfigurationModel is the discovery document of OpenID Connect Discovery section 3
+type OIDCConfigurationModel struct {
+	Issuer string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint string `json:"token_endpoint"`
+	UserInfoEndpoint string `json:"userinfo_endpoint"`
+	RevocationEndpoint string `json:"revocation_endpoint"`
+	IntrospectionEndpoint string `json:"introspection_endpoint"`
+	JWKSURI string `json:"jwks_uri"`
+	ResponseTypesSupported []string `json:"response_types_supported"`
+	GrantTypesSupported []string `json:"grant_types_supported"`
+	SubjectTypesSupported []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
+	ScopesSupported []string `json:"scopes_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported []string `json:"claims_supported"`
+}
+
+// JWKSModel is the JSON Web Key Set of RFC 7517 section 5
+type JWKSModel struct {
+	Keys []JWKModel `json:"keys"`
+}
+
+type JWKModel struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X string `json:"x,omitempty"`
+	Y string `json:"y,omitempty"`
+}
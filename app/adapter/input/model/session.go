@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// SessionModel flags the session of the token used in the request as current
+type SessionModel struct {
+	Id string `json:"id"`
+	UserAgent string `json:"userAgent"`
+	Ip string `json:"ip"`
+	CreatedAt time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Current bool `json:"current"`
+}
+
+// LoginAttemptModel is one login of the user, failureReason is only sent for the failed ones
+type LoginAttemptModel struct {
+	Id string `json:"id"`
+	Outcome string `json:"outcome"`
+	FailureReason string `json:"failureReason,omitempty"`
+	Ip string `json:"ip"`
+	UserAgent string `json:"userAgent"`
+	CreatedAt time.Time `json:"createdAt"`
+}
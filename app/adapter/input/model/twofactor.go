@@ -0,0 +1,32 @@
+package model
+
+// LoginModel has either the token or, when two-factor is enabled, the challenge token
+// that is sent to /user/login/2fa with a code. An expired password gets the token that
+// is sent to /user/login/password with a new password instead
+type LoginModel struct {
+	Token string `json:"token,omitempty"`
+	TwoFactorRequired bool `json:"twoFactorRequired"`
+	ChallengeToken string `json:"challengeToken,omitempty"`
+	PasswordExpired bool `json:"passwordExpired,omitempty"`
+	PasswordChangeToken string `json:"passwordChangeToken,omitempty"`
+}
+
+// TwoFactorEnrollmentModel carries the qr code as a png data uri ready for an img tag
+type TwoFactorEnrollmentModel struct {
+	Secret string `json:"secret"`
+	Uri string `json:"uri"`
+	QrCode string `json:"qrCode"`
+}
+
+type TwoFactorCodeModel struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorLoginModel struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+	Code string `json:"code" binding:"required"`
+}
+
+type RecoveryCodesModel struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
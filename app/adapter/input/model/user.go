@@ -1,23 +1,146 @@
 package model
 
+import "time"
+
 type CreateUserModel struct {
-	Email string `json:"email" binding:"required,email"`
-  Password string `json:"password" binding:"required,min=6,containsany=!@#$%*"`
+	Email string `json:"email" binding:"required,email,max=100"`
+  Password string `json:"password" binding:"required"`
   Name string `json:"name" binding:"required,min=3,max=100"`
 	Phone string `json:"phone" binding:"required,min=11,max=11"`
 }
 
 type UpdateUserModel struct {
-	Email string `json:"email" binding:"omitempty,email"`
+	Email string `json:"email" binding:"omitempty,email,max=100"`
   Name string `json:"name" binding:"omitempty,min=3,max=100"`
 	Phone string `json:"phone" binding:"omitempty,min=11,max=11"`
 }
 
 type UpdateUserPasswordModel struct {
-	Password string `json:"password" binding:"required,min=6,containsany=!@#$%*"`
+	Password string `json:"password" binding:"required"`
+}
+
+type ChangePasswordModel struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// ExpiredPasswordModel finishes a login whose password expired with the token it returned
+type ExpiredPasswordModel struct {
+	PasswordChangeToken string `json:"passwordChangeToken" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// SuspendUserModel says why the user is suspended, without until the suspension lasts until a reactivation
+type SuspendUserModel struct {
+	Reason string `json:"reason" binding:"required,max=255"`
+	Until *time.Time `json:"until"`
+}
+
+type ReactivateUserModel struct {
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+type UserRoleModel struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type DeactivateUserModel struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// UserModel is the profile of the authenticated user, the password hash is never included
+type UserModel struct {
+	Id string `json:"id"`
+	Name string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	LastLoginAt *time.Time `json:"lastLoginAt,omitempty"`
 }
 
 type UserLoginModel struct {
-	Password string `json:"password" binding:"required,min=6,containsany=!@#$%*"`
-	Email string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+	Email string `json:"email" binding:"required,email,max=100"`
+}
+
+// DeletedUserModel is a soft deleted user waiting for a restore or the purge, without the password hash
+type DeletedUserModel struct {
+	Id string `json:"id"`
+	Name string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+	CreatedAt time.Time `json:"createdAt"`
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+// StatusUserModel is an user listed by status for the admins, without the password hash
+type StatusUserModel struct {
+	Id string `json:"id"`
+	Name string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+	Role string `json:"role"`
+	Status string `json:"status"`
+	StatusReason string `json:"statusReason"`
+	StatusChangedBy string `json:"statusChangedBy"`
+	StatusChangedAt time.Time `json:"statusChangedAt"`
+	SuspendedUntil *time.Time `json:"suspendedUntil,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type PurgedUserModel struct {
+	Id string `json:"id"`
+	Email string `json:"email"`
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+type PurgeReportModel struct {
+	DryRun bool `json:"dryRun"`
+	Count int `json:"count"`
+	Users []PurgedUserModel `json:"users"`
+}
+
+type ImportRowModel struct {
+	Row int `json:"row"`
+	Id string `json:"id,omitempty"`
+	Status string `json:"status"`
+	Error string `json:"error,omitempty"`
+}
+
+type ImportReportModel struct {
+	Mode string `json:"mode"`
+	DryRun bool `json:"dryRun"`
+	Total int `json:"total"`
+	Created int `json:"created"`
+	Failed int `json:"failed"`
+	Rows []ImportRowModel `json:"rows"`
+}
+
+type SearchUserModel struct {
+	Id string `json:"id"`
+	Name string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+	CreatedAt time.Time `json:"createdAt"`
+	Score float64 `json:"score"`
+	Field string `json:"field"`
+	Highlight string `json:"highlight,omitempty"`
+}
+
+type SearchUsersModel struct {
+	Total int `json:"total"`
+	Limit int `json:"limit"`
+	Offset int `json:"offset"`
+	Results []SearchUserModel `json:"results"`
+}
+
+type PasswordViolationModel struct {
+	Rule string `json:"rule"`
+	Message string `json:"message"`
+}
+
+type PasswordPolicyErrorModel struct {
+	Error string `json:"error"`
+	Violations []PasswordViolationModel `json:"violations"`
 }
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type CreateWebhookModel struct {
+	Url string `json:"url" binding:"required,url"`
+	EventTypes []string `json:"eventTypes" binding:"required,min=1"`
+	Secret string `json:"secret" binding:"omitempty,min=16"`
+}
+
+type UpdateWebhookModel struct {
+	Url string `json:"url" binding:"omitempty,url"`
+	EventTypes []string `json:"eventTypes"`
+	Secret string `json:"secret" binding:"omitempty,min=16"`
+	Active *bool `json:"active" binding:"required"`
+}
+
+// WebhookModel only carries the secret in the creation response
+type WebhookModel struct {
+	Id string `json:"id"`
+	Url string `json:"url"`
+	EventTypes []string `json:"eventTypes"`
+	Secret string `json:"secret,omitempty"`
+	Active bool `json:"active"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type WebhookDeliveryModel struct {
+	Id string `json:"id"`
+	SubscriptionId string `json:"subscriptionId"`
+	EventId string `json:"eventId"`
+	EventType string `json:"eventType"`
+	Status string `json:"status"`
+	Attempts int `json:"attempts"`
+	NextAttemptAt time.Time `json:"nextAttemptAt"`
+	LastStatusCode int `json:"lastStatusCode"`
+	LastError string `json:"lastError,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	DeliveredAt time.Time `json:"deliveredAt"`
+	Payload json.RawMessage `json:"payload"`
+}
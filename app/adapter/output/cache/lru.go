@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+)
+
+// NewLRU keeps up to capacity entries in memory, the least recently used one is evicted
+// when it is full and entries older than ttl are treated as missing
+func NewLRU(capacity int, ttl time.Duration) port.CacheBackend {
+	return &lru{
+		capacity: capacity,
+		ttl: ttl,
+		items: map[string]*list.Element{},
+		order: list.New(),
+	}
+}
+
+type lru struct {
+	mutex sync.Mutex
+	capacity int
+	ttl time.Duration
+	items map[string]*list.Element
+	order *list.List
+}
+
+type lruEntry struct {
+	key string
+	value []byte
+	expiresAt time.Time
+}
+
+func (cache *lru) Get(key string) ([]byte, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	element, found := cache.items[key]
+
+	if !found {
+		return nil, false
+	}
+
+	entry := element.Value.(*lruEntry)
+
+	if time.Now().After(entry.expiresAt) {
+		cache.remove(element)
+
+		return nil, false
+	}
+
+	cache.order.MoveToFront(element)
+
+	return entry.value, true
+}
+
+func (cache *lru) Set(key string, value []byte) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	expiresAt := time.Now().Add(cache.ttl)
+
+	if element, found := cache.items[key]; found {
+		entry := element.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+
+		cache.order.MoveToFront(element)
+
+		return
+	}
+
+	cache.items[key] = cache.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+
+	for cache.order.Len() > cache.capacity {
+		cache.remove(cache.order.Back())
+	}
+}
+
+func (cache *lru) Delete(keys ...string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	for _, key := range keys {
+		if element, found := cache.items[key]; found {
+			cache.remove(element)
+		}
+	}
+}
+
+func (cache *lru) remove(element *list.Element) {
+	cache.order.Remove(element)
+	delete(cache.items, element.Value.(*lruEntry).key)
+}
@@ -0,0 +1,353 @@
+package cache
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
+)
+
+// NewUserRepository caches the reads by id, email and phone of the wrapped repository.
+// The user is stored under its id and the email and phone keys only point to that id,
+// so a write only has to drop the id key for every lookup of that user to miss.
+// A read racing with a write may store the old user again, the ttl bounds how long it lives.
+func NewUserRepository(next port.UserRepository, backend port.CacheBackend) UserRepository {
+	return &userRepository{
+		next: next,
+		backend: backend,
+		group: &singleflight.Group{},
+		stats: &counters{},
+	}
+}
+
+type UserRepository interface {
+	port.UserRepository
+	Stats() domain.CacheStats
+}
+
+type userRepository struct {
+	next port.UserRepository
+	backend port.CacheBackend
+	group *singleflight.Group
+	stats *counters
+	// pending holds the keys written inside a transaction, nil outside of one
+	pending *[]string
+}
+
+type counters struct {
+	hits atomic.Uint64
+	misses atomic.Uint64
+	shared atomic.Uint64
+	invalidations atomic.Uint64
+}
+
+func (repository *userRepository) Stats() domain.CacheStats {
+	return domain.CacheStats{
+		Hits: repository.stats.hits.Load(),
+		Misses: repository.stats.misses.Load(),
+		Shared: repository.stats.shared.Load(),
+		Invalidations: repository.stats.invalidations.Load(),
+	}
+}
+
+func (repository *userRepository) List(id uuid.UUID) (domain.UserDomain, error) {
+	if repository.pending != nil {
+		return repository.next.List(id)
+	}
+
+	if user, found := repository.cached(id); found {
+		repository.stats.hits.Add(1)
+
+		return user, nil
+	}
+
+	return repository.load(idKey(id), func() (domain.UserDomain, error) {
+		return repository.next.List(id)
+	})
+}
+
+func (repository *userRepository) FindUserByEmail(email string) (domain.UserDomain, error) {
+	if repository.pending != nil {
+		return repository.next.FindUserByEmail(email)
+	}
+
+	if user, found := repository.alias(emailKey(email)); found && user.Email == email {
+		repository.stats.hits.Add(1)
+
+		return user, nil
+	}
+
+	return repository.load(emailKey(email), func() (domain.UserDomain, error) {
+		return repository.next.FindUserByEmail(email)
+	})
+}
+
+func (repository *userRepository) FindUserByPhone(phone string) (domain.UserDomain, error) {
+	if repository.pending != nil {
+		return repository.next.FindUserByPhone(phone)
+	}
+
+	if user, found := repository.alias(phoneKey(phone)); found && user.Phone == phone {
+		repository.stats.hits.Add(1)
+
+		return user, nil
+	}
+
+	return repository.load(phoneKey(phone), func() (domain.UserDomain, error) {
+		return repository.next.FindUserByPhone(phone)
+	})
+}
+
+func (repository *userRepository) Create(dto domain.UserDomain) (uuid.UUID, error) {
+	result, err := repository.next.Create(dto)
+
+	repository.invalidate(emailKey(dto.Email), phoneKey(dto.Phone))
+
+	return result, err
+}
+
+func (repository *userRepository) Delete(id uuid.UUID) (uuid.UUID, error) {
+	result, err := repository.next.Delete(id)
+
+	repository.invalidate(idKey(id))
+
+	return result, err
+}
+
+func (repository *userRepository) Update(id uuid.UUID, dto domain.UserDomain) (uuid.UUID, error) {
+	result, err := repository.next.Update(id, dto)
+
+	repository.invalidate(idKey(id), emailKey(dto.Email), phoneKey(dto.Phone))
+
+	return result, err
+}
+
+func (repository *userRepository) UpdatePassword(id uuid.UUID, dto domain.UserDomain) (uuid.UUID, error) {
+	result, err := repository.next.UpdatePassword(id, dto)
+
+	repository.invalidate(idKey(id))
+
+	return result, err
+}
+
+func (repository *userRepository) UpdateRole(id uuid.UUID, role string) (uuid.UUID, error) {
+	result, err := repository.next.UpdateRole(id, role)
+
+	repository.invalidate(idKey(id))
+
+	return result, err
+}
+
+func (repository *userRepository) RequirePasswordChange(id uuid.UUID) (uuid.UUID, error) {
+	result, err := repository.next.RequirePasswordChange(id)
+
+	repository.invalidate(idKey(id))
+
+	return result, err
+}
+
+func (repository *userRepository) UpdateStatus(id uuid.UUID, status domain.UserStatus) (uuid.UUID, error) {
+	result, err := repository.next.UpdateStatus(id, status)
+
+	repository.invalidate(idKey(id))
+
+	return result, err
+}
+
+// UpdateLastLogin invalidates too, otherwise the profile would show the login before the cached one
+func (repository *userRepository) UpdateLastLogin(id uuid.UUID, lastLoginAt time.Time) error {
+	err := repository.next.UpdateLastLogin(id, lastLoginAt)
+
+	repository.invalidate(idKey(id))
+
+	return err
+}
+
+func (repository *userRepository) Restore(id uuid.UUID) (uuid.UUID, error) {
+	result, err := repository.next.Restore(id)
+
+	repository.invalidate(idKey(id))
+
+	return result, err
+}
+
+func (repository *userRepository) Purge(deletedBefore time.Time) ([]uuid.UUID, error) {
+	ids, err := repository.next.Purge(deletedBefore)
+
+	keys := []string{}
+
+	for _, id := range ids {
+		keys = append(keys, idKey(id))
+	}
+
+	repository.invalidate(keys...)
+
+	return ids, err
+}
+
+// Transaction skips the cache for the reads made inside fn, they may see uncommitted rows,
+// and drops the keys written by fn again once the transaction is over
+func (repository *userRepository) Transaction(fn func(port.UserRepository) error) error {
+	if repository.pending != nil {
+		return repository.next.Transaction(func(tx port.UserRepository) error {
+			return fn(repository.with(tx, repository.pending))
+		})
+	}
+
+	pending := []string{}
+
+	err := repository.next.Transaction(func(tx port.UserRepository) error {
+		return fn(repository.with(tx, &pending))
+	})
+
+	repository.invalidate(pending...)
+
+	return err
+}
+
+func (repository *userRepository) ListAll() ([]domain.UserDomain, error) {
+	return repository.next.ListAll()
+}
+
+func (repository *userRepository) ListByStatus(state string) ([]domain.UserDomain, error) {
+	return repository.next.ListByStatus(state)
+}
+
+func (repository *userRepository) ListDeleted() ([]domain.UserDomain, error) {
+	return repository.next.ListDeleted()
+}
+
+func (repository *userRepository) FindDeletedUser(id uuid.UUID) (domain.UserDomain, error) {
+	return repository.next.FindDeletedUser(id)
+}
+
+func (repository *userRepository) Iterate(filter domain.UserFilter) (domain.UserIterator, error) {
+	return repository.next.Iterate(filter)
+}
+
+func (repository *userRepository) Search(search domain.UserSearch) (domain.UserSearchPage, error) {
+	return repository.next.Search(search)
+}
+
+func (repository *userRepository) AddEvent(event domain.Event) error {
+	return repository.next.AddEvent(event)
+}
+
+func (repository *userRepository) AddPasswordHistory(userId uuid.UUID, hash string, replacedAt time.Time) error {
+	return repository.next.AddPasswordHistory(userId, hash, replacedAt)
+}
+
+func (repository *userRepository) ListPasswordHistory(userId uuid.UUID, limit int) ([]string, error) {
+	return repository.next.ListPasswordHistory(userId, limit)
+}
+
+func (repository *userRepository) PrunePasswordHistory(userId uuid.UUID, keep int) error {
+	return repository.next.PrunePasswordHistory(userId, keep)
+}
+
+func (repository *userRepository) with(next port.UserRepository, pending *[]string) *userRepository {
+	return &userRepository{
+		next: next,
+		backend: repository.backend,
+		group: repository.group,
+		stats: repository.stats,
+		pending: pending,
+	}
+}
+
+// load reads through the wrapped repository, concurrent misses for the same key share one query.
+// Errors, "no rows" included, are never cached.
+func (repository *userRepository) load(key string, fn func() (domain.UserDomain, error)) (domain.UserDomain, error) {
+	repository.stats.misses.Add(1)
+
+	result, err, shared := repository.group.Do(key, func() (any, error) {
+		user, err := fn()
+
+		if err != nil {
+			return domain.UserDomain{}, err
+		}
+
+		repository.store(user)
+
+		return user, nil
+	})
+
+	if shared {
+		repository.stats.shared.Add(1)
+	}
+
+	return result.(domain.UserDomain), err
+}
+
+func (repository *userRepository) store(user domain.UserDomain) {
+	encoded, err := json.Marshal(user)
+
+	if err != nil {
+		return
+	}
+
+	repository.backend.Set(idKey(user.Id), encoded)
+	repository.backend.Set(emailKey(user.Email), []byte(user.Id.String()))
+	repository.backend.Set(phoneKey(user.Phone), []byte(user.Id.String()))
+}
+
+func (repository *userRepository) cached(id uuid.UUID) (domain.UserDomain, bool) {
+	encoded, found := repository.backend.Get(idKey(id))
+
+	if !found {
+		return domain.UserDomain{}, false
+	}
+
+	user := domain.UserDomain{}
+
+	if err := json.Unmarshal(encoded, &user); err != nil {
+		return domain.UserDomain{}, false
+	}
+
+	return user, true
+}
+
+func (repository *userRepository) alias(key string) (domain.UserDomain, bool) {
+	encoded, found := repository.backend.Get(key)
+
+	if !found {
+		return domain.UserDomain{}, false
+	}
+
+	id, err := uuid.ParseBytes(encoded)
+
+	if err != nil {
+		return domain.UserDomain{}, false
+	}
+
+	return repository.cached(id)
+}
+
+func (repository *userRepository) invalidate(keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	if repository.pending != nil {
+		*repository.pending = append(*repository.pending, keys...)
+	}
+
+	repository.stats.invalidations.Add(uint64(len(keys)))
+	repository.backend.Delete(keys...)
+}
+
+func idKey(id uuid.UUID) string {
+	return "user:id:" + id.String()
+}
+
+func emailKey(email string) string {
+	return "user:email:" + email
+}
+
+func phoneKey(phone string) string {
+	return "user:phone:" + phone
+}
@@ -0,0 +1,73 @@
+package cipher
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+)
+
+const aesGCMPrefix = "v1:"
+
+// NewAESGCMCipher encrypts with AES-256-GCM, the key must have 32 bytes
+func NewAESGCMCipher(key []byte) (port.SecretCipher, error) {
+	if len(key) != 32 {
+		return nil, errors.New("The encryption key must have 32 bytes")
+	}
+
+	block, err := aes.NewCipher(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &aesGCMCipher{aead: aead}, nil
+}
+
+type aesGCMCipher struct {
+	aead cipher.AEAD
+}
+
+// Encrypt returns "v1:" followed by the base64 of the nonce and the sealed plaintext,
+// the prefix leaves room to rotate the key later
+func (c *aesGCMCipher) Encrypt(plaintext []byte) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := c.aead.Seal(nonce, nonce, plaintext, nil)
+
+	return aesGCMPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (c *aesGCMCipher) Decrypt(ciphertext string) ([]byte, error) {
+	if !strings.HasPrefix(ciphertext, aesGCMPrefix) {
+		return nil, errors.New("Unknown ciphertext format")
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(ciphertext, aesGCMPrefix))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < c.aead.NonceSize() {
+		return nil, errors.New("Ciphertext is too short")
+	}
+
+	nonce, sealed := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+
+	return c.aead.Open(nil, nonce, sealed, nil)
+}
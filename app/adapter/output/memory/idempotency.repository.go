@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+)
+
+// NewIdempotencyRepository keeps the records in process memory, useful for tests and single instance deployments
+func NewIdempotencyRepository() port.IdempotencyRepository {
+	return &idempotencyRepository{
+		records: map[string]domain.IdempotencyRecord{},
+	}
+}
+
+type idempotencyRepository struct {
+	mutex sync.Mutex
+	records map[string]domain.IdempotencyRecord
+}
+
+func (repository *idempotencyRepository) Reserve(record domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	existing, found := repository.records[record.Key]
+
+	if found && !existing.IsExpired(record.CreatedAt) {
+		return existing, false, nil
+	}
+
+	record.Status = domain.IdempotencyPending
+	repository.records[record.Key] = record
+
+	return record, true, nil
+}
+
+func (repository *idempotencyRepository) Find(key string) (domain.IdempotencyRecord, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	record, found := repository.records[key]
+
+	if !found || record.IsExpired(time.Now()) {
+		return domain.IdempotencyRecord{}, sql.ErrNoRows
+	}
+
+	return record, nil
+}
+
+func (repository *idempotencyRepository) Complete(key string, status int, contentType string, body []byte) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	record, found := repository.records[key]
+
+	if !found {
+		return sql.ErrNoRows
+	}
+
+	record.Status = domain.IdempotencyCompleted
+	record.ResponseStatus = status
+	record.ResponseContentType = contentType
+	record.ResponseBody = body
+	repository.records[key] = record
+
+	return nil
+}
+
+func (repository *idempotencyRepository) Release(key string) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if record, found := repository.records[key]; found && record.Status == domain.IdempotencyPending {
+		delete(repository.records, key)
+	}
+
+	return nil
+}
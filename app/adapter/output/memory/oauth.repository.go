@@ -0,0 +1,181 @@
+package memory
+
+import (
+	"database/sql"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+	"github.com/google/uuid"
+)
+
+// NewOAuthRepository keeps clients, codes and tokens in process memory, useful for tests and
+// for running the authorization server without a database
+func NewOAuthRepository() port.OAuthRepository {
+	return &oauthRepository{
+		clients: map[string]domain.OAuthClient{},
+		codes: map[string]domain.AuthorizationCode{},
+		tokens: map[uuid.UUID]domain.OAuthToken{},
+	}
+}
+
+type oauthRepository struct {
+	mutex sync.Mutex
+	clients map[string]domain.OAuthClient
+	codes map[string]domain.AuthorizationCode
+	tokens map[uuid.UUID]domain.OAuthToken
+}
+
+func (repository *oauthRepository) CreateClient(client domain.OAuthClient) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.clients[client.Id] = client
+
+	return nil
+}
+
+func (repository *oauthRepository) FindClient(id string) (domain.OAuthClient, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	client, found := repository.clients[id]
+
+	if !found {
+		return domain.OAuthClient{}, sql.ErrNoRows
+	}
+
+	return client, nil
+}
+
+func (repository *oauthRepository) ListClients() ([]domain.OAuthClient, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	clients := []domain.OAuthClient{}
+
+	for _, client := range repository.clients {
+		clients = append(clients, client)
+	}
+
+	slices.SortFunc(clients, func(a, b domain.OAuthClient) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return clients, nil
+}
+
+// DeleteClient also drops the codes and tokens of the client like the foreign keys of the database do
+func (repository *oauthRepository) DeleteClient(id string) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if _, found := repository.clients[id]; !found {
+		return sql.ErrNoRows
+	}
+
+	delete(repository.clients, id)
+
+	for hash, code := range repository.codes {
+		if code.ClientId == id {
+			delete(repository.codes, hash)
+		}
+	}
+
+	for tokenId, token := range repository.tokens {
+		if token.ClientId == id {
+			delete(repository.tokens, tokenId)
+		}
+	}
+
+	return nil
+}
+
+func (repository *oauthRepository) CreateAuthorizationCode(code domain.AuthorizationCode) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.codes[code.Hash] = code
+
+	return nil
+}
+
+func (repository *oauthRepository) UseAuthorizationCode(hash string) (domain.AuthorizationCode, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	code, found := repository.codes[hash]
+
+	if !found {
+		return domain.AuthorizationCode{}, sql.ErrNoRows
+	}
+
+	delete(repository.codes, hash)
+
+	return code, nil
+}
+
+func (repository *oauthRepository) CreateToken(token domain.OAuthToken) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.tokens[token.Id] = token
+
+	return nil
+}
+
+func (repository *oauthRepository) FindToken(id uuid.UUID) (domain.OAuthToken, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	token, found := repository.tokens[id]
+
+	if !found {
+		return domain.OAuthToken{}, sql.ErrNoRows
+	}
+
+	return token, nil
+}
+
+func (repository *oauthRepository) FindTokenByHash(hash string) (domain.OAuthToken, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for _, token := range repository.tokens {
+		if token.Hash != "" && token.Hash == hash {
+			return token, nil
+		}
+	}
+
+	return domain.OAuthToken{}, sql.ErrNoRows
+}
+
+func (repository *oauthRepository) RevokeToken(id uuid.UUID) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if token, found := repository.tokens[id]; found && token.RevokedAt.IsZero() {
+		token.RevokedAt = time.Now()
+		repository.tokens[id] = token
+	}
+
+	return nil
+}
+
+func (repository *oauthRepository) RevokeGrant(grantId uuid.UUID) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	now := time.Now()
+
+	for id, token := range repository.tokens {
+		if token.GrantId == grantId && token.RevokedAt.IsZero() {
+			token.RevokedAt = now
+			repository.tokens[id] = token
+		}
+	}
+
+	return nil
+}
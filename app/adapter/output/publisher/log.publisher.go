@@ -0,0 +1,52 @@
+package publisher
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+)
+
+// NewLogPublisher writes every event as a json line, it is the default until a broker is configured
+func NewLogPublisher(writer io.Writer) port.EventPublisher {
+	return &logPublisher{
+		writer: writer,
+	}
+}
+
+type logPublisher struct {
+	mutex sync.Mutex
+	writer io.Writer
+}
+
+type loggedEvent struct {
+	Id string `json:"id"`
+	Type string `json:"type"`
+	UserId string `json:"userId"`
+	OccurredAt time.Time `json:"occurredAt"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+func (publisher *logPublisher) Publish(message domain.OutboxMessage) error {
+	encoded, err := json.Marshal(loggedEvent{
+		Id: message.Id.String(),
+		Type: message.Type,
+		UserId: message.UserId.String(),
+		OccurredAt: message.OccurredAt,
+		Payload: message.Payload,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	publisher.mutex.Lock()
+	defer publisher.mutex.Unlock()
+
+	_, err = publisher.writer.Write(append(encoded, '\n'))
+
+	return err
+}
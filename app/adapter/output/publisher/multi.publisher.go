@@ -0,0 +1,28 @@
+package publisher
+
+import (
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+)
+
+// NewMultiPublisher publishes to every publisher in order and stops at the first error,
+// the relay retries the whole message so publishers must tolerate duplicates
+func NewMultiPublisher(publishers ...port.EventPublisher) port.EventPublisher {
+	return &multiPublisher{
+		publishers: publishers,
+	}
+}
+
+type multiPublisher struct {
+	publishers []port.EventPublisher
+}
+
+func (publisher *multiPublisher) Publish(message domain.OutboxMessage) error {
+	for _, next := range publisher.publishers {
+		if err := next.Publish(message); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+	"github.com/google/uuid"
+)
+
+const createAPIKeysTableQuery = `CREATE TABLE IF NOT EXISTS api_keys (
+	id uuid PRIMARY KEY,
+	userId uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	name varchar(100) NOT NULL,
+	prefix varchar(8) NOT NULL UNIQUE,
+	hash varchar(64) NOT NULL,
+	scopes text NOT NULL,
+	createdAt timestamp NOT NULL DEFAULT NOW(),
+	expiresAt timestamp,
+	lastUsedAt timestamp,
+	revokedAt timestamp
+);
+CREATE INDEX IF NOT EXISTS api_keys_user_idx ON api_keys (userId, createdAt)`
+
+const apiKeyColumns = `id, userId, name, prefix, hash, scopes, createdAt, expiresAt, lastUsedAt, revokedAt`
+
+func NewAPIKeyRepository(db *sql.DB) port.APIKeyRepository {
+	return &apiKeyRepository{
+		db: db,
+	}
+}
+
+type apiKeyRepository struct {
+	db *sql.DB
+}
+
+// scopes are stored comma separated like the webhook event types
+func (repository *apiKeyRepository) CreateAPIKey(dto domain.APIKey) error {
+	var expiresAt sql.NullTime
+
+	if !dto.ExpiresAt.IsZero() {
+		expiresAt = sql.NullTime{Time: dto.ExpiresAt, Valid: true}
+	}
+
+	query := `INSERT INTO api_keys (id, userId, name, prefix, hash, scopes, createdAt, expiresAt) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err := repository.db.Exec(query, dto.Id, dto.UserId, dto.Name, dto.Prefix, dto.Hash, strings.Join(dto.Scopes, ","), dto.CreatedAt, expiresAt)
+
+	return err
+}
+
+func (repository *apiKeyRepository) FindAPIKeyByPrefix(prefix string) (domain.APIKey, error) {
+	return scanAPIKey(repository.db.QueryRow(`SELECT ` + apiKeyColumns + ` FROM api_keys WHERE prefix = $1`, prefix))
+}
+
+// ListAPIKeys also returns the revoked and expired keys so the user can see why one stopped working
+func (repository *apiKeyRepository) ListAPIKeys(userId uuid.UUID) ([]domain.APIKey, error) {
+	keys := []domain.APIKey{}
+
+	rows, err := repository.db.Query(`SELECT ` + apiKeyColumns + ` FROM api_keys WHERE userId = $1 ORDER BY createdAt`, userId)
+
+	if err != nil {
+		return []domain.APIKey{}, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+
+		if err != nil {
+			return []domain.APIKey{}, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (repository *apiKeyRepository) RevokeAPIKey(userId uuid.UUID, id uuid.UUID) error {
+	var pk uuid.UUID
+
+	query := `UPDATE api_keys SET revokedAt = NOW() WHERE id = $1 AND userId = $2 AND revokedAt IS NULL RETURNING id`
+
+	return repository.db.QueryRow(query, id, userId).Scan(&pk)
+}
+
+func (repository *apiKeyRepository) TouchAPIKey(id uuid.UUID, lastUsedAt time.Time) error {
+	_, err := repository.db.Exec(`UPDATE api_keys SET lastUsedAt = $1 WHERE id = $2`, lastUsedAt, id)
+
+	return err
+}
+
+func scanAPIKey(row rowScanner) (domain.APIKey, error) {
+	var key domain.APIKey
+	var scopes string
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+
+	err := row.Scan(
+		&key.Id,
+		&key.UserId,
+		&key.Name,
+		&key.Prefix,
+		&key.Hash,
+		&scopes,
+		&key.CreatedAt,
+		&expiresAt,
+		&lastUsedAt,
+		&revokedAt,
+	)
+
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+
+	key.Scopes = strings.Split(scopes, ",")
+	key.ExpiresAt = expiresAt.Time
+	key.LastUsedAt = lastUsedAt.Time
+	key.RevokedAt = revokedAt.Time
+
+	return key, nil
+}
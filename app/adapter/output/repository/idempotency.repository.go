@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+)
+
+func NewIdempotencyRepository(db *sql.DB) IdempotencyRepository {
+	return &idempotencyRepository{
+		db: db,
+	}
+}
+
+type IdempotencyRepository interface {
+	CreateTable() error
+	Reserve(domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error)
+	Find(string) (domain.IdempotencyRecord, error)
+	Complete(string, int, string, []byte) error
+	Release(string) error
+	DeleteExpired() (int64, error)
+}
+
+const createIdempotencyKeysTableQuery = `CREATE TABLE IF NOT EXISTS idempotency_keys (
+	key varchar(255) PRIMARY KEY,
+	fingerprint varchar(64) NOT NULL,
+	status varchar(20) NOT NULL,
+	responseStatus integer,
+	responseContentType varchar(255),
+	responseBody bytea,
+	createdAt timestamp DEFAULT NOW(),
+	expiresAt timestamp NOT NULL
+)`
+
+type idempotencyRepository struct {
+	db *sql.DB
+}
+
+func (repository *idempotencyRepository) CreateTable() error {
+	_, err := repository.db.Exec(createIdempotencyKeysTableQuery)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repository *idempotencyRepository) Reserve(record domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error) {
+	query := `INSERT INTO idempotency_keys (
+		key,
+		fingerprint,
+		status,
+		createdAt,
+		expiresAt
+	) VALUES (
+		$1, $2, $3, $4, $5
+	) ON CONFLICT (key) DO NOTHING RETURNING key`
+
+	// the second attempt only happens when the stored key had already expired
+	for attempt := 0; attempt < 2; attempt++ {
+		var key string
+
+		err := repository.db.QueryRow(query, record.Key, record.Fingerprint, domain.IdempotencyPending, record.CreatedAt, record.ExpiresAt).Scan(&key)
+
+		if err == nil {
+			record.Status = domain.IdempotencyPending
+
+			return record, true, nil
+		}
+
+		if err != sql.ErrNoRows {
+			return domain.IdempotencyRecord{}, false, err
+		}
+
+		existing, err := repository.Find(record.Key)
+
+		if err == sql.ErrNoRows {
+			continue
+		}
+
+		if err != nil {
+			return domain.IdempotencyRecord{}, false, err
+		}
+
+		if !existing.IsExpired(record.CreatedAt) {
+			return existing, false, nil
+		}
+
+		_, err = repository.db.Exec(`DELETE FROM idempotency_keys WHERE key = $1 AND expiresAt < $2`, record.Key, record.CreatedAt)
+
+		if err != nil {
+			return domain.IdempotencyRecord{}, false, err
+		}
+	}
+
+	return domain.IdempotencyRecord{}, false, errors.New("Could not reserve the idempotency key")
+}
+
+func (repository *idempotencyRepository) Find(key string) (domain.IdempotencyRecord, error) {
+	record := domain.IdempotencyRecord{}
+	var responseStatus sql.NullInt64
+	var responseContentType sql.NullString
+	var createdAt sql.NullString
+	var expiresAt sql.NullString
+
+	query := `SELECT key, fingerprint, status, responseStatus, responseContentType, responseBody, createdAt, expiresAt FROM idempotency_keys WHERE key = $1`
+
+	err := repository.db.QueryRow(query, key).Scan(&record.Key, &record.Fingerprint, &record.Status, &responseStatus, &responseContentType, &record.ResponseBody, &createdAt, &expiresAt)
+
+	if err != nil {
+		return domain.IdempotencyRecord{}, err
+	}
+
+	record.ResponseStatus = int(responseStatus.Int64)
+	record.ResponseContentType = responseContentType.String
+
+	if record.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+		return domain.IdempotencyRecord{}, err
+	}
+
+	if record.ExpiresAt, err = parseTimestamp(expiresAt); err != nil {
+		return domain.IdempotencyRecord{}, err
+	}
+
+	return record, nil
+}
+
+func (repository *idempotencyRepository) Complete(key string, status int, contentType string, body []byte) error {
+	query := `UPDATE idempotency_keys SET status = $2, responseStatus = $3, responseContentType = $4, responseBody = $5 WHERE key = $1`
+
+	_, err := repository.db.Exec(query, key, domain.IdempotencyCompleted, status, contentType, body)
+
+	return err
+}
+
+func (repository *idempotencyRepository) Release(key string) error {
+	query := `DELETE FROM idempotency_keys WHERE key = $1 AND status = $2`
+
+	_, err := repository.db.Exec(query, key, domain.IdempotencyPending)
+
+	return err
+}
+
+// DeleteExpired is meant to be called periodically to keep the table small
+func (repository *idempotencyRepository) DeleteExpired() (int64, error) {
+	result, err := repository.db.Exec(`DELETE FROM idempotency_keys WHERE expiresAt < $1`, time.Now())
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
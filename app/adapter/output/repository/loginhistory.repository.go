@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+	"github.com/google/uuid"
+)
+
+// login_attempts is only kept for the retention period, users.lastLoginAt stays after the attempts are gone
+const createLoginHistoryQuery = `CREATE TABLE IF NOT EXISTS login_attempts (
+	id uuid PRIMARY KEY,
+	userId uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	outcome varchar(20) NOT NULL,
+	failureReason varchar(100) NOT NULL DEFAULT '',
+	ip varchar(45) NOT NULL DEFAULT '',
+	userAgent varchar(255) NOT NULL DEFAULT '',
+	createdAt timestamp NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS login_attempts_user_idx ON login_attempts (userId, createdAt);
+CREATE INDEX IF NOT EXISTS login_attempts_created_idx ON login_attempts (createdAt);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS lastLoginAt timestamp`
+
+const loginAttemptColumns = `id, userId, outcome, failureReason, ip, userAgent, createdAt`
+
+func NewLoginHistoryRepository(db *sql.DB) port.LoginHistoryRepository {
+	return &loginHistoryRepository{
+		db: db,
+	}
+}
+
+type loginHistoryRepository struct {
+	db *sql.DB
+}
+
+func (repository *loginHistoryRepository) AddLoginAttempt(attempt domain.LoginAttempt) error {
+	var userId uuid.NullUUID
+
+	if attempt.UserId != uuid.Nil {
+		userId = uuid.NullUUID{UUID: attempt.UserId, Valid: true}
+	}
+
+	userAgent := attempt.UserAgent
+
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	query := `INSERT INTO login_attempts (` + loginAttemptColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := repository.db.Exec(query, attempt.Id, userId, attempt.Outcome, attempt.FailureReason, attempt.IP, userAgent, attempt.CreatedAt.UTC())
+
+	return err
+}
+
+func (repository *loginHistoryRepository) ListLoginAttempts(userId uuid.UUID, limit int) ([]domain.LoginAttempt, error) {
+	attempts := []domain.LoginAttempt{}
+
+	query := `SELECT ` + loginAttemptColumns + ` FROM login_attempts WHERE userId = $1 ORDER BY createdAt DESC LIMIT $2`
+
+	rows, err := repository.db.Query(query, userId, limit)
+
+	if err != nil {
+		return []domain.LoginAttempt{}, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var attempt domain.LoginAttempt
+
+		err := rows.Scan(
+			&attempt.Id,
+			&attempt.UserId,
+			&attempt.Outcome,
+			&attempt.FailureReason,
+			&attempt.IP,
+			&attempt.UserAgent,
+			&attempt.CreatedAt,
+		)
+
+		if err != nil {
+			return []domain.LoginAttempt{}, err
+		}
+
+		attempts = append(attempts, attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []domain.LoginAttempt{}, err
+	}
+
+	return attempts, nil
+}
+
+func (repository *loginHistoryRepository) DeleteLoginAttempts(before time.Time) (int64, error) {
+	result, err := repository.db.Exec(`DELETE FROM login_attempts WHERE createdAt < $1`, before.UTC())
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,319 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Migration is a versioned schema change, Down must undo exactly what Up did
+type Migration struct {
+	Version int
+	Name string
+	Up string
+	Down string
+}
+
+type MigrationStatus struct {
+	Migration
+	Applied bool
+	AppliedAt time.Time
+}
+
+// migrations must only be appended, never edited once released
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name: "create_users",
+		Up: createUsersTableQuery,
+		Down: `DROP TABLE IF EXISTS users`,
+	},
+	{
+		Version: 2,
+		Name: "create_idempotency_keys",
+		Up: createIdempotencyKeysTableQuery,
+		Down: `DROP TABLE IF EXISTS idempotency_keys`,
+	},
+	{
+		Version: 3,
+		Name: "create_outbox",
+		Up: createOutboxTableQuery,
+		Down: `DROP TABLE IF EXISTS outbox`,
+	},
+	{
+		Version: 4,
+		Name: "create_webhooks",
+		Up: createWebhooksTablesQuery,
+		Down: `DROP TABLE IF EXISTS webhook_deliveries; DROP TABLE IF EXISTS webhook_subscriptions`,
+	},
+	{
+		Version: 5,
+		Name: "create_user_search_indexes",
+		Up: createUserSearchIndexesQuery,
+		Down: `DROP INDEX IF EXISTS users_name_trgm_idx, users_email_trgm_idx, users_phone_trgm_idx, users_name_fts_idx`,
+	},
+	{
+		// argon2id hashes with stronger parameters don't fit in 100 characters
+		Version: 6,
+		Name: "widen_users_password",
+		Up: widenUsersPasswordQuery,
+		Down: `ALTER TABLE users ALTER COLUMN password TYPE varchar(100)`,
+	},
+	{
+		Version: 7,
+		Name: "create_two_factor",
+		Up: createTwoFactorTablesQuery,
+		Down: `DROP TABLE IF EXISTS user_recovery_codes; DROP TABLE IF EXISTS user_two_factor`,
+	},
+	{
+		Version: 8,
+		Name: "create_sessions",
+		Up: createSessionsTableQuery,
+		Down: `DROP TABLE IF EXISTS sessions`,
+	},
+	{
+		Version: 9,
+		Name: "create_api_keys",
+		Up: createAPIKeysTableQuery,
+		Down: `DROP TABLE IF EXISTS api_keys`,
+	},
+	{
+		Version: 10,
+		Name: "create_oauth",
+		Up: createOAuthTablesQuery,
+		Down: `DROP TABLE IF EXISTS oauth_tokens; DROP TABLE IF EXISTS oauth_authorization_codes; DROP TABLE IF EXISTS oauth_clients`,
+	},
+	{
+		Version: 11,
+		Name: "add_oauth_nonce",
+		Up: `ALTER TABLE oauth_authorization_codes ADD COLUMN IF NOT EXISTS nonce varchar(255) NOT NULL DEFAULT ''`,
+		Down: `ALTER TABLE oauth_authorization_codes DROP COLUMN IF EXISTS nonce`,
+	},
+	{
+		Version: 12,
+		Name: "create_signing_keys",
+		Up: createSigningKeysTableQuery,
+		Down: `DROP TABLE IF EXISTS signing_keys`,
+	},
+	{
+		Version: 13,
+		Name: "add_users_password_changed_at",
+		Up: addUsersPasswordChangedAtQuery,
+		Down: `ALTER TABLE users DROP COLUMN IF EXISTS passwordChangedAt`,
+	},
+	{
+		Version: 14,
+		Name: "create_password_history",
+		Up: createPasswordHistoryTableQuery,
+		Down: `DROP TABLE IF EXISTS password_history`,
+	},
+	{
+		Version: 15,
+		Name: "add_users_role",
+		Up: addUsersRoleQuery,
+		Down: `ALTER TABLE users DROP COLUMN IF EXISTS role, DROP COLUMN IF EXISTS passwordChangeRequired`,
+	},
+	{
+		Version: 16,
+		Name: "add_users_status",
+		Up: addUsersStatusQuery,
+		Down: `DROP INDEX IF EXISTS users_status_idx;
+ALTER TABLE users DROP COLUMN IF EXISTS status, DROP COLUMN IF EXISTS statusReason, DROP COLUMN IF EXISTS statusChangedBy,
+	DROP COLUMN IF EXISTS statusChangedAt, DROP COLUMN IF EXISTS suspendedUntil`,
+	},
+	{
+		Version: 17,
+		Name: "create_login_history",
+		Up: createLoginHistoryQuery,
+		Down: `DROP TABLE IF EXISTS login_attempts; ALTER TABLE users DROP COLUMN IF EXISTS lastLoginAt`,
+	},
+	{
+		Version: 18,
+		Name: "add_outbox_user_pending_index",
+		Up: `CREATE INDEX IF NOT EXISTS outbox_user_pending_idx ON outbox (userId, sequence) WHERE publishedAt IS NULL AND deadAt IS NULL`,
+		Down: `DROP INDEX IF EXISTS outbox_user_pending_idx`,
+	},
+	{
+		Version: 19,
+		Name: "record_unknown_user_logins",
+		Up: `ALTER TABLE login_attempts ALTER COLUMN userId DROP NOT NULL`,
+		Down: `DELETE FROM login_attempts WHERE userId IS NULL; ALTER TABLE login_attempts ALTER COLUMN userId SET NOT NULL`,
+	},
+	{
+		Version: 20,
+		Name: "add_outbox_claims",
+		Up: addOutboxClaimsQuery,
+		Down: `ALTER TABLE outbox DROP COLUMN IF EXISTS claimedUntil`,
+	},
+}
+
+func NewMigrator(db *sql.DB) Migrator {
+	return &migrator{
+		db: db,
+		migrations: migrations,
+	}
+}
+
+type Migrator interface {
+	Up() ([]Migration, error)
+	Down() (Migration, error)
+	Status() ([]MigrationStatus, error)
+}
+
+type migrator struct {
+	db *sql.DB
+	migrations []Migration
+}
+
+func (migrator *migrator) createTable() error {
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version integer PRIMARY KEY,
+		name varchar(100) NOT NULL,
+		appliedAt timestamp DEFAULT NOW()
+	)`
+
+	_, err := migrator.db.Exec(query)
+
+	return err
+}
+
+func (migrator *migrator) applied() (map[int]time.Time, error) {
+	applied := map[int]time.Time{}
+
+	rows, err := migrator.db.Query(`SELECT version, appliedAt FROM schema_migrations`)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var version int
+		var appliedAt sql.NullString
+
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+
+		parsedAppliedAt, err := parseTimestamp(appliedAt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		applied[version] = parsedAppliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+// Up applies every pending migration in order, each one inside its own transaction
+func (migrator *migrator) Up() ([]Migration, error) {
+	if err := migrator.createTable(); err != nil {
+		return nil, err
+	}
+
+	applied, err := migrator.applied()
+
+	if err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+
+	for _, migration := range migrator.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		err := migrator.inTransaction(migration.Up, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, migration.Version, migration.Name)
+
+		if err != nil {
+			return done, err
+		}
+
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Down reverts only the last applied migration
+func (migrator *migrator) Down() (Migration, error) {
+	if err := migrator.createTable(); err != nil {
+		return Migration{}, err
+	}
+
+	applied, err := migrator.applied()
+
+	if err != nil {
+		return Migration{}, err
+	}
+
+	for i := len(migrator.migrations) - 1; i >= 0; i-- {
+		migration := migrator.migrations[i]
+
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		err := migrator.inTransaction(migration.Down, `DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+
+		if err != nil {
+			return Migration{}, err
+		}
+
+		return migration, nil
+	}
+
+	return Migration{}, errors.New("No migration to revert")
+}
+
+func (migrator *migrator) Status() ([]MigrationStatus, error) {
+	if err := migrator.createTable(); err != nil {
+		return nil, err
+	}
+
+	applied, err := migrator.applied()
+
+	if err != nil {
+		return nil, err
+	}
+
+	status := []MigrationStatus{}
+
+	for _, migration := range migrator.migrations {
+		appliedAt, ok := applied[migration.Version]
+
+		status = append(status, MigrationStatus{
+			Migration: migration,
+			Applied: ok,
+			AppliedAt: appliedAt,
+		})
+	}
+
+	return status, nil
+}
+
+func (migrator *migrator) inTransaction(statement string, bookkeeping string, args ...any) error {
+	tx, err := migrator.db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(statement); err != nil {
+		tx.Rollback()
+
+		return err
+	}
+
+	if _, err := tx.Exec(bookkeeping, args...); err != nil {
+		tx.Rollback()
+
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+	"github.com/google/uuid"
+)
+
+// tokens of client_credentials have no user, so userId is nullable and not a foreign key
+// of users, deleting a user revokes nothing by itself
+const createOAuthTablesQuery = `CREATE TABLE IF NOT EXISTS oauth_clients (
+	id varchar(64) PRIMARY KEY,
+	name varchar(100) NOT NULL,
+	secretHash varchar(64) NOT NULL DEFAULT '',
+	redirectUris text NOT NULL,
+	grantTypes text NOT NULL,
+	scopes text NOT NULL,
+	createdAt timestamp NOT NULL DEFAULT NOW()
+);
+CREATE TABLE IF NOT EXISTS oauth_authorization_codes (
+	codeHash varchar(64) PRIMARY KEY,
+	clientId varchar(64) NOT NULL REFERENCES oauth_clients (id) ON DELETE CASCADE,
+	userId uuid NOT NULL,
+	redirectUri text NOT NULL,
+	scope text NOT NULL,
+	codeChallenge varchar(128) NOT NULL DEFAULT '',
+	codeChallengeMethod varchar(10) NOT NULL DEFAULT '',
+	createdAt timestamp NOT NULL DEFAULT NOW(),
+	expiresAt timestamp NOT NULL,
+	usedAt timestamp
+);
+CREATE TABLE IF NOT EXISTS oauth_tokens (
+	id uuid PRIMARY KEY,
+	grantId uuid NOT NULL,
+	tokenType varchar(20) NOT NULL,
+	hash varchar(64) UNIQUE,
+	clientId varchar(64) NOT NULL REFERENCES oauth_clients (id) ON DELETE CASCADE,
+	userId uuid,
+	scope text NOT NULL,
+	createdAt timestamp NOT NULL DEFAULT NOW(),
+	expiresAt timestamp NOT NULL,
+	revokedAt timestamp
+);
+CREATE INDEX IF NOT EXISTS oauth_tokens_grant_idx ON oauth_tokens (grantId)`
+
+const (
+	oauthClientColumns = `id, name, secretHash, redirectUris, grantTypes, scopes, createdAt`
+	oauthTokenColumns = `id, grantId, tokenType, hash, clientId, userId, scope, createdAt, expiresAt, revokedAt`
+)
+
+func NewOAuthRepository(db *sql.DB) port.OAuthRepository {
+	return &oauthRepository{
+		db: db,
+	}
+}
+
+type oauthRepository struct {
+	db *sql.DB
+}
+
+// redirect uris, grant types and scopes never contain spaces so they are stored space separated
+func (repository *oauthRepository) CreateClient(dto domain.OAuthClient) error {
+	query := `INSERT INTO oauth_clients (` + oauthClientColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := repository.db.Exec(
+		query,
+		dto.Id,
+		dto.Name,
+		dto.SecretHash,
+		strings.Join(dto.RedirectURIs, " "),
+		strings.Join(dto.GrantTypes, " "),
+		strings.Join(dto.Scopes, " "),
+		dto.CreatedAt,
+	)
+
+	return err
+}
+
+func (repository *oauthRepository) FindClient(id string) (domain.OAuthClient, error) {
+	return scanOAuthClient(repository.db.QueryRow(`SELECT ` + oauthClientColumns + ` FROM oauth_clients WHERE id = $1`, id))
+}
+
+func (repository *oauthRepository) ListClients() ([]domain.OAuthClient, error) {
+	clients := []domain.OAuthClient{}
+
+	rows, err := repository.db.Query(`SELECT ` + oauthClientColumns + ` FROM oauth_clients ORDER BY createdAt`)
+
+	if err != nil {
+		return []domain.OAuthClient{}, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		client, err := scanOAuthClient(rows)
+
+		if err != nil {
+			return []domain.OAuthClient{}, err
+		}
+
+		clients = append(clients, client)
+	}
+
+	return clients, rows.Err()
+}
+
+func (repository *oauthRepository) DeleteClient(id string) error {
+	var pk string
+
+	return repository.db.QueryRow(`DELETE FROM oauth_clients WHERE id = $1 RETURNING id`, id).Scan(&pk)
+}
+
+func (repository *oauthRepository) CreateAuthorizationCode(dto domain.AuthorizationCode) error {
+	query := `INSERT INTO oauth_authorization_codes (codeHash, clientId, userId, redirectUri, scope, codeChallenge, codeChallengeMethod, nonce, createdAt, expiresAt)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := repository.db.Exec(
+		query,
+		dto.Hash,
+		dto.ClientId,
+		dto.UserId,
+		dto.RedirectURI,
+		dto.Scope,
+		dto.CodeChallenge,
+		dto.CodeChallengeMethod,
+		dto.Nonce,
+		dto.CreatedAt,
+		dto.ExpiresAt,
+	)
+
+	return err
+}
+
+// UseAuthorizationCode marks the code as used in the same statement that reads it, two
+// concurrent exchanges of the same code can't both succeed
+func (repository *oauthRepository) UseAuthorizationCode(hash string) (domain.AuthorizationCode, error) {
+	var code domain.AuthorizationCode
+
+	query := `UPDATE oauth_authorization_codes SET usedAt = NOW() WHERE codeHash = $1 AND usedAt IS NULL
+		RETURNING codeHash, clientId, userId, redirectUri, scope, codeChallenge, codeChallengeMethod, nonce, createdAt, expiresAt`
+
+	err := repository.db.QueryRow(query, hash).Scan(
+		&code.Hash,
+		&code.ClientId,
+		&code.UserId,
+		&code.RedirectURI,
+		&code.Scope,
+		&code.CodeChallenge,
+		&code.CodeChallengeMethod,
+		&code.Nonce,
+		&code.CreatedAt,
+		&code.ExpiresAt,
+	)
+
+	if err != nil {
+		return domain.AuthorizationCode{}, err
+	}
+
+	return code, nil
+}
+
+func (repository *oauthRepository) CreateToken(dto domain.OAuthToken) error {
+	var hash sql.NullString
+	var userId uuid.NullUUID
+
+	if dto.Hash != "" {
+		hash = sql.NullString{String: dto.Hash, Valid: true}
+	}
+
+	if dto.UserId != uuid.Nil {
+		userId = uuid.NullUUID{UUID: dto.UserId, Valid: true}
+	}
+
+	query := `INSERT INTO oauth_tokens (id, grantId, tokenType, hash, clientId, userId, scope, createdAt, expiresAt) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	_, err := repository.db.Exec(query, dto.Id, dto.GrantId, dto.Type, hash, dto.ClientId, userId, dto.Scope, dto.CreatedAt, dto.ExpiresAt)
+
+	return err
+}
+
+func (repository *oauthRepository) FindToken(id uuid.UUID) (domain.OAuthToken, error) {
+	return scanOAuthToken(repository.db.QueryRow(`SELECT ` + oauthTokenColumns + ` FROM oauth_tokens WHERE id = $1`, id))
+}
+
+func (repository *oauthRepository) FindTokenByHash(hash string) (domain.OAuthToken, error) {
+	return scanOAuthToken(repository.db.QueryRow(`SELECT ` + oauthTokenColumns + ` FROM oauth_tokens WHERE hash = $1`, hash))
+}
+
+func (repository *oauthRepository) RevokeToken(id uuid.UUID) error {
+	_, err := repository.db.Exec(`UPDATE oauth_tokens SET revokedAt = NOW() WHERE id = $1 AND revokedAt IS NULL`, id)
+
+	return err
+}
+
+func (repository *oauthRepository) RevokeGrant(grantId uuid.UUID) error {
+	_, err := repository.db.Exec(`UPDATE oauth_tokens SET revokedAt = NOW() WHERE grantId = $1 AND revokedAt IS NULL`, grantId)
+
+	return err
+}
+
+func scanOAuthClient(row rowScanner) (domain.OAuthClient, error) {
+	var client domain.OAuthClient
+	var redirectURIs, grantTypes, scopes string
+
+	err := row.Scan(
+		&client.Id,
+		&client.Name,
+		&client.SecretHash,
+		&redirectURIs,
+		&grantTypes,
+		&scopes,
+		&client.CreatedAt,
+	)
+
+	if err != nil {
+		return domain.OAuthClient{}, err
+	}
+
+	client.RedirectURIs = strings.Fields(redirectURIs)
+	client.GrantTypes = strings.Fields(grantTypes)
+	client.Scopes = strings.Fields(scopes)
+
+	return client, nil
+}
+
+func scanOAuthToken(row rowScanner) (domain.OAuthToken, error) {
+	var token domain.OAuthToken
+	var hash sql.NullString
+	var userId uuid.NullUUID
+	var revokedAt sql.NullTime
+
+	err := row.Scan(
+		&token.Id,
+		&token.GrantId,
+		&token.Type,
+		&hash,
+		&token.ClientId,
+		&userId,
+		&token.Scope,
+		&token.CreatedAt,
+		&token.ExpiresAt,
+		&revokedAt,
+	)
+
+	if err != nil {
+		return domain.OAuthToken{}, err
+	}
+
+	token.Hash = hash.String
+	token.UserId = userId.UUID
+	token.RevokedAt = revokedAt.Time
+
+	return token, nil
+}
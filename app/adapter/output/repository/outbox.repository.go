@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+)
+
+const createOutboxTableQuery = `CREATE TABLE IF NOT EXISTS outbox (
+	sequence bigserial PRIMARY KEY,
+	id uuid UNIQUE NOT NULL,
+	type varchar(100) NOT NULL,
+	userId uuid NOT NULL,
+	payload jsonb NOT NULL,
+	occurredAt timestamp NOT NULL,
+	attempts integer NOT NULL DEFAULT 0,
+	nextAttemptAt timestamp,
+	lastError text,
+	publishedAt timestamp,
+	deadAt timestamp
+);
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (sequence) WHERE publishedAt IS NULL AND deadAt IS NULL`
+
+const addOutboxClaimsQuery = `ALTER TABLE outbox ADD COLUMN IF NOT EXISTS claimedUntil timestamp`
+
+// outboxClaimLock is the advisory lock that makes the relays claim one at a time
+const outboxClaimLock = 7301
+
+func NewOutboxRepository(db *sql.DB) OutboxRepository {
+	return &outboxRepository{
+		db: db,
+	}
+}
+
+type OutboxRepository interface {
+	ClaimPending(int, time.Time, time.Duration) ([]domain.OutboxMessage, error)
+	MarkPublished(uuid.UUID) error
+	MarkFailed(uuid.UUID, string, time.Time) error
+	MarkDead(uuid.UUID, string) error
+}
+
+type outboxRepository struct {
+	db *sql.DB
+}
+
+// ClaimPending returns the messages not published yet in the order they were written and sets their
+// claimedUntil so the other relays skip them until the lease ends. A message waiting for its next attempt
+// or claimed by another relay holds back the later messages of the same user, so they are left out in the
+// query instead of filling the batch and starving the other users. The claims are made one relay at a time
+// under an advisory lock, otherwise two relays could each take a message of the same user and publish them
+// out of order.
+func (repository *outboxRepository) ClaimPending(limit int, now time.Time, lease time.Duration) ([]domain.OutboxMessage, error) {
+	messages := []domain.OutboxMessage{}
+
+	tx, err := repository.db.Begin()
+
+	if err != nil {
+		return []domain.OutboxMessage{}, err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, outboxClaimLock); err != nil {
+		return []domain.OutboxMessage{}, err
+	}
+
+	query := `WITH claimed AS (
+		UPDATE outbox SET claimedUntil = $3 WHERE id IN (
+			SELECT id FROM outbox message
+			WHERE publishedAt IS NULL AND deadAt IS NULL AND NOT EXISTS (
+				SELECT 1 FROM outbox waiting WHERE waiting.userId = message.userId AND waiting.sequence <= message.sequence
+				AND waiting.publishedAt IS NULL AND waiting.deadAt IS NULL AND (waiting.nextAttemptAt > $2 OR waiting.claimedUntil > $2)
+			)
+			ORDER BY sequence LIMIT $1
+		)
+		RETURNING sequence, id, type, userId, payload, occurredAt, attempts, nextAttemptAt, lastError
+	)
+	SELECT id, type, userId, payload, occurredAt, attempts, nextAttemptAt, lastError FROM claimed ORDER BY sequence`
+
+	rows, err := tx.Query(query, limit, now, now.Add(lease))
+
+	if err != nil {
+		return []domain.OutboxMessage{}, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		message := domain.OutboxMessage{}
+		var occurredAt sql.NullString
+		var nextAttemptAt sql.NullString
+		var lastError sql.NullString
+
+		err := rows.Scan(&message.Id, &message.Type, &message.UserId, &message.Payload, &occurredAt, &message.Attempts, &nextAttemptAt, &lastError)
+
+		if err != nil {
+			return []domain.OutboxMessage{}, err
+		}
+
+		if message.OccurredAt, err = parseTimestamp(occurredAt); err != nil {
+			return []domain.OutboxMessage{}, err
+		}
+
+		if message.NextAttemptAt, err = parseTimestamp(nextAttemptAt); err != nil {
+			return []domain.OutboxMessage{}, err
+		}
+
+		message.LastError = lastError.String
+
+		messages = append(messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []domain.OutboxMessage{}, err
+	}
+
+	rows.Close()
+
+	if err := tx.Commit(); err != nil {
+		return []domain.OutboxMessage{}, err
+	}
+
+	return messages, nil
+}
+
+func (repository *outboxRepository) MarkPublished(id uuid.UUID) error {
+	_, err := repository.db.Exec(`UPDATE outbox SET publishedAt = $2, attempts = attempts + 1, claimedUntil = NULL WHERE id = $1`, id, time.Now())
+
+	return err
+}
+
+func (repository *outboxRepository) MarkFailed(id uuid.UUID, reason string, nextAttemptAt time.Time) error {
+	_, err := repository.db.Exec(`UPDATE outbox SET attempts = attempts + 1, lastError = $2, nextAttemptAt = $3, claimedUntil = NULL WHERE id = $1`, id, reason, nextAttemptAt)
+
+	return err
+}
+
+// MarkDead stops retrying a message, it stays in the table to be inspected
+func (repository *outboxRepository) MarkDead(id uuid.UUID, reason string) error {
+	_, err := repository.db.Exec(`UPDATE outbox SET attempts = attempts + 1, lastError = $2, deadAt = $3, claimedUntil = NULL WHERE id = $1`, id, reason, time.Now())
+
+	return err
+}
+
+// insertOutboxMessage is shared with the user repository so events are written with its transaction
+func insertOutboxMessage(db executor, message domain.OutboxMessage) error {
+	query := `INSERT INTO outbox (id, type, userId, payload, occurredAt) VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := db.Exec(query, message.Id, message.Type, message.UserId, message.Payload, message.OccurredAt)
+
+	return err
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// password_history only keeps the hashes of replaced passwords, the current one is in users
+const createPasswordHistoryTableQuery = `CREATE TABLE IF NOT EXISTS password_history (
+	id uuid PRIMARY KEY,
+	userId uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	hash varchar(255) NOT NULL,
+	replacedAt timestamp NOT NULL
+);
+CREATE INDEX IF NOT EXISTS password_history_user_idx ON password_history (userId, replacedAt)`
+
+func (repository *userRepository) AddPasswordHistory(userId uuid.UUID, hash string, replacedAt time.Time) error {
+	query := `INSERT INTO password_history (id, userId, hash, replacedAt) VALUES ($1, $2, $3, $4)`
+
+	_, err := repository.db.Exec(query, uuid.New(), userId, hash, replacedAt.UTC())
+
+	return err
+}
+
+func (repository *userRepository) ListPasswordHistory(userId uuid.UUID, limit int) ([]string, error) {
+	hashes := []string{}
+
+	query := `SELECT hash FROM password_history WHERE userId = $1 ORDER BY replacedAt DESC LIMIT $2`
+
+	rows, err := repository.db.Query(query, userId, limit)
+
+	if err != nil {
+		return []string{}, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var hash string
+
+		if err := rows.Scan(&hash); err != nil {
+			return []string{}, err
+		}
+
+		hashes = append(hashes, hash)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []string{}, err
+	}
+
+	return hashes, nil
+}
+
+func (repository *userRepository) PrunePasswordHistory(userId uuid.UUID, keep int) error {
+	query := `DELETE FROM password_history WHERE userId = $1 AND id NOT IN (
+		SELECT id FROM password_history WHERE userId = $1 ORDER BY replacedAt DESC LIMIT $2
+	)`
+
+	_, err := repository.db.Exec(query, userId, keep)
+
+	return err
+}
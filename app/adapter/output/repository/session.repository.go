@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+	"github.com/google/uuid"
+)
+
+const createSessionsTableQuery = `CREATE TABLE IF NOT EXISTS sessions (
+	id uuid PRIMARY KEY,
+	userId uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	userAgent varchar(255) NOT NULL DEFAULT '',
+	ip varchar(45) NOT NULL DEFAULT '',
+	createdAt timestamp NOT NULL DEFAULT NOW(),
+	lastSeenAt timestamp NOT NULL DEFAULT NOW(),
+	expiresAt timestamp NOT NULL,
+	revokedAt timestamp
+);
+CREATE INDEX IF NOT EXISTS sessions_user_idx ON sessions (userId, createdAt)`
+
+const sessionColumns = `id, userId, userAgent, ip, createdAt, lastSeenAt, expiresAt, revokedAt`
+
+// the user agent is informed by the client, longer values are cut to fit the column
+const maxUserAgentLength = 255
+
+func NewSessionRepository(db *sql.DB) port.SessionRepository {
+	return &sessionRepository{
+		db: db,
+	}
+}
+
+type sessionRepository struct {
+	db *sql.DB
+}
+
+func (repository *sessionRepository) CreateSession(dto domain.Session) error {
+	userAgent := dto.UserAgent
+
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	query := `INSERT INTO sessions (id, userId, userAgent, ip, createdAt, lastSeenAt, expiresAt) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := repository.db.Exec(query, dto.Id, dto.UserId, userAgent, dto.IP, dto.CreatedAt, dto.LastSeenAt, dto.ExpiresAt)
+
+	return err
+}
+
+func (repository *sessionRepository) FindSession(id uuid.UUID) (domain.Session, error) {
+	return scanSession(repository.db.QueryRow(`SELECT ` + sessionColumns + ` FROM sessions WHERE id = $1`, id))
+}
+
+func (repository *sessionRepository) ListSessions(userId uuid.UUID) ([]domain.Session, error) {
+	sessions := []domain.Session{}
+
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE userId = $1 AND revokedAt IS NULL AND expiresAt > NOW() ORDER BY lastSeenAt DESC`
+
+	rows, err := repository.db.Query(query, userId)
+
+	if err != nil {
+		return []domain.Session{}, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		session, err := scanSession(rows)
+
+		if err != nil {
+			return []domain.Session{}, err
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+func (repository *sessionRepository) RevokeSession(userId uuid.UUID, id uuid.UUID) error {
+	var pk uuid.UUID
+
+	query := `UPDATE sessions SET revokedAt = NOW() WHERE id = $1 AND userId = $2 AND revokedAt IS NULL RETURNING id`
+
+	return repository.db.QueryRow(query, id, userId).Scan(&pk)
+}
+
+func (repository *sessionRepository) TouchSession(id uuid.UUID, lastSeenAt time.Time) error {
+	_, err := repository.db.Exec(`UPDATE sessions SET lastSeenAt = $1 WHERE id = $2`, lastSeenAt, id)
+
+	return err
+}
+
+func scanSession(row rowScanner) (domain.Session, error) {
+	var session domain.Session
+	var revokedAt sql.NullTime
+
+	err := row.Scan(
+		&session.Id,
+		&session.UserId,
+		&session.UserAgent,
+		&session.IP,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+		&session.ExpiresAt,
+		&revokedAt,
+	)
+
+	if err != nil {
+		return domain.Session{}, err
+	}
+
+	session.RevokedAt = revokedAt.Time
+
+	return session, nil
+}
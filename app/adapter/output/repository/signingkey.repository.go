@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+)
+
+const createSigningKeysTableQuery = `CREATE TABLE IF NOT EXISTS signing_keys (
+	id varchar(64) PRIMARY KEY,
+	algorithm varchar(10) NOT NULL,
+	privateKey text NOT NULL,
+	createdAt timestamp NOT NULL DEFAULT NOW(),
+	retiresAt timestamp NOT NULL,
+	expiresAt timestamp NOT NULL
+)`
+
+const signingKeyColumns = `id, algorithm, privateKey, createdAt, retiresAt, expiresAt`
+
+func NewSigningKeyRepository(db *sql.DB) port.SigningKeyRepository {
+	return &signingKeyRepository{
+		db: db,
+	}
+}
+
+type signingKeyRepository struct {
+	db *sql.DB
+}
+
+// ON CONFLICT DO NOTHING lets two instances generate the next key at the same time, both keys
+// are kept since they get different ids
+func (repository *signingKeyRepository) CreateSigningKey(dto domain.SigningKey) error {
+	query := `INSERT INTO signing_keys (` + signingKeyColumns + `) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (id) DO NOTHING`
+
+	_, err := repository.db.Exec(query, dto.Id, dto.Algorithm, dto.EncryptedKey, dto.CreatedAt, dto.RetiresAt, dto.ExpiresAt)
+
+	return err
+}
+
+func (repository *signingKeyRepository) ListSigningKeys(now time.Time) ([]domain.SigningKey, error) {
+	keys := []domain.SigningKey{}
+
+	rows, err := repository.db.Query(`SELECT ` + signingKeyColumns + ` FROM signing_keys WHERE expiresAt > $1 ORDER BY createdAt`, now)
+
+	if err != nil {
+		return []domain.SigningKey{}, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var key domain.SigningKey
+
+		err := rows.Scan(&key.Id, &key.Algorithm, &key.EncryptedKey, &key.CreatedAt, &key.RetiresAt, &key.ExpiresAt)
+
+		if err != nil {
+			return []domain.SigningKey{}, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (repository *signingKeyRepository) DeleteExpiredSigningKeys(now time.Time) (int64, error) {
+	result, err := repository.db.Exec(`DELETE FROM signing_keys WHERE expiresAt <= $1`, now)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
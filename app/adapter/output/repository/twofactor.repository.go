@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+	"github.com/google/uuid"
+)
+
+const createTwoFactorTablesQuery = `CREATE TABLE IF NOT EXISTS user_two_factor (
+	userId uuid PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+	secret text NOT NULL,
+	confirmedAt timestamp,
+	lastUsedStep bigint NOT NULL DEFAULT 0,
+	createdAt timestamp DEFAULT NOW()
+);
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+	userId uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	codeHash varchar(64) NOT NULL,
+	usedAt timestamp,
+	PRIMARY KEY (userId, codeHash)
+)`
+
+func NewTwoFactorRepository(db *sql.DB) port.TwoFactorRepository {
+	return &twoFactorRepository{
+		db: db,
+	}
+}
+
+type twoFactorRepository struct {
+	db *sql.DB
+}
+
+func (repository *twoFactorRepository) FindTwoFactor(userId uuid.UUID) (domain.TwoFactor, error) {
+	var twoFactor domain.TwoFactor
+	var confirmedAt sql.NullTime
+
+	query := `SELECT userId, secret, confirmedAt, lastUsedStep, createdAt FROM user_two_factor WHERE userId = $1`
+
+	err := repository.db.QueryRow(query, userId).Scan(
+		&twoFactor.UserId,
+		&twoFactor.Secret,
+		&confirmedAt,
+		&twoFactor.LastUsedStep,
+		&twoFactor.CreatedAt,
+	)
+
+	if err != nil {
+		return domain.TwoFactor{}, err
+	}
+
+	twoFactor.ConfirmedAt = confirmedAt.Time
+
+	return twoFactor, nil
+}
+
+func (repository *twoFactorRepository) SaveTwoFactor(dto domain.TwoFactor) error {
+	var confirmedAt sql.NullTime
+
+	if dto.Enabled() {
+		confirmedAt = sql.NullTime{Time: dto.ConfirmedAt, Valid: true}
+	}
+
+	query := `INSERT INTO user_two_factor (userId, secret, confirmedAt, lastUsedStep, createdAt) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (userId) DO UPDATE SET secret = EXCLUDED.secret, confirmedAt = EXCLUDED.confirmedAt, lastUsedStep = EXCLUDED.lastUsedStep, createdAt = EXCLUDED.createdAt`
+
+	_, err := repository.db.Exec(query, dto.UserId, dto.Secret, confirmedAt, dto.LastUsedStep, dto.CreatedAt)
+
+	return err
+}
+
+func (repository *twoFactorRepository) DeleteTwoFactor(userId uuid.UUID) error {
+	tx, err := repository.db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM user_recovery_codes WHERE userId = $1`, userId); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM user_two_factor WHERE userId = $1`, userId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReplaceRecoveryCodes drops the previous codes, used or not, so only the last batch is valid
+func (repository *twoFactorRepository) ReplaceRecoveryCodes(userId uuid.UUID, hashes []string) error {
+	tx, err := repository.db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM user_recovery_codes WHERE userId = $1`, userId); err != nil {
+		return err
+	}
+
+	for _, hash := range hashes {
+		if _, err := tx.Exec(`INSERT INTO user_recovery_codes (userId, codeHash) VALUES ($1, $2)`, userId, hash); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UseRecoveryCode flips usedAt in a single statement so two logins can't spend the same code
+func (repository *twoFactorRepository) UseRecoveryCode(userId uuid.UUID, hash string) (bool, error) {
+	var codeHash string
+
+	query := `UPDATE user_recovery_codes SET usedAt = NOW() WHERE userId = $1 AND codeHash = $2 AND usedAt IS NULL RETURNING codeHash`
+
+	err := repository.db.QueryRow(query, userId, hash).Scan(&codeHash)
+
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
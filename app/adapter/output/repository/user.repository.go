@@ -3,6 +3,7 @@ package repository
 import (
 	"database/sql"
 	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
 	"github.com/google/uuid"
 	"time"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 func NewUserRepository(db *sql.DB) UserRepository {
 	return &userRepository{
 		db: db,
+		conn: db,
 	}
 }
 
@@ -25,15 +27,25 @@ type UserRepository interface {
 	Delete(uuid.UUID) (uuid.UUID, error)
 	Update(uuid.UUID, domain.UserDomain) (uuid.UUID, error)
 	UpdatePassword(uuid.UUID, domain.UserDomain) (uuid.UUID, error)
+	UpdateRole(uuid.UUID, string) (uuid.UUID, error)
+	RequirePasswordChange(uuid.UUID) (uuid.UUID, error)
+	UpdateStatus(uuid.UUID, domain.UserStatus) (uuid.UUID, error)
+	ListByStatus(string) ([]domain.UserDomain, error)
+	UpdateLastLogin(uuid.UUID, time.Time) error
+	ListDeleted() ([]domain.UserDomain, error)
+	FindDeletedUser(uuid.UUID) (domain.UserDomain, error)
+	Restore(uuid.UUID) (uuid.UUID, error)
+	Purge(time.Time) ([]uuid.UUID, error)
+	Transaction(func(port.UserRepository) error) error
+	Iterate(domain.UserFilter) (domain.UserIterator, error)
+	Search(domain.UserSearch) (domain.UserSearchPage, error)
+	AddEvent(domain.Event) error
+	AddPasswordHistory(uuid.UUID, string, time.Time) error
+	ListPasswordHistory(uuid.UUID, int) ([]string, error)
+	PrunePasswordHistory(uuid.UUID, int) error
 }
 
-type userRepository struct {
-	db *sql.DB
-}
-
-func (repository *userRepository) CreateTable() error {
-
-query := `CREATE TABLE IF NOT EXISTS users (
+const createUsersTableQuery = `CREATE TABLE IF NOT EXISTS users (
     id uuid UNIQUE PRIMARY KEY,
     name varchar(100) NOT NULL,
     password varchar(100) NOT NULL,
@@ -44,7 +56,22 @@ query := `CREATE TABLE IF NOT EXISTS users (
     deletedAt timestamp
   )`
 
-  _, err := repository.db.Exec(query)
+// executor is satisfied by both *sql.DB and *sql.Tx so the same queries run inside transactions
+type executor interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+type userRepository struct {
+	db executor
+	// conn is nil when the repository is already bound to a transaction
+	conn *sql.DB
+}
+
+func (repository *userRepository) CreateTable() error {
+
+  _, err := repository.db.Exec(createUsersTableQuery)
 
   if err != nil {
     return err
@@ -54,7 +81,7 @@ query := `CREATE TABLE IF NOT EXISTS users (
 }
 
 func (repository *userRepository) Create(dto domain.UserDomain) (uuid.UUID, error) {
-	uDomain, err := domain.CreateUser(
+	uDomain, err := domain.RestoreUser(
 		dto.Id,
 		dto.Name,
 		dto.Email,
@@ -92,322 +119,638 @@ func (repository *userRepository) Create(dto domain.UserDomain) (uuid.UUID, erro
 }
 
 func (repository *userRepository) FindUserByPhone(phone string) (domain.UserDomain,error) {
-	uDomain := domain.UserDomain{}
-	var createdAt sql.NullString
-	var updatedAt sql.NullString
-	var deletedAt sql.NullString
+  query := `SELECT id, name, password, email, phone, createdAt, updatedAt, deletedAt, passwordChangedAt, role, passwordChangeRequired, status, statusReason, statusChangedBy, statusChangedAt, suspendedUntil, lastLoginAt FROM users WHERE phone = $1 AND deletedAt IS NULL`
 
-  query := `SELECT id, name, password, email, phone, createdAt, updatedAt, deletedAt FROM users WHERE phone = $1 AND deletedAt IS NULL`
+	return scanUser(repository.db.QueryRow(query, phone))
+}
 
-  err := repository.db.QueryRow(query, phone).Scan(&uDomain.Id, &uDomain.Name, &uDomain.Password, &uDomain.Email, &uDomain.Phone, &createdAt, &updatedAt, &deletedAt)
+
+func (repository *userRepository) FindUserByEmail(email string) (domain.UserDomain, error) {
+  query := `SELECT id, name, password, email, phone, createdAt, updatedAt, deletedAt, passwordChangedAt, role, passwordChangeRequired, status, statusReason, statusChangedBy, statusChangedAt, suspendedUntil, lastLoginAt FROM users WHERE email = $1 AND deletedAt IS NULL`
+
+	return scanUser(repository.db.QueryRow(query, email))
+}
+
+
+func (repository *userRepository) List(id uuid.UUID) (domain.UserDomain, error) {
+  query := `SELECT id, name, password, email, phone, createdAt, updatedAt, deletedAt, passwordChangedAt, role, passwordChangeRequired, status, statusReason, statusChangedBy, statusChangedAt, suspendedUntil, lastLoginAt FROM users WHERE id = $1 AND deletedAt IS NULL`
+
+	return scanUser(repository.db.QueryRow(query, id))
+}
+
+
+func (repository *userRepository) ListAll() ([]domain.UserDomain, error) {
+	var users []domain.UserDomain
+
+  query := `SELECT id, name, password, email, phone, createdAt, updatedAt, deletedAt, passwordChangedAt, role, passwordChangeRequired, status, statusReason, statusChangedBy, statusChangedAt, suspendedUntil, lastLoginAt FROM users WHERE deletedAt IS NULL`
+
+  rows, err := repository.db.Query(query)
 
 	if err != nil {
-    return domain.UserDomain{}, err
+    return []domain.UserDomain{}, err
   }
 
-	// to use time.Parse is necessary to pass the layout first and the value second
-	// the layout is exclusively made with this date
-	// this date is used because every field has a exclusive value
-
-	timeParserLayout := "2006-01-02T15:04:05"
+	defer rows.Close()
 
-	if createdAt.Valid {
-		parsedCreatedAt, err := time.Parse(timeParserLayout, createdAt.String[0:19])
+	for rows.Next() {
+		uDomain, err := scanUser(rows)
 
 		if err != nil {
-			return domain.UserDomain{}, err
+			return []domain.UserDomain{}, err
 		}
 
-		uDomain.CreatedAt = parsedCreatedAt
+		users = append(users, uDomain)
 	}
 
-	if updatedAt.Valid {
-		parsedUpdatedAt, err := time.Parse(timeParserLayout, updatedAt.String[0:19])
+	if err := rows.Err(); err != nil {
+		return []domain.UserDomain{}, err
+	}
+
+  return users, nil
+}
 
-		if err != nil {
-			return domain.UserDomain{}, err
-		}
 
-		uDomain.UpdatedAt = parsedUpdatedAt
+func (repository *userRepository) Delete(id uuid.UUID) (uuid.UUID, error) {
+	var pk uuid.UUID
+
+  //query := `DELETE FROM users WHERE id = $1 RETURNING id`
+	query := `UPDATE users SET deletedAt = $2, status = 'deactivated', statusReason = '', statusChangedBy = '', statusChangedAt = $2, suspendedUntil = NULL WHERE id = $1 RETURNING id`
+
+  err := repository.db.QueryRow(query, id, time.Now().UTC()).Scan(&pk)
+
+  if err != nil {
+    return uuid.Nil, err
+  }
+
+  return pk, nil
+}
+
+func (repository *userRepository) Update(id uuid.UUID, dto domain.UserDomain) (uuid.UUID, error) {
+	uDomain, err := domain.RestoreUser(
+		dto.Id,
+		dto.Name,
+		dto.Email,
+		dto.Phone,
+		dto.Password,
+		dto.CreatedAt,
+		dto.UpdatedAt,
+		dto.DeletedAt,
+	)
+
+	if err != nil {
+		return uuid.Nil, err
 	}
 
-	if deletedAt.Valid {
-		parsedDeletedAt, err := time.Parse(timeParserLayout, deletedAt.String[0:19])
+	setClauses := []string{}
+	args := []any{id}
+	argIndex := 2
 
-		if err != nil {
-			return domain.UserDomain{}, err
-		}
+	if uDomain.Name != "" {
+		setClauses = append(setClauses, fmt.Sprintf("name = $%d", argIndex))
+		args = append(args, uDomain.Name)
+		argIndex++
+	}
 
-		uDomain.DeletedAt = parsedDeletedAt
+	if uDomain.Email != "" {
+		setClauses = append(setClauses, fmt.Sprintf("email = $%d", argIndex)) // 3
+		args = append(args, uDomain.Email)
+		argIndex++
 	}
 
-  return uDomain, nil
-}
+	if uDomain.Phone != "" {
+		setClauses = append(setClauses, fmt.Sprintf("phone = $%d", argIndex))
+		args = append(args, uDomain.Phone)
+		argIndex++
+	}
 
-func (repository *userRepository) FindUserByEmail(email string) (domain.UserDomain, error) {
-	uDomain := domain.UserDomain{}
-	var createdAt sql.NullString
-	var updatedAt sql.NullString
-	var deletedAt sql.NullString
+	setClauses = append(setClauses, fmt.Sprintf("updatedAt = $%d", argIndex))
+	args = append(args, time.Now())
+
+	query := fmt.Sprintf(`UPDATE users SET %s WHERE id = $1 RETURNING id`, strings.Join(setClauses, ", "))
 
-  query := `SELECT id, name, password, email, phone, createdAt, updatedAt, deletedAt FROM users WHERE email = $1 AND deletedAt IS NULL`
+	var pk uuid.UUID
 
-  err := repository.db.QueryRow(query, email).Scan(&uDomain.Id, &uDomain.Name, &uDomain.Password, &uDomain.Email, &uDomain.Phone, &createdAt, &updatedAt, &deletedAt)
+	err = repository.db.QueryRow(query, args...).Scan(&pk)
 
 	if err != nil {
-    return domain.UserDomain{}, err
-  }
+		return uuid.Nil, err
+	}
 
-	// to use time.Parse is necessary to pass the layout first and the value second
-	// the layout is exclusively made with this date
-	// this date is used because every field has a exclusive value
+	return pk, nil
+}
 
-	timeParserLayout := "2006-01-02T15:04:05"
+func (repository *userRepository) UpdatePassword(id uuid.UUID, dto domain.UserDomain) (uuid.UUID, error) {
+	uDomain, err := domain.RestoreUser(
+		dto.Id,
+		dto.Name,
+		dto.Email,
+		dto.Phone,
+		dto.Password,
+		dto.CreatedAt,
+		dto.UpdatedAt,
+		dto.DeletedAt,
+	)
 
-	if createdAt.Valid {
-		parsedCreatedAt, err := time.Parse(timeParserLayout, createdAt.String[0:19])
+	if err != nil {
+		return uuid.Nil, err
+	}
 
-		if err != nil {
-			return domain.UserDomain{}, err
-		}
+	// a rehash keeps the password so it doesn't inform when it changed
+	query := `UPDATE users SET password = $2, passwordChangedAt = COALESCE($3, passwordChangedAt), passwordChangeRequired = $4 WHERE id = $1 RETURNING id`
 
-		uDomain.CreatedAt = parsedCreatedAt
-	}
+	changedAt := sql.NullTime{Time: dto.PasswordChangedAt.UTC(), Valid: !dto.PasswordChangedAt.IsZero()}
 
-	if updatedAt.Valid {
-		parsedUpdatedAt, err := time.Parse(timeParserLayout, updatedAt.String[0:19])
+	var pk uuid.UUID
 
-		if err != nil {
-			return domain.UserDomain{}, err
-		}
+	err = repository.db.QueryRow(query, id, uDomain.Password, changedAt, dto.PasswordChangeRequired).Scan(&pk)
 
-		uDomain.UpdatedAt = parsedUpdatedAt
+	if err != nil {
+		return uuid.Nil, err
 	}
 
-	if deletedAt.Valid {
-		parsedDeletedAt, err := time.Parse(timeParserLayout, deletedAt.String[0:19])
+	return pk, nil
+}
 
-		if err != nil {
-			return domain.UserDomain{}, err
-		}
+func (repository *userRepository) UpdateRole(id uuid.UUID, role string) (uuid.UUID, error) {
+	var pk uuid.UUID
+
+	query := `UPDATE users SET role = $2, updatedAt = $3 WHERE id = $1 AND deletedAt IS NULL RETURNING id`
 
-		uDomain.DeletedAt = parsedDeletedAt
+	err := repository.db.QueryRow(query, id, role, time.Now()).Scan(&pk)
+
+	if err != nil {
+		return uuid.Nil, err
 	}
 
-  return uDomain, nil
+	return pk, nil
 }
 
-func (repository *userRepository) List(id uuid.UUID) (domain.UserDomain, error) {
-	uDomain := domain.UserDomain{}
-	var createdAt sql.NullString
-	var updatedAt sql.NullString
-	var deletedAt sql.NullString
+// RequirePasswordChange makes the next login ask for a new password, changing it clears the flag
+func (repository *userRepository) RequirePasswordChange(id uuid.UUID) (uuid.UUID, error) {
+	var pk uuid.UUID
 
-  query := `SELECT id, name, password, email, phone, createdAt, updatedAt, deletedAt FROM users WHERE id = $1 AND deletedAt IS NULL`
+	query := `UPDATE users SET passwordChangeRequired = true WHERE id = $1 AND deletedAt IS NULL RETURNING id`
 
-  err := repository.db.QueryRow(query, id).Scan(&uDomain.Id, &uDomain.Name, &uDomain.Password, &uDomain.Email, &uDomain.Phone, &createdAt, &updatedAt, &deletedAt)
+	err := repository.db.QueryRow(query, id).Scan(&pk)
 
 	if err != nil {
-    return domain.UserDomain{}, err
-  }
+		return uuid.Nil, err
+	}
 
-	// to use time.Parse is necessary to pass the layout first and the value second
-	// the layout is exclusively made with this date
-	// this date is used because every field has a exclusive value
+	return pk, nil
+}
 
-	timeParserLayout := "2006-01-02T15:04:05"
+func (repository *userRepository) UpdateStatus(id uuid.UUID, status domain.UserStatus) (uuid.UUID, error) {
+	var pk uuid.UUID
 
-	if createdAt.Valid {
-		parsedCreatedAt, err := time.Parse(timeParserLayout, createdAt.String[0:19])
+	query := `UPDATE users SET status = $2, statusReason = $3, statusChangedBy = $4, statusChangedAt = $5, suspendedUntil = $6
+	WHERE id = $1 AND deletedAt IS NULL RETURNING id`
 
-		if err != nil {
-			return domain.UserDomain{}, err
-		}
+	until := sql.NullTime{Time: status.SuspendedUntil.UTC(), Valid: !status.SuspendedUntil.IsZero()}
+
+	err := repository.db.QueryRow(query, id, status.State, status.Reason, status.ChangedBy, status.ChangedAt.UTC(), until).Scan(&pk)
 
-		uDomain.CreatedAt = parsedCreatedAt
+	if err != nil {
+		return uuid.Nil, err
 	}
 
-	if updatedAt.Valid {
-		parsedUpdatedAt, err := time.Parse(timeParserLayout, updatedAt.String[0:19])
+	return pk, nil
+}
 
-		if err != nil {
-			return domain.UserDomain{}, err
-		}
+// UpdateLastLogin only records the time, it is not an update of the user so updatedAt is kept
+func (repository *userRepository) UpdateLastLogin(id uuid.UUID, lastLoginAt time.Time) error {
+	_, err := repository.db.Exec(`UPDATE users SET lastLoginAt = $2 WHERE id = $1`, id, lastLoginAt.UTC())
+
+	return err
+}
 
-		uDomain.UpdatedAt = parsedUpdatedAt
+// ListByStatus filters by the current state, a suspension that reached its end counts as active
+func (repository *userRepository) ListByStatus(state string) ([]domain.UserDomain, error) {
+	users := []domain.UserDomain{}
+
+	query := `SELECT id, name, password, email, phone, createdAt, updatedAt, deletedAt, passwordChangedAt, role, passwordChangeRequired, status, statusReason, statusChangedBy, statusChangedAt, suspendedUntil, lastLoginAt FROM users
+	WHERE CASE WHEN status = 'suspended' AND suspendedUntil <= $2 THEN 'active' ELSE status END = $1
+	ORDER BY createdAt, id`
+
+	rows, err := repository.db.Query(query, state, time.Now().UTC())
+
+	if err != nil {
+		return []domain.UserDomain{}, err
 	}
 
-	if deletedAt.Valid {
-		parsedDeletedAt, err := time.Parse(timeParserLayout, deletedAt.String[0:19])
+	defer rows.Close()
+
+	for rows.Next() {
+		uDomain, err := scanUser(rows)
 
 		if err != nil {
-			return domain.UserDomain{}, err
+			return []domain.UserDomain{}, err
 		}
 
-		uDomain.DeletedAt = parsedDeletedAt
+		users = append(users, uDomain)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []domain.UserDomain{}, err
 	}
 
-  return uDomain, nil
+	return users, nil
 }
 
-func (repository *userRepository) ListAll() ([]domain.UserDomain, error) {
+func (repository *userRepository) ListDeleted() ([]domain.UserDomain, error) {
 	var users []domain.UserDomain
 
-  query := `SELECT id, name, password, email, phone, createdAt, updatedAt, deletedAt FROM users WHERE deletedAt IS NULL`
+	query := `SELECT id, name, password, email, phone, createdAt, updatedAt, deletedAt, passwordChangedAt, role, passwordChangeRequired, status, statusReason, statusChangedBy, statusChangedAt, suspendedUntil, lastLoginAt FROM users WHERE deletedAt IS NOT NULL ORDER BY deletedAt`
 
-  rows, err := repository.db.Query(query)
+	rows, err := repository.db.Query(query)
 
 	if err != nil {
-    return []domain.UserDomain{}, err
-  }
+		return []domain.UserDomain{}, err
+	}
 
 	defer rows.Close()
 
 	for rows.Next() {
-		uDomain := domain.UserDomain{}
-		var createdAt sql.NullString
-		var updatedAt sql.NullString
-		var deletedAt sql.NullString
+		uDomain, err := scanUser(rows)
 
-		rows.Scan(&uDomain.Id, &uDomain.Name, &uDomain.Password, &uDomain.Email, &uDomain.Phone, &createdAt, &updatedAt, &deletedAt)
+		if err != nil {
+			return []domain.UserDomain{}, err
+		}
 
-		// to use time.Parse is necessary to pass the layout first and the value second
-		// the layout is exclusively made with this date
-		// this date is used because every field has a exclusive value
-		timeParserLayout := "2006-01-02T15:04:05"
+		users = append(users, uDomain)
+	}
 
-		if createdAt.Valid {
-			parsedCreatedAt, err := time.Parse(timeParserLayout, createdAt.String[0:19])
+	if err := rows.Err(); err != nil {
+		return []domain.UserDomain{}, err
+	}
 
-			if err != nil {
-				return []domain.UserDomain{}, err
-			}
+	return users, nil
+}
 
-			uDomain.CreatedAt = parsedCreatedAt
-		}
+func (repository *userRepository) FindDeletedUser(id uuid.UUID) (domain.UserDomain, error) {
+	query := `SELECT id, name, password, email, phone, createdAt, updatedAt, deletedAt, passwordChangedAt, role, passwordChangeRequired, status, statusReason, statusChangedBy, statusChangedAt, suspendedUntil, lastLoginAt FROM users WHERE id = $1 AND deletedAt IS NOT NULL`
 
-		if updatedAt.Valid {
-			parsedUpdatedAt, err := time.Parse(timeParserLayout, updatedAt.String[0:19])
+	uDomain, err := scanUser(repository.db.QueryRow(query, id))
 
-			if err != nil {
-				return []domain.UserDomain{}, err
-			}
+	if err != nil {
+		return domain.UserDomain{}, err
+	}
 
-			uDomain.UpdatedAt = parsedUpdatedAt
-		}
+	return uDomain, nil
+}
+
+func (repository *userRepository) Restore(id uuid.UUID) (uuid.UUID, error) {
+	var pk uuid.UUID
 
-		if deletedAt.Valid {
-			parsedDeletedAt, err := time.Parse(timeParserLayout, deletedAt.String[0:19])
+	query := `UPDATE users SET deletedAt = NULL, updatedAt = $2, status = 'active', statusReason = '', statusChangedBy = '', statusChangedAt = $2, suspendedUntil = NULL
+	WHERE id = $1 AND deletedAt IS NOT NULL RETURNING id`
+
+	err := repository.db.QueryRow(query, id, time.Now().UTC()).Scan(&pk)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return pk, nil
+}
 
-			if err != nil {
-				return []domain.UserDomain{}, err
-			}
+// Purge permanently removes every user soft deleted before the informed date
+func (repository *userRepository) Purge(deletedBefore time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
 
-			uDomain.DeletedAt = parsedDeletedAt
+	query := `DELETE FROM users WHERE deletedAt IS NOT NULL AND deletedAt < $1 RETURNING id`
+
+	rows, err := repository.db.Query(query, deletedBefore)
+
+	if err != nil {
+		return []uuid.UUID{}, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var pk uuid.UUID
+
+		if err := rows.Scan(&pk); err != nil {
+			return []uuid.UUID{}, err
 		}
 
-		users = append(users, uDomain)
+		ids = append(ids, pk)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []uuid.UUID{}, err
+	}
 
-  return users, nil
+	return ids, nil
 }
 
+// Transaction runs fn with a repository bound to a single transaction, it is committed when fn returns nil.
+// Inside another transaction fn runs in a savepoint, so its failure only undoes its own writes and
+// leaves the outer transaction usable
+func (repository *userRepository) Transaction(fn func(port.UserRepository) error) error {
+	if repository.conn == nil {
+		return repository.savepoint(fn)
+	}
 
-func (repository *userRepository) Delete(id uuid.UUID) (uuid.UUID, error) {
-	var pk uuid.UUID
+	tx, err := repository.conn.Begin()
 
-  //query := `DELETE FROM users WHERE id = $1 RETURNING id`
-	query := `UPDATE users SET deletedAt = $2 WHERE id = $1 RETURNING id`
+	if err != nil {
+		return err
+	}
 
-  err := repository.db.QueryRow(query, id, time.Now()).Scan(&pk)
+	if err := fn(&userRepository{db: tx}); err != nil {
+		tx.Rollback()
 
-  if err != nil {
-    return uuid.Nil, err
-  }
+		return err
+	}
 
-  return pk, nil
+	return tx.Commit()
 }
 
-func (repository *userRepository) Update(id uuid.UUID, dto domain.UserDomain) (uuid.UUID, error) {
-	uDomain, err := domain.CreateUser(
-		dto.Id,
-		dto.Name,
-		dto.Email,
-		dto.Phone,
-		dto.Password,
-		dto.CreatedAt,
-		dto.UpdatedAt,
-		dto.DeletedAt,
-	)
+// savepoint names can be reused, ROLLBACK TO and RELEASE act on the latest one so nesting still works
+func (repository *userRepository) savepoint(fn func(port.UserRepository) error) error {
+	if _, err := repository.db.Exec("SAVEPOINT nested_transaction"); err != nil {
+		return err
+	}
+
+	if err := fn(repository); err != nil {
+		repository.db.Exec("ROLLBACK TO SAVEPOINT nested_transaction")
+
+		return err
+	}
+
+	_, err := repository.db.Exec("RELEASE SAVEPOINT nested_transaction")
+
+	return err
+}
+
+// Iterate streams the users matching the filter ordered by creation date without loading them in memory
+func (repository *userRepository) Iterate(filter domain.UserFilter) (domain.UserIterator, error) {
+	whereClauses := []string{}
+	args := []any{}
+
+	if !filter.IncludeDeleted {
+		whereClauses = append(whereClauses, "deletedAt IS NULL")
+	}
+
+	if !filter.CreatedFrom.IsZero() {
+		args = append(args, filter.CreatedFrom)
+		whereClauses = append(whereClauses, fmt.Sprintf("createdAt >= $%d", len(args)))
+	}
+
+	if !filter.CreatedTo.IsZero() {
+		args = append(args, filter.CreatedTo)
+		whereClauses = append(whereClauses, fmt.Sprintf("createdAt < $%d", len(args)))
+	}
+
+	query := `SELECT id, name, password, email, phone, createdAt, updatedAt, deletedAt, passwordChangedAt, role, passwordChangeRequired, status, statusReason, statusChangedBy, statusChangedAt, suspendedUntil, lastLoginAt FROM users`
+
+	if len(whereClauses) > 0 {
+		query += " WHERE " + strings.Join(whereClauses, " AND ")
+	}
+
+	query += " ORDER BY createdAt, id"
+
+	rows, err := repository.db.Query(query, args...)
 
 	if err != nil {
-		return uuid.Nil, err
+		return nil, err
 	}
 
-	setClauses := []string{}
-	args := []any{id}
-	argIndex := 2
+	return &userIterator{rows: rows}, nil
+}
 
-	if uDomain.Name != "" {
-		setClauses = append(setClauses, fmt.Sprintf("name = $%d", argIndex))
-		args = append(args, uDomain.Name)
-		argIndex++
+type userIterator struct {
+	rows *sql.Rows
+	user domain.UserDomain
+	err error
+}
+
+func (iterator *userIterator) Next() bool {
+	if iterator.err != nil || !iterator.rows.Next() {
+		return false
 	}
 
-	if uDomain.Email != "" {
-		setClauses = append(setClauses, fmt.Sprintf("email = $%d", argIndex)) // 3
-		args = append(args, uDomain.Email)
-		argIndex++
+	iterator.user, iterator.err = scanUser(iterator.rows)
+
+	return iterator.err == nil
+}
+
+func (iterator *userIterator) User() domain.UserDomain {
+	return iterator.user
+}
+
+func (iterator *userIterator) Err() error {
+	if iterator.err != nil {
+		return iterator.err
 	}
 
-	if uDomain.Phone != "" {
-		setClauses = append(setClauses, fmt.Sprintf("phone = $%d", argIndex))
-		args = append(args, uDomain.Phone)
-		argIndex++
+	return iterator.rows.Err()
+}
+
+func (iterator *userIterator) Close() error {
+	return iterator.rows.Close()
+}
+
+const createUserSearchIndexesQuery = `CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS users_name_trgm_idx ON users USING gin (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS users_email_trgm_idx ON users USING gin (email gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS users_phone_trgm_idx ON users USING gin (phone gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS users_name_fts_idx ON users USING gin (to_tsvector('simple', name))`
+
+const addUsersPasswordChangedAtQuery = `ALTER TABLE users ADD COLUMN IF NOT EXISTS passwordChangedAt timestamp`
+
+const addUsersRoleQuery = `ALTER TABLE users ADD COLUMN IF NOT EXISTS role varchar(20) NOT NULL DEFAULT 'user',
+	ADD COLUMN IF NOT EXISTS passwordChangeRequired boolean NOT NULL DEFAULT false`
+
+// the users deleted before the status existed are deactivated
+const addUsersStatusQuery = `ALTER TABLE users ADD COLUMN IF NOT EXISTS status varchar(20) NOT NULL DEFAULT 'active',
+	ADD COLUMN IF NOT EXISTS statusReason varchar(255) NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS statusChangedBy varchar(100) NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS statusChangedAt timestamp,
+	ADD COLUMN IF NOT EXISTS suspendedUntil timestamp;
+UPDATE users SET status = 'deactivated', statusChangedAt = deletedAt WHERE deletedAt IS NOT NULL;
+CREATE INDEX IF NOT EXISTS users_status_idx ON users (status)`
+
+const widenUsersPasswordQuery = `ALTER TABLE users ALTER COLUMN password TYPE varchar(255)`
+
+// Search ranks the active users by full text and trigram similarity, the scores follow
+// domain.ScoreUser: exact 1, prefix 0.9, substring 0.8, otherwise the similarity
+func (repository *userRepository) Search(search domain.UserSearch) (domain.UserSearchPage, error) {
+	page := domain.UserSearchPage{
+		Hits: []domain.UserSearchHit{},
+		Limit: search.Limit,
+		Offset: search.Offset,
 	}
 
-	setClauses = append(setClauses, fmt.Sprintf("updatedAt = $%d", argIndex))
-	args = append(args, time.Now())
+	query := `SELECT id, name, password, email, phone, createdAt, updatedAt, deletedAt, nameScore, emailScore, phoneScore, COUNT(*) OVER() AS total
+	FROM (
+		SELECT id, name, password, email, phone, createdAt, updatedAt, deletedAt,
+			CASE WHEN lower(name) = lower($1) THEN 1
+				WHEN name ILIKE $2 THEN 0.9
+				WHEN name ILIKE $3 THEN 0.8
+				ELSE GREATEST(similarity(name, $1), ts_rank(to_tsvector('simple', name), plainto_tsquery('simple', $1))) END AS nameScore,
+			CASE WHEN lower(email) = lower($1) THEN 1
+				WHEN email ILIKE $2 THEN 0.9
+				WHEN email ILIKE $3 THEN 0.8
+				ELSE similarity(email, $1) END AS emailScore,
+			CASE WHEN $4 <> '' AND phone LIKE $5 THEN 0.8 ELSE 0 END AS phoneScore
+		FROM users
+		WHERE deletedAt IS NULL AND (
+			to_tsvector('simple', name) @@ plainto_tsquery('simple', $1)
+			OR name % $1 OR email % $1
+			OR name ILIKE $3 OR email ILIKE $3
+			OR ($4 <> '' AND phone LIKE $5)
+		)
+	) AS matches
+	WHERE GREATEST(nameScore, emailScore, phoneScore) >= $6
+	ORDER BY GREATEST(nameScore, emailScore, phoneScore) DESC, name, id
+	LIMIT $7 OFFSET $8`
+
+	escaped := escapeLike(search.Query)
+	digits := domain.SearchDigits(search.Query)
+
+	if len(digits) < 3 {
+		digits = ""
+	}
 
-	query := fmt.Sprintf(`UPDATE users SET %s WHERE id = $1 RETURNING id`, strings.Join(setClauses, ", "))
+	rows, err := repository.db.Query(query, search.Query, escaped + "%", "%" + escaped + "%", digits, "%" + digits + "%", domain.SearchSimilarityThreshold, search.Limit, search.Offset)
 
-	var pk uuid.UUID
+	if err != nil {
+		return page, err
+	}
 
-	err = repository.db.QueryRow(query, args...).Scan(&pk)
+	defer rows.Close()
+
+	for rows.Next() {
+		var createdAt sql.NullString
+		var updatedAt sql.NullString
+		var deletedAt sql.NullString
+		var nameScore, emailScore, phoneScore float64
+
+		hit := domain.UserSearchHit{}
+		user := &hit.User
+
+		err := rows.Scan(&user.Id, &user.Name, &user.Password, &user.Email, &user.Phone, &createdAt, &updatedAt, &deletedAt, &nameScore, &emailScore, &phoneScore, &page.Total)
+
+		if err != nil {
+			return page, err
+		}
+
+		if user.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+			return page, err
+		}
+
+		if user.UpdatedAt, err = parseTimestamp(updatedAt); err != nil {
+			return page, err
+		}
+
+		if user.DeletedAt, err = parseTimestamp(deletedAt); err != nil {
+			return page, err
+		}
+
+		hit.Field, hit.Score = domain.SearchFieldName, nameScore
+
+		if emailScore > hit.Score {
+			hit.Field, hit.Score = domain.SearchFieldEmail, emailScore
+		}
+
+		if phoneScore > hit.Score {
+			hit.Field, hit.Score = domain.SearchFieldPhone, phoneScore
+		}
+
+		page.Hits = append(page.Hits, hit)
+	}
+
+	return page, rows.Err()
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// AddEvent writes the event to the outbox, call it inside Transaction so it is only
+// stored together with the users change
+func (repository *userRepository) AddEvent(event domain.Event) error {
+	message, err := domain.NewOutboxMessage(event)
 
 	if err != nil {
-		return uuid.Nil, err
+		return err
 	}
 
-	return pk, nil
+	return insertOutboxMessage(repository.db, message)
 }
 
-func (repository *userRepository) UpdatePassword(id uuid.UUID, dto domain.UserDomain) (uuid.UUID, error) {
-	uDomain, err := domain.CreateUser(
-		dto.Id,
-		dto.Name,
-		dto.Email,
-		dto.Phone,
-		dto.Password,
-		dto.CreatedAt,
-		dto.UpdatedAt,
-		dto.DeletedAt,
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanUser reads a row selected as "id, name, password, email, phone, createdAt, updatedAt, deletedAt, passwordChangedAt,
+// role, passwordChangeRequired, status, statusReason, statusChangedBy, statusChangedAt, suspendedUntil, lastLoginAt"
+func scanUser(row rowScanner) (domain.UserDomain, error) {
+	uDomain := domain.UserDomain{}
+	var createdAt sql.NullString
+	var updatedAt sql.NullString
+	var deletedAt sql.NullString
+	var passwordChangedAt sql.NullString
+	var statusChangedAt sql.NullString
+	var suspendedUntil sql.NullString
+	var lastLoginAt sql.NullString
+
+	err := row.Scan(
+		&uDomain.Id, &uDomain.Name, &uDomain.Password, &uDomain.Email, &uDomain.Phone, &createdAt, &updatedAt, &deletedAt, &passwordChangedAt,
+		&uDomain.Role, &uDomain.PasswordChangeRequired,
+		&uDomain.Status.State, &uDomain.Status.Reason, &uDomain.Status.ChangedBy, &statusChangedAt, &suspendedUntil,
+		&lastLoginAt,
 	)
 
 	if err != nil {
-		return uuid.Nil, err
+		return domain.UserDomain{}, err
 	}
 
-	query := `UPDATE users SET password = $2 WHERE id = $1 RETURNING id`
+	if uDomain.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+		return domain.UserDomain{}, err
+	}
 
-	var pk uuid.UUID
+	if uDomain.UpdatedAt, err = parseTimestamp(updatedAt); err != nil {
+		return domain.UserDomain{}, err
+	}
 
-	err = repository.db.QueryRow(query, id, uDomain.Password).Scan(&pk)
+	if uDomain.DeletedAt, err = parseTimestamp(deletedAt); err != nil {
+		return domain.UserDomain{}, err
+	}
 
-	if err != nil {
-		return uuid.Nil, err
+	if uDomain.PasswordChangedAt, err = parseTimestamp(passwordChangedAt); err != nil {
+		return domain.UserDomain{}, err
 	}
 
-	return pk, nil
+	if uDomain.Status.ChangedAt, err = parseTimestamp(statusChangedAt); err != nil {
+		return domain.UserDomain{}, err
+	}
+
+	if uDomain.Status.SuspendedUntil, err = parseTimestamp(suspendedUntil); err != nil {
+		return domain.UserDomain{}, err
+	}
+
+	if uDomain.LastLoginAt, err = parseTimestamp(lastLoginAt); err != nil {
+		return domain.UserDomain{}, err
+	}
+
+	return uDomain, nil
+}
+
+// parseTimestamp uses the same layout as the other queries, a null column becomes the zero time
+func parseTimestamp(value sql.NullString) (time.Time, error) {
+	if !value.Valid {
+		return time.Time{}, nil
+	}
+
+	timeParserLayout := "2006-01-02T15:04:05"
+
+	if len(value.String) < len(timeParserLayout) {
+		return time.Parse(timeParserLayout, value.String)
+	}
+
+	return time.Parse(timeParserLayout, value.String[0:len(timeParserLayout)])
 }
@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+	"github.com/google/uuid"
+)
+
+const createWebhooksTablesQuery = `CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+	id uuid PRIMARY KEY,
+	url text NOT NULL,
+	eventTypes text NOT NULL,
+	secret varchar(255) NOT NULL,
+	active boolean NOT NULL DEFAULT true,
+	createdAt timestamp DEFAULT NOW(),
+	updatedAt timestamp
+);
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	id uuid PRIMARY KEY,
+	subscriptionId uuid NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+	eventId uuid NOT NULL,
+	eventType varchar(100) NOT NULL,
+	payload bytea NOT NULL,
+	status varchar(20) NOT NULL,
+	attempts integer NOT NULL DEFAULT 0,
+	nextAttemptAt timestamp,
+	lastStatusCode integer NOT NULL DEFAULT 0,
+	lastError text,
+	createdAt timestamp DEFAULT NOW(),
+	deliveredAt timestamp,
+	UNIQUE (subscriptionId, eventId)
+);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (nextAttemptAt) WHERE status = 'pending'`
+
+const webhookDeliveryColumns = `id, subscriptionId, eventId, eventType, payload, status, attempts, nextAttemptAt, lastStatusCode, lastError, createdAt, deliveredAt`
+
+func NewWebhookRepository(db *sql.DB) port.WebhookRepository {
+	return &webhookRepository{
+		db: db,
+	}
+}
+
+type webhookRepository struct {
+	db *sql.DB
+}
+
+// event types are stored comma separated, they never contain commas
+func (repository *webhookRepository) CreateSubscription(dto domain.WebhookSubscription) (uuid.UUID, error) {
+	var pk uuid.UUID
+
+	query := `INSERT INTO webhook_subscriptions (id, url, eventTypes, secret, active, createdAt) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+
+	err := repository.db.QueryRow(query, dto.Id, dto.Url, strings.Join(dto.EventTypes, ","), dto.Secret, dto.Active, dto.CreatedAt).Scan(&pk)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return pk, nil
+}
+
+func (repository *webhookRepository) FindSubscription(id uuid.UUID) (domain.WebhookSubscription, error) {
+	query := `SELECT id, url, eventTypes, secret, active, createdAt, updatedAt FROM webhook_subscriptions WHERE id = $1`
+
+	return scanWebhookSubscription(repository.db.QueryRow(query, id))
+}
+
+func (repository *webhookRepository) ListSubscriptions() ([]domain.WebhookSubscription, error) {
+	subscriptions := []domain.WebhookSubscription{}
+
+	rows, err := repository.db.Query(`SELECT id, url, eventTypes, secret, active, createdAt, updatedAt FROM webhook_subscriptions ORDER BY createdAt`)
+
+	if err != nil {
+		return []domain.WebhookSubscription{}, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		subscription, err := scanWebhookSubscription(rows)
+
+		if err != nil {
+			return []domain.WebhookSubscription{}, err
+		}
+
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []domain.WebhookSubscription{}, err
+	}
+
+	return subscriptions, nil
+}
+
+func (repository *webhookRepository) UpdateSubscription(dto domain.WebhookSubscription) (uuid.UUID, error) {
+	var pk uuid.UUID
+
+	query := `UPDATE webhook_subscriptions SET url = $2, eventTypes = $3, secret = $4, active = $5, updatedAt = $6 WHERE id = $1 RETURNING id`
+
+	err := repository.db.QueryRow(query, dto.Id, dto.Url, strings.Join(dto.EventTypes, ","), dto.Secret, dto.Active, dto.UpdatedAt).Scan(&pk)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return pk, nil
+}
+
+// DeleteSubscription also removes its deliveries
+func (repository *webhookRepository) DeleteSubscription(id uuid.UUID) (uuid.UUID, error) {
+	var pk uuid.UUID
+
+	err := repository.db.QueryRow(`DELETE FROM webhook_subscriptions WHERE id = $1 RETURNING id`, id).Scan(&pk)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return pk, nil
+}
+
+func (repository *webhookRepository) CreateDelivery(dto domain.WebhookDelivery) error {
+	query := `INSERT INTO webhook_deliveries (id, subscriptionId, eventId, eventType, payload, status, nextAttemptAt, createdAt) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (subscriptionId, eventId) DO NOTHING`
+
+	_, err := repository.db.Exec(query, dto.Id, dto.SubscriptionId, dto.EventId, dto.EventType, dto.Payload, dto.Status, dto.NextAttemptAt, dto.CreatedAt)
+
+	return err
+}
+
+func (repository *webhookRepository) FindDelivery(id uuid.UUID) (domain.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id = $1`
+
+	return scanWebhookDelivery(repository.db.QueryRow(query, id))
+}
+
+// ListDeliveries returns the most recent deliveries of a subscription first
+func (repository *webhookRepository) ListDeliveries(subscriptionId uuid.UUID, limit int) ([]domain.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE subscriptionId = $1 ORDER BY createdAt DESC LIMIT $2`
+
+	return repository.queryDeliveries(query, subscriptionId, limit)
+}
+
+func (repository *webhookRepository) ListDueDeliveries(now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE status = $1 AND nextAttemptAt <= $2 ORDER BY nextAttemptAt LIMIT $3`
+
+	return repository.queryDeliveries(query, domain.WebhookDeliveryPending, now, limit)
+}
+
+func (repository *webhookRepository) UpdateDelivery(dto domain.WebhookDelivery) error {
+	var deliveredAt sql.NullTime
+
+	if !dto.DeliveredAt.IsZero() {
+		deliveredAt = sql.NullTime{Time: dto.DeliveredAt, Valid: true}
+	}
+
+	query := `UPDATE webhook_deliveries SET status = $2, attempts = $3, nextAttemptAt = $4, lastStatusCode = $5, lastError = $6, deliveredAt = $7 WHERE id = $1`
+
+	_, err := repository.db.Exec(query, dto.Id, dto.Status, dto.Attempts, dto.NextAttemptAt, dto.LastStatusCode, dto.LastError, deliveredAt)
+
+	return err
+}
+
+func (repository *webhookRepository) queryDeliveries(query string, args ...any) ([]domain.WebhookDelivery, error) {
+	deliveries := []domain.WebhookDelivery{}
+
+	rows, err := repository.db.Query(query, args...)
+
+	if err != nil {
+		return []domain.WebhookDelivery{}, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+
+		if err != nil {
+			return []domain.WebhookDelivery{}, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []domain.WebhookDelivery{}, err
+	}
+
+	return deliveries, nil
+}
+
+func scanWebhookSubscription(row rowScanner) (domain.WebhookSubscription, error) {
+	subscription := domain.WebhookSubscription{}
+	var eventTypes string
+	var createdAt sql.NullString
+	var updatedAt sql.NullString
+
+	err := row.Scan(&subscription.Id, &subscription.Url, &eventTypes, &subscription.Secret, &subscription.Active, &createdAt, &updatedAt)
+
+	if err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+
+	subscription.EventTypes = strings.Split(eventTypes, ",")
+
+	if subscription.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+
+	if subscription.UpdatedAt, err = parseTimestamp(updatedAt); err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+
+	return subscription, nil
+}
+
+func scanWebhookDelivery(row rowScanner) (domain.WebhookDelivery, error) {
+	delivery := domain.WebhookDelivery{}
+	var nextAttemptAt sql.NullString
+	var lastError sql.NullString
+	var createdAt sql.NullString
+	var deliveredAt sql.NullString
+
+	err := row.Scan(&delivery.Id, &delivery.SubscriptionId, &delivery.EventId, &delivery.EventType, &delivery.Payload, &delivery.Status, &delivery.Attempts, &nextAttemptAt, &delivery.LastStatusCode, &lastError, &createdAt, &deliveredAt)
+
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	delivery.LastError = lastError.String
+
+	if delivery.NextAttemptAt, err = parseTimestamp(nextAttemptAt); err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	if delivery.CreatedAt, err = parseTimestamp(createdAt); err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	if delivery.DeliveredAt, err = parseTimestamp(deliveredAt); err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	return delivery, nil
+}
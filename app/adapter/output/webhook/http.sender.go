@@ -0,0 +1,50 @@
+package webhook
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+)
+
+func NewHTTPSender(timeout time.Duration) port.WebhookSender {
+	return &httpSender{
+		client: &http.Client{
+			Timeout: timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+type httpSender struct {
+	client *http.Client
+}
+
+// Send posts the body, redirects are not followed so the subscriber has to register the final url
+func (sender *httpSender) Send(url string, headers map[string]string, body []byte) (int, error) {
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+
+	if err != nil {
+		return 0, err
+	}
+
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := sender.client.Do(request)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer response.Body.Close()
+
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	return response.StatusCode, nil
+}
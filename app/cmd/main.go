@@ -0,0 +1,29 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/command"
+	"github.com/PedroPereiraN/go-hexagonal/config"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	cfg := config.Load()
+
+	domain.SetPasswordPolicy(cfg.PasswordPolicy)
+	domain.SetPasswordHasher(cfg.PasswordHasher)
+
+	cliApp := command.NewApp(cfg, func(url string) (*sql.DB, error) {
+		return sql.Open("postgres", url)
+	})
+
+	if err := cliApp.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+
+		os.Exit(1)
+	}
+}
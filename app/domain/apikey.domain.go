@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// scopes an API key can be granted, user tokens are allowed everything
+const (
+	ScopeUsersRead = "users:read"
+	ScopeUsersWrite = "users:write"
+	ScopeSessions = "sessions"
+	ScopeTokensIntrospect = "tokens:introspect"
+)
+
+var APIKeyScopes = []string{ScopeUsersRead, ScopeUsersWrite, ScopeSessions, ScopeTokensIntrospect}
+
+// keys look like "gohex_<prefix>_<secret>", the prefix finds the key and only a hash of
+// the whole key is stored
+const apiKeyPrefix = "gohex"
+
+// APIKey is a long lived credential of a user for machine to machine calls
+type APIKey struct {
+	Id uuid.UUID
+	UserId uuid.UUID
+	Name string
+	Prefix string
+	Hash string
+	Scopes []string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	LastUsedAt time.Time
+	RevokedAt time.Time
+}
+
+// Active is false once the key was revoked or expired, keys without ExpiresAt never expire
+func (key APIKey) Active(now time.Time) bool {
+	return key.RevokedAt.IsZero() && (key.ExpiresAt.IsZero() || now.Before(key.ExpiresAt))
+}
+
+func ValidateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return errors.New("Inform at least one scope")
+	}
+
+	for _, scope := range scopes {
+		if !slices.Contains(APIKeyScopes, scope) {
+			return errors.New("Invalid scope: " + scope)
+		}
+	}
+
+	return nil
+}
+
+// GenerateAPIKey returns the key shown once to the user and its prefix
+func GenerateAPIKey() (string, string, error) {
+	random := make([]byte, 36)
+
+	if _, err := rand.Read(random); err != nil {
+		return "", "", err
+	}
+
+	prefix := hex.EncodeToString(random[:4])
+
+	return apiKeyPrefix + "_" + prefix + "_" + hex.EncodeToString(random[4:]), prefix, nil
+}
+
+// ParseAPIKeyPrefix returns the prefix of a key in the expected format
+func ParseAPIKeyPrefix(key string) (string, bool) {
+	parts := strings.Split(key, "_")
+
+	if len(parts) != 3 || parts[0] != apiKeyPrefix || len(parts[1]) != 8 || parts[2] == "" {
+		return "", false
+	}
+
+	return parts[1], true
+}
+
+// HashAPIKey is enough for keys since they have 256 random bits, unlike passwords
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
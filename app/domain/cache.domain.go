@@ -0,0 +1,18 @@
+package domain
+
+// CacheStats counts how the cached reads were served since the process started,
+// Shared are the misses answered by a load another caller had already started
+type CacheStats struct {
+	Hits uint64
+	Misses uint64
+	Shared uint64
+	Invalidations uint64
+}
+
+func (stats CacheStats) HitRatio() float64 {
+	if stats.Hits + stats.Misses == 0 {
+		return 0
+	}
+
+	return float64(stats.Hits) / float64(stats.Hits + stats.Misses)
+}
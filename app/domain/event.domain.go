@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	UserCreatedEvent = "user.created"
+	UserUpdatedEvent = "user.updated"
+	UserDeletedEvent = "user.deleted"
+	UserRestoredEvent = "user.restored"
+	UserPasswordChangedEvent = "user.password_changed"
+	UserStatusChangedEvent = "user.status_changed"
+)
+
+// Event is something that happened to an user, other services are notified through the outbox
+type Event interface {
+	Type() string
+	Subject() uuid.UUID
+	Time() time.Time
+}
+
+type UserCreated struct {
+	UserId uuid.UUID `json:"userId"`
+	Name string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (event UserCreated) Type() string { return UserCreatedEvent }
+func (event UserCreated) Subject() uuid.UUID { return event.UserId }
+func (event UserCreated) Time() time.Time { return event.CreatedAt }
+
+// UserUpdated carries only the fields that changed, the others are empty
+type UserUpdated struct {
+	UserId uuid.UUID `json:"userId"`
+	Name string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+	Phone string `json:"phone,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (event UserUpdated) Type() string { return UserUpdatedEvent }
+func (event UserUpdated) Subject() uuid.UUID { return event.UserId }
+func (event UserUpdated) Time() time.Time { return event.UpdatedAt }
+
+type UserDeleted struct {
+	UserId uuid.UUID `json:"userId"`
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+func (event UserDeleted) Type() string { return UserDeletedEvent }
+func (event UserDeleted) Subject() uuid.UUID { return event.UserId }
+func (event UserDeleted) Time() time.Time { return event.DeletedAt }
+
+type UserRestored struct {
+	UserId uuid.UUID `json:"userId"`
+	RestoredAt time.Time `json:"restoredAt"`
+}
+
+func (event UserRestored) Type() string { return UserRestoredEvent }
+func (event UserRestored) Subject() uuid.UUID { return event.UserId }
+func (event UserRestored) Time() time.Time { return event.RestoredAt }
+
+type UserPasswordChanged struct {
+	UserId uuid.UUID `json:"userId"`
+	ChangedAt time.Time `json:"changedAt"`
+}
+
+func (event UserPasswordChanged) Type() string { return UserPasswordChangedEvent }
+func (event UserPasswordChanged) Subject() uuid.UUID { return event.UserId }
+func (event UserPasswordChanged) Time() time.Time { return event.ChangedAt }
+
+type UserStatusChanged struct {
+	UserId uuid.UUID `json:"userId"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+	ChangedBy string `json:"changedBy,omitempty"`
+	SuspendedUntil *time.Time `json:"suspendedUntil,omitempty"`
+	ChangedAt time.Time `json:"changedAt"`
+}
+
+func (event UserStatusChanged) Type() string { return UserStatusChangedEvent }
+func (event UserStatusChanged) Subject() uuid.UUID { return event.UserId }
+func (event UserStatusChanged) Time() time.Time { return event.ChangedAt }
+
+// OutboxMessage is the serialized form of an event waiting to be published
+type OutboxMessage struct {
+	Id uuid.UUID
+	Type string
+	UserId uuid.UUID
+	Payload []byte
+	OccurredAt time.Time
+	Attempts int
+	NextAttemptAt time.Time
+	LastError string
+	PublishedAt time.Time
+}
+
+func NewOutboxMessage(event Event) (OutboxMessage, error) {
+	payload, err := json.Marshal(event)
+
+	if err != nil {
+		return OutboxMessage{}, err
+	}
+
+	return OutboxMessage{
+		Id: uuid.New(),
+		Type: event.Type(),
+		UserId: event.Subject(),
+		Payload: payload,
+		OccurredAt: event.Time(),
+	}, nil
+}
@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"time"
+)
+
+// UserFilter narrows the users read by an export, zero values don't filter
+type UserFilter struct {
+	CreatedFrom time.Time
+	CreatedTo time.Time
+	IncludeDeleted bool
+}
+
+// UserIterator streams users one row at a time, it must always be closed
+type UserIterator interface {
+	Next() bool
+	User() UserDomain
+	Err() error
+	Close() error
+}
@@ -0,0 +1,282 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	HashAlgorithmArgon2id = "argon2id"
+	HashAlgorithmBcrypt = "bcrypt"
+)
+
+// PasswordHasher produces self describing hashes, the algorithm and its parameters are
+// stored in the hash so any hasher of the same algorithm can verify it
+type PasswordHasher interface {
+	Algorithm() string
+	// Identify tells whether the hash was produced by this algorithm
+	Identify(hash string) bool
+	Hash(password string) (string, error)
+	Verify(hash string, password string) (bool, error)
+	// NeedsRehash is true when the hash was made with other parameters than the hasher's
+	NeedsRehash(hash string) bool
+}
+
+// the parameters of a stored argon2id hash are bounded so a tampered hash can't make a login
+// allocate gigabytes of memory or spin for minutes
+const (
+	argon2idMaxMemory = 1024 * 1024
+	argon2idMaxIterations = 10
+	argon2idMaxParallelism = 16
+	argon2idMaxKeyLength = 64
+)
+
+type Argon2idParams struct {
+	// Memory is in KiB
+	Memory uint32
+	Iterations uint32
+	Parallelism uint8
+	SaltLength uint32
+	KeyLength uint32
+}
+
+func (params Argon2idParams) Validate() error {
+	if params.Memory == 0 || params.Memory > argon2idMaxMemory ||
+		params.Iterations == 0 || params.Iterations > argon2idMaxIterations ||
+		params.Parallelism == 0 || params.Parallelism > argon2idMaxParallelism ||
+		params.KeyLength == 0 || params.KeyLength > argon2idMaxKeyLength {
+		return errors.New("Argon2id parameters out of range")
+	}
+
+	return nil
+}
+
+// DefaultArgon2idParams follow the OWASP recommendation of 19 MiB, 2 iterations and 1 thread
+func DefaultArgon2idParams() Argon2idParams {
+	return Argon2idParams{
+		Memory: 19 * 1024,
+		Iterations: 2,
+		Parallelism: 1,
+		SaltLength: 16,
+		KeyLength: 32,
+	}
+}
+
+func NewArgon2idHasher(params Argon2idParams) PasswordHasher {
+	return &argon2idHasher{params: params}
+}
+
+type argon2idHasher struct {
+	params Argon2idParams
+}
+
+func (hasher *argon2idHasher) Algorithm() string {
+	return HashAlgorithmArgon2id
+}
+
+func (hasher *argon2idHasher) Identify(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
+
+// Hash returns the PHC string $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>
+func (hasher *argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, hasher.params.SaltLength)
+
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, hasher.params.Iterations, hasher.params.Memory, hasher.params.Parallelism, hasher.params.KeyLength)
+
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		hasher.params.Memory,
+		hasher.params.Iterations,
+		hasher.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (hasher *argon2idHasher) Verify(hash string, password string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(hash)
+
+	if err != nil {
+		return false, err
+	}
+
+	candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+
+	return subtle.ConstantTimeCompare(key, candidate) == 1, nil
+}
+
+func (hasher *argon2idHasher) NeedsRehash(hash string) bool {
+	params, _, _, err := decodeArgon2id(hash)
+
+	if err != nil {
+		return true
+	}
+
+	return params != hasher.params
+}
+
+func decodeArgon2id(hash string) (Argon2idParams, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+
+	if len(parts) != 6 || parts[1] != HashAlgorithmArgon2id {
+		return Argon2idParams{}, nil, nil, errors.New("Invalid argon2id hash")
+	}
+
+	var version int
+
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Argon2idParams{}, nil, nil, errors.New("Unsupported argon2id version")
+	}
+
+	params := Argon2idParams{}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return Argon2idParams{}, nil, nil, errors.New("Invalid argon2id parameters")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+
+	if err != nil {
+		return Argon2idParams{}, nil, nil, errors.New("Invalid argon2id salt")
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+
+	if err != nil {
+		return Argon2idParams{}, nil, nil, errors.New("Invalid argon2id key")
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+
+	if err := params.Validate(); err != nil {
+		return Argon2idParams{}, nil, nil, err
+	}
+
+	return params, salt, key, nil
+}
+
+func NewBcryptHasher(cost int) PasswordHasher {
+	return &bcryptHasher{cost: cost}
+}
+
+type bcryptHasher struct {
+	cost int
+}
+
+func (hasher *bcryptHasher) Algorithm() string {
+	return HashAlgorithmBcrypt
+}
+
+func (hasher *bcryptHasher) Identify(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+func (hasher *bcryptHasher) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), hasher.cost)
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(hashed), nil
+}
+
+func (hasher *bcryptHasher) Verify(hash string, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (hasher *bcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+
+	return err != nil || cost != hasher.cost
+}
+
+var (
+	passwordHasherMutex sync.RWMutex
+	passwordHasher = NewArgon2idHasher(DefaultArgon2idParams())
+	// knownHashers verify the hashes made before the preferred algorithm changed
+	knownHashers = []PasswordHasher{NewArgon2idHasher(DefaultArgon2idParams()), NewBcryptHasher(bcrypt.DefaultCost)}
+)
+
+// SetPasswordHasher replaces the hasher used for new hashes, it is set once at startup
+func SetPasswordHasher(hasher PasswordHasher) {
+	passwordHasherMutex.Lock()
+	defer passwordHasherMutex.Unlock()
+
+	passwordHasher = hasher
+}
+
+func CurrentPasswordHasher() PasswordHasher {
+	passwordHasherMutex.RLock()
+	defer passwordHasherMutex.RUnlock()
+
+	return passwordHasher
+}
+
+// HashPassword hashes with the preferred hasher
+func HashPassword(password string) (string, error) {
+	return CurrentPasswordHasher().Hash(password)
+}
+
+// VerifyPassword checks the password with the hasher that produced the hash
+func VerifyPassword(hash string, password string) (bool, error) {
+	hasher := identifyHasher(hash)
+
+	if hasher == nil {
+		return false, errors.New("Unknown password hash format")
+	}
+
+	return hasher.Verify(hash, password)
+}
+
+// PasswordNeedsRehash is true when the hash doesn't use the preferred algorithm and parameters
+func PasswordNeedsRehash(hash string) bool {
+	preferred := CurrentPasswordHasher()
+
+	if !preferred.Identify(hash) {
+		return true
+	}
+
+	return preferred.NeedsRehash(hash)
+}
+
+func IsPasswordHash(value string) bool {
+	return identifyHasher(value) != nil
+}
+
+func identifyHasher(hash string) PasswordHasher {
+	preferred := CurrentPasswordHasher()
+
+	if preferred.Identify(hash) {
+		return preferred
+	}
+
+	for _, hasher := range knownHashers {
+		if hasher.Identify(hash) {
+			return hasher
+		}
+	}
+
+	return nil
+}
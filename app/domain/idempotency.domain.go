@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	IdempotencyPending = "pending"
+	IdempotencyCompleted = "completed"
+)
+
+// IdempotencyRecord keeps the first response given to an Idempotency-Key so retries can be replayed
+type IdempotencyRecord struct {
+	Key string
+	Fingerprint string
+	Status string
+	ResponseStatus int
+	ResponseContentType string
+	ResponseBody []byte
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+func (record IdempotencyRecord) IsExpired(now time.Time) bool {
+	return !record.ExpiresAt.IsZero() && now.After(record.ExpiresAt)
+}
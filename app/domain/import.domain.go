@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+)
+
+const (
+	// ImportAllOrNothing creates every row in a single transaction, one invalid row discards the whole file
+	ImportAllOrNothing = "all-or-nothing"
+	// ImportBestEffort creates the valid rows and reports the invalid ones
+	ImportBestEffort = "best-effort"
+)
+
+const (
+	ImportRowCreated = "created"
+	ImportRowValid = "valid"
+	ImportRowFailed = "failed"
+	ImportRowRolledBack = "rolled_back"
+)
+
+// ImportRow is one user read from an import file, Problem is filled by the reader
+// when the row could not be parsed or validated so the service only reports it
+type ImportRow struct {
+	Row int
+	Name string
+	Email string
+	Phone string
+	Password string
+	Problem string
+}
+
+// ImportRowReader yields the rows of an import file one at a time and returns io.EOF at the end
+type ImportRowReader interface {
+	Next() (ImportRow, error)
+}
+
+type ImportResult struct {
+	Row int
+	Id uuid.UUID
+	Status string
+	Error string
+}
+
+type ImportReport struct {
+	Mode string
+	DryRun bool
+	Total int
+	Created int
+	Failed int
+	Rows []ImportResult
+}
+
+func IsValidImportMode(mode string) bool {
+	return mode == ImportAllOrNothing || mode == ImportBestEffort
+}
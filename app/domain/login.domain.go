@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// outcomes of a login attempt, a challenge is a right password that still needs the second
+// factor or a new password before the token is handed out
+const (
+	LoginSucceeded = "success"
+	LoginFailed = "failure"
+	LoginChallenged = "challenge"
+)
+
+// LoginUnknownUser is the failure reason of the attempts with an email that isn't registered
+const LoginUnknownUser = "unknown_user"
+
+// LoginAttempt is kept for every login, UserId is uuid.Nil when the email isn't registered and
+// FailureReason is only set when it failed
+type LoginAttempt struct {
+	Id uuid.UUID
+	UserId uuid.UUID
+	Outcome string
+	FailureReason string
+	IP string
+	UserAgent string
+	CreatedAt time.Time
+}
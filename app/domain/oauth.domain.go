@@ -0,0 +1,228 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// grants supported by the authorization server
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantRefreshToken = "refresh_token"
+	GrantClientCredentials = "client_credentials"
+)
+
+var OAuthGrantTypes = []string{GrantAuthorizationCode, GrantRefreshToken, GrantClientCredentials}
+
+const (
+	OAuthAccessToken = "access_token"
+	OAuthRefreshToken = "refresh_token"
+	PKCEMethodS256 = "S256"
+)
+
+// error codes of RFC 6749 section 5.2 and 4.1.2.1
+const (
+	OAuthInvalidRequest = "invalid_request"
+	OAuthInvalidClient = "invalid_client"
+	OAuthInvalidGrant = "invalid_grant"
+	OAuthUnauthorizedClient = "unauthorized_client"
+	OAuthUnsupportedGrantType = "unsupported_grant_type"
+	OAuthUnsupportedResponseType = "unsupported_response_type"
+	OAuthInvalidScope = "invalid_scope"
+	OAuthAccessDenied = "access_denied"
+)
+
+// OAuthError is answered as {"error", "error_description"}
+type OAuthError struct {
+	Code string
+	Description string
+}
+
+func (err *OAuthError) Error() string {
+	return err.Code + ": " + err.Description
+}
+
+// Status is 401 for client authentication failures and 400 for everything else
+func (err *OAuthError) Status() int {
+	if err.Code == OAuthInvalidClient {
+		return http.StatusUnauthorized
+	}
+
+	return http.StatusBadRequest
+}
+
+func NewOAuthError(code string, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
+
+// OAuthClient is an application that uses this service as identity provider. Public clients
+// (SPAs, mobile apps) have no secret and must use PKCE
+type OAuthClient struct {
+	Id string
+	Name string
+	SecretHash string
+	RedirectURIs []string
+	GrantTypes []string
+	Scopes []string
+	CreatedAt time.Time
+}
+
+func (client OAuthClient) Confidential() bool {
+	return client.SecretHash != ""
+}
+
+func (client OAuthClient) AllowsGrant(grantType string) bool {
+	return slices.Contains(client.GrantTypes, grantType)
+}
+
+// AllowsScopes tells if every requested scope was registered for the client
+func (client OAuthClient) AllowsScopes(scopes []string) bool {
+	for _, scope := range scopes {
+		if !slices.Contains(client.Scopes, scope) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// AuthorizationRequest holds the parameters of /oauth/authorize
+type AuthorizationRequest struct {
+	ResponseType string
+	ClientId string
+	RedirectURI string
+	Scope string
+	State string
+	CodeChallenge string
+	CodeChallengeMethod string
+	Nonce string
+}
+
+// AuthorizationCode is stored hashed and can be exchanged once
+type AuthorizationCode struct {
+	Hash string
+	ClientId string
+	UserId uuid.UUID
+	RedirectURI string
+	Scope string
+	CodeChallenge string
+	CodeChallengeMethod string
+	Nonce string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// OAuthToken is an issued access or refresh token, tokens issued from the same authorization
+// share the GrantId so the whole grant can be revoked at once. Access tokens are found by id
+// (the jti claim) and refresh tokens by the hash of their value
+type OAuthToken struct {
+	Id uuid.UUID
+	GrantId uuid.UUID
+	Type string
+	Hash string
+	ClientId string
+	UserId uuid.UUID
+	Scope string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	RevokedAt time.Time
+}
+
+func (token OAuthToken) Active(now time.Time) bool {
+	return token.RevokedAt.IsZero() && now.Before(token.ExpiresAt)
+}
+
+// TokenRequest holds the form parameters of /oauth/token
+type TokenRequest struct {
+	GrantType string
+	ClientId string
+	ClientSecret string
+	Code string
+	RedirectURI string
+	CodeVerifier string
+	RefreshToken string
+	Scope string
+}
+
+type TokenResponse struct {
+	AccessToken string
+	TokenType string
+	ExpiresIn int
+	RefreshToken string
+	Scope string
+	IDToken string
+}
+
+// TokenIntrospection answers RFC 7662 section 2.2, an inactive token carries nothing else so
+// callers can't learn anything about tokens they shouldn't accept
+type TokenIntrospection struct {
+	Active bool
+	TokenType string
+	Scope string
+	ClientId string
+	Username string
+	Subject string
+	Issuer string
+	TokenId string
+	IssuedAt time.Time
+	ExpiresAt time.Time
+}
+
+var scopePattern = regexp.MustCompile(`^[a-zA-Z0-9:._-]+$`)
+
+// ParseScope splits a space separated scope, it fails on characters RFC 6749 doesn't allow
+// and ignores repeated values
+func ParseScope(scope string) ([]string, error) {
+	scopes := []string{}
+
+	for _, value := range strings.Fields(scope) {
+		if !scopePattern.MatchString(value) {
+			return nil, NewOAuthError(OAuthInvalidScope, "Invalid scope: " + value)
+		}
+
+		if !slices.Contains(scopes, value) {
+			scopes = append(scopes, value)
+		}
+	}
+
+	return scopes, nil
+}
+
+// VerifyPKCE checks the code_verifier against the S256 code_challenge of RFC 7636
+func VerifyPKCE(challenge string, verifier string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+
+	return subtle.ConstantTimeCompare([]byte(base64.RawURLEncoding.EncodeToString(sum[:])), []byte(challenge)) == 1
+}
+
+// GenerateOAuthSecret returns a random url safe value for client secrets, codes and refresh tokens
+func GenerateOAuthSecret() (string, error) {
+	random := make([]byte, 32)
+
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(random), nil
+}
+
+// HashOAuthSecret is enough for values with 256 random bits
+func HashOAuthSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "slices"
+
+// scopes of OpenID Connect, an ID token is only issued when openid is granted and the
+// other ones decide which claims of the user are released
+const (
+	ScopeOpenID = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail = "email"
+	ScopePhone = "phone"
+)
+
+var OIDCScopes = []string{ScopeOpenID, ScopeProfile, ScopeEmail, ScopePhone}
+
+// OIDCClaims returns the standard claims (OpenID Connect Core section 5.1) of the user released by
+// the scopes. Emails and phones are never verified by this service so they are always reported as such
+func OIDCClaims(user UserDomain, scopes []string) map[string]any {
+	claims := map[string]any{
+		"sub": user.Id.String(),
+	}
+
+	if slices.Contains(scopes, ScopeProfile) {
+		claims["name"] = user.Name
+	}
+
+	if slices.Contains(scopes, ScopeEmail) {
+		claims["email"] = user.Email
+		claims["email_verified"] = false
+	}
+
+	if slices.Contains(scopes, ScopePhone) {
+		claims["phone_number"] = user.Phone
+		claims["phone_number_verified"] = false
+	}
+
+	return claims
+}
@@ -0,0 +1,214 @@
+package domain
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// password policy rules, they identify each violation so clients can show their own messages
+const (
+	PasswordRuleMinLength = "min_length"
+	PasswordRuleMaxLength = "max_length"
+	PasswordRuleUppercase = "uppercase"
+	PasswordRuleLowercase = "lowercase"
+	PasswordRuleDigit = "digit"
+	PasswordRuleSpecial = "special"
+	PasswordRuleRepeated = "repeated"
+	PasswordRuleUserData = "user_data"
+	PasswordRuleBreached = "breached"
+)
+
+// DefaultSpecialCharacters are the characters the api always required one of
+const DefaultSpecialCharacters = "!@#$%*"
+
+type PasswordPolicy struct {
+	MinLength int
+	// MaxLength can't go over 72, bcrypt ignores anything after that
+	MaxLength int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit bool
+	RequireSpecial bool
+	SpecialCharacters string
+	// MaxRepeated is how many times in a row the same character may appear, 0 disables the rule
+	MaxRepeated int
+	// ForbidUserData rejects passwords containing the name or the email of the user
+	ForbidUserData bool
+	// Breached holds lowercase passwords known from leaks
+	Breached map[string]bool
+}
+
+type PasswordViolation struct {
+	Rule string
+	Message string
+}
+
+// PasswordPolicyError lists every rule the password failed, not only the first one
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+func (err *PasswordPolicyError) Error() string {
+	messages := []string{}
+
+	for _, violation := range err.Violations {
+		messages = append(messages, violation.Message)
+	}
+
+	return "Password does not satisfy the password policy: " + strings.Join(messages, "; ")
+}
+
+// DefaultPasswordPolicy keeps the rules the api had before the policy was configurable
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength: 6,
+		MaxLength: 72,
+		RequireSpecial: true,
+		SpecialCharacters: DefaultSpecialCharacters,
+		ForbidUserData: true,
+	}
+}
+
+var (
+	passwordPolicyMutex sync.RWMutex
+	passwordPolicy = DefaultPasswordPolicy()
+)
+
+// SetPasswordPolicy replaces the policy applied by CreateUser and UpdatePassword, it is set once at startup
+func SetPasswordPolicy(policy PasswordPolicy) {
+	passwordPolicyMutex.Lock()
+	defer passwordPolicyMutex.Unlock()
+
+	passwordPolicy = policy
+}
+
+func CurrentPasswordPolicy() PasswordPolicy {
+	passwordPolicyMutex.RLock()
+	defer passwordPolicyMutex.RUnlock()
+
+	return passwordPolicy
+}
+
+// Validate returns a *PasswordPolicyError with every failed rule, userData are the values
+// (name, email) the password must not contain
+func (policy PasswordPolicy) Validate(password string, userData ...string) error {
+	violations := []PasswordViolation{}
+
+	fail := func(rule string, message string) {
+		violations = append(violations, PasswordViolation{Rule: rule, Message: message})
+	}
+
+	length := len([]rune(password))
+
+	if length < policy.MinLength {
+		fail(PasswordRuleMinLength, "Password must have at least " + strconv.Itoa(policy.MinLength) + " characters")
+	}
+
+	if policy.MaxLength > 0 && length > policy.MaxLength {
+		fail(PasswordRuleMaxLength, "Password must have at most " + strconv.Itoa(policy.MaxLength) + " characters")
+	}
+
+	if policy.RequireUppercase && !strings.ContainsFunc(password, unicode.IsUpper) {
+		fail(PasswordRuleUppercase, "Password must contain an uppercase letter")
+	}
+
+	if policy.RequireLowercase && !strings.ContainsFunc(password, unicode.IsLower) {
+		fail(PasswordRuleLowercase, "Password must contain a lowercase letter")
+	}
+
+	if policy.RequireDigit && !strings.ContainsFunc(password, unicode.IsDigit) {
+		fail(PasswordRuleDigit, "Password must contain a digit")
+	}
+
+	if policy.RequireSpecial && !strings.ContainsAny(password, policy.SpecialCharacters) {
+		fail(PasswordRuleSpecial, "Password must contain one of " + policy.SpecialCharacters)
+	}
+
+	if policy.MaxRepeated > 0 && longestRun(password) > policy.MaxRepeated {
+		fail(PasswordRuleRepeated, "Password can't repeat a character more than " + strconv.Itoa(policy.MaxRepeated) + " times in a row")
+	}
+
+	if policy.ForbidUserData && containsUserData(password, userData) {
+		fail(PasswordRuleUserData, "Password can't contain your name or email")
+	}
+
+	if policy.Breached[strings.ToLower(password)] {
+		fail(PasswordRuleBreached, "Password is known from data breaches")
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+
+	return nil
+}
+
+// LoadBreachedPasswords reads one password per line, empty lines and lines starting with # are skipped
+func LoadBreachedPasswords(reader io.Reader) (map[string]bool, error) {
+	breached := map[string]bool{}
+
+	scanner := bufio.NewScanner(reader)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		breached[strings.ToLower(line)] = true
+	}
+
+	return breached, scanner.Err()
+}
+
+func longestRun(password string) int {
+	longest, current := 0, 0
+	var previous rune
+
+	for i, r := range []rune(password) {
+		if i > 0 && r == previous {
+			current++
+		} else {
+			current = 1
+		}
+
+		previous = r
+
+		if current > longest {
+			longest = current
+		}
+	}
+
+	return longest
+}
+
+// containsUserData checks the words of the name and the email local part, words shorter
+// than 3 letters are ignored so short names don't forbid half of the passwords
+func containsUserData(password string, userData []string) bool {
+	lower := strings.ToLower(password)
+
+	for _, value := range userData {
+		value = strings.ToLower(value)
+
+		if at := strings.Index(value, "@"); at >= 0 {
+			value = value[:at]
+		}
+
+		words := strings.FieldsFunc(value, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+
+		for _, word := range words {
+			if len([]rune(word)) >= 3 && strings.Contains(lower, word) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,222 @@
+package domain
+
+import (
+	"html"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	SearchFieldName = "name"
+	SearchFieldEmail = "email"
+	SearchFieldPhone = "phone"
+)
+
+// SearchSimilarityThreshold is the minimum trigram similarity for a fuzzy match, the pg_trgm default
+const SearchSimilarityThreshold = 0.3
+
+type UserSearch struct {
+	Query string
+	Limit int
+	Offset int
+}
+
+// UserSearchHit is a matched user with the field that matched best, Highlight is that field
+// with the matched fragment wrapped in <mark></mark>, it is empty when the match was only fuzzy
+type UserSearchHit struct {
+	User UserDomain
+	Score float64
+	Field string
+	Highlight string
+}
+
+type UserSearchPage struct {
+	Hits []UserSearchHit
+	Total int
+	Limit int
+	Offset int
+}
+
+// SearchDigits keeps only the digits of the query, it is what is matched against the phone
+func SearchDigits(query string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+
+		return -1
+	}, query)
+}
+
+// SearchUsers ranks the users in memory the way the Postgres search does, substring matches
+// first and trigram similarity for typos. It is meant for adapters without full text search.
+func SearchUsers(users []UserDomain, search UserSearch) UserSearchPage {
+	hits := []UserSearchHit{}
+
+	for _, user := range users {
+		if hit, matched := ScoreUser(user, search.Query); matched {
+			hits = append(hits, hit)
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+
+		return hits[i].User.Name < hits[j].User.Name
+	})
+
+	page := UserSearchPage{
+		Hits: []UserSearchHit{},
+		Total: len(hits),
+		Limit: search.Limit,
+		Offset: search.Offset,
+	}
+
+	if search.Offset >= len(hits) {
+		return page
+	}
+
+	end := len(hits)
+
+	if search.Limit > 0 && search.Offset + search.Limit < end {
+		end = search.Offset + search.Limit
+	}
+
+	page.Hits = hits[search.Offset:end]
+
+	return page
+}
+
+// ScoreUser returns the best matching field of the user, scores go from 0 to 1
+func ScoreUser(user UserDomain, query string) (UserSearchHit, bool) {
+	query = strings.TrimSpace(query)
+	digits := SearchDigits(query)
+
+	hit := UserSearchHit{User: user}
+
+	consider := func(field string, score float64) {
+		if score > hit.Score {
+			hit.Score = score
+			hit.Field = field
+		}
+	}
+
+	consider(SearchFieldName, matchScore(user.Name, query))
+	consider(SearchFieldEmail, matchScore(user.Email, query))
+
+	if len(digits) >= 3 && strings.Contains(user.Phone, digits) {
+		consider(SearchFieldPhone, substringScore)
+	}
+
+	if hit.Score < SearchSimilarityThreshold {
+		return UserSearchHit{}, false
+	}
+
+	return hit, true
+}
+
+const substringScore = 0.8
+
+func matchScore(value string, query string) float64 {
+	lowerValue := strings.ToLower(value)
+	lowerQuery := strings.ToLower(query)
+
+	switch {
+	case lowerQuery == "":
+		return 0
+	case lowerValue == lowerQuery:
+		return 1
+	case strings.HasPrefix(lowerValue, lowerQuery):
+		return 0.9
+	case strings.Contains(lowerValue, lowerQuery):
+		return substringScore
+	}
+
+	return TrigramSimilarity(value, query)
+}
+
+// TrigramSimilarity mirrors pg_trgm similarity(): the shared trigrams of the padded words
+// divided by all the distinct trigrams of both strings
+func TrigramSimilarity(a string, b string) float64 {
+	first := trigrams(a)
+	second := trigrams(b)
+
+	if len(first) == 0 || len(second) == 0 {
+		return 0
+	}
+
+	shared := 0
+
+	for trigram := range first {
+		if second[trigram] {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(first) + len(second) - shared)
+}
+
+func trigrams(value string) map[string]bool {
+	result := map[string]bool{}
+
+	words := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+
+		for i := 0; i + 3 <= len(padded); i++ {
+			result[string(padded[i:i + 3])] = true
+		}
+	}
+
+	return result
+}
+
+// Highlight wraps the first case insensitive occurrence of fragment in value with <mark></mark>.
+// The value is written by users so it is HTML escaped, only the mark tags are markup.
+func Highlight(value string, fragment string) string {
+	if fragment == "" {
+		return ""
+	}
+
+	// runes are lowered one by one so the positions match the runes of value, lowering the whole
+	// string may change the length of some non ascii letters
+	runes := []rune(value)
+	index := indexRunes(lowerRunes(runes), lowerRunes([]rune(fragment)))
+
+	if index < 0 {
+		return ""
+	}
+
+	end := index + len([]rune(fragment))
+
+	return html.EscapeString(string(runes[:index])) +
+		"<mark>" + html.EscapeString(string(runes[index:end])) + "</mark>" +
+		html.EscapeString(string(runes[end:]))
+}
+
+func lowerRunes(runes []rune) []rune {
+	lowered := make([]rune, len(runes))
+
+	for i, r := range runes {
+		lowered[i] = unicode.ToLower(r)
+	}
+
+	return lowered
+}
+
+func indexRunes(value []rune, fragment []rune) int {
+	for i := 0; i + len(fragment) <= len(value); i++ {
+		if slices.Equal(value[i:i + len(fragment)], fragment) {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Client describes where a login came from, both values are informed by the caller and only
+// shown back to the user
+type Client struct {
+	IP string
+	UserAgent string
+}
+
+// Session is created on every login and referenced by the sid claim of the token,
+// revoking it signs the token out before it expires
+type Session struct {
+	Id uuid.UUID
+	UserId uuid.UUID
+	UserAgent string
+	IP string
+	CreatedAt time.Time
+	LastSeenAt time.Time
+	ExpiresAt time.Time
+	RevokedAt time.Time
+}
+
+func (session Session) Active(now time.Time) bool {
+	return session.RevokedAt.IsZero() && now.Before(session.ExpiresAt)
+}
+
+// Identity is the authenticated caller of a request, a token has a session while an API key
+// has its id and the scopes it was granted. Both carry the role of the user.
+type Identity struct {
+	UserId uuid.UUID
+	Email string
+	Role string
+	SessionId uuid.UUID
+	APIKeyId uuid.UUID
+	Scopes []string
+}
+
+// HasScope is always true for user tokens, API keys are limited to their scopes
+func (identity Identity) HasScope(scope string) bool {
+	if identity.APIKeyId == uuid.Nil {
+		return true
+	}
+
+	return slices.Contains(identity.Scopes, scope)
+}
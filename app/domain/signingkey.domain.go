@@ -0,0 +1,210 @@
+package domain
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"time"
+)
+
+// algorithms the tokens can be signed with, HS256 is the shared secret every verifier must hold
+const (
+	SigningHS256 = "HS256"
+	SigningRS256 = "RS256"
+	SigningES256 = "ES256"
+	SigningEdDSA = "EdDSA"
+)
+
+var AsymmetricSigningAlgorithms = []string{SigningRS256, SigningES256, SigningEdDSA}
+
+const rsaSigningKeyBits = 2048
+
+// SigningKey is a private key of the key ring, Id is the kid header of the tokens it signs.
+// A key signs until RetiresAt and is still published and accepted until ExpiresAt so the
+// tokens it signed stay valid, a zero time means never
+type SigningKey struct {
+	Id string
+	Algorithm string
+	Key crypto.Signer
+	EncryptedKey string
+	CreatedAt time.Time
+	RetiresAt time.Time
+	ExpiresAt time.Time
+}
+
+// Signs tells if the key can sign new tokens
+func (key SigningKey) Signs(now time.Time) bool {
+	return !now.Before(key.CreatedAt) && (key.RetiresAt.IsZero() || now.Before(key.RetiresAt))
+}
+
+// Verifies tells if tokens signed by the key are still accepted
+func (key SigningKey) Verifies(now time.Time) bool {
+	return key.ExpiresAt.IsZero() || now.Before(key.ExpiresAt)
+}
+
+// KeyRotation sets how long a generated key signs and how long it is published before and after
+// signing, the overlap must be longer than the longest token lifetime
+type KeyRotation struct {
+	Interval time.Duration
+	Overlap time.Duration
+}
+
+func DefaultKeyRotation() KeyRotation {
+	return KeyRotation{
+		Interval: 30 * 24 * time.Hour,
+		Overlap: 48 * time.Hour,
+	}
+}
+
+// JWK is the public part of a signing key as published in the JWKS (RFC 7517)
+type JWK struct {
+	Kty string
+	Kid string
+	Use string
+	Alg string
+	N string
+	E string
+	Crv string
+	X string
+	Y string
+}
+
+// GenerateSigningKey creates a private key for the algorithm
+func GenerateSigningKey(algorithm string) (crypto.Signer, error) {
+	switch algorithm {
+	case SigningRS256:
+		return rsa.GenerateKey(rand.Reader, rsaSigningKeyBits)
+	case SigningES256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case SigningEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+
+		return key, err
+	default:
+		return nil, errors.New("Unsupported signing algorithm: " + algorithm)
+	}
+}
+
+// SigningAlgorithmOf returns the algorithm a key signs with, only RSA keys of at least 2048
+// bits, P-256 keys and Ed25519 keys are accepted
+func SigningAlgorithmOf(key crypto.Signer) (string, error) {
+	switch private := key.(type) {
+	case *rsa.PrivateKey:
+		if private.N.BitLen() < rsaSigningKeyBits {
+			return "", errors.New("RSA signing keys must have at least 2048 bits")
+		}
+
+		return SigningRS256, nil
+	case *ecdsa.PrivateKey:
+		if private.Curve != elliptic.P256() {
+			return "", errors.New("EC signing keys must use the P-256 curve")
+		}
+
+		return SigningES256, nil
+	case ed25519.PrivateKey:
+		return SigningEdDSA, nil
+	default:
+		return "", errors.New("Unsupported signing key")
+	}
+}
+
+// ParseSigningKeyPEM reads a PKCS #8, PKCS #1 (RSA) or SEC 1 (EC) private key
+func ParseSigningKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+
+	if block == nil {
+		return nil, errors.New("No PEM block found")
+	}
+
+	var key any
+	var err error
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return nil, errors.New("Unsupported PEM block: " + block.Type)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+
+	if !ok {
+		return nil, errors.New("Unsupported signing key")
+	}
+
+	if _, err := SigningAlgorithmOf(signer); err != nil {
+		return nil, err
+	}
+
+	return signer, nil
+}
+
+// NewSigningKey wraps a private key, its id is the RFC 7638 thumbprint of the public key so the
+// same key always gets the same kid
+func NewSigningKey(key crypto.Signer) (SigningKey, error) {
+	algorithm, err := SigningAlgorithmOf(key)
+
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	signingKey := SigningKey{Algorithm: algorithm, Key: key}
+	jwk := signingKey.JWK()
+
+	var members string
+
+	// the required members in lexicographic order, without whitespace
+	switch jwk.Kty {
+	case "RSA":
+		members = `{"e":"` + jwk.E + `","kty":"RSA","n":"` + jwk.N + `"}`
+	case "EC":
+		members = `{"crv":"` + jwk.Crv + `","kty":"EC","x":"` + jwk.X + `","y":"` + jwk.Y + `"}`
+	default:
+		members = `{"crv":"` + jwk.Crv + `","kty":"OKP","x":"` + jwk.X + `"}`
+	}
+
+	sum := sha256.Sum256([]byte(members))
+	signingKey.Id = base64.RawURLEncoding.EncodeToString(sum[:])
+
+	return signingKey, nil
+}
+
+// JWK returns the public key, never the private one
+func (key SigningKey) JWK() JWK {
+	jwk := JWK{Kid: key.Id, Use: "sig", Alg: key.Algorithm}
+
+	switch public := key.Key.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case *ecdsa.PublicKey:
+		jwk.Kty = "EC"
+		jwk.Crv = "P-256"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public.X.FillBytes(make([]byte, 32)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(public.Y.FillBytes(make([]byte, 32)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+
+	return jwk
+}
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// account states, a deactivated account is also soft deleted and a restore makes it active again
+const (
+	StatusPending = "pending"
+	StatusActive = "active"
+	StatusSuspended = "suspended"
+	StatusDeactivated = "deactivated"
+)
+
+// statusTransitions lists the states each state can go to, suspending a suspended account
+// replaces the reason and the end of the suspension
+var statusTransitions = map[string][]string{
+	StatusPending: {StatusActive, StatusDeactivated},
+	StatusActive: {StatusSuspended, StatusDeactivated},
+	StatusSuspended: {StatusActive, StatusSuspended, StatusDeactivated},
+	StatusDeactivated: {StatusActive},
+}
+
+// UserStatus is where the account is in its lifecycle and who put it there
+type UserStatus struct {
+	State string
+	Reason string
+	// ChangedBy is whoever made the last transition, an admin or "system"
+	ChangedBy string
+	ChangedAt time.Time
+	// SuspendedUntil ends the suspension on its own, zero keeps it until a reactivation
+	SuspendedUntil time.Time
+}
+
+func IsValidStatus(state string) bool {
+	_, ok := statusTransitions[state]
+
+	return ok
+}
+
+// Current is the state at now, a suspension that reached its end is active. An empty state is
+// active too, like the accounts created before the status existed.
+func (status UserStatus) Current(now time.Time) string {
+	if status.State == "" {
+		return StatusActive
+	}
+
+	if status.State == StatusSuspended && !status.SuspendedUntil.IsZero() && !now.Before(status.SuspendedUntil) {
+		return StatusActive
+	}
+
+	return status.State
+}
+
+// CanLogin refuses every account that isn't active with an error telling why
+func (status UserStatus) CanLogin(now time.Time) error {
+	switch status.Current(now) {
+	case StatusPending:
+		return errors.New("Account is pending activation")
+	case StatusSuspended:
+		return errors.New("Account is suspended")
+	case StatusDeactivated:
+		return errors.New("Account is deactivated")
+	}
+
+	return nil
+}
+
+// ChangeStatus moves the account to state when the transition is allowed from the current state,
+// only a suspension has an end and it needs a reason
+func (user *UserDomain) ChangeStatus(state string, reason string, actor string, until time.Time, now time.Time) error {
+	if !IsValidStatus(state) {
+		return errors.New("Invalid status")
+	}
+
+	if !slices.Contains(statusTransitions[user.Status.Current(now)], state) {
+		return errors.New("Invalid status transition")
+	}
+
+	if state == StatusSuspended && reason == "" {
+		return errors.New("Suspension reason is required")
+	}
+
+	if !until.IsZero() && (state != StatusSuspended || !until.After(now)) {
+		return errors.New("Suspension must end in the future")
+	}
+
+	user.Status = UserStatus{
+		State: state,
+		Reason: reason,
+		ChangedBy: actor,
+		ChangedAt: now,
+		SuspendedUntil: until,
+	}
+
+	return nil
+}
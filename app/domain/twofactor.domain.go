@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecoveryCodeCount is how many one time codes are handed out when two-factor is confirmed
+const RecoveryCodeCount = 10
+
+// TwoFactor is the TOTP (RFC 6238) enrolment of a user, Secret is stored encrypted and the
+// enrolment only protects the login after it was confirmed with a first code
+type TwoFactor struct {
+	UserId uuid.UUID
+	Secret string
+	ConfirmedAt time.Time
+	// LastUsedStep is the time step of the last accepted code, a code can't be used twice
+	LastUsedStep int64
+	CreatedAt time.Time
+}
+
+func (twoFactor TwoFactor) Enabled() bool {
+	return !twoFactor.ConfirmedAt.IsZero()
+}
+
+// TwoFactorEnrollment is shown once to the user so the secret can be added to an authenticator app
+type TwoFactorEnrollment struct {
+	Secret string
+	URI string
+	QRCode []byte
+}
+
+// LoginResult carries either the access token or, when two-factor is enabled, the challenge
+// token that must be sent back with a code to finish the login. When the password expired it
+// carries a token that only changes the password instead. UserId is only set along with the
+// token, once the user is fully authenticated
+type LoginResult struct {
+	UserId uuid.UUID
+	Token string
+	TwoFactorRequired bool
+	ChallengeToken string
+	PasswordExpired bool
+	PasswordChangeToken string
+}
+
+// GenerateRecoveryCodes returns codes like "k3j9f-a8d2m", only their hashes are stored
+func GenerateRecoveryCodes(count int) ([]string, error) {
+	codes := []string{}
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	for range count {
+		random := make([]byte, 7)
+
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+
+		code := strings.ToLower(encoding.EncodeToString(random))[:10]
+
+		codes = append(codes, code[:5] + "-" + code[5:])
+	}
+
+	return codes, nil
+}
+
+// HashRecoveryCode ignores case, spaces and dashes so users can type the code as they like
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+
+	return hex.EncodeToString(sum[:])
+}
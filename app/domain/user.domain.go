@@ -1,7 +1,7 @@
 package domain
 
 import (
-	"strings"
+	"errors"
 	"time"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
@@ -44,11 +44,11 @@ func CreateUser(
 		uDomain.DeletedAt = deletedAt
 	}
 
-	if uDomain.IsBcryptHash(password) && password != "" {
-		uDomain.Password = password
-	}
+	if password != "" {
+		if err := CurrentPasswordPolicy().Validate(password, name, email); err != nil {
+			return UserDomain{}, err
+		}
 
-	if !uDomain.IsBcryptHash(password) && password != "" {
 		err := uDomain.EncryptPassword(password)
 
 		if err != nil {
@@ -59,6 +59,30 @@ func CreateUser(
 	return uDomain, nil
 }
 
+// RestoreUser rebuilds an user the domain already created, the password is kept as it is because it is
+// already hashed, so it must only be used by the repository and never with client input
+func RestoreUser(
+	id uuid.UUID,
+	name string,
+	email string,
+	phone string,
+	password string,
+	createdAt time.Time,
+	updatedAt time.Time,
+	deletedAt time.Time,
+) (UserDomain, error) {
+	return UserDomain{
+		Id: id,
+		Name: name,
+		Email: email,
+		Phone: phone,
+		Password: password,
+		CreatedAt: createdAt,
+		UpdatedAt: updatedAt,
+		DeletedAt: deletedAt,
+	}, nil
+}
+
 type UserDomain struct {
 	Id uuid.UUID
 	Name string
@@ -68,20 +92,104 @@ type UserDomain struct {
 	CreatedAt time.Time
 	DeletedAt time.Time
 	UpdatedAt time.Time
+	// PasswordChangedAt is zero until the first change, the tokens issued before it are refused
+	PasswordChangedAt time.Time
+	Role string
+	// PasswordChangeRequired is set by an admin, the next login must change the password first
+	PasswordChangeRequired bool
+	Status UserStatus
+	// LastLoginAt is zero until the first successful login
+	LastLoginAt time.Time
+}
+
+// roles of the users, new users are RoleUser and the password expiry is set per role
+const (
+	RoleUser = "user"
+	RoleAdmin = "admin"
+)
+
+func IsValidRole(role string) bool {
+	return role == RoleUser || role == RoleAdmin
+}
+
+// PasswordExpiry is how long a password lasts for each role, a role without a positive
+// duration keeps its passwords until they are changed
+type PasswordExpiry map[string]time.Duration
+
+// UpdatePassword checks the new password against the password policy with the user data, refuses the
+// current password and the hashes of the previous ones and stores its hash with the time of the change,
+// which fulfills a change required by an admin
+func (user *UserDomain) UpdatePassword(password string, previous ...string) error {
+	if err := CurrentPasswordPolicy().Validate(password, user.Name, user.Email); err != nil {
+		return err
+	}
+
+	if valid, _ := VerifyPassword(user.Password, password); valid {
+		return errors.New("New password must be different from the current password")
+	}
+
+	for _, hash := range previous {
+		if valid, _ := VerifyPassword(hash, password); valid {
+			return errors.New("New password was used recently, choose another one")
+		}
+	}
+
+	if err := user.EncryptPassword(password); err != nil {
+		return err
+	}
+
+	user.PasswordChangedAt = time.Now()
+	user.PasswordChangeRequired = false
+
+	return nil
+}
+
+// PasswordExpired tells if the password must be changed before signing in, because an admin
+// required it or because it is older than the expiry of the role. A password never changed
+// is as old as the user.
+func (user UserDomain) PasswordExpired(expiry PasswordExpiry, now time.Time) bool {
+	if user.PasswordChangeRequired {
+		return true
+	}
+
+	maxAge := expiry[user.Role]
+
+	if maxAge <= 0 {
+		return false
+	}
+
+	changedAt := user.PasswordChangedAt
+
+	if changedAt.IsZero() {
+		changedAt = user.CreatedAt
+	}
+
+	return now.Sub(changedAt) >= maxAge
+}
+
+// IssuedBeforePasswordChange tells if a token issued at issuedAt predates the last password change.
+// The timestamps only keep seconds so a token issued in the same second as the change is accepted.
+func (user UserDomain) IssuedBeforePasswordChange(issuedAt time.Time) bool {
+	if user.PasswordChangedAt.IsZero() {
+		return false
+	}
+
+	return issuedAt.Before(user.PasswordChangedAt.Truncate(time.Second))
 }
 
+// EncryptPassword hashes the password with the preferred hasher, see SetPasswordHasher
 func (user *UserDomain) EncryptPassword(password string) error {
 
-	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashed, err := HashPassword(password)
 	if err != nil {
 		return err
 	}
 
-	user.Password = string(hashed)
+	user.Password = hashed
 
 	return nil
 }
 
 func (user *UserDomain) IsBcryptHash(s string) bool {
-	return strings.HasPrefix(s, "$2a$") || strings.HasPrefix(s, "$2b$") || strings.HasPrefix(s, "$2y$")
+	return NewBcryptHasher(bcrypt.DefaultCost).Identify(s)
 }
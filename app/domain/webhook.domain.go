@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookAllEvents subscribes to every user event
+const WebhookAllEvents = "*"
+
+const (
+	WebhookDeliveryPending = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryDead = "dead"
+)
+
+type WebhookSubscription struct {
+	Id uuid.UUID
+	Url string
+	EventTypes []string
+	Secret string
+	Active bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (subscription WebhookSubscription) Accepts(eventType string) bool {
+	if !subscription.Active {
+		return false
+	}
+
+	for _, accepted := range subscription.EventTypes {
+		if accepted == WebhookAllEvents || accepted == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WebhookDelivery is one event sent to one subscription, it keeps the result of the last attempt
+type WebhookDelivery struct {
+	Id uuid.UUID
+	SubscriptionId uuid.UUID
+	EventId uuid.UUID
+	EventType string
+	Payload []byte
+	Status string
+	Attempts int
+	NextAttemptAt time.Time
+	LastStatusCode int
+	LastError string
+	CreatedAt time.Time
+	DeliveredAt time.Time
+}
+
+func IsWebhookEventType(eventType string) bool {
+	switch eventType {
+	case WebhookAllEvents, UserCreatedEvent, UserUpdatedEvent, UserDeletedEvent, UserRestoredEvent, UserPasswordChangedEvent, UserStatusChangedEvent:
+		return true
+	}
+
+	return false
+}
+
+// SignWebhook returns the hex HMAC-SHA256 of "timestamp.body", receivers recompute it
+// with their secret and reject old timestamps to avoid replays
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,152 @@
+// Package authclient verifies the tokens issued by the go-hexagonal api so other Go services
+// don't have to reimplement it. Tokens signed with the shared secret (HS256) are verified with
+// Config.Secret and tokens signed with the rotating keys with the JWKS of Config.JWKSURL, both
+// can be set while the api moves from one to the other
+package authclient
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	DefaultClockSkew = 30 * time.Second
+	DefaultJWKSRefreshInterval = time.Hour
+	// a token with an unknown kid refetches the JWKS at most once per interval
+	DefaultJWKSMinRefreshInterval = 10 * time.Second
+)
+
+var (
+	ErrMissingToken = errors.New("Missing bearer token")
+	ErrInvalidToken = errors.New("Invalid token")
+	// ErrKeysUnavailable means the JWKS couldn't be fetched, the token may still be valid
+	ErrKeysUnavailable = errors.New("Signing keys unavailable")
+)
+
+// Config of the verifier, only Secret or JWKSURL is required
+type Config struct {
+	// Secret verifies the HS256 tokens
+	Secret []byte
+	// JWKSURL is the /.well-known/jwks.json of the api, it verifies the RS256, ES256 and EdDSA tokens
+	JWKSURL string
+	// Issuer, when set, must be the iss claim of the tokens, it's the OIDC_ISSUER of the api
+	Issuer string
+	// Audience, when set, must be one of the aud claims of the tokens
+	Audience string
+	// TokenTypes restricts the accepted tokens to TokenUser or TokenAccess, both when empty
+	TokenTypes []string
+	// ClockSkew is tolerated when checking exp, nbf and iat, zero uses DefaultClockSkew
+	ClockSkew time.Duration
+	HTTPClient *http.Client
+	// JWKSRefreshInterval is how long the fetched keys are cached, zero uses DefaultJWKSRefreshInterval
+	JWKSRefreshInterval time.Duration
+	// JWKSMinRefreshInterval limits the refetches caused by unknown keys, zero uses DefaultJWKSMinRefreshInterval
+	JWKSMinRefreshInterval time.Duration
+}
+
+type Verifier interface {
+	// Verify returns the claims of a valid token, ErrInvalidToken otherwise or ErrKeysUnavailable
+	// when the keys couldn't be fetched
+	Verify(token string) (Claims, error)
+}
+
+func NewVerifier(config Config) (Verifier, error) {
+	if len(config.Secret) == 0 && config.JWKSURL == "" {
+		return nil, errors.New("Inform a secret or a JWKS url")
+	}
+
+	for _, tokenType := range config.TokenTypes {
+		if tokenType != TokenUser && tokenType != TokenAccess {
+			return nil, errors.New("Invalid token type: " + tokenType)
+		}
+	}
+
+	if config.ClockSkew == 0 {
+		config.ClockSkew = DefaultClockSkew
+	}
+
+	verifier := &verifier{config: config}
+
+	if len(config.Secret) > 0 {
+		verifier.algorithms = append(verifier.algorithms, jwt.SigningMethodHS256.Alg())
+	}
+
+	if config.JWKSURL != "" {
+		verifier.keys = newJWKSCache(config)
+		verifier.algorithms = append(verifier.algorithms, jwksAlgorithms...)
+	}
+
+	return verifier, nil
+}
+
+type verifier struct {
+	config Config
+	algorithms []string
+	keys *jwksCache
+}
+
+func (verifier *verifier) Verify(token string) (Claims, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(verifier.algorithms),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(verifier.config.ClockSkew),
+	}
+
+	if verifier.config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(verifier.config.Issuer))
+	}
+
+	if verifier.config.Audience != "" {
+		options = append(options, jwt.WithAudience(verifier.config.Audience))
+	}
+
+	claims := Claims{}
+
+	_, err := jwt.ParseWithClaims(token, &claims, verifier.key, options...)
+
+	if errors.Is(err, ErrKeysUnavailable) {
+		return Claims{}, ErrKeysUnavailable
+	}
+
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+
+	tokenType := claims.Type()
+
+	// two-factor challenges and ID tokens are signed with the same keys but aren't credentials
+	if tokenType == "" {
+		return Claims{}, ErrInvalidToken
+	}
+
+	if len(verifier.config.TokenTypes) > 0 && !slices.Contains(verifier.config.TokenTypes, tokenType) {
+		return Claims{}, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+func (verifier *verifier) key(token *jwt.Token) (any, error) {
+	if token.Method.Alg() == jwt.SigningMethodHS256.Alg() {
+		return verifier.config.Secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+
+	key, err := verifier.keys.find(kid)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if key.algorithm != token.Method.Alg() {
+		return nil, errors.New("Unexpected signing algorithm")
+	}
+
+	return key.public, nil
+}
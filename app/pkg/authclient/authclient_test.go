@@ -0,0 +1,473 @@
+package authclient_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/pkg/authclient"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+const issuer = "https://id.example.com"
+
+// jwksServer publishes the public part of the keys like /.well-known/jwks.json of the api
+type jwksServer struct {
+	*httptest.Server
+	mutex sync.Mutex
+	keys map[string]crypto.Signer
+	fetches int
+	fail bool
+}
+
+func newJWKSServer(t *testing.T) *jwksServer {
+	server := &jwksServer{keys: map[string]crypto.Signer{}}
+
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+
+		server.fetches++
+
+		if server.fail {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		keys := []map[string]string{}
+
+		for kid, key := range server.keys {
+			keys = append(keys, publicJWK(kid, key))
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func (server *jwksServer) add(kid string, key crypto.Signer) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.keys[kid] = key
+}
+
+func (server *jwksServer) unavailable() {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.fail = true
+}
+
+func (server *jwksServer) count() int {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	return server.fetches
+}
+
+func publicJWK(kid string, key crypto.Signer) map[string]string {
+	encode := base64.RawURLEncoding.EncodeToString
+
+	switch public := key.Public().(type) {
+	case *rsa.PublicKey:
+		return map[string]string{"kty": "RSA", "kid": kid, "use": "sig", "alg": "RS256", "n": encode(public.N.Bytes()), "e": encode(big.NewInt(int64(public.E)).Bytes())}
+	case *ecdsa.PublicKey:
+		return map[string]string{"kty": "EC", "kid": kid, "use": "sig", "alg": "ES256", "crv": "P-256", "x": encode(public.X.FillBytes(make([]byte, 32))), "y": encode(public.Y.FillBytes(make([]byte, 32)))}
+	default:
+		return map[string]string{"kty": "OKP", "kid": kid, "use": "sig", "alg": "EdDSA", "crv": "Ed25519", "x": encode(public.(ed25519.PublicKey))}
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when signing the token", err.Error())
+	}
+
+	return signed
+}
+
+// userClaims are the claims of a token issued by the login of the api
+func userClaims(userId uuid.UUID) jwt.MapClaims {
+	now := time.Now()
+
+	return jwt.MapClaims{
+		"id": userId.String(),
+		"email": "john@email.com",
+		"sid": uuid.NewString(),
+		"iss": issuer,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+}
+
+func TestVerifier(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	jwks := newJWKSServer(t)
+	jwks.add("rsa", rsaKey)
+	jwks.add("ec", ecKey)
+	jwks.add("ed", edKey)
+
+	verifier, err := authclient.NewVerifier(authclient.Config{JWKSURL: jwks.URL, Issuer: issuer})
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when creating the verifier", err.Error())
+	}
+
+	userId := uuid.New()
+
+	t.Run("asymmetric_algorithms", func(t *testing.T) {
+		tokens := []string{
+			sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, userClaims(userId)),
+			sign(t, jwt.SigningMethodES256, "ec", ecKey, userClaims(userId)),
+			sign(t, jwt.SigningMethodEdDSA, "ed", edKey, userClaims(userId)),
+		}
+
+		for _, token := range tokens {
+			claims, err := verifier.Verify(token)
+
+			assert.NoError(t, err)
+			assert.EqualValues(t, authclient.TokenUser, claims.Type())
+			assert.EqualValues(t, "john@email.com", claims.Email)
+
+			id, ok := claims.User()
+
+			assert.True(t, ok)
+			assert.EqualValues(t, userId, id)
+			assert.True(t, claims.HasScope("anything"))
+		}
+
+		// the keys are fetched once and cached
+		assert.EqualValues(t, 1, jwks.count())
+	})
+
+	t.Run("access_token", func(t *testing.T) {
+		now := time.Now()
+
+		claims, err := verifier.Verify(sign(t, jwt.SigningMethodES256, "ec", ecKey, jwt.MapClaims{
+			"iss": issuer,
+			"sub": userId.String(),
+			"client_id": "client",
+			"scope": "openid email",
+			"jti": uuid.NewString(),
+			"iat": now.Unix(),
+			"exp": now.Add(time.Hour).Unix(),
+		}))
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, authclient.TokenAccess, claims.Type())
+		assert.EqualValues(t, []string{"openid", "email"}, claims.Scopes())
+		assert.True(t, claims.HasScope("email"))
+		assert.False(t, claims.HasScope("phone"))
+
+		id, ok := claims.User()
+
+		assert.True(t, ok)
+		assert.EqualValues(t, userId, id)
+
+		// client_credentials tokens have the client as subject
+		claims, err = verifier.Verify(sign(t, jwt.SigningMethodES256, "ec", ecKey, jwt.MapClaims{
+			"iss": issuer,
+			"sub": "client",
+			"client_id": "client",
+			"scope": "reports",
+			"exp": now.Add(time.Hour).Unix(),
+		}))
+
+		assert.NoError(t, err)
+
+		_, ok = claims.User()
+
+		assert.False(t, ok)
+	})
+
+	t.Run("not_credentials", func(t *testing.T) {
+		challenge := jwt.MapClaims{"id": userId.String(), "purpose": "2fa", "iss": issuer, "exp": time.Now().Add(time.Minute).Unix()}
+		idToken := jwt.MapClaims{"sub": userId.String(), "aud": "client", "iss": issuer, "exp": time.Now().Add(time.Minute).Unix()}
+
+		_, err := verifier.Verify(sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, challenge))
+
+		assert.ErrorIs(t, err, authclient.ErrInvalidToken)
+
+		_, err = verifier.Verify(sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, idToken))
+
+		assert.ErrorIs(t, err, authclient.ErrInvalidToken)
+	})
+
+	t.Run("wrong_issuer", func(t *testing.T) {
+		claims := userClaims(userId)
+		claims["iss"] = "https://evil.example.com"
+
+		_, err := verifier.Verify(sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, claims))
+
+		assert.ErrorIs(t, err, authclient.ErrInvalidToken)
+	})
+
+	t.Run("expiration_with_clock_skew", func(t *testing.T) {
+		claims := userClaims(userId)
+		claims["exp"] = time.Now().Add(-10 * time.Second).Unix()
+
+		_, err := verifier.Verify(sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, claims))
+
+		assert.NoError(t, err)
+
+		claims["exp"] = time.Now().Add(-time.Minute).Unix()
+
+		_, err = verifier.Verify(sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, claims))
+
+		assert.ErrorIs(t, err, authclient.ErrInvalidToken)
+
+		delete(claims, "exp")
+
+		_, err = verifier.Verify(sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, claims))
+
+		assert.ErrorIs(t, err, authclient.ErrInvalidToken)
+	})
+
+	t.Run("issued_in_the_future", func(t *testing.T) {
+		claims := userClaims(userId)
+		claims["iat"] = time.Now().Add(time.Hour).Unix()
+
+		_, err := verifier.Verify(sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, claims))
+
+		assert.ErrorIs(t, err, authclient.ErrInvalidToken)
+	})
+
+	t.Run("algorithm_of_another_key", func(t *testing.T) {
+		_, err := verifier.Verify(sign(t, jwt.SigningMethodES256, "rsa", ecKey, userClaims(userId)))
+
+		assert.ErrorIs(t, err, authclient.ErrInvalidToken)
+	})
+
+	t.Run("hmac_without_secret", func(t *testing.T) {
+		_, err := verifier.Verify(sign(t, jwt.SigningMethodHS256, "", []byte("super-secret"), userClaims(userId)))
+
+		assert.ErrorIs(t, err, authclient.ErrInvalidToken)
+	})
+
+	t.Run("audience", func(t *testing.T) {
+		audienceVerifier, _ := authclient.NewVerifier(authclient.Config{JWKSURL: jwks.URL, Audience: "billing"})
+
+		claims := userClaims(userId)
+
+		_, err := audienceVerifier.Verify(sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, claims))
+
+		assert.ErrorIs(t, err, authclient.ErrInvalidToken)
+
+		claims["aud"] = []string{"reports", "billing"}
+
+		_, err = audienceVerifier.Verify(sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, claims))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("token_types", func(t *testing.T) {
+		accessOnly, _ := authclient.NewVerifier(authclient.Config{JWKSURL: jwks.URL, TokenTypes: []string{authclient.TokenAccess}})
+
+		_, err := accessOnly.Verify(sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, userClaims(userId)))
+
+		assert.ErrorIs(t, err, authclient.ErrInvalidToken)
+
+		_, err = authclient.NewVerifier(authclient.Config{JWKSURL: jwks.URL, TokenTypes: []string{"id"}})
+
+		assert.EqualError(t, err, "Invalid token type: id")
+	})
+
+	t.Run("without_keys", func(t *testing.T) {
+		_, err := authclient.NewVerifier(authclient.Config{})
+
+		assert.EqualError(t, err, "Inform a secret or a JWKS url")
+	})
+}
+
+func TestVerifierHMAC(t *testing.T) {
+	secret := []byte("super-secret")
+	verifier, _ := authclient.NewVerifier(authclient.Config{Secret: secret})
+	userId := uuid.New()
+
+	claims, err := verifier.Verify(sign(t, jwt.SigningMethodHS256, "", secret, userClaims(userId)))
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, userId.String(), claims.UserId)
+
+	_, err = verifier.Verify(sign(t, jwt.SigningMethodHS256, "", []byte("another-secret"), userClaims(userId)))
+
+	assert.ErrorIs(t, err, authclient.ErrInvalidToken)
+
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	_, err = verifier.Verify(sign(t, jwt.SigningMethodES256, "ec", ecKey, userClaims(userId)))
+
+	assert.ErrorIs(t, err, authclient.ErrInvalidToken)
+}
+
+func TestVerifierKeyRefresh(t *testing.T) {
+	current, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	next, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	userId := uuid.New()
+
+	t.Run("unknown_kid_refetches_once_per_interval", func(t *testing.T) {
+		jwks := newJWKSServer(t)
+		jwks.add("current", current)
+
+		verifier, _ := authclient.NewVerifier(authclient.Config{JWKSURL: jwks.URL, JWKSMinRefreshInterval: time.Hour})
+
+		_, err := verifier.Verify(sign(t, jwt.SigningMethodES256, "current", current, userClaims(userId)))
+
+		assert.NoError(t, err)
+
+		jwks.add("next", next)
+
+		_, err = verifier.Verify(sign(t, jwt.SigningMethodES256, "next", next, userClaims(userId)))
+
+		assert.ErrorIs(t, err, authclient.ErrInvalidToken)
+		assert.EqualValues(t, 1, jwks.count())
+	})
+
+	t.Run("unknown_kid_refetches", func(t *testing.T) {
+		jwks := newJWKSServer(t)
+		jwks.add("current", current)
+
+		verifier, _ := authclient.NewVerifier(authclient.Config{JWKSURL: jwks.URL, JWKSMinRefreshInterval: time.Nanosecond})
+
+		_, err := verifier.Verify(sign(t, jwt.SigningMethodES256, "current", current, userClaims(userId)))
+
+		assert.NoError(t, err)
+
+		jwks.add("next", next)
+
+		_, err = verifier.Verify(sign(t, jwt.SigningMethodES256, "next", next, userClaims(userId)))
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, jwks.count())
+	})
+
+	t.Run("stale_keys_when_unreachable", func(t *testing.T) {
+		jwks := newJWKSServer(t)
+		jwks.add("current", current)
+
+		verifier, _ := authclient.NewVerifier(authclient.Config{JWKSURL: jwks.URL, JWKSRefreshInterval: time.Nanosecond})
+
+		_, err := verifier.Verify(sign(t, jwt.SigningMethodES256, "current", current, userClaims(userId)))
+
+		assert.NoError(t, err)
+
+		jwks.unavailable()
+
+		_, err = verifier.Verify(sign(t, jwt.SigningMethodES256, "current", current, userClaims(userId)))
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, jwks.count())
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		jwks := newJWKSServer(t)
+		jwks.unavailable()
+
+		verifier, _ := authclient.NewVerifier(authclient.Config{JWKSURL: jwks.URL})
+
+		_, err := verifier.Verify(sign(t, jwt.SigningMethodES256, "current", current, userClaims(userId)))
+
+		assert.ErrorIs(t, err, authclient.ErrKeysUnavailable)
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	secret := []byte("super-secret")
+	verifier, _ := authclient.NewVerifier(authclient.Config{Secret: secret})
+	userId := uuid.New()
+	token := sign(t, jwt.SigningMethodHS256, "", secret, userClaims(userId))
+
+	unreachable, _ := authclient.NewVerifier(authclient.Config{JWKSURL: "http://127.0.0.1:1/jwks.json"})
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	handler := authclient.Middleware(verifier)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := authclient.ClaimsFromContext(r.Context())
+
+		assert.True(t, ok)
+
+		w.Write([]byte(claims.UserId))
+	}))
+
+	router := gin.New()
+	router.GET("/gin", authclient.GinMiddleware(verifier), func(c *gin.Context) {
+		claims, ok := authclient.ClaimsFromGin(c)
+
+		assert.True(t, ok)
+
+		c.String(http.StatusOK, claims.UserId)
+	})
+	router.GET("/unreachable", authclient.GinMiddleware(unreachable), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(handler http.Handler, path string, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		return recorder
+	}
+
+	for name, handler := range map[string]http.Handler{"net_http": handler, "gin": router} {
+		t.Run(name, func(t *testing.T) {
+			recorder := request(handler, "/gin", "Bearer " + token)
+
+			assert.EqualValues(t, http.StatusOK, recorder.Code)
+			assert.EqualValues(t, userId.String(), recorder.Body.String())
+
+			recorder = request(handler, "/gin", "")
+
+			assert.EqualValues(t, http.StatusUnauthorized, recorder.Code)
+			assert.EqualValues(t, "Bearer", recorder.Header().Get("WWW-Authenticate"))
+			assert.JSONEq(t, `"Missing bearer token"`, recorder.Body.String())
+
+			recorder = request(handler, "/gin", "Bearer invalid")
+
+			assert.EqualValues(t, http.StatusUnauthorized, recorder.Code)
+			assert.JSONEq(t, `"Invalid token"`, recorder.Body.String())
+		})
+	}
+
+	t.Run("keys_unavailable", func(t *testing.T) {
+		recorder := request(router, "/unreachable", "Bearer " + sign(t, jwt.SigningMethodES256, "ec", ecKey, userClaims(userId)))
+
+		assert.EqualValues(t, http.StatusServiceUnavailable, recorder.Code)
+	})
+}
@@ -0,0 +1,72 @@
+package authclient
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	// TokenUser is issued by the login of the api, it acts on behalf of the user without scopes
+	TokenUser = "user"
+	// TokenAccess is an OAuth access token issued to a client, with or without a user
+	TokenAccess = "access"
+)
+
+// Claims of the tokens of the api, user tokens carry UserId and Email while access tokens
+// carry ClientId, Scope and the user or the client in the subject
+type Claims struct {
+	jwt.RegisteredClaims
+	UserId string `json:"id,omitempty"`
+	Email string `json:"email,omitempty"`
+	SessionId string `json:"sid,omitempty"`
+	ClientId string `json:"client_id,omitempty"`
+	Scope string `json:"scope,omitempty"`
+	Purpose string `json:"purpose,omitempty"`
+}
+
+// Type returns TokenUser, TokenAccess or "" for the tokens that aren't credentials
+func (claims Claims) Type() string {
+	switch {
+	case claims.Purpose != "":
+		return ""
+	case claims.ClientId != "":
+		return TokenAccess
+	case claims.UserId != "":
+		return TokenUser
+	default:
+		return ""
+	}
+}
+
+// User returns the user the token acts for, access tokens of the client_credentials grant have none
+func (claims Claims) User() (uuid.UUID, bool) {
+	subject := claims.UserId
+
+	if claims.Type() == TokenAccess {
+		subject = claims.Subject
+	}
+
+	if subject == "" || subject == claims.ClientId {
+		return uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(subject)
+
+	return id, err == nil
+}
+
+func (claims Claims) Scopes() []string {
+	return strings.Fields(claims.Scope)
+}
+
+// HasScope is always true for user tokens, like in the api they aren't restricted by scopes
+func (claims Claims) HasScope(scope string) bool {
+	if claims.Type() == TokenUser {
+		return true
+	}
+
+	return slices.Contains(claims.Scopes(), scope)
+}
@@ -0,0 +1,205 @@
+package authclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var jwksAlgorithms = []string{"RS256", "ES256", "EdDSA"}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N string `json:"n"`
+	E string `json:"e"`
+	Crv string `json:"crv"`
+	X string `json:"x"`
+	Y string `json:"y"`
+}
+
+type publicKey struct {
+	algorithm string
+	public any
+}
+
+func newJWKSCache(config Config) *jwksCache {
+	cache := &jwksCache{
+		url: config.JWKSURL,
+		client: config.HTTPClient,
+		refreshInterval: config.JWKSRefreshInterval,
+		minRefreshInterval: config.JWKSMinRefreshInterval,
+	}
+
+	if cache.client == nil {
+		cache.client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	if cache.refreshInterval == 0 {
+		cache.refreshInterval = DefaultJWKSRefreshInterval
+	}
+
+	if cache.minRefreshInterval == 0 {
+		cache.minRefreshInterval = DefaultJWKSMinRefreshInterval
+	}
+
+	return cache
+}
+
+// jwksCache keeps the keys between refreshes, the api publishes a key well before it signs so
+// an unknown kid is rare and usually means a forged token, hence the refetch limit
+type jwksCache struct {
+	url string
+	client *http.Client
+	refreshInterval time.Duration
+	minRefreshInterval time.Duration
+	mutex sync.Mutex
+	keys map[string]publicKey
+	fetchedAt time.Time
+}
+
+func (cache *jwksCache) find(kid string) (publicKey, error) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cache.keys == nil || time.Since(cache.fetchedAt) >= cache.refreshInterval {
+		// stale keys are still better than none while the api can't be reached
+		if err := cache.refresh(); err != nil && cache.keys == nil {
+			return publicKey{}, err
+		}
+	}
+
+	if key, ok := cache.keys[kid]; ok {
+		return key, nil
+	}
+
+	if time.Since(cache.fetchedAt) < cache.minRefreshInterval {
+		return publicKey{}, errors.New("Unknown signing key")
+	}
+
+	if err := cache.refresh(); err != nil {
+		return publicKey{}, err
+	}
+
+	if key, ok := cache.keys[kid]; ok {
+		return key, nil
+	}
+
+	return publicKey{}, errors.New("Unknown signing key")
+}
+
+// refresh must be called with the mutex held, a failed fetch also counts for the refetch limit
+func (cache *jwksCache) refresh() error {
+	cache.fetchedAt = time.Now()
+
+	response, err := cache.client.Get(cache.url)
+
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrKeysUnavailable, err)
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s", ErrKeysUnavailable, response.Status)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(&set); err != nil {
+		return fmt.Errorf("%w: %v", ErrKeysUnavailable, err)
+	}
+
+	keys := map[string]publicKey{}
+
+	// keys this package can't use are skipped so a new key type doesn't break the others
+	for _, key := range set.Keys {
+		if key.Kid == "" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+
+		public, err := parseJWK(key)
+
+		if err != nil {
+			continue
+		}
+
+		keys[key.Kid] = public
+	}
+
+	cache.keys = keys
+
+	return nil
+}
+
+func parseJWK(key jwk) (publicKey, error) {
+	switch {
+	case key.Kty == "RSA" && (key.Alg == "" || key.Alg == "RS256"):
+		n, err := decodeSegment(key.N)
+
+		if err != nil {
+			return publicKey{}, err
+		}
+
+		e, err := decodeSegment(key.E)
+
+		if err != nil || len(e) > 4 {
+			return publicKey{}, errors.New("Invalid RSA exponent")
+		}
+
+		public := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+
+		return publicKey{algorithm: "RS256", public: public}, nil
+	case key.Kty == "EC" && key.Crv == "P-256" && (key.Alg == "" || key.Alg == "ES256"):
+		x, err := decodeSegment(key.X)
+
+		if err != nil {
+			return publicKey{}, err
+		}
+
+		y, err := decodeSegment(key.Y)
+
+		if err != nil {
+			return publicKey{}, err
+		}
+
+		public := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+
+		if !public.Curve.IsOnCurve(public.X, public.Y) {
+			return publicKey{}, errors.New("Invalid EC point")
+		}
+
+		return publicKey{algorithm: "ES256", public: public}, nil
+	case key.Kty == "OKP" && key.Crv == "Ed25519" && (key.Alg == "" || key.Alg == "EdDSA"):
+		x, err := decodeSegment(key.X)
+
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return publicKey{}, errors.New("Invalid Ed25519 key")
+		}
+
+		return publicKey{algorithm: "EdDSA", public: ed25519.PublicKey(x)}, nil
+	default:
+		return publicKey{}, errors.New("Unsupported key")
+	}
+}
+
+func decodeSegment(value string) ([]byte, error) {
+	if value == "" {
+		return nil, errors.New("Missing key parameter")
+	}
+
+	return base64.RawURLEncoding.DecodeString(value)
+}
@@ -0,0 +1,102 @@
+package authclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type contextKey struct{}
+
+const ginClaimsKey = "authclient.claims"
+
+// BearerToken reads the token of the "Authorization: Bearer <token>" header
+func BearerToken(r *http.Request) (string, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	if !ok || token == "" {
+		return "", ErrMissingToken
+	}
+
+	return token, nil
+}
+
+// Middleware refuses the requests without a valid bearer token, the handlers read the claims
+// with ClaimsFromContext
+func Middleware(verifier Verifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := authenticate(verifier, r)
+
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.WriteHeader(status(err))
+
+				json.NewEncoder(w).Encode(err.Error())
+
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, claims)))
+		})
+	}
+}
+
+func ClaimsFromContext(ctx context.Context) (Claims, bool) {
+	claims, ok := ctx.Value(contextKey{}).(Claims)
+
+	return claims, ok
+}
+
+// GinMiddleware is Middleware for gin, the handlers read the claims with ClaimsFromGin
+func GinMiddleware(verifier Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := authenticate(verifier, c.Request)
+
+		if err != nil {
+			c.Header("WWW-Authenticate", "Bearer")
+			c.AbortWithStatusJSON(status(err), err.Error())
+
+			return
+		}
+
+		c.Set(ginClaimsKey, claims)
+
+		c.Next()
+	}
+}
+
+func ClaimsFromGin(c *gin.Context) (Claims, bool) {
+	value, ok := c.Get(ginClaimsKey)
+
+	if !ok {
+		return Claims{}, false
+	}
+
+	claims, ok := value.(Claims)
+
+	return claims, ok
+}
+
+func authenticate(verifier Verifier, r *http.Request) (Claims, error) {
+	token, err := BearerToken(r)
+
+	if err != nil {
+		return Claims{}, err
+	}
+
+	return verifier.Verify(token)
+}
+
+func status(err error) int {
+	if errors.Is(err, ErrKeysUnavailable) {
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusUnauthorized
+}
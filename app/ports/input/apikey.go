@@ -0,0 +1,12 @@
+package port
+
+import (
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+)
+
+type APIKeyService interface {
+	Create(domain.APIKey) (domain.APIKey, string, error)
+	List(uuid.UUID) ([]domain.APIKey, error)
+	Revoke(uuid.UUID, uuid.UUID) error
+}
@@ -0,0 +1,8 @@
+package port
+
+import "github.com/PedroPereiraN/go-hexagonal/domain"
+
+// Authenticator resolves the caller of a request from its credential
+type Authenticator interface {
+	Authenticate(string) (domain.Identity, error)
+}
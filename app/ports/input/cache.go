@@ -0,0 +1,7 @@
+package port
+
+import "github.com/PedroPereiraN/go-hexagonal/domain"
+
+type CacheStatsProvider interface {
+	Stats() domain.CacheStats
+}
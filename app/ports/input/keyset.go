@@ -0,0 +1,9 @@
+package port
+
+import "github.com/PedroPereiraN/go-hexagonal/domain"
+
+// KeySet publishes the keys that verify the tokens
+type KeySet interface {
+	Algorithms() []string
+	PublicKeys() []domain.JWK
+}
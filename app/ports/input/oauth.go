@@ -0,0 +1,19 @@
+package port
+
+import (
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+)
+
+type OAuthService interface {
+	CreateClient(domain.OAuthClient, bool) (domain.OAuthClient, string, error)
+	ListClients() ([]domain.OAuthClient, error)
+	DeleteClient(string) error
+	ValidateAuthorization(domain.AuthorizationRequest) (domain.AuthorizationRequest, domain.OAuthClient, error)
+	Authorize(domain.AuthorizationRequest, uuid.UUID) (string, error)
+	Token(domain.TokenRequest) (domain.TokenResponse, error)
+	Revoke(string, string, string) error
+	UserInfo(string) (map[string]any, error)
+	AuthenticateClient(string, string) (domain.OAuthClient, error)
+	Introspect(string) (domain.TokenIntrospection, error)
+}
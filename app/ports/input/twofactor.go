@@ -0,0 +1,13 @@
+package port
+
+import (
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+)
+
+type TwoFactorService interface {
+	Enroll(uuid.UUID) (domain.TwoFactorEnrollment, error)
+	Confirm(uuid.UUID, string) ([]string, error)
+	CompleteLogin(string, string, domain.Client) (domain.LoginResult, error)
+	Reset(uuid.UUID) error
+}
@@ -1,6 +1,8 @@
 package port
 
 import (
+	"time"
+
 	"github.com/PedroPereiraN/go-hexagonal/domain"
 	"github.com/google/uuid"
 )
@@ -11,6 +13,25 @@ type UserService interface {
 	ListAll() ([]domain.UserDomain, error)
 	Delete(uuid.UUID) (uuid.UUID, error)
 	Update(uuid.UUID, domain.UserDomain) (uuid.UUID, error)
-	UpdatePassword(uuid.UUID, string) (uuid.UUID, error)
-	Login(string, string) (string, error)
+	UpdatePassword(uuid.UUID, string, string) (uuid.UUID, error)
+	ResetPassword(uuid.UUID, string) (uuid.UUID, error)
+	Deactivate(uuid.UUID, string) (uuid.UUID, error)
+	Login(string, string, domain.Client) (domain.LoginResult, error)
+	ChangeExpiredPassword(string, string, domain.Client) (domain.LoginResult, error)
+	SetRole(uuid.UUID, string) (uuid.UUID, error)
+	RequirePasswordChange(uuid.UUID) (uuid.UUID, error)
+	Suspend(uuid.UUID, string, string, time.Time) (uuid.UUID, error)
+	Reactivate(uuid.UUID, string, string) (uuid.UUID, error)
+	ListByStatus(string) ([]domain.UserDomain, error)
+	ListDeleted() ([]domain.UserDomain, error)
+	Restore(uuid.UUID) (uuid.UUID, error)
+	Purge(bool) ([]domain.UserDomain, error)
+	IssueToken(uuid.UUID, domain.Client) (string, error)
+	ListSessions(uuid.UUID) ([]domain.Session, error)
+	RevokeSession(uuid.UUID, uuid.UUID) error
+	ListLogins(uuid.UUID, int) ([]domain.LoginAttempt, error)
+	PurgeLoginHistory() (int64, error)
+	Import(domain.ImportRowReader, string, bool) (domain.ImportReport, error)
+	Export(domain.UserFilter) (domain.UserIterator, error)
+	Search(string, int, int) (domain.UserSearchPage, error)
 }
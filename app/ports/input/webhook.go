@@ -0,0 +1,16 @@
+package port
+
+import (
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+)
+
+type WebhookService interface {
+	CreateSubscription(domain.WebhookSubscription) (domain.WebhookSubscription, error)
+	GetSubscription(uuid.UUID) (domain.WebhookSubscription, error)
+	ListSubscriptions() ([]domain.WebhookSubscription, error)
+	UpdateSubscription(uuid.UUID, domain.WebhookSubscription) (uuid.UUID, error)
+	DeleteSubscription(uuid.UUID) (uuid.UUID, error)
+	ListDeliveries(uuid.UUID) ([]domain.WebhookDelivery, error)
+	Redeliver(uuid.UUID) (uuid.UUID, error)
+}
@@ -0,0 +1,17 @@
+package port
+
+import (
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+)
+
+type APIKeyRepository interface {
+	CreateAPIKey(domain.APIKey) error
+	FindAPIKeyByPrefix(string) (domain.APIKey, error)
+	ListAPIKeys(userId uuid.UUID) ([]domain.APIKey, error)
+	// RevokeAPIKey fails with sql.ErrNoRows when the key doesn't belong to the user or was already revoked
+	RevokeAPIKey(userId uuid.UUID, id uuid.UUID) error
+	TouchAPIKey(id uuid.UUID, lastUsedAt time.Time) error
+}
@@ -0,0 +1,8 @@
+package port
+
+// CacheBackend stores encoded values by key, entries expire after the ttl the backend was built with
+type CacheBackend interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte)
+	Delete(keys ...string)
+}
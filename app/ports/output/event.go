@@ -0,0 +1,26 @@
+package port
+
+import (
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+)
+
+// EventPublisher delivers outbox messages to whoever listens to user events, it may be called
+// more than once for the same message so consumers must deduplicate by message id
+type EventPublisher interface {
+	Publish(domain.OutboxMessage) error
+}
+
+// OutboxRepository is used by the relay, messages are written by UserRepository.AddEvent
+// inside the same transaction as the users change
+type OutboxRepository interface {
+	// ClaimPending hands the pending messages to a single relay until the lease ends, it leaves out the
+	// messages of the users whose earlier message is waiting for a retry or claimed by another relay
+	ClaimPending(limit int, now time.Time, lease time.Duration) ([]domain.OutboxMessage, error)
+	// MarkPublished, MarkFailed and MarkDead end the claim of the message
+	MarkPublished(id uuid.UUID) error
+	MarkFailed(id uuid.UUID, reason string, nextAttemptAt time.Time) error
+	MarkDead(id uuid.UUID, reason string) error
+}
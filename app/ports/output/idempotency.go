@@ -0,0 +1,14 @@
+package port
+
+import (
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+)
+
+type IdempotencyRepository interface {
+	// Reserve stores the record as pending, when the key is already taken
+	// the stored record is returned and the boolean is false
+	Reserve(domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error)
+	Find(key string) (domain.IdempotencyRecord, error)
+	Complete(key string, status int, contentType string, body []byte) error
+	Release(key string) error
+}
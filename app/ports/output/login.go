@@ -0,0 +1,16 @@
+package port
+
+import (
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+)
+
+type LoginHistoryRepository interface {
+	AddLoginAttempt(domain.LoginAttempt) error
+	// ListLoginAttempts returns the most recent attempts first, up to limit
+	ListLoginAttempts(userId uuid.UUID, limit int) ([]domain.LoginAttempt, error)
+	// DeleteLoginAttempts removes the attempts made before the time and tells how many were removed
+	DeleteLoginAttempts(time.Time) (int64, error)
+}
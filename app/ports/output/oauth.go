@@ -0,0 +1,22 @@
+package port
+
+import (
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+)
+
+type OAuthRepository interface {
+	CreateClient(domain.OAuthClient) error
+	FindClient(string) (domain.OAuthClient, error)
+	ListClients() ([]domain.OAuthClient, error)
+	DeleteClient(string) error
+	CreateAuthorizationCode(domain.AuthorizationCode) error
+	// UseAuthorizationCode consumes the code, a code can only be used once so the second call fails with sql.ErrNoRows
+	UseAuthorizationCode(hash string) (domain.AuthorizationCode, error)
+	CreateToken(domain.OAuthToken) error
+	FindToken(uuid.UUID) (domain.OAuthToken, error)
+	FindTokenByHash(string) (domain.OAuthToken, error)
+	RevokeToken(uuid.UUID) error
+	// RevokeGrant revokes every access and refresh token issued from the same authorization
+	RevokeGrant(grantId uuid.UUID) error
+}
@@ -0,0 +1,18 @@
+package port
+
+import (
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+)
+
+type SessionRepository interface {
+	CreateSession(domain.Session) error
+	FindSession(uuid.UUID) (domain.Session, error)
+	// ListSessions only returns the sessions that were not revoked and did not expire
+	ListSessions(userId uuid.UUID) ([]domain.Session, error)
+	// RevokeSession fails with sql.ErrNoRows when the session doesn't belong to the user or was already revoked
+	RevokeSession(userId uuid.UUID, id uuid.UUID) error
+	TouchSession(id uuid.UUID, lastSeenAt time.Time) error
+}
@@ -0,0 +1,15 @@
+package port
+
+import (
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+)
+
+type SigningKeyRepository interface {
+	// CreateSigningKey stores the key with its private part already encrypted in EncryptedKey
+	CreateSigningKey(domain.SigningKey) error
+	// ListSigningKeys returns the keys not expired at the given time, oldest first
+	ListSigningKeys(now time.Time) ([]domain.SigningKey, error)
+	DeleteExpiredSigningKeys(now time.Time) (int64, error)
+}
@@ -0,0 +1,23 @@
+package port
+
+import (
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+)
+
+type TwoFactorRepository interface {
+	FindTwoFactor(uuid.UUID) (domain.TwoFactor, error)
+	// SaveTwoFactor inserts or replaces the enrolment of the user
+	SaveTwoFactor(domain.TwoFactor) error
+	// DeleteTwoFactor removes the enrolment and the recovery codes
+	DeleteTwoFactor(uuid.UUID) error
+	ReplaceRecoveryCodes(userId uuid.UUID, hashes []string) error
+	// UseRecoveryCode marks the code as used, it returns false when it doesn't exist or was already used
+	UseRecoveryCode(userId uuid.UUID, hash string) (bool, error)
+}
+
+// SecretCipher encrypts the secrets stored at rest
+type SecretCipher interface {
+	Encrypt(plaintext []byte) (string, error)
+	Decrypt(ciphertext string) ([]byte, error)
+}
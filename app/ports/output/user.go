@@ -1,6 +1,7 @@
 package port
 
 import (
+	"time"
 	"github.com/PedroPereiraN/go-hexagonal/domain"
 	"github.com/google/uuid"
 )
@@ -14,4 +15,27 @@ type UserRepository interface {
 	Delete(uuid.UUID) (uuid.UUID, error)
 	Update(uuid.UUID, domain.UserDomain) (uuid.UUID, error)
 	UpdatePassword(uuid.UUID, domain.UserDomain) (uuid.UUID, error)
+	UpdateRole(uuid.UUID, string) (uuid.UUID, error)
+	// RequirePasswordChange makes the next login ask for a new password, UpdatePassword clears it
+	RequirePasswordChange(uuid.UUID) (uuid.UUID, error)
+	UpdateStatus(uuid.UUID, domain.UserStatus) (uuid.UUID, error)
+	// ListByStatus returns the users in the state, deleted ones included since they are deactivated
+	ListByStatus(string) ([]domain.UserDomain, error)
+	UpdateLastLogin(uuid.UUID, time.Time) error
+	ListDeleted() ([]domain.UserDomain, error)
+	FindDeletedUser(uuid.UUID) (domain.UserDomain, error)
+	Restore(uuid.UUID) (uuid.UUID, error)
+	Purge(time.Time) ([]uuid.UUID, error)
+	// Transaction runs fn with a repository bound to a single transaction, it is committed when fn returns nil
+	Transaction(fn func(UserRepository) error) error
+	Iterate(domain.UserFilter) (domain.UserIterator, error)
+	Search(domain.UserSearch) (domain.UserSearchPage, error)
+	// AddEvent writes the event to the outbox, it must be called inside Transaction
+	AddEvent(domain.Event) error
+	// AddPasswordHistory keeps the hash of a replaced password, call it inside Transaction with the change
+	AddPasswordHistory(userId uuid.UUID, hash string, replacedAt time.Time) error
+	// ListPasswordHistory returns up to limit hashes, the most recently replaced first
+	ListPasswordHistory(userId uuid.UUID, limit int) ([]string, error)
+	// PrunePasswordHistory removes every hash of the user but the keep most recent ones
+	PrunePasswordHistory(userId uuid.UUID, keep int) error
 }
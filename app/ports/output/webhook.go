@@ -0,0 +1,27 @@
+package port
+
+import (
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+)
+
+type WebhookRepository interface {
+	CreateSubscription(domain.WebhookSubscription) (uuid.UUID, error)
+	FindSubscription(uuid.UUID) (domain.WebhookSubscription, error)
+	ListSubscriptions() ([]domain.WebhookSubscription, error)
+	UpdateSubscription(domain.WebhookSubscription) (uuid.UUID, error)
+	DeleteSubscription(uuid.UUID) (uuid.UUID, error)
+	// CreateDelivery ignores a delivery for an event the subscription already received
+	CreateDelivery(domain.WebhookDelivery) error
+	FindDelivery(uuid.UUID) (domain.WebhookDelivery, error)
+	ListDeliveries(subscriptionId uuid.UUID, limit int) ([]domain.WebhookDelivery, error)
+	ListDueDeliveries(now time.Time, limit int) ([]domain.WebhookDelivery, error)
+	UpdateDelivery(domain.WebhookDelivery) error
+}
+
+// WebhookSender performs the http call, it returns the response status code
+type WebhookSender interface {
+	Send(url string, headers map[string]string, body []byte) (int, error)
+}
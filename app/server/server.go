@@ -0,0 +1,318 @@
+package server
+
+import (
+	"crypto"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/controller"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/middleware"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/cache"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/cipher"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/publisher"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/repository"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/webhook"
+	"github.com/PedroPereiraN/go-hexagonal/config"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+	"github.com/PedroPereiraN/go-hexagonal/services"
+	"github.com/gin-gonic/gin"
+	swaggerfiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+)
+
+// NewUserService builds the user service with the repository adapters, shared by the http server and the cli
+func NewUserService(db *sql.DB, cfg config.Config) service.UserService {
+	return newUserService(db, repository.NewUserRepository(db), repository.NewTwoFactorRepository(db), cfg)
+}
+
+func newUserService(db *sql.DB, uRepository port.UserRepository, tfRepository port.TwoFactorRepository, cfg config.Config) service.UserService {
+	return service.NewUserService(
+		uRepository,
+		service.WithRestoreGracePeriod(cfg.RestoreGracePeriod),
+		service.WithPurgeRetention(cfg.PurgeRetention),
+		service.WithTwoFactor(tfRepository),
+		service.WithSessions(repository.NewSessionRepository(db)),
+		service.WithLoginHistory(repository.NewLoginHistoryRepository(db), cfg.LoginHistoryRetention),
+		service.WithIssuer(cfg.OIDCIssuer),
+		service.WithPasswordHistory(cfg.PasswordHistoryDepth),
+		service.WithPasswordExpiry(cfg.PasswordExpiry),
+	)
+}
+
+// NewAPIKeyService builds the API key service used by the cli to hand out keys to internal jobs
+func NewAPIKeyService(db *sql.DB) service.APIKeyService {
+	return service.NewAPIKeyService(repository.NewAPIKeyRepository(db), repository.NewUserRepository(db))
+}
+
+// NewTwoFactorService builds the two-factor service, it fails when the encryption key is invalid
+func NewTwoFactorService(db *sql.DB, cfg config.Config) (service.TwoFactorService, error) {
+	uRepository := repository.NewUserRepository(db)
+	tfRepository := repository.NewTwoFactorRepository(db)
+
+	return newTwoFactorService(uRepository, tfRepository, newUserService(db, uRepository, tfRepository, cfg), cfg)
+}
+
+func newTwoFactorService(
+	uRepository port.UserRepository,
+	tfRepository port.TwoFactorRepository,
+	uService service.UserService,
+	cfg config.Config,
+) (service.TwoFactorService, error) {
+	if len(cfg.TOTPEncryptionKey) == 0 {
+		return nil, errors.New("TOTP_ENCRYPTION_KEY must be set to the base64 of a 32 byte key")
+	}
+
+	secretCipher, err := cipher.NewAESGCMCipher(cfg.TOTPEncryptionKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return service.NewTwoFactorService(tfRepository, uRepository, uService, secretCipher, cfg.TOTPIssuer), nil
+}
+
+// NewKeyRing builds the key ring that signs the tokens and loads its keys, PEM files take
+// precedence over the keys generated and rotated in the database
+func NewKeyRing(db *sql.DB, cfg config.Config) (service.KeyRing, error) {
+	if len(cfg.SigningKeyFiles) > 0 {
+		keys := []crypto.Signer{}
+
+		for _, path := range cfg.SigningKeyFiles {
+			data, err := os.ReadFile(path)
+
+			if err != nil {
+				return nil, err
+			}
+
+			key, err := domain.ParseSigningKeyPEM(data)
+
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+
+			keys = append(keys, key)
+		}
+
+		return service.NewStaticKeyRing(keys...)
+	}
+
+	// the default key ring signs with the shared secret
+	if cfg.SigningAlgorithm == domain.SigningHS256 {
+		return service.CurrentKeyRing(), nil
+	}
+
+	if len(cfg.SigningKeyEncryptionKey) == 0 {
+		return nil, errors.New("JWT_KEY_ENCRYPTION_KEY must be set to the base64 of a 32 byte key")
+	}
+
+	keyCipher, err := cipher.NewAESGCMCipher(cfg.SigningKeyEncryptionKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	keyRing, err := service.NewKeyRing(repository.NewSigningKeyRepository(db), keyCipher, cfg.SigningAlgorithm, cfg.KeyRotation)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return keyRing, keyRing.Rotate(time.Now())
+}
+
+// AdminGroup is the group of the /admin routes, only admins signed in with a token get past it,
+// the API keys of an admin are refused since their scopes don't cover the admin actions
+func AdminGroup(router gin.IRouter, authenticate gin.HandlerFunc) *gin.RouterGroup {
+	return router.Group(
+		"/admin",
+		authenticate,
+		middleware.RequireRole(domain.RoleAdmin),
+		middleware.RequireUserToken("API keys can't use the admin routes"),
+	)
+}
+
+// Run migrates the database, starts the background jobs and serves the api until the router stops
+func Run(db *sql.DB, cfg config.Config, addr ...string) error {
+	domain.SetPasswordPolicy(cfg.PasswordPolicy)
+	domain.SetPasswordHasher(cfg.PasswordHasher)
+
+	if _, err := repository.NewMigrator(db).Up(); err != nil {
+		return err
+	}
+
+	keyRing, err := NewKeyRing(db, cfg)
+
+	if err != nil {
+		return err
+	}
+
+	service.SetKeyRing(keyRing)
+
+	go func() {
+		for range time.Tick(time.Minute) {
+			if err := keyRing.Rotate(time.Now()); err != nil {
+				fmt.Println(err)
+			}
+		}
+	}()
+
+	router := gin.Default()
+
+	iRepository := repository.NewIdempotencyRepository(db)
+
+	// only on the routes creating resources, the responses with credentials must not be stored
+	idempotent := middleware.Idempotency(iRepository, cfg.IdempotencyKeyTTL, cfg.IdempotencyWait)
+
+	go func() {
+		for range time.Tick(time.Hour) {
+			if _, err := iRepository.DeleteExpired(); err != nil {
+				fmt.Println(err)
+			}
+		}
+	}()
+
+	// db e route user
+	// the cache only lives in the server process, the cli always reads from the database
+	var uRepository port.UserRepository = repository.NewUserRepository(db)
+	var uCache cache.UserRepository
+
+	if cfg.UserCacheSize > 0 {
+		uCache = cache.NewUserRepository(uRepository, cache.NewLRU(cfg.UserCacheSize, cfg.UserCacheTTL))
+		uRepository = uCache
+	}
+
+	tfRepository := repository.NewTwoFactorRepository(db)
+
+	uService := newUserService(db, uRepository, tfRepository, cfg)
+
+	tfService, err := newTwoFactorService(uRepository, tfRepository, uService, cfg)
+
+	if err != nil {
+		return err
+	}
+
+	purgeJob := service.NewPurgeJob(uService, cfg.PurgeInterval, cfg.PurgeDryRun)
+	purgeJob.Start()
+	defer purgeJob.Stop()
+
+	go func() {
+		for range time.Tick(time.Hour) {
+			if _, err := uService.PurgeLoginHistory(); err != nil {
+				fmt.Println(err)
+			}
+		}
+	}()
+
+	wService := service.NewWebhookService(repository.NewWebhookRepository(db), webhook.NewHTTPSender(cfg.WebhookTimeout))
+
+	dispatcher := service.NewWebhookDispatcher(wService, cfg.WebhookDispatchInterval)
+	dispatcher.Start()
+	defer dispatcher.Stop()
+
+	relay := service.NewOutboxRelay(
+		repository.NewOutboxRepository(db),
+		publisher.NewMultiPublisher(publisher.NewLogPublisher(os.Stdout), wService),
+		cfg.OutboxRelayInterval,
+	)
+	relay.Start()
+	defer relay.Stop()
+
+	uController := controller.NewUserController(uService)
+
+	router.POST("/user", idempotent, uController.Create)
+	router.GET("/user", uController.List)
+	router.DELETE("/user", uController.Delete)
+	router.PUT("/user", uController.Update)
+	router.PATCH("/user/update-password", uController.UpdatePassword)
+	router.POST("/user/login", uController.Login)
+	router.POST("/user/login/password", uController.ChangeExpiredPassword)
+
+	tfController := controller.NewTwoFactorController(tfService)
+
+	router.POST("/user/login/2fa", tfController.Login)
+
+	akService := service.NewAPIKeyService(repository.NewAPIKeyRepository(db), uRepository)
+
+	// routes under /v1 take a user token or an API key
+	authenticate := middleware.Authenticate(uService, akService)
+
+	v1 := router.Group("/v1", authenticate)
+	v1.GET("/users/search", middleware.RequireRole(domain.RoleAdmin), middleware.RequireScope(domain.ScopeUsersRead), uController.Search)
+
+	sController := controller.NewSessionController(uService)
+
+	v1.GET("/users/:id/logins", middleware.RequireRole(domain.RoleAdmin), middleware.RequireScope(domain.ScopeUsersRead), sController.ListLogins)
+	akController := controller.NewAPIKeyController(akService)
+
+	me := v1.Group("/me")
+	me.GET("", middleware.RequireScope(domain.ScopeUsersRead), uController.Me)
+	me.PATCH("", middleware.RequireScope(domain.ScopeUsersWrite), uController.UpdateMe)
+	me.DELETE("", uController.DeactivateMe)
+	me.POST("/password", uController.ChangeMyPassword)
+	me.GET("/sessions", middleware.RequireScope(domain.ScopeSessions), sController.ListMine)
+	me.DELETE("/sessions/:id", middleware.RequireScope(domain.ScopeSessions), sController.RevokeMine)
+	me.GET("/logins", middleware.RequireScope(domain.ScopeSessions), sController.ListMyLogins)
+	me.POST("/2fa/enroll", tfController.Enroll)
+	me.POST("/2fa/confirm", tfController.Confirm)
+	me.POST("/api-keys", akController.CreateMine)
+	me.GET("/api-keys", akController.ListMine)
+	me.DELETE("/api-keys/:id", akController.RevokeMine)
+
+	oService := service.NewOAuthService(repository.NewOAuthRepository(db), uRepository, cfg.OIDCIssuer)
+	oController := controller.NewOAuthController(oService, uService, tfService, akService)
+
+	router.GET("/oauth/authorize", oController.AuthorizePage)
+	router.POST("/oauth/authorize", oController.Authorize)
+	router.POST("/oauth/token", oController.Token)
+	router.POST("/oauth/revoke", oController.Revoke)
+	router.POST("/oauth/introspect", oController.Introspect)
+
+	oidcController := controller.NewOIDCController(cfg.OIDCIssuer, oService, keyRing)
+
+	router.GET("/.well-known/openid-configuration", oidcController.Discovery)
+	router.GET("/.well-known/jwks.json", oidcController.JWKS)
+	router.GET("/userinfo", oidcController.UserInfo)
+	router.POST("/userinfo", oidcController.UserInfo)
+
+	admin := AdminGroup(router, authenticate)
+	admin.GET("/user/deleted", uController.ListDeleted)
+	admin.GET("/user/status", uController.ListByStatus)
+	admin.PATCH("/user/restore", uController.Restore)
+	admin.DELETE("/user/purge", uController.Purge)
+	admin.PATCH("/user/password", uController.ResetPassword)
+	admin.PATCH("/user/password/expire", uController.RequirePasswordChange)
+	admin.PATCH("/user/role", uController.SetRole)
+	admin.PATCH("/user/suspend", uController.Suspend)
+	admin.PATCH("/user/reactivate", uController.Reactivate)
+	admin.POST("/user/import", idempotent, uController.Import)
+	admin.GET("/user/export", uController.Export)
+	admin.DELETE("/user/2fa", tfController.Reset)
+	admin.GET("/user/sessions", sController.List)
+	admin.DELETE("/user/sessions", sController.Revoke)
+	admin.GET("/user/api-keys", akController.List)
+	admin.DELETE("/user/api-keys", akController.Revoke)
+	admin.POST("/oauth/client", oController.CreateClient)
+	admin.GET("/oauth/client", oController.ListClients)
+	admin.DELETE("/oauth/client", oController.DeleteClient)
+
+	if uCache != nil {
+		admin.GET("/cache/stats", controller.NewCacheController(uCache).Stats)
+	}
+
+	wController := controller.NewWebhookController(wService)
+
+	admin.POST("/webhook", wController.Create)
+	admin.GET("/webhook", wController.List)
+	admin.PUT("/webhook", wController.Update)
+	admin.DELETE("/webhook", wController.Delete)
+	admin.GET("/webhook/deliveries", wController.ListDeliveries)
+	admin.POST("/webhook/redeliver", idempotent, wController.Redeliver)
+
+	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
+
+	return router.Run(addr...)
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+	"github.com/google/uuid"
+)
+
+// like sessions, the last use of a key is only written once per interval
+const apiKeyTouchInterval = time.Minute
+
+func NewAPIKeyService(repository port.APIKeyRepository, users port.UserRepository) APIKeyService {
+	return &apiKeyService{
+		repository: repository,
+		users: users,
+	}
+}
+
+type APIKeyService interface {
+	Create(domain.APIKey) (domain.APIKey, string, error)
+	List(uuid.UUID) ([]domain.APIKey, error)
+	Revoke(uuid.UUID, uuid.UUID) error
+	Authenticate(string) (domain.Identity, error)
+}
+
+type apiKeyService struct {
+	repository port.APIKeyRepository
+	users port.UserRepository
+}
+
+// Create returns the key itself only here, afterwards just its prefix is known
+func (service *apiKeyService) Create(dto domain.APIKey) (domain.APIKey, string, error) {
+	if err := domain.ValidateScopes(dto.Scopes); err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	now := time.Now()
+
+	if !dto.ExpiresAt.IsZero() && !dto.ExpiresAt.After(now) {
+		return domain.APIKey{}, "", errors.New("Expiration must be in the future")
+	}
+
+	if _, err := service.users.List(dto.UserId); err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	secret, prefix, err := domain.GenerateAPIKey()
+
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	key := domain.APIKey{
+		Id: uuid.New(),
+		UserId: dto.UserId,
+		Name: dto.Name,
+		Prefix: prefix,
+		Hash: domain.HashAPIKey(secret),
+		Scopes: dto.Scopes,
+		CreatedAt: now,
+		ExpiresAt: dto.ExpiresAt,
+	}
+
+	if err := service.repository.CreateAPIKey(key); err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	return key, secret, nil
+}
+
+func (service *apiKeyService) List(userId uuid.UUID) ([]domain.APIKey, error) {
+	if _, err := service.users.List(userId); err != nil {
+		return []domain.APIKey{}, err
+	}
+
+	return service.repository.ListAPIKeys(userId)
+}
+
+func (service *apiKeyService) Revoke(userId uuid.UUID, id uuid.UUID) error {
+	return service.repository.RevokeAPIKey(userId, id)
+}
+
+// Authenticate resolves the owner of the key, keys of deleted or suspended users stop working with them
+func (service *apiKeyService) Authenticate(secret string) (domain.Identity, error) {
+	prefix, ok := domain.ParseAPIKeyPrefix(secret)
+
+	if !ok {
+		return domain.Identity{}, errors.New("Invalid API key")
+	}
+
+	key, err := service.repository.FindAPIKeyByPrefix(prefix)
+
+	if err != nil && err.Error() != "sql: no rows in result set" {
+		return domain.Identity{}, err
+	}
+
+	now := time.Now()
+
+	if err != nil || subtle.ConstantTimeCompare([]byte(key.Hash), []byte(domain.HashAPIKey(secret))) != 1 || !key.Active(now) {
+		return domain.Identity{}, errors.New("Invalid API key")
+	}
+
+	user, err := service.users.List(key.UserId)
+
+	if err != nil && err.Error() != "sql: no rows in result set" {
+		return domain.Identity{}, err
+	}
+
+	if err != nil {
+		return domain.Identity{}, errors.New("Invalid API key")
+	}
+
+	if err := user.Status.CanLogin(now); err != nil {
+		return domain.Identity{}, err
+	}
+
+	// failing to record the last use doesn't make the key invalid
+	if now.Sub(key.LastUsedAt) > apiKeyTouchInterval {
+		service.repository.TouchAPIKey(key.Id, now)
+	}
+
+	return domain.Identity{
+		UserId: user.Id,
+		Email: user.Email,
+		Role: user.Role,
+		APIKeyId: key.Id,
+		Scopes: key.Scopes,
+	}, nil
+}
@@ -0,0 +1,387 @@
+package service
+
+import (
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// a token with an unknown kid reloads the keys at most once per interval, so a key generated
+// by another instance is found without letting made up kids hit the database on every request
+const keyRingReloadInterval = 10 * time.Second
+
+// KeyRing signs and verifies every token issued by the services
+type KeyRing interface {
+	// Sign signs the claims with the current key and names it in the kid header
+	Sign(claims jwt.Claims) (string, error)
+	// Parse verifies the token with the key named by its kid header
+	Parse(token string, claims jwt.Claims, options ...jwt.ParserOption) error
+	Algorithms() []string
+	// PublicKeys returns the keys tokens can currently be verified with, for the JWKS
+	PublicKeys() []domain.JWK
+	// Rotate reloads the keys and generates the next one when the current one is about to retire
+	Rotate(now time.Time) error
+}
+
+var (
+	keyRingMutex sync.RWMutex
+	currentKeyRing KeyRing = NewHMACKeyRing(jwtSecret)
+)
+
+// SetKeyRing replaces the key ring used by every service, it defaults to the HS256 shared secret
+func SetKeyRing(keyRing KeyRing) {
+	keyRingMutex.Lock()
+	defer keyRingMutex.Unlock()
+
+	currentKeyRing = keyRing
+}
+
+func CurrentKeyRing() KeyRing {
+	keyRingMutex.RLock()
+	defer keyRingMutex.RUnlock()
+
+	return currentKeyRing
+}
+
+func signToken(claims jwt.Claims) (string, error) {
+	return CurrentKeyRing().Sign(claims)
+}
+
+func parseToken(token string, claims jwt.Claims, options ...jwt.ParserOption) error {
+	return CurrentKeyRing().Parse(token, claims, options...)
+}
+
+// NewHMACKeyRing signs with a shared secret, verifiers need the secret so it publishes no keys
+func NewHMACKeyRing(secret []byte) KeyRing {
+	return &hmacKeyRing{secret: secret}
+}
+
+type hmacKeyRing struct {
+	secret []byte
+}
+
+func (keyRing *hmacKeyRing) Sign(claims jwt.Claims) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(keyRing.secret)
+}
+
+func (keyRing *hmacKeyRing) Parse(token string, claims jwt.Claims, options ...jwt.ParserOption) error {
+	options = append([]jwt.ParserOption{jwt.WithValidMethods([]string{domain.SigningHS256})}, options...)
+
+	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (any, error) {
+		return keyRing.secret, nil
+	}, options...)
+
+	return err
+}
+
+func (keyRing *hmacKeyRing) Algorithms() []string {
+	return []string{domain.SigningHS256}
+}
+
+func (keyRing *hmacKeyRing) PublicKeys() []domain.JWK {
+	return []domain.JWK{}
+}
+
+func (keyRing *hmacKeyRing) Rotate(now time.Time) error {
+	return nil
+}
+
+// NewStaticKeyRing signs with the first key, the others only verify so tokens signed by a
+// previous key stay valid after it's replaced. Static keys are never rotated
+func NewStaticKeyRing(keys ...crypto.Signer) (KeyRing, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("Inform at least one signing key")
+	}
+
+	keyRing := &asymmetricKeyRing{}
+
+	for i, private := range keys {
+		key, err := domain.NewSigningKey(private)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if i > 0 {
+			key.RetiresAt = time.Unix(0, 0)
+		}
+
+		keyRing.keys = append(keyRing.keys, key)
+	}
+
+	return keyRing, nil
+}
+
+// NewKeyRing generates its keys and stores them encrypted so every instance signs with the same
+// key, Rotate must be called once before signing and then periodically
+func NewKeyRing(
+	repository port.SigningKeyRepository,
+	cipher port.SecretCipher,
+	algorithm string,
+	rotation domain.KeyRotation,
+) (KeyRing, error) {
+	if !slices.Contains(domain.AsymmetricSigningAlgorithms, algorithm) {
+		return nil, errors.New("Unsupported signing algorithm: " + algorithm)
+	}
+
+	if rotation.Interval <= 0 || rotation.Overlap < 0 {
+		return nil, errors.New("Invalid key rotation")
+	}
+
+	return &asymmetricKeyRing{
+		repository: repository,
+		cipher: cipher,
+		algorithm: algorithm,
+		rotation: rotation,
+	}, nil
+}
+
+type asymmetricKeyRing struct {
+	mutex sync.RWMutex
+	keys []domain.SigningKey
+	loadedAt time.Time
+	repository port.SigningKeyRepository
+	cipher port.SecretCipher
+	algorithm string
+	rotation domain.KeyRotation
+}
+
+func (keyRing *asymmetricKeyRing) Sign(claims jwt.Claims) (string, error) {
+	key, ok := keyRing.signingKey(time.Now())
+
+	if !ok {
+		return "", errors.New("No signing key available")
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.Id
+
+	return token.SignedString(key.Key)
+}
+
+func (keyRing *asymmetricKeyRing) Parse(token string, claims jwt.Claims, options ...jwt.ParserOption) error {
+	options = append([]jwt.ParserOption{jwt.WithValidMethods(domain.AsymmetricSigningAlgorithms)}, options...)
+
+	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		key, ok := keyRing.verificationKey(kid, time.Now())
+
+		if !ok {
+			return nil, errors.New("Unknown signing key")
+		}
+
+		if token.Method.Alg() != key.Algorithm {
+			return nil, errors.New("Unexpected signing algorithm")
+		}
+
+		return key.Key.Public(), nil
+	}, options...)
+
+	return err
+}
+
+func (keyRing *asymmetricKeyRing) Algorithms() []string {
+	keyRing.mutex.RLock()
+	defer keyRing.mutex.RUnlock()
+
+	algorithms := []string{}
+
+	if keyRing.algorithm != "" {
+		algorithms = append(algorithms, keyRing.algorithm)
+	}
+
+	for _, key := range keyRing.keys {
+		if !slices.Contains(algorithms, key.Algorithm) {
+			algorithms = append(algorithms, key.Algorithm)
+		}
+	}
+
+	return algorithms
+}
+
+func (keyRing *asymmetricKeyRing) PublicKeys() []domain.JWK {
+	keyRing.mutex.RLock()
+	defer keyRing.mutex.RUnlock()
+
+	now := time.Now()
+	keys := []domain.JWK{}
+
+	for _, key := range keyRing.keys {
+		if key.Verifies(now) {
+			keys = append(keys, key.JWK())
+		}
+	}
+
+	return keys
+}
+
+// Rotate publishes the next key an overlap before the current one retires, so verifiers caching
+// the JWKS know it before it signs, and the retired key is published for another overlap so the
+// tokens it signed can still be verified
+func (keyRing *asymmetricKeyRing) Rotate(now time.Time) error {
+	if keyRing.repository == nil {
+		return nil
+	}
+
+	if _, err := keyRing.repository.DeleteExpiredSigningKeys(now); err != nil {
+		return err
+	}
+
+	if err := keyRing.load(now); err != nil {
+		return err
+	}
+
+	keyRing.mutex.RLock()
+
+	latest := now
+
+	for _, key := range keyRing.keys {
+		if key.Algorithm == keyRing.algorithm && key.RetiresAt.After(latest) {
+			latest = key.RetiresAt
+		}
+	}
+
+	keyRing.mutex.RUnlock()
+
+	if latest.After(now.Add(keyRing.rotation.Overlap)) {
+		return nil
+	}
+
+	private, err := domain.GenerateSigningKey(keyRing.algorithm)
+
+	if err != nil {
+		return err
+	}
+
+	key, err := domain.NewSigningKey(private)
+
+	if err != nil {
+		return err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+
+	if err != nil {
+		return err
+	}
+
+	key.EncryptedKey, err = keyRing.cipher.Encrypt(der)
+
+	if err != nil {
+		return err
+	}
+
+	key.CreatedAt = now
+	key.RetiresAt = latest.Add(keyRing.rotation.Interval)
+	key.ExpiresAt = key.RetiresAt.Add(keyRing.rotation.Overlap)
+
+	if err := keyRing.repository.CreateSigningKey(key); err != nil {
+		return err
+	}
+
+	// reloading also picks the keys other instances generated at the same time
+	return keyRing.load(now)
+}
+
+func (keyRing *asymmetricKeyRing) load(now time.Time) error {
+	stored, err := keyRing.repository.ListSigningKeys(now)
+
+	if err != nil {
+		return err
+	}
+
+	keys := make([]domain.SigningKey, 0, len(stored))
+
+	for _, key := range stored {
+		der, err := keyRing.cipher.Decrypt(key.EncryptedKey)
+
+		if err != nil {
+			return errors.New("Can't decrypt the signing key " + key.Id)
+		}
+
+		private, err := x509.ParsePKCS8PrivateKey(der)
+
+		if err != nil {
+			return err
+		}
+
+		signer, ok := private.(crypto.Signer)
+
+		if !ok {
+			return errors.New("Unsupported signing key")
+		}
+
+		key.Key = signer
+		keys = append(keys, key)
+	}
+
+	keyRing.mutex.Lock()
+	defer keyRing.mutex.Unlock()
+
+	keyRing.keys = keys
+	keyRing.loadedAt = now
+
+	return nil
+}
+
+// signingKey is the key of the configured algorithm that retires first, a zero RetiresAt
+// retires last
+func (keyRing *asymmetricKeyRing) signingKey(now time.Time) (domain.SigningKey, bool) {
+	keyRing.mutex.RLock()
+	defer keyRing.mutex.RUnlock()
+
+	var signing domain.SigningKey
+	found := false
+
+	for _, key := range keyRing.keys {
+		if !key.Signs(now) || (keyRing.algorithm != "" && key.Algorithm != keyRing.algorithm) {
+			continue
+		}
+
+		if !found || (!key.RetiresAt.IsZero() && (signing.RetiresAt.IsZero() || key.RetiresAt.Before(signing.RetiresAt))) {
+			signing = key
+			found = true
+		}
+	}
+
+	return signing, found
+}
+
+func (keyRing *asymmetricKeyRing) verificationKey(kid string, now time.Time) (domain.SigningKey, bool) {
+	key, ok := keyRing.findKey(kid, now)
+
+	if ok || keyRing.repository == nil {
+		return key, ok
+	}
+
+	keyRing.mutex.RLock()
+	stale := now.Sub(keyRing.loadedAt) > keyRingReloadInterval
+	keyRing.mutex.RUnlock()
+
+	if !stale || keyRing.load(now) != nil {
+		return domain.SigningKey{}, false
+	}
+
+	return keyRing.findKey(kid, now)
+}
+
+func (keyRing *asymmetricKeyRing) findKey(kid string, now time.Time) (domain.SigningKey, bool) {
+	keyRing.mutex.RLock()
+	defer keyRing.mutex.RUnlock()
+
+	for _, key := range keyRing.keys {
+		if key.Id == kid && key.Verifies(now) {
+			return key, true
+		}
+	}
+
+	return domain.SigningKey{}, false
+}
@@ -0,0 +1,758 @@
+package service
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// codes are exchanged right after the redirect so they live a few minutes, access tokens are
+// short lived and refresh tokens are rotated on every use
+const (
+	AuthorizationCodeTTL = 10 * time.Minute
+	OAuthAccessTokenTTL = time.Hour
+	OAuthRefreshTokenTTL = 30 * 24 * time.Hour
+	IDTokenTTL = time.Hour
+)
+
+// DefaultOIDCIssuer is the public url of the api, it goes in the iss claim of the tokens
+const DefaultOIDCIssuer = "http://localhost:8080"
+
+func NewOAuthService(repository port.OAuthRepository, users port.UserRepository, issuer string) OAuthService {
+	return &oauthService{
+		repository: repository,
+		users: users,
+		issuer: issuer,
+	}
+}
+
+type OAuthService interface {
+	CreateClient(domain.OAuthClient, bool) (domain.OAuthClient, string, error)
+	ListClients() ([]domain.OAuthClient, error)
+	DeleteClient(string) error
+	ValidateAuthorization(domain.AuthorizationRequest) (domain.AuthorizationRequest, domain.OAuthClient, error)
+	Authorize(domain.AuthorizationRequest, uuid.UUID) (string, error)
+	Token(domain.TokenRequest) (domain.TokenResponse, error)
+	Revoke(string, string, string) error
+	UserInfo(string) (map[string]any, error)
+	AuthenticateClient(string, string) (domain.OAuthClient, error)
+	Introspect(string) (domain.TokenIntrospection, error)
+}
+
+type oauthService struct {
+	repository port.OAuthRepository
+	users port.UserRepository
+	issuer string
+}
+
+// oauthGrant is what a new pair of tokens is issued for, scope is the one of the access token
+// and grantScope the one kept by the refresh token
+type oauthGrant struct {
+	id uuid.UUID
+	userId uuid.UUID
+	scope string
+	grantScope string
+	nonce string
+	// when the code or refresh token exchanged for this grant was issued
+	issuedAt time.Time
+}
+
+// CreateClient registers a client, confidential clients get a secret that is only returned here
+func (service *oauthService) CreateClient(dto domain.OAuthClient, confidential bool) (domain.OAuthClient, string, error) {
+	if strings.TrimSpace(dto.Name) == "" {
+		return domain.OAuthClient{}, "", errors.New("Inform the client name")
+	}
+
+	grantTypes := dto.GrantTypes
+
+	if len(grantTypes) == 0 {
+		grantTypes = []string{domain.GrantAuthorizationCode, domain.GrantRefreshToken}
+	}
+
+	for _, grantType := range grantTypes {
+		if !slices.Contains(domain.OAuthGrantTypes, grantType) {
+			return domain.OAuthClient{}, "", errors.New("Invalid grant type: " + grantType)
+		}
+	}
+
+	if !confidential && slices.Contains(grantTypes, domain.GrantClientCredentials) {
+		return domain.OAuthClient{}, "", errors.New("Public clients can't use client_credentials")
+	}
+
+	if slices.Contains(grantTypes, domain.GrantAuthorizationCode) && len(dto.RedirectURIs) == 0 {
+		return domain.OAuthClient{}, "", errors.New("Inform at least one redirect uri")
+	}
+
+	for _, redirectURI := range dto.RedirectURIs {
+		if !validRedirectURI(redirectURI) {
+			return domain.OAuthClient{}, "", errors.New("Invalid redirect uri: " + redirectURI)
+		}
+	}
+
+	scopes := []string{}
+
+	for _, scope := range dto.Scopes {
+		if parsed, err := domain.ParseScope(scope); err != nil || len(parsed) != 1 {
+			return domain.OAuthClient{}, "", errors.New("Invalid scope: " + scope)
+		}
+
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	client := domain.OAuthClient{
+		Id: uuid.New().String(),
+		Name: dto.Name,
+		RedirectURIs: dto.RedirectURIs,
+		GrantTypes: grantTypes,
+		Scopes: scopes,
+		CreatedAt: time.Now(),
+	}
+
+	secret := ""
+
+	if confidential {
+		var err error
+
+		secret, err = domain.GenerateOAuthSecret()
+
+		if err != nil {
+			return domain.OAuthClient{}, "", err
+		}
+
+		client.SecretHash = domain.HashOAuthSecret(secret)
+	}
+
+	if err := service.repository.CreateClient(client); err != nil {
+		return domain.OAuthClient{}, "", err
+	}
+
+	return client, secret, nil
+}
+
+func (service *oauthService) ListClients() ([]domain.OAuthClient, error) {
+	return service.repository.ListClients()
+}
+
+func (service *oauthService) DeleteClient(id string) error {
+	return service.repository.DeleteClient(id)
+}
+
+// ValidateAuthorization checks the parameters of /oauth/authorize and returns them with the
+// redirect uri and the scope resolved. When the returned redirect uri is empty the error can't
+// be sent to the client and must be shown to the user instead
+func (service *oauthService) ValidateAuthorization(request domain.AuthorizationRequest) (domain.AuthorizationRequest, domain.OAuthClient, error) {
+	client, err := service.repository.FindClient(request.ClientId)
+
+	if err != nil && err.Error() != "sql: no rows in result set" {
+		return domain.AuthorizationRequest{}, domain.OAuthClient{}, err
+	}
+
+	if err != nil {
+		return domain.AuthorizationRequest{}, domain.OAuthClient{}, domain.NewOAuthError(domain.OAuthInvalidClient, "Unknown client")
+	}
+
+	// the redirect uri can be left out when the client registered a single one
+	switch {
+	case request.RedirectURI == "" && len(client.RedirectURIs) == 1:
+		request.RedirectURI = client.RedirectURIs[0]
+	case !slices.Contains(client.RedirectURIs, request.RedirectURI):
+		return domain.AuthorizationRequest{}, client, domain.NewOAuthError(domain.OAuthInvalidRequest, "Invalid redirect uri")
+	}
+
+	if request.ResponseType != "code" {
+		return request, client, domain.NewOAuthError(domain.OAuthUnsupportedResponseType, "Only the code response type is supported")
+	}
+
+	if !client.AllowsGrant(domain.GrantAuthorizationCode) {
+		return request, client, domain.NewOAuthError(domain.OAuthUnauthorizedClient, "The client can't use the authorization code grant")
+	}
+
+	if request.CodeChallenge == "" && !client.Confidential() {
+		return request, client, domain.NewOAuthError(domain.OAuthInvalidRequest, "Public clients must use PKCE")
+	}
+
+	if request.CodeChallenge != "" && request.CodeChallengeMethod != domain.PKCEMethodS256 {
+		return request, client, domain.NewOAuthError(domain.OAuthInvalidRequest, "Only the S256 code challenge method is supported")
+	}
+
+	scope, err := resolveScope(client.Scopes, request.Scope)
+
+	if err != nil {
+		return request, client, err
+	}
+
+	request.Scope = scope
+
+	return request, client, nil
+}
+
+// Authorize issues the code once the user approved the request
+func (service *oauthService) Authorize(request domain.AuthorizationRequest, userId uuid.UUID) (string, error) {
+	request, _, err := service.ValidateAuthorization(request)
+
+	if err != nil {
+		return "", err
+	}
+
+	code, err := domain.GenerateOAuthSecret()
+
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+
+	err = service.repository.CreateAuthorizationCode(domain.AuthorizationCode{
+		Hash: domain.HashOAuthSecret(code),
+		ClientId: request.ClientId,
+		UserId: userId,
+		RedirectURI: request.RedirectURI,
+		Scope: request.Scope,
+		CodeChallenge: request.CodeChallenge,
+		CodeChallengeMethod: request.CodeChallengeMethod,
+		Nonce: request.Nonce,
+		CreatedAt: now,
+		ExpiresAt: now.Add(AuthorizationCodeTTL),
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// Token implements the token endpoint for the authorization code, refresh token and
+// client credentials grants
+func (service *oauthService) Token(request domain.TokenRequest) (domain.TokenResponse, error) {
+	client, err := service.authenticateClient(request.ClientId, request.ClientSecret)
+
+	if err != nil {
+		return domain.TokenResponse{}, err
+	}
+
+	if request.GrantType == "" {
+		return domain.TokenResponse{}, domain.NewOAuthError(domain.OAuthInvalidRequest, "Inform the grant type")
+	}
+
+	if !slices.Contains(domain.OAuthGrantTypes, request.GrantType) {
+		return domain.TokenResponse{}, domain.NewOAuthError(domain.OAuthUnsupportedGrantType, "Unsupported grant type: " + request.GrantType)
+	}
+
+	if !client.AllowsGrant(request.GrantType) {
+		return domain.TokenResponse{}, domain.NewOAuthError(domain.OAuthUnauthorizedClient, "The client can't use the " + request.GrantType + " grant")
+	}
+
+	switch request.GrantType {
+	case domain.GrantAuthorizationCode:
+		return service.exchangeCode(client, request)
+	case domain.GrantRefreshToken:
+		return service.refresh(client, request)
+	default:
+		return service.clientCredentials(client, request)
+	}
+}
+
+// Revoke implements RFC 7009, revoking a refresh token revokes every token of the grant while
+// an access token is revoked alone. Unknown tokens and tokens of other clients are ignored
+func (service *oauthService) Revoke(clientId string, clientSecret string, token string) error {
+	client, err := service.authenticateClient(clientId, clientSecret)
+
+	if err != nil {
+		return err
+	}
+
+	stored, err := service.repository.FindTokenByHash(domain.HashOAuthSecret(token))
+
+	if err != nil && err.Error() != "sql: no rows in result set" {
+		return err
+	}
+
+	if err == nil {
+		if stored.ClientId != client.Id {
+			return nil
+		}
+
+		return service.repository.RevokeGrant(stored.GrantId)
+	}
+
+	tokenId, ok := service.parseAccessTokenId(token, false)
+
+	if !ok {
+		return nil
+	}
+
+	stored, err = service.repository.FindToken(tokenId)
+
+	if err != nil && err.Error() != "sql: no rows in result set" {
+		return err
+	}
+
+	if err != nil || stored.ClientId != client.Id {
+		return nil
+	}
+
+	return service.repository.RevokeToken(stored.Id)
+}
+
+func (service *oauthService) exchangeCode(client domain.OAuthClient, request domain.TokenRequest) (domain.TokenResponse, error) {
+	if request.Code == "" {
+		return domain.TokenResponse{}, domain.NewOAuthError(domain.OAuthInvalidRequest, "Inform the code")
+	}
+
+	code, err := service.repository.UseAuthorizationCode(domain.HashOAuthSecret(request.Code))
+
+	if err != nil && err.Error() != "sql: no rows in result set" {
+		return domain.TokenResponse{}, err
+	}
+
+	invalidCode := domain.NewOAuthError(domain.OAuthInvalidGrant, "Invalid authorization code")
+
+	if err != nil || code.ClientId != client.Id || !time.Now().Before(code.ExpiresAt) {
+		return domain.TokenResponse{}, invalidCode
+	}
+
+	if request.RedirectURI != "" && request.RedirectURI != code.RedirectURI {
+		return domain.TokenResponse{}, invalidCode
+	}
+
+	if code.CodeChallenge != "" && !domain.VerifyPKCE(code.CodeChallenge, request.CodeVerifier) {
+		return domain.TokenResponse{}, domain.NewOAuthError(domain.OAuthInvalidGrant, "Invalid code verifier")
+	}
+
+	return service.issue(client, oauthGrant{id: uuid.New(), userId: code.UserId, scope: code.Scope, grantScope: code.Scope, nonce: code.Nonce, issuedAt: code.CreatedAt})
+}
+
+// refresh rotates the refresh token, presenting one that was already rotated means it leaked
+// so the whole grant is revoked
+func (service *oauthService) refresh(client domain.OAuthClient, request domain.TokenRequest) (domain.TokenResponse, error) {
+	if request.RefreshToken == "" {
+		return domain.TokenResponse{}, domain.NewOAuthError(domain.OAuthInvalidRequest, "Inform the refresh token")
+	}
+
+	stored, err := service.repository.FindTokenByHash(domain.HashOAuthSecret(request.RefreshToken))
+
+	if err != nil && err.Error() != "sql: no rows in result set" {
+		return domain.TokenResponse{}, err
+	}
+
+	invalidToken := domain.NewOAuthError(domain.OAuthInvalidGrant, "Invalid refresh token")
+
+	if err != nil || stored.Type != domain.OAuthRefreshToken || stored.ClientId != client.Id {
+		return domain.TokenResponse{}, invalidToken
+	}
+
+	if !stored.RevokedAt.IsZero() {
+		if err := service.repository.RevokeGrant(stored.GrantId); err != nil {
+			return domain.TokenResponse{}, err
+		}
+
+		return domain.TokenResponse{}, invalidToken
+	}
+
+	if !stored.Active(time.Now()) {
+		return domain.TokenResponse{}, invalidToken
+	}
+
+	// the access token may be narrowed down, the new refresh token keeps the scope of the grant
+	scope := stored.Scope
+
+	if request.Scope != "" {
+		scope, err = resolveScope(strings.Fields(stored.Scope), request.Scope)
+
+		if err != nil {
+			return domain.TokenResponse{}, err
+		}
+	}
+
+	if err := service.repository.RevokeToken(stored.Id); err != nil {
+		return domain.TokenResponse{}, err
+	}
+
+	return service.issue(client, oauthGrant{id: stored.GrantId, userId: stored.UserId, scope: scope, grantScope: stored.Scope, issuedAt: stored.CreatedAt})
+}
+
+func (service *oauthService) clientCredentials(client domain.OAuthClient, request domain.TokenRequest) (domain.TokenResponse, error) {
+	if !client.Confidential() {
+		return domain.TokenResponse{}, domain.NewOAuthError(domain.OAuthUnauthorizedClient, "Public clients can't use client_credentials")
+	}
+
+	scope, err := resolveScope(client.Scopes, request.Scope)
+
+	if err != nil {
+		return domain.TokenResponse{}, err
+	}
+
+	return service.issue(client, oauthGrant{id: uuid.New(), scope: scope})
+}
+
+// issue signs the access token and, when the client may refresh and the grant has a user, a
+// new refresh token. Both are stored so they can be revoked. Grants of a user with the openid
+// scope also get an ID token
+func (service *oauthService) issue(client domain.OAuthClient, grant oauthGrant) (domain.TokenResponse, error) {
+	subject := client.Id
+
+	var user domain.UserDomain
+
+	if grant.userId != uuid.Nil {
+		var err error
+
+		// the user may have been deleted since the authorization
+		user, err = service.users.List(grant.userId)
+
+		if err != nil {
+			if err.Error() == "sql: no rows in result set" {
+				return domain.TokenResponse{}, domain.NewOAuthError(domain.OAuthInvalidGrant, "The user no longer exists")
+			}
+
+			return domain.TokenResponse{}, err
+		}
+
+		// the grant outlives a suspension but no token is issued while the account is inactive
+		if err := user.Status.CanLogin(time.Now()); err != nil {
+			return domain.TokenResponse{}, domain.NewOAuthError(domain.OAuthInvalidGrant, err.Error())
+		}
+
+		// changing the password signs the user out of the applications too
+		if user.IssuedBeforePasswordChange(grant.issuedAt) {
+			return domain.TokenResponse{}, domain.NewOAuthError(domain.OAuthInvalidGrant, "The password changed since the authorization")
+		}
+
+		subject = grant.userId.String()
+	}
+
+	now := time.Now()
+
+	accessToken := domain.OAuthToken{
+		Id: uuid.New(),
+		GrantId: grant.id,
+		Type: domain.OAuthAccessToken,
+		ClientId: client.Id,
+		UserId: grant.userId,
+		Scope: grant.scope,
+		CreatedAt: now,
+		ExpiresAt: now.Add(OAuthAccessTokenTTL),
+	}
+
+	signed, err := service.signAccessToken(accessToken, subject)
+
+	if err != nil {
+		return domain.TokenResponse{}, err
+	}
+
+	if err := service.repository.CreateToken(accessToken); err != nil {
+		return domain.TokenResponse{}, err
+	}
+
+	response := domain.TokenResponse{
+		AccessToken: signed,
+		TokenType: "Bearer",
+		ExpiresIn: int(OAuthAccessTokenTTL.Seconds()),
+		Scope: grant.scope,
+	}
+
+	if grant.userId == uuid.Nil {
+		return response, nil
+	}
+
+	scopes := strings.Fields(grant.scope)
+
+	if slices.Contains(scopes, domain.ScopeOpenID) {
+		response.IDToken, err = service.signIDToken(user, client.Id, scopes, grant.nonce, now)
+
+		if err != nil {
+			return domain.TokenResponse{}, err
+		}
+	}
+
+	if !client.AllowsGrant(domain.GrantRefreshToken) {
+		return response, nil
+	}
+
+	refreshToken, err := domain.GenerateOAuthSecret()
+
+	if err != nil {
+		return domain.TokenResponse{}, err
+	}
+
+	err = service.repository.CreateToken(domain.OAuthToken{
+		Id: uuid.New(),
+		GrantId: grant.id,
+		Type: domain.OAuthRefreshToken,
+		Hash: domain.HashOAuthSecret(refreshToken),
+		ClientId: client.Id,
+		UserId: grant.userId,
+		Scope: grant.grantScope,
+		CreatedAt: now,
+		ExpiresAt: now.Add(OAuthRefreshTokenTTL),
+	})
+
+	if err != nil {
+		return domain.TokenResponse{}, err
+	}
+
+	response.RefreshToken = refreshToken
+
+	return response, nil
+}
+
+// UserInfo answers the userinfo endpoint of OpenID Connect, the access token must belong to an
+// active user, be issued after his last password change, still be active and have the openid scope
+func (service *oauthService) UserInfo(accessToken string) (map[string]any, error) {
+	tokenId, ok := service.parseAccessTokenId(accessToken, true)
+
+	if !ok {
+		return nil, errors.New("Invalid token")
+	}
+
+	stored, err := service.repository.FindToken(tokenId)
+
+	if err != nil && err.Error() != "sql: no rows in result set" {
+		return nil, err
+	}
+
+	if err != nil || stored.Type != domain.OAuthAccessToken || stored.UserId == uuid.Nil || !stored.Active(time.Now()) {
+		return nil, errors.New("Invalid token")
+	}
+
+	scopes := strings.Fields(stored.Scope)
+
+	if !slices.Contains(scopes, domain.ScopeOpenID) {
+		return nil, errors.New("Insufficient scope")
+	}
+
+	user, err := service.users.List(stored.UserId)
+
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return nil, errors.New("Invalid token")
+		}
+
+		return nil, err
+	}
+
+	if user.Status.CanLogin(time.Now()) != nil || user.IssuedBeforePasswordChange(stored.CreatedAt) {
+		return nil, errors.New("Invalid token")
+	}
+
+	return domain.OIDCClaims(user, scopes), nil
+}
+
+// AuthenticateClient only accepts confidential clients, it protects the endpoints meant for
+// resource servers where a public client id would prove nothing
+func (service *oauthService) AuthenticateClient(clientId string, clientSecret string) (domain.OAuthClient, error) {
+	client, err := service.authenticateClient(clientId, clientSecret)
+
+	if err != nil {
+		return domain.OAuthClient{}, err
+	}
+
+	if !client.Confidential() {
+		return domain.OAuthClient{}, domain.NewOAuthError(domain.OAuthInvalidClient, "Client authentication failed")
+	}
+
+	return client, nil
+}
+
+// Introspect describes an access or refresh token of this server (RFC 7662), tokens that were
+// revoked, expired, issued before a password change or whose user was deleted or isn't active
+// are only reported as inactive
+func (service *oauthService) Introspect(token string) (domain.TokenIntrospection, error) {
+	inactive := domain.TokenIntrospection{}
+
+	stored, err := service.repository.FindTokenByHash(domain.HashOAuthSecret(token))
+
+	if err != nil && err.Error() != "sql: no rows in result set" {
+		return inactive, err
+	}
+
+	if err != nil {
+		tokenId, ok := service.parseAccessTokenId(token, true)
+
+		if !ok {
+			return inactive, nil
+		}
+
+		stored, err = service.repository.FindToken(tokenId)
+
+		if err != nil && err.Error() != "sql: no rows in result set" {
+			return inactive, err
+		}
+
+		if err != nil || stored.Type != domain.OAuthAccessToken {
+			return inactive, nil
+		}
+	}
+
+	if !stored.Active(time.Now()) {
+		return inactive, nil
+	}
+
+	introspection := domain.TokenIntrospection{
+		Active: true,
+		TokenType: stored.Type,
+		Scope: stored.Scope,
+		ClientId: stored.ClientId,
+		Subject: stored.ClientId,
+		Issuer: service.issuer,
+		IssuedAt: stored.CreatedAt,
+		ExpiresAt: stored.ExpiresAt,
+	}
+
+	// refresh tokens are opaque, only the access tokens carry the jti
+	if stored.Type == domain.OAuthAccessToken {
+		introspection.TokenId = stored.Id.String()
+	}
+
+	if stored.UserId == uuid.Nil {
+		return introspection, nil
+	}
+
+	user, err := service.users.List(stored.UserId)
+
+	if err != nil && err.Error() != "sql: no rows in result set" {
+		return inactive, err
+	}
+
+	if err != nil || user.Status.CanLogin(time.Now()) != nil || user.IssuedBeforePasswordChange(stored.CreatedAt) {
+		return inactive, nil
+	}
+
+	introspection.Subject = user.Id.String()
+	introspection.Username = user.Email
+
+	return introspection, nil
+}
+
+// authenticateClient checks the secret of confidential clients, public clients only identify themselves
+func (service *oauthService) authenticateClient(clientId string, clientSecret string) (domain.OAuthClient, error) {
+	invalidClient := domain.NewOAuthError(domain.OAuthInvalidClient, "Client authentication failed")
+
+	if clientId == "" {
+		return domain.OAuthClient{}, invalidClient
+	}
+
+	client, err := service.repository.FindClient(clientId)
+
+	if err != nil && err.Error() != "sql: no rows in result set" {
+		return domain.OAuthClient{}, err
+	}
+
+	if err != nil {
+		return domain.OAuthClient{}, invalidClient
+	}
+
+	if client.Confidential() && subtle.ConstantTimeCompare([]byte(domain.HashOAuthSecret(clientSecret)), []byte(client.SecretHash)) != 1 {
+		return domain.OAuthClient{}, invalidClient
+	}
+
+	return client, nil
+}
+
+// resolveScope defaults to every allowed scope and fails when something else is requested
+func resolveScope(allowed []string, requested string) (string, error) {
+	scopes, err := domain.ParseScope(requested)
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(scopes) == 0 {
+		return strings.Join(allowed, " "), nil
+	}
+
+	if !(domain.OAuthClient{Scopes: allowed}).AllowsScopes(scopes) {
+		return "", domain.NewOAuthError(domain.OAuthInvalidScope, "The requested scope is not allowed")
+	}
+
+	return strings.Join(scopes, " "), nil
+}
+
+// validRedirectURI accepts absolute uris without fragment, custom schemes are allowed for native apps
+func validRedirectURI(redirectURI string) bool {
+	parsed, err := url.Parse(redirectURI)
+
+	if err != nil || !parsed.IsAbs() || parsed.Fragment != "" {
+		return false
+	}
+
+	if parsed.Scheme == "http" || parsed.Scheme == "https" {
+		return parsed.Host != ""
+	}
+
+	return true
+}
+
+// signAccessToken has no id claim, so the /v1 routes that expect a user token refuse it,
+// access tokens are meant for the clients and the services they call
+func (service *oauthService) signAccessToken(token domain.OAuthToken, subject string) (string, error) {
+	claims := jwt.MapClaims{
+		"iss": service.issuer,
+		"sub": subject,
+		"client_id": token.ClientId,
+		"scope": token.Scope,
+		"jti": token.Id.String(),
+		"iat": token.CreatedAt.Unix(),
+		"exp": token.ExpiresAt.Unix(),
+	}
+
+	return signToken(claims)
+}
+
+// signIDToken issues the ID token of OpenID Connect Core section 2 with the claims released by the scopes
+func (service *oauthService) signIDToken(user domain.UserDomain, clientId string, scopes []string, nonce string, now time.Time) (string, error) {
+	claims := jwt.MapClaims{}
+
+	for name, value := range domain.OIDCClaims(user, scopes) {
+		claims[name] = value
+	}
+
+	claims["iss"] = service.issuer
+	claims["aud"] = clientId
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(IDTokenTTL).Unix()
+
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+
+	return signToken(claims)
+}
+
+// parseAccessTokenId returns the jti of an access token of this server, revoking expired
+// tokens is harmless so the expiration is only checked when asked
+func (service *oauthService) parseAccessTokenId(token string, validate bool) (uuid.UUID, bool) {
+	claims := jwt.MapClaims{}
+
+	options := []jwt.ParserOption{jwt.WithoutClaimsValidation()}
+
+	if validate {
+		options = []jwt.ParserOption{jwt.WithExpirationRequired(), jwt.WithIssuer(service.issuer)}
+	}
+
+	err := parseToken(token, claims, options...)
+
+	if err != nil {
+		return uuid.Nil, false
+	}
+
+	if _, ok := claims["client_id"]; !ok {
+		return uuid.Nil, false
+	}
+
+	jti, _ := claims["jti"].(string)
+
+	tokenId, err := uuid.Parse(jti)
+
+	return tokenId, err == nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultRelayBatchSize = 100
+	DefaultRelayMaxAttempts = 10
+	DefaultRelayLease = time.Minute
+)
+
+func NewOutboxRelay(repository port.OutboxRepository, publisher port.EventPublisher, interval time.Duration) *OutboxRelay {
+	return &OutboxRelay{
+		repository: repository,
+		publisher: publisher,
+		interval: interval,
+		BatchSize: DefaultRelayBatchSize,
+		MaxAttempts: DefaultRelayMaxAttempts,
+		BaseBackoff: time.Second,
+		MaxBackoff: time.Hour,
+		Lease: DefaultRelayLease,
+		stop: make(chan struct{}),
+	}
+}
+
+// OutboxRelay publishes the outbox messages at least once. Messages of the same user are
+// published in the order they were written: while one of them waits for a retry the
+// following ones are held back. After MaxAttempts a message is marked dead so it stops
+// blocking the user. Every relay claims the messages it publishes for Lease, so several
+// instances can run and a message claimed by a relay that stopped is published again
+// once the lease ends.
+type OutboxRelay struct {
+	repository port.OutboxRepository
+	publisher port.EventPublisher
+	interval time.Duration
+	BatchSize int
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff time.Duration
+	// Lease must be longer than publishing a batch takes, or another relay publishes it too
+	Lease time.Duration
+	stop chan struct{}
+}
+
+func (relay *OutboxRelay) Start() {
+	go func() {
+		ticker := time.NewTicker(relay.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := relay.RunOnce(); err != nil {
+					fmt.Println("outbox relay:", err)
+				}
+			case <-relay.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (relay *OutboxRelay) Stop() {
+	close(relay.stop)
+}
+
+// RunOnce publishes the pending messages that are due and returns how many were published
+func (relay *OutboxRelay) RunOnce() (int, error) {
+	now := time.Now()
+
+	messages, err := relay.repository.ClaimPending(relay.BatchSize, now, relay.Lease)
+
+	if err != nil {
+		return 0, err
+	}
+
+	blocked := map[uuid.UUID]bool{}
+	published := 0
+
+	for _, message := range messages {
+		if blocked[message.UserId] {
+			continue
+		}
+
+		if !message.NextAttemptAt.IsZero() && message.NextAttemptAt.After(now) {
+			blocked[message.UserId] = true
+
+			continue
+		}
+
+		if err := relay.publisher.Publish(message); err != nil {
+			blocked[message.UserId] = true
+
+			if message.Attempts + 1 >= relay.MaxAttempts {
+				err = relay.repository.MarkDead(message.Id, err.Error())
+			} else {
+				err = relay.repository.MarkFailed(message.Id, err.Error(), now.Add(relay.backoff(message.Attempts)))
+			}
+
+			if err != nil {
+				return published, err
+			}
+
+			continue
+		}
+
+		if err := relay.repository.MarkPublished(message.Id); err != nil {
+			return published, err
+		}
+
+		published++
+	}
+
+	return published, nil
+}
+
+// backoff doubles the wait after every failed attempt
+func (relay *OutboxRelay) backoff(attempts int) time.Duration {
+	wait := relay.BaseBackoff
+
+	for i := 0; i < attempts && wait < relay.MaxBackoff; i++ {
+		wait *= 2
+	}
+
+	if wait > relay.MaxBackoff {
+		return relay.MaxBackoff
+	}
+
+	return wait
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"fmt"
+	"time"
+)
+
+func NewPurgeJob(service UserService, interval time.Duration, dryRun bool) *PurgeJob {
+	return &PurgeJob{
+		service: service,
+		interval: interval,
+		dryRun: dryRun,
+		stop: make(chan struct{}),
+	}
+}
+
+// PurgeJob periodically removes the users that stayed soft deleted for longer than the retention window
+type PurgeJob struct {
+	service UserService
+	interval time.Duration
+	dryRun bool
+	stop chan struct{}
+}
+
+func (job *PurgeJob) Start() {
+	go func() {
+		ticker := time.NewTicker(job.interval)
+		defer ticker.Stop()
+
+		job.Run()
+
+		for {
+			select {
+			case <-ticker.C:
+				job.Run()
+			case <-job.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (job *PurgeJob) Stop() {
+	close(job.stop)
+}
+
+// Run executes a single purge and reports which users were (or would be, on dry run) removed
+func (job *PurgeJob) Run() {
+	users, err := job.service.Purge(job.dryRun)
+
+	if err != nil {
+		fmt.Println("purge job:", err)
+
+		return
+	}
+
+	if job.dryRun {
+		fmt.Printf("purge job (dry run): %d users would be purged\n", len(users))
+	} else {
+		fmt.Printf("purge job: %d users purged\n", len(users))
+	}
+
+	for _, user := range users {
+		fmt.Printf("  %s deleted at %s\n", user.Id, user.DeletedAt.Format(time.RFC3339))
+	}
+}
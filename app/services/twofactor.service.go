@@ -0,0 +1,314 @@
+package service
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"image/png"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+// codes are checked with the usual authenticator settings (SHA1, 6 digits, 30 seconds)
+// accepting one step of clock drift in each direction
+const (
+	DefaultTOTPIssuer = "go-hexagonal"
+	TwoFactorChallengeTTL = 5 * time.Minute
+	twoFactorChallengePurpose = "2fa"
+	totpPeriod = 30
+	totpSkew = 1
+	totpQRCodeSize = 256
+)
+
+var totpOptions = totp.ValidateOpts{
+	Period: totpPeriod,
+	Digits: otp.DigitsSix,
+	Algorithm: otp.AlgorithmSHA1,
+}
+
+func NewTwoFactorService(
+	repository port.TwoFactorRepository,
+	users port.UserRepository,
+	tokens UserService,
+	cipher port.SecretCipher,
+	issuer string,
+) TwoFactorService {
+	return &twoFactorService{
+		repository: repository,
+		users: users,
+		tokens: tokens,
+		cipher: cipher,
+		issuer: issuer,
+	}
+}
+
+type TwoFactorService interface {
+	Enroll(uuid.UUID) (domain.TwoFactorEnrollment, error)
+	Confirm(uuid.UUID, string) ([]string, error)
+	CompleteLogin(string, string, domain.Client) (domain.LoginResult, error)
+	Reset(uuid.UUID) error
+}
+
+type twoFactorService struct {
+	repository port.TwoFactorRepository
+	users port.UserRepository
+	tokens UserService
+	cipher port.SecretCipher
+	issuer string
+}
+
+// Enroll generates a new secret, enrolling again before confirming replaces the previous one
+func (service *twoFactorService) Enroll(userId uuid.UUID) (domain.TwoFactorEnrollment, error) {
+	user, err := service.users.List(userId)
+
+	if err != nil {
+		return domain.TwoFactorEnrollment{}, err
+	}
+
+	current, err := service.repository.FindTwoFactor(userId)
+
+	if err != nil && err.Error() != "sql: no rows in result set" {
+		return domain.TwoFactorEnrollment{}, err
+	}
+
+	if err == nil && current.Enabled() {
+		return domain.TwoFactorEnrollment{}, errors.New("Two-factor is already enabled")
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer: service.issuer,
+		AccountName: user.Email,
+		Period: totpPeriod,
+		Digits: otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	})
+
+	if err != nil {
+		return domain.TwoFactorEnrollment{}, err
+	}
+
+	secret, err := service.cipher.Encrypt([]byte(key.Secret()))
+
+	if err != nil {
+		return domain.TwoFactorEnrollment{}, err
+	}
+
+	err = service.repository.SaveTwoFactor(domain.TwoFactor{
+		UserId: userId,
+		Secret: secret,
+		CreatedAt: time.Now(),
+	})
+
+	if err != nil {
+		return domain.TwoFactorEnrollment{}, err
+	}
+
+	image, err := key.Image(totpQRCodeSize, totpQRCodeSize)
+
+	if err != nil {
+		return domain.TwoFactorEnrollment{}, err
+	}
+
+	var qrCode bytes.Buffer
+
+	if err := png.Encode(&qrCode, image); err != nil {
+		return domain.TwoFactorEnrollment{}, err
+	}
+
+	return domain.TwoFactorEnrollment{
+		Secret: key.Secret(),
+		URI: key.URL(),
+		QRCode: qrCode.Bytes(),
+	}, nil
+}
+
+// Confirm enables two-factor once the user proves the authenticator works and returns
+// the recovery codes, they are only shown here
+func (service *twoFactorService) Confirm(userId uuid.UUID, code string) ([]string, error) {
+	twoFactor, err := service.repository.FindTwoFactor(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if twoFactor.Enabled() {
+		return nil, errors.New("Two-factor is already enabled")
+	}
+
+	step, err := service.verifyCode(twoFactor, code)
+
+	if err != nil {
+		return nil, err
+	}
+
+	twoFactor.ConfirmedAt = time.Now()
+	twoFactor.LastUsedStep = step
+
+	codes, err := domain.GenerateRecoveryCodes(domain.RecoveryCodeCount)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := []string{}
+
+	for _, code := range codes {
+		hashes = append(hashes, domain.HashRecoveryCode(code))
+	}
+
+	if err := service.repository.ReplaceRecoveryCodes(userId, hashes); err != nil {
+		return nil, err
+	}
+
+	if err := service.repository.SaveTwoFactor(twoFactor); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// CompleteLogin trades the challenge returned by the password step and a TOTP or
+// recovery code for the access token, or for the password change token when the password expired
+func (service *twoFactorService) CompleteLogin(challenge string, code string, client domain.Client) (domain.LoginResult, error) {
+	userId, err := parseTwoFactorChallenge(challenge)
+
+	if err != nil {
+		return domain.LoginResult{}, err
+	}
+
+	twoFactor, err := service.repository.FindTwoFactor(userId)
+
+	if err != nil && err.Error() != "sql: no rows in result set" {
+		return domain.LoginResult{}, err
+	}
+
+	// the enrolment may have been reset after the challenge was issued
+	if err != nil || !twoFactor.Enabled() {
+		return domain.LoginResult{}, errors.New("Invalid challenge token")
+	}
+
+	if isTOTPCode(code) {
+		step, err := service.verifyCode(twoFactor, code)
+
+		if err != nil {
+			return domain.LoginResult{}, err
+		}
+
+		twoFactor.LastUsedStep = step
+
+		if err := service.repository.SaveTwoFactor(twoFactor); err != nil {
+			return domain.LoginResult{}, err
+		}
+
+		return service.tokens.FinishLogin(userId, client)
+	}
+
+	used, err := service.repository.UseRecoveryCode(userId, domain.HashRecoveryCode(code))
+
+	if err != nil {
+		return domain.LoginResult{}, err
+	}
+
+	if !used {
+		return domain.LoginResult{}, errors.New("Invalid two-factor code")
+	}
+
+	return service.tokens.FinishLogin(userId, client)
+}
+
+// Reset removes the enrolment and the recovery codes, it is meant for admins helping a user
+// that lost the authenticator
+func (service *twoFactorService) Reset(userId uuid.UUID) error {
+	if _, err := service.users.List(userId); err != nil {
+		return err
+	}
+
+	return service.repository.DeleteTwoFactor(userId)
+}
+
+// verifyCode returns the time step of the code, steps up to the last accepted one are
+// refused so a code seen by someone else can't be replayed
+func (service *twoFactorService) verifyCode(twoFactor domain.TwoFactor, code string) (int64, error) {
+	plain, err := service.cipher.Decrypt(twoFactor.Secret)
+
+	if err != nil {
+		return 0, err
+	}
+
+	secret := string(plain)
+	current := time.Now().Unix() / totpPeriod
+
+	for step := current - totpSkew; step <= current + totpSkew; step++ {
+		if step <= twoFactor.LastUsedStep {
+			continue
+		}
+
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(step * totpPeriod, 0), totpOptions)
+
+		if err != nil {
+			return 0, err
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, nil
+		}
+	}
+
+	return 0, errors.New("Invalid two-factor code")
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != int(otp.DigitsSix) {
+		return false
+	}
+
+	for _, char := range code {
+		if char < '0' || char > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// signTwoFactorChallenge issues the token returned by the password step, it can't be used
+// as an access token because it has no email and carries its own purpose
+func signTwoFactorChallenge(userId uuid.UUID) (string, error) {
+	claims := jwt.MapClaims{
+		"id": userId,
+		"purpose": twoFactorChallengePurpose,
+		"exp": time.Now().Add(TwoFactorChallengeTTL).Unix(),
+	}
+
+	return signToken(claims)
+}
+
+func parseTwoFactorChallenge(challenge string) (uuid.UUID, error) {
+	claims := jwt.MapClaims{}
+
+	err := parseToken(challenge, claims, jwt.WithExpirationRequired())
+
+	if err != nil || claims["purpose"] != twoFactorChallengePurpose {
+		return uuid.Nil, errors.New("Invalid challenge token")
+	}
+
+	id, ok := claims["id"].(string)
+
+	if !ok {
+		return uuid.Nil, errors.New("Invalid challenge token")
+	}
+
+	userId, err := uuid.Parse(id)
+
+	if err != nil {
+		return uuid.Nil, errors.New("Invalid challenge token")
+	}
+
+	return userId, nil
+}
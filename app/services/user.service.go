@@ -2,8 +2,10 @@ package service
 
 import (
 	"errors"
+	"io"
+	"strings"
 	"time"
-	"golang.org/x/crypto/bcrypt"
+	"unicode/utf8"
 	"github.com/PedroPereiraN/go-hexagonal/domain"
 	"github.com/PedroPereiraN/go-hexagonal/ports/output"
 	"github.com/golang-jwt/jwt/v5"
@@ -12,9 +14,109 @@ import (
 
 var jwtSecret = []byte("super-secret")
 
-func NewUserService(repository port.UserRepository) UserService {
-	return &userService{
+// users soft deleted for longer than the grace period can't be restored anymore
+// and the purge removes them for good once they exceed the retention window
+const (
+	DefaultRestoreGracePeriod = 30 * 24 * time.Hour
+	DefaultPurgeRetention = 90 * 24 * time.Hour
+)
+
+// login attempts are kept for 90 days and the users list at most 100 of them at a time
+const (
+	DefaultLoginHistoryRetention = 90 * 24 * time.Hour
+	DefaultLoginHistoryLimit = 20
+	MaxLoginHistoryLimit = 100
+)
+
+// a new password can't be one of the last 5 passwords of the user, the current one included
+const DefaultPasswordHistoryDepth = 5
+
+// the token returned when the password expired only changes the password, like the
+// two-factor challenge it is short lived
+const (
+	PasswordChangeTokenTTL = 10 * time.Minute
+	passwordChangePurpose = "password_change"
+)
+
+// tokens and their sessions last a day, the last seen time of a session is only written
+// once per interval so authenticating a request doesn't always hit the database with a write
+const (
+	tokenTTL = 24 * time.Hour
+	sessionTouchInterval = time.Minute
+)
+
+func NewUserService(repository port.UserRepository, options ...UserServiceOption) UserService {
+	service := &userService{
 		repository: repository,
+		restoreGracePeriod: DefaultRestoreGracePeriod,
+		purgeRetention: DefaultPurgeRetention,
+		loginHistoryRetention: DefaultLoginHistoryRetention,
+	}
+
+	for _, option := range options {
+		option(service)
+	}
+
+	return service
+}
+
+type UserServiceOption func(*userService)
+
+func WithRestoreGracePeriod(period time.Duration) UserServiceOption {
+	return func(service *userService) {
+		service.restoreGracePeriod = period
+	}
+}
+
+func WithPurgeRetention(retention time.Duration) UserServiceOption {
+	return func(service *userService) {
+		service.purgeRetention = retention
+	}
+}
+
+// WithSessions makes Login record a session for every token so it can be listed and revoked
+func WithSessions(repository port.SessionRepository) UserServiceOption {
+	return func(service *userService) {
+		service.sessions = repository
+	}
+}
+
+// WithLoginHistory makes Login record every attempt of a known user, PurgeLoginHistory removes
+// the ones older than the retention
+func WithLoginHistory(repository port.LoginHistoryRepository, retention time.Duration) UserServiceOption {
+	return func(service *userService) {
+		service.loginHistory = repository
+		service.loginHistoryRetention = retention
+	}
+}
+
+// WithTwoFactor makes Login ask for a second factor from the users that enabled it
+func WithTwoFactor(repository port.TwoFactorRepository) UserServiceOption {
+	return func(service *userService) {
+		service.twoFactor = repository
+	}
+}
+
+// WithPasswordHistory refuses a new password that matches one of the last depth passwords of the user,
+// the current one included, keeping the hashes of the depth - 1 passwords before it
+func WithPasswordHistory(depth int) UserServiceOption {
+	return func(service *userService) {
+		service.passwordHistoryDepth = depth
+	}
+}
+
+// WithPasswordExpiry makes Login trade an expired password for a token that only changes it,
+// see domain.UserDomain.PasswordExpired
+func WithPasswordExpiry(expiry domain.PasswordExpiry) UserServiceOption {
+	return func(service *userService) {
+		service.passwordExpiry = expiry
+	}
+}
+
+// WithIssuer sets the iss claim of the tokens so services verifying them can check where they come from
+func WithIssuer(issuer string) UserServiceOption {
+	return func(service *userService) {
+		service.issuer = issuer
 	}
 }
 
@@ -24,12 +126,42 @@ type UserService interface {
 	ListAll() ([]domain.UserDomain, error)
 	Delete(uuid.UUID) (uuid.UUID, error)
 	Update(uuid.UUID, domain.UserDomain) (uuid.UUID, error)
-	UpdatePassword(uuid.UUID, string) (uuid.UUID, error)
-	Login(string, string) (string, error)
+	UpdatePassword(uuid.UUID, string, string) (uuid.UUID, error)
+	ResetPassword(uuid.UUID, string) (uuid.UUID, error)
+	Deactivate(uuid.UUID, string) (uuid.UUID, error)
+	Login(string, string, domain.Client) (domain.LoginResult, error)
+	FinishLogin(uuid.UUID, domain.Client) (domain.LoginResult, error)
+	ChangeExpiredPassword(string, string, domain.Client) (domain.LoginResult, error)
+	SetRole(uuid.UUID, string) (uuid.UUID, error)
+	RequirePasswordChange(uuid.UUID) (uuid.UUID, error)
+	Suspend(uuid.UUID, string, string, time.Time) (uuid.UUID, error)
+	Reactivate(uuid.UUID, string, string) (uuid.UUID, error)
+	ListByStatus(string) ([]domain.UserDomain, error)
+	ListDeleted() ([]domain.UserDomain, error)
+	Restore(uuid.UUID) (uuid.UUID, error)
+	Purge(bool) ([]domain.UserDomain, error)
+	IssueToken(uuid.UUID, domain.Client) (string, error)
+	Authenticate(string) (domain.Identity, error)
+	ListSessions(uuid.UUID) ([]domain.Session, error)
+	RevokeSession(uuid.UUID, uuid.UUID) error
+	ListLogins(uuid.UUID, int) ([]domain.LoginAttempt, error)
+	PurgeLoginHistory() (int64, error)
+	Import(domain.ImportRowReader, string, bool) (domain.ImportReport, error)
+	Export(domain.UserFilter) (domain.UserIterator, error)
+	Search(string, int, int) (domain.UserSearchPage, error)
 }
 
 type userService struct {
 	repository port.UserRepository
+	restoreGracePeriod time.Duration
+	purgeRetention time.Duration
+	twoFactor port.TwoFactorRepository
+	sessions port.SessionRepository
+	loginHistory port.LoginHistoryRepository
+	loginHistoryRetention time.Duration
+	issuer string
+	passwordHistoryDepth int
+	passwordExpiry domain.PasswordExpiry
 }
 
 func (service * userService) Create(dto domain.UserDomain) (uuid.UUID, error) {
@@ -75,7 +207,23 @@ func (service * userService) Create(dto domain.UserDomain) (uuid.UUID, error) {
 		return uuid.Nil, errors.New("Email is already registered")
 	}
 
-	result, err := service.repository.Create(uDomain)
+	var result uuid.UUID
+
+	err = service.repository.Transaction(func(repository port.UserRepository) error {
+		result, err = repository.Create(uDomain)
+
+		if err != nil {
+			return err
+		}
+
+		return repository.AddEvent(domain.UserCreated{
+			UserId: result,
+			Name: uDomain.Name,
+			Email: uDomain.Email,
+			Phone: uDomain.Phone,
+			CreatedAt: uDomain.CreatedAt,
+		})
+	})
 
 	if err != nil {
 		return uuid.Nil, err
@@ -91,7 +239,7 @@ func (service *userService) List(id uuid.UUID) (domain.UserDomain, error) {
 		return domain.UserDomain{}, err
 	}
 
-	uDomain, err := domain.CreateUser(
+	uDomain, err := domain.RestoreUser(
 		userData.Id,
 		userData.Name,
 		userData.Email,
@@ -106,6 +254,10 @@ func (service *userService) List(id uuid.UUID) (domain.UserDomain, error) {
 		return domain.UserDomain{}, err
 	}
 
+	uDomain.Role = userData.Role
+	uDomain.Status = userData.Status
+	uDomain.LastLoginAt = userData.LastLoginAt
+
 	return uDomain, nil
 }
 
@@ -118,7 +270,7 @@ func (service *userService) ListAll() ([]domain.UserDomain, error) {
 	}
 
 	for _, userData := range usersData {
-		uDomain, err := domain.CreateUser(
+		uDomain, err := domain.RestoreUser(
 			userData.Id,
 			userData.Name,
 			userData.Email,
@@ -133,6 +285,10 @@ func (service *userService) ListAll() ([]domain.UserDomain, error) {
 			return []domain.UserDomain{}, err
 		}
 
+		uDomain.Role = userData.Role
+		uDomain.Status = userData.Status
+		uDomain.LastLoginAt = userData.LastLoginAt
+
 		users = append(users, uDomain)
 	}
 
@@ -146,7 +302,20 @@ func (service *userService) Delete(id uuid.UUID) (uuid.UUID, error) {
 		return uuid.Nil, err
 	}
 
-	userId, err := service.repository.Delete(id)
+	var userId uuid.UUID
+
+	err = service.repository.Transaction(func(repository port.UserRepository) error {
+		userId, err = repository.Delete(id)
+
+		if err != nil {
+			return err
+		}
+
+		return repository.AddEvent(domain.UserDeleted{
+			UserId: userId,
+			DeletedAt: time.Now(),
+		})
+	})
 
   if err != nil {
     return uuid.Nil, err
@@ -157,7 +326,7 @@ func (service *userService) Delete(id uuid.UUID) (uuid.UUID, error) {
 }
 
 func (service *userService) Update(id uuid.UUID, dto domain.UserDomain) (uuid.UUID, error) {
-	_, err := service.repository.List(id)
+	current, err := service.repository.List(id)
 
 	if err != nil {
 		return uuid.Nil, err
@@ -179,7 +348,7 @@ func (service *userService) Update(id uuid.UUID, dto domain.UserDomain) (uuid.UU
 	}
 
 	//check phone
-	_, err = service.repository.FindUserByPhone(uDomain.Phone)
+	found, err := service.repository.FindUserByPhone(uDomain.Phone)
 
 	// first we check if an error exists
 	// if the error exists and is NOT because the value could not be found we return the error
@@ -189,23 +358,47 @@ func (service *userService) Update(id uuid.UUID, dto domain.UserDomain) (uuid.UU
 	}
 
 	// second we check if errors doesnt exists, because if the error doesn't exists an user with this phone already exists
-	// so we cant let this user be created
-	if err == nil {
+	// so we cant let this user be created, unless it is the user being updated
+	if err == nil && found.Id != id {
 		return uuid.Nil, errors.New("Phone is already registered")
 	}
 
 	// check email
-	_, err = service.repository.FindUserByEmail(uDomain.Email)
+	found, err = service.repository.FindUserByEmail(uDomain.Email)
 
 	if err != nil && err.Error() != "sql: no rows in result set" {
 		return uuid.Nil, err
 	}
 
-	if err == nil {
+	if err == nil && found.Id != id {
 		return uuid.Nil, errors.New("Email is already registered")
 	}
 
-	userId, err := service.repository.Update(id, uDomain)
+	var userId uuid.UUID
+
+	err = service.repository.Transaction(func(repository port.UserRepository) error {
+		userId, err = repository.Update(id, uDomain)
+
+		if err != nil {
+			return err
+		}
+
+		event := domain.UserUpdated{UserId: userId, UpdatedAt: time.Now()}
+
+		if uDomain.Name != current.Name {
+			event.Name = uDomain.Name
+		}
+
+		if uDomain.Email != current.Email {
+			event.Email = uDomain.Email
+		}
+
+		if uDomain.Phone != current.Phone {
+			event.Phone = uDomain.Phone
+		}
+
+		return repository.AddEvent(event)
+	})
 
 	if err != nil {
 		return uuid.Nil, err
@@ -214,29 +407,80 @@ func (service *userService) Update(id uuid.UUID, dto domain.UserDomain) (uuid.UU
 	return userId, nil
 }
 
-func (service *userService) UpdatePassword(id uuid.UUID, password string) (uuid.UUID, error) {
-	_, err := service.repository.List(id)
+// UpdatePassword is the password change made by the user himself, he must confirm the current password.
+// Every token issued before the change stops being accepted.
+func (service *userService) UpdatePassword(id uuid.UUID, currentPassword string, password string) (uuid.UUID, error) {
+	uDomain, err := service.repository.List(id)
 
 	if err != nil {
 		return uuid.Nil, err
 	}
 
-	uDomain, err := domain.CreateUser(
-		uuid.Nil,
-		"",
-		"",
-		"",
-		password,
-		time.Time{},
-		time.Time{},
-		time.Time{},
-	)
+	if err := confirmPassword(uDomain, currentPassword); err != nil {
+		return uuid.Nil, err
+	}
+
+	return service.updatePassword(id, uDomain, password)
+}
+
+// ResetPassword replaces the password without the current one, it is meant for admins and operators
+func (service *userService) ResetPassword(id uuid.UUID, password string) (uuid.UUID, error) {
+	uDomain, err := service.repository.List(id)
 
 	if err != nil {
 		return uuid.Nil, err
 	}
 
-	userId, err := service.repository.UpdatePassword(id, uDomain)
+	return service.updatePassword(id, uDomain, password)
+}
+
+func (service *userService) updatePassword(id uuid.UUID, uDomain domain.UserDomain, password string) (uuid.UUID, error) {
+	// the current password is always refused, the history holds the ones before it
+	keep := service.passwordHistoryDepth - 1
+	previous := []string{}
+
+	if keep > 0 {
+		var err error
+
+		previous, err = service.repository.ListPasswordHistory(id, keep)
+
+		if err != nil {
+			return uuid.Nil, err
+		}
+	}
+
+	replaced := uDomain.Password
+
+	if err := uDomain.UpdatePassword(password, previous...); err != nil {
+		return uuid.Nil, err
+	}
+
+	var userId uuid.UUID
+
+	err := service.repository.Transaction(func(repository port.UserRepository) error {
+		var err error
+
+		userId, err = repository.UpdatePassword(id, uDomain)
+
+		if err != nil {
+			return err
+		}
+
+		if keep > 0 {
+			if err := repository.AddPasswordHistory(id, replaced, uDomain.PasswordChangedAt); err != nil {
+				return err
+			}
+
+			if err := repository.PrunePasswordHistory(id, keep); err != nil {
+				return err
+			}
+		}
+
+		return repository.AddEvent(domain.UserPasswordChanged{
+			UserId: userId,
+			ChangedAt: uDomain.PasswordChangedAt,
+		})
+	})
 
   if err != nil {
     return uuid.Nil, err
@@ -245,32 +489,861 @@ func (service *userService) UpdatePassword(id uuid.UUID, password string) (uuid.
   return userId, nil
 }
 
-func (service *userService) Login(email string, password string) (string, error) {
+// Deactivate soft deletes the account of the user himself after he confirms the password, his
+// sessions are revoked first so a failure leaves him signed out rather than signed in
+func (service *userService) Deactivate(id uuid.UUID, password string) (uuid.UUID, error) {
+	user, err := service.repository.List(id)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := confirmPassword(user, password); err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := service.revokeSessions(id); err != nil {
+		return uuid.Nil, err
+	}
+
+	return service.Delete(id)
+}
 
+func (service *userService) revokeSessions(id uuid.UUID) error {
+	if service.sessions == nil {
+		return nil
+	}
+
+	sessions, err := service.sessions.ListSessions(id)
+
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		err := service.sessions.RevokeSession(id, session.Id)
+
+		// a session revoked in the meantime is already what we want
+		if err != nil && err.Error() != "sql: no rows in result set" {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Suspend keeps the user from signing in until he is reactivated or until the suspension ends when
+// until isn't zero, his sessions are revoked and his tokens and API keys are refused meanwhile
+func (service *userService) Suspend(id uuid.UUID, reason string, actor string, until time.Time) (uuid.UUID, error) {
+	userId, err := service.changeStatus(id, domain.StatusSuspended, reason, actor, until)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := service.revokeSessions(id); err != nil {
+		return uuid.Nil, err
+	}
+
+	return userId, nil
+}
+
+// Reactivate ends a suspension or activates a pending account, deactivated accounts are restored instead
+func (service *userService) Reactivate(id uuid.UUID, reason string, actor string) (uuid.UUID, error) {
+	return service.changeStatus(id, domain.StatusActive, reason, actor, time.Time{})
+}
+
+func (service *userService) changeStatus(id uuid.UUID, state string, reason string, actor string, until time.Time) (uuid.UUID, error) {
+	user, err := service.repository.List(id)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := user.ChangeStatus(state, reason, actor, until, time.Now()); err != nil {
+		return uuid.Nil, err
+	}
+
+	var userId uuid.UUID
+
+	err = service.repository.Transaction(func(repository port.UserRepository) error {
+		userId, err = repository.UpdateStatus(id, user.Status)
+
+		if err != nil {
+			return err
+		}
+
+		event := domain.UserStatusChanged{
+			UserId: userId,
+			Status: user.Status.State,
+			Reason: user.Status.Reason,
+			ChangedBy: user.Status.ChangedBy,
+			ChangedAt: user.Status.ChangedAt,
+		}
+
+		if !user.Status.SuspendedUntil.IsZero() {
+			event.SuspendedUntil = &user.Status.SuspendedUntil
+		}
+
+		return repository.AddEvent(event)
+	})
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return userId, nil
+}
+
+func (service *userService) ListByStatus(state string) ([]domain.UserDomain, error) {
+	if !domain.IsValidStatus(state) {
+		return []domain.UserDomain{}, errors.New("Invalid status")
+	}
+
+	return service.repository.ListByStatus(state)
+}
+
+func confirmPassword(user domain.UserDomain, password string) error {
+	valid, err := domain.VerifyPassword(user.Password, password)
+
+	if err != nil || !valid {
+		return errors.New("Wrong password")
+	}
+
+	return nil
+}
+
+// Login records every attempt whatever the outcome, an email nobody registered is recorded
+// against uuid.Nil as an unknown user
+func (service *userService) Login(email string, password string, client domain.Client) (domain.LoginResult, error) {
 	user, err := service.repository.FindUserByEmail(email)
 
 	if err != nil {
-    return "", err
+		if err.Error() == "sql: no rows in result set" {
+			service.recordLogin(uuid.Nil, client, domain.LoginResult{}, err)
+		}
+
+    return domain.LoginResult{}, err
   }
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	result, err := service.login(user, password, client)
+
+	service.recordLogin(user.Id, client, result, err)
+
+	return result, err
+}
+
+func (service *userService) login(user domain.UserDomain, password string, client domain.Client) (domain.LoginResult, error) {
+	valid, err := domain.VerifyPassword(user.Password, password)
+	if err != nil || !valid {
+		return domain.LoginResult{}, errors.New("Wrong password")
+	}
+
+	// only told once the password is right so it doesn't reveal the state of any account
+	if err := user.Status.CanLogin(time.Now()); err != nil {
+		return domain.LoginResult{}, err
+	}
+
+	if domain.PasswordNeedsRehash(user.Password) {
+		service.rehashPassword(user, password)
+	}
+
+	if service.twoFactor != nil {
+		twoFactor, err := service.twoFactor.FindTwoFactor(user.Id)
+
+		if err != nil && err.Error() != "sql: no rows in result set" {
+			return domain.LoginResult{}, err
+		}
+
+		if err == nil && twoFactor.Enabled() {
+			challenge, err := signTwoFactorChallenge(user.Id)
+
+			if err != nil {
+				return domain.LoginResult{}, err
+			}
+
+			return domain.LoginResult{TwoFactorRequired: true, ChallengeToken: challenge}, nil
+		}
+	}
+
+	return service.finishLogin(user, client)
+}
+
+// FinishLogin is the last step of a login, once the second factor passed when the user has one
+func (service *userService) FinishLogin(id uuid.UUID, client domain.Client) (domain.LoginResult, error) {
+	user, err := service.repository.List(id)
+
+	if err != nil {
+		return domain.LoginResult{}, err
+	}
+
+	// the account may have been suspended since the password step
+	if err := user.Status.CanLogin(time.Now()); err != nil {
+		service.recordLogin(user.Id, client, domain.LoginResult{}, err)
+
+		return domain.LoginResult{}, err
+	}
+
+	result, err := service.finishLogin(user, client)
+
+	service.recordLogin(user.Id, client, result, err)
+
+	return result, err
+}
+
+// recordLogin keeps the attempt in the login history and the time of the last successful login,
+// a failure to record doesn't change the outcome of the login. Attempts of unknown emails come
+// with uuid.Nil and are kept as failed with the unknown user reason
+func (service *userService) recordLogin(userId uuid.UUID, client domain.Client, result domain.LoginResult, loginErr error) {
+	now := time.Now()
+
+	attempt := domain.LoginAttempt{
+		Id: uuid.New(),
+		UserId: userId,
+		Outcome: domain.LoginSucceeded,
+		IP: client.IP,
+		UserAgent: client.UserAgent,
+		CreatedAt: now,
+	}
+
+	switch {
+	case userId == uuid.Nil:
+		attempt.Outcome = domain.LoginFailed
+		attempt.FailureReason = domain.LoginUnknownUser
+	case loginErr != nil:
+		attempt.Outcome = domain.LoginFailed
+		attempt.FailureReason = loginErr.Error()
+	case result.TwoFactorRequired || result.PasswordExpired:
+		attempt.Outcome = domain.LoginChallenged
+	default:
+		service.repository.UpdateLastLogin(userId, now)
+	}
+
+	if service.loginHistory != nil {
+		service.loginHistory.AddLoginAttempt(attempt)
+	}
+}
+
+// finishLogin only hands out the password change token when the password expired, the session
+// starts once it is changed
+func (service *userService) finishLogin(user domain.UserDomain, client domain.Client) (domain.LoginResult, error) {
+	if user.PasswordExpired(service.passwordExpiry, time.Now()) {
+		token, err := signPasswordChangeToken(user.Id)
+
+		if err != nil {
+			return domain.LoginResult{}, err
+		}
+
+		return domain.LoginResult{PasswordExpired: true, PasswordChangeToken: token}, nil
+	}
+
+	token, err := service.generateToken(user, client)
+
+	if err != nil {
+		return domain.LoginResult{}, err
+	}
+
+	return domain.LoginResult{UserId: user.Id, Token: token}, nil
+}
+
+// ChangeExpiredPassword trades the token returned by a login with an expired password and a new
+// password for the access token. The password rules and history apply like on any other change
+// and the token can't be used again since it predates the change.
+func (service *userService) ChangeExpiredPassword(changeToken string, password string, client domain.Client) (domain.LoginResult, error) {
+	userId, issuedAt, err := parsePasswordChangeToken(changeToken)
+
 	if err != nil {
-		return "", errors.New("Wrong password")
+		return domain.LoginResult{}, err
+	}
+
+	user, err := service.repository.List(userId)
+
+	if err != nil && err.Error() != "sql: no rows in result set" {
+		return domain.LoginResult{}, err
+	}
+
+	if err != nil || user.IssuedBeforePasswordChange(issuedAt) {
+		return domain.LoginResult{}, errors.New("Invalid password change token")
+	}
+
+	// the account may have been suspended since the login asked for the new password
+	if err := user.Status.CanLogin(time.Now()); err != nil {
+		service.recordLogin(user.Id, client, domain.LoginResult{}, err)
+
+		return domain.LoginResult{}, err
+	}
+
+	if _, err := service.updatePassword(userId, user, password); err != nil {
+		return domain.LoginResult{}, err
 	}
 
+	token, err := service.generateToken(user, client)
+
+	if err != nil {
+		return domain.LoginResult{}, err
+	}
+
+	result := domain.LoginResult{UserId: user.Id, Token: token}
+
+	service.recordLogin(user.Id, client, result, nil)
+
+	return result, nil
+}
+
+// SetRole changes the role of the user, which decides when his password expires
+func (service *userService) SetRole(id uuid.UUID, role string) (uuid.UUID, error) {
+	if !domain.IsValidRole(role) {
+		return uuid.Nil, errors.New("Invalid role")
+	}
+
+	return service.repository.UpdateRole(id, role)
+}
+
+// RequirePasswordChange makes the next login of the user ask for a new password, the tokens he
+// already has keep working until he changes it
+func (service *userService) RequirePasswordChange(id uuid.UUID) (uuid.UUID, error) {
+	return service.repository.RequirePasswordChange(id)
+}
+
+func signPasswordChangeToken(userId uuid.UUID) (string, error) {
+	now := time.Now()
+
+	claims := jwt.MapClaims{
+		"id": userId,
+		"purpose": passwordChangePurpose,
+		"iat": now.Unix(),
+		"exp": now.Add(PasswordChangeTokenTTL).Unix(),
+	}
+
+	return signToken(claims)
+}
+
+func parsePasswordChangeToken(changeToken string) (uuid.UUID, time.Time, error) {
+	claims := jwt.MapClaims{}
+
+	err := parseToken(changeToken, claims, jwt.WithExpirationRequired(), jwt.WithIssuedAt())
+
+	if err != nil || claims["purpose"] != passwordChangePurpose {
+		return uuid.Nil, time.Time{}, errors.New("Invalid password change token")
+	}
+
+	id, _ := claims["id"].(string)
+	issuedAt, _ := claims.GetIssuedAt()
+
+	userId, err := uuid.Parse(id)
+
+	if err != nil || issuedAt == nil {
+		return uuid.Nil, time.Time{}, errors.New("Invalid password change token")
+	}
+
+	return userId, issuedAt.Time, nil
+}
+
+// rehashPassword upgrades the stored hash to the preferred algorithm and parameters while the
+// plain password is at hand. It is not a password change so no event is raised, and a failure
+// doesn't fail the login, it is tried again on the next one.
+func (service *userService) rehashPassword(user domain.UserDomain, password string) {
+	if err := user.EncryptPassword(password); err != nil {
+		return
+	}
+
+	service.repository.UpdatePassword(user.Id, user)
+}
+
+// IssueToken signs a token for the user without asking for his password, it is meant for operators
+// and for logins that already passed the second factor
+func (service *userService) IssueToken(id uuid.UUID, client domain.Client) (string, error) {
+	user, err := service.repository.List(id)
+
+	if err != nil {
+		return "", err
+	}
+
+	return service.generateToken(user, client)
+}
+
+// generateToken starts a session when sessions are enabled, its id goes in the sid claim
+func (service *userService) generateToken(user domain.UserDomain, client domain.Client) (string, error) {
+	now := time.Now()
+
 	claims := jwt.MapClaims{
 		"id": user.Id,
 		"email": user.Email,
-		"exp": time.Now().Add(time.Hour * 24).Unix(),
+		"iat": now.Unix(),
+		"exp": now.Add(tokenTTL).Unix(),
+	}
+
+	if service.issuer != "" {
+		claims["iss"] = service.issuer
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if service.sessions != nil {
+		session := domain.Session{
+			Id: uuid.New(),
+			UserId: user.Id,
+			UserAgent: client.UserAgent,
+			IP: client.IP,
+			CreatedAt: now,
+			LastSeenAt: now,
+			ExpiresAt: now.Add(tokenTTL),
+		}
+
+		if err := service.sessions.CreateSession(session); err != nil {
+			return "", err
+		}
 
-	tokenString, err := token.SignedString(jwtSecret)
+		claims["sid"] = session.Id
+	}
+
+	tokenString, err := signToken(claims)
 	if err != nil {
 		return "", err
 	}
 
 	return tokenString, nil
+}
+
+// Authenticate validates an access token and, when sessions are enabled, refuses the
+// tokens whose session was revoked or that were issued without one
+func (service *userService) Authenticate(tokenString string) (domain.Identity, error) {
+	claims := jwt.MapClaims{}
+
+	err := parseToken(tokenString, claims, jwt.WithExpirationRequired())
+
+	if err != nil {
+		return domain.Identity{}, errors.New("Invalid token")
+	}
+
+	// challenge tokens of the two-factor login and OAuth access tokens are signed with the same key
+	if _, ok := claims["purpose"]; ok {
+		return domain.Identity{}, errors.New("Invalid token")
+	}
+
+	if _, ok := claims["client_id"]; ok {
+		return domain.Identity{}, errors.New("Invalid token")
+	}
+
+	id, _ := claims["id"].(string)
+	email, _ := claims["email"].(string)
+	sid, _ := claims["sid"].(string)
+
+	userId, err := uuid.Parse(id)
+
+	if err != nil {
+		return domain.Identity{}, errors.New("Invalid token")
+	}
+
+	user, err := service.repository.List(userId)
+
+	if err != nil && err.Error() != "sql: no rows in result set" {
+		return domain.Identity{}, err
+	}
+
+	// the tokens of deleted users and the ones issued before the last password change are refused,
+	// a token without iat predates every change
+	var issuedAt time.Time
+
+	if claim, _ := claims.GetIssuedAt(); claim != nil {
+		issuedAt = claim.Time
+	}
+
+	if err != nil || user.IssuedBeforePasswordChange(issuedAt) {
+		return domain.Identity{}, errors.New("Invalid token")
+	}
+
+	if err := user.Status.CanLogin(time.Now()); err != nil {
+		return domain.Identity{}, err
+	}
+
+	identity := domain.Identity{UserId: userId, Email: email, Role: user.Role}
+
+	if service.sessions == nil {
+		return identity, nil
+	}
+
+	sessionId, err := uuid.Parse(sid)
+
+	if err != nil {
+		return domain.Identity{}, errors.New("Invalid token")
+	}
+
+	session, err := service.sessions.FindSession(sessionId)
+
+	if err != nil && err.Error() != "sql: no rows in result set" {
+		return domain.Identity{}, err
+	}
+
+	now := time.Now()
+
+	if err != nil || session.UserId != userId || !session.Active(now) {
+		return domain.Identity{}, errors.New("Session has been revoked")
+	}
+
+	// failing to record the last seen time doesn't make the token invalid
+	if now.Sub(session.LastSeenAt) > sessionTouchInterval {
+		service.sessions.TouchSession(sessionId, now)
+	}
+
+	identity.SessionId = sessionId
+
+	return identity, nil
+}
+
+func (service *userService) ListSessions(userId uuid.UUID) ([]domain.Session, error) {
+	if service.sessions == nil {
+		return []domain.Session{}, nil
+	}
+
+	if _, err := service.repository.List(userId); err != nil {
+		return []domain.Session{}, err
+	}
+
+	return service.sessions.ListSessions(userId)
+}
+
+// RevokeSession signs the session out, the tokens that reference it stop being accepted right away
+func (service *userService) RevokeSession(userId uuid.UUID, sessionId uuid.UUID) error {
+	// without a session store there is no session to find
+	if service.sessions == nil {
+		return errors.New("sql: no rows in result set")
+	}
+
+	return service.sessions.RevokeSession(userId, sessionId)
+}
+
+// ListLogins returns the most recent login attempts of the user, limit defaults to
+// DefaultLoginHistoryLimit and can't exceed MaxLoginHistoryLimit
+func (service *userService) ListLogins(userId uuid.UUID, limit int) ([]domain.LoginAttempt, error) {
+	if limit < 0 || limit > MaxLoginHistoryLimit {
+		return []domain.LoginAttempt{}, errors.New("Invalid limit")
+	}
+
+	if limit == 0 {
+		limit = DefaultLoginHistoryLimit
+	}
+
+	if _, err := service.repository.List(userId); err != nil {
+		return []domain.LoginAttempt{}, err
+	}
+
+	if service.loginHistory == nil {
+		return []domain.LoginAttempt{}, nil
+	}
+
+	return service.loginHistory.ListLoginAttempts(userId, limit)
+}
+
+// PurgeLoginHistory removes the login attempts older than the retention, the last login time
+// of the users is kept
+func (service *userService) PurgeLoginHistory() (int64, error) {
+	if service.loginHistory == nil {
+		return 0, nil
+	}
+
+	return service.loginHistory.DeleteLoginAttempts(time.Now().Add(-service.loginHistoryRetention))
+}
+
+func (service *userService) ListDeleted() ([]domain.UserDomain, error) {
+	usersData, err := service.repository.ListDeleted()
+
+	if err != nil {
+		return []domain.UserDomain{}, err
+	}
+
+	return usersData, nil
+}
+
+func (service *userService) Restore(id uuid.UUID) (uuid.UUID, error) {
+	user, err := service.repository.FindDeletedUser(id)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if time.Since(user.DeletedAt) > service.restoreGracePeriod {
+		return uuid.Nil, errors.New("Restore grace period has expired")
+	}
+
+	// while the user was deleted another account may have taken his phone or email
+	_, err = service.repository.FindUserByPhone(user.Phone)
+
+	if err != nil && err.Error() != "sql: no rows in result set" {
+		return uuid.Nil, err
+	}
+
+	if err == nil {
+		return uuid.Nil, errors.New("Phone is already registered")
+	}
+
+	_, err = service.repository.FindUserByEmail(user.Email)
+
+	if err != nil && err.Error() != "sql: no rows in result set" {
+		return uuid.Nil, err
+	}
+
+	if err == nil {
+		return uuid.Nil, errors.New("Email is already registered")
+	}
+
+	var userId uuid.UUID
+
+	err = service.repository.Transaction(func(repository port.UserRepository) error {
+		userId, err = repository.Restore(id)
+
+		if err != nil {
+			return err
+		}
+
+		return repository.AddEvent(domain.UserRestored{
+			UserId: userId,
+			RestoredAt: time.Now(),
+		})
+	})
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return userId, nil
+}
+
+// Purge hard deletes the users whose deletedAt is older than the retention window
+// and returns them, with dryRun the users are only reported
+func (service *userService) Purge(dryRun bool) ([]domain.UserDomain, error) {
+	cutoff := time.Now().Add(-service.purgeRetention)
+
+	deletedUsers, err := service.repository.ListDeleted()
+
+	if err != nil {
+		return []domain.UserDomain{}, err
+	}
+
+	expired := []domain.UserDomain{}
+
+	for _, user := range deletedUsers {
+		if user.DeletedAt.Before(cutoff) {
+			expired = append(expired, user)
+		}
+	}
+
+	if dryRun || len(expired) == 0 {
+		return expired, nil
+	}
+
+	purgedIds, err := service.repository.Purge(cutoff)
+
+	if err != nil {
+		return []domain.UserDomain{}, err
+	}
+
+	purged := make(map[uuid.UUID]bool, len(purgedIds))
+
+	for _, id := range purgedIds {
+		purged[id] = true
+	}
+
+	result := []domain.UserDomain{}
+
+	for _, user := range expired {
+		if purged[user.Id] {
+			result = append(result, user)
+		}
+	}
+
+	return result, nil
+}
+
+
+// errImportRollback discards the import transaction without being reported as a failure
+var errImportRollback = errors.New("import rolled back")
+
+// Import creates the users yielded by the reader with the same rules as Create.
+// All-or-nothing imports and dry runs use a single transaction that is rolled back
+// when a row fails or when nothing should be persisted.
+func (service *userService) Import(reader domain.ImportRowReader, mode string, dryRun bool) (domain.ImportReport, error) {
+	if !domain.IsValidImportMode(mode) {
+		return domain.ImportReport{}, errors.New("Invalid import mode")
+	}
+
+	report := domain.ImportReport{
+		Mode: mode,
+		DryRun: dryRun,
+		Rows: []domain.ImportResult{},
+	}
+
+	if mode == domain.ImportBestEffort && !dryRun {
+		err := service.importRows(reader, &report)
+
+		return report, err
+	}
+
+	err := service.repository.Transaction(func(repository port.UserRepository) error {
+		txService := *service
+		txService.repository = repository
+
+		if err := txService.importRows(reader, &report); err != nil {
+			return err
+		}
+
+		if dryRun || report.Failed > 0 && mode == domain.ImportAllOrNothing {
+			return errImportRollback
+		}
+
+		return nil
+	})
+
+	if err != nil && err != errImportRollback {
+		return domain.ImportReport{}, err
+	}
+
+	if err == errImportRollback {
+		report.Created = 0
+
+		for i, row := range report.Rows {
+			if row.Status != domain.ImportRowCreated {
+				continue
+			}
+
+			report.Rows[i].Id = uuid.Nil
+
+			if dryRun {
+				report.Rows[i].Status = domain.ImportRowValid
+			} else {
+				report.Rows[i].Status = domain.ImportRowRolledBack
+			}
+		}
+	}
+
+	return report, nil
+}
+
+func (service *userService) importRows(reader domain.ImportRowReader, report *domain.ImportReport) error {
+	for {
+		row, err := reader.Next()
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		report.Total++
+
+		result := domain.ImportResult{Row: row.Row}
+
+		if row.Problem != "" {
+			result.Status = domain.ImportRowFailed
+			result.Error = row.Problem
+			report.Failed++
+			report.Rows = append(report.Rows, result)
+
+			continue
+		}
+
+		var id uuid.UUID
+
+		// each row gets its own savepoint when the import runs in a transaction, so a row the
+		// database refuses doesn't abort the transaction for the rows after it
+		err = service.repository.Transaction(func(repository port.UserRepository) error {
+			rowService := *service
+			rowService.repository = repository
+
+			id, err = rowService.Create(domain.UserDomain{
+				Name: row.Name,
+				Email: row.Email,
+				Phone: row.Phone,
+				Password: row.Password,
+			})
+
+			return err
+		})
+
+		if err != nil {
+			result.Status = domain.ImportRowFailed
+			result.Error = err.Error()
+			report.Failed++
+		} else {
+			result.Status = domain.ImportRowCreated
+			result.Id = id
+			report.Created++
+		}
+
+		report.Rows = append(report.Rows, result)
+	}
+}
+
+// Export streams the users matching the filter, password hashes are never exposed
+func (service *userService) Export(filter domain.UserFilter) (domain.UserIterator, error) {
+	iterator, err := service.repository.Iterate(filter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &withoutPasswordIterator{UserIterator: iterator}, nil
+}
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit = 100
+)
+
+// Search finds active users by a fragment of their name, email or phone, ranked by relevance
+func (service *userService) Search(query string, limit int, offset int) (domain.UserSearchPage, error) {
+	query = strings.TrimSpace(query)
+
+	if utf8.RuneCountInString(query) < 2 {
+		return domain.UserSearchPage{}, errors.New("Search query must have at least 2 characters")
+	}
+
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+
+	if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	page, err := service.repository.Search(domain.UserSearch{
+		Query: query,
+		Limit: limit,
+		Offset: offset,
+	})
+
+	if err != nil {
+		return domain.UserSearchPage{}, err
+	}
+
+	for i := range page.Hits {
+		hit := &page.Hits[i]
+		hit.User.Password = ""
+
+		switch hit.Field {
+		case domain.SearchFieldName:
+			hit.Highlight = domain.Highlight(hit.User.Name, query)
+		case domain.SearchFieldEmail:
+			hit.Highlight = domain.Highlight(hit.User.Email, query)
+		case domain.SearchFieldPhone:
+			hit.Highlight = domain.Highlight(hit.User.Phone, domain.SearchDigits(query))
+		}
+	}
+
+	return page, nil
+}
+
+type withoutPasswordIterator struct {
+	domain.UserIterator
+}
+
+func (iterator *withoutPasswordIterator) User() domain.UserDomain {
+	user := iterator.UserIterator.User()
+	user.Password = ""
 
+	return user
 }
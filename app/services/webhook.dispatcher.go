@@ -0,0 +1,43 @@
+package service
+
+import (
+	"fmt"
+	"time"
+)
+
+func NewWebhookDispatcher(service WebhookService, interval time.Duration) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		service: service,
+		interval: interval,
+		stop: make(chan struct{}),
+	}
+}
+
+// WebhookDispatcher periodically sends the webhook deliveries that are due
+type WebhookDispatcher struct {
+	service WebhookService
+	interval time.Duration
+	stop chan struct{}
+}
+
+func (dispatcher *WebhookDispatcher) Start() {
+	go func() {
+		ticker := time.NewTicker(dispatcher.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := dispatcher.service.DeliverPending(); err != nil {
+					fmt.Println("webhook dispatcher:", err)
+				}
+			case <-dispatcher.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (dispatcher *WebhookDispatcher) Stop() {
+	close(dispatcher.stop)
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultWebhookMaxAttempts = 8
+	DefaultWebhookBaseBackoff = 10 * time.Second
+	DefaultWebhookMaxBackoff = time.Hour
+	webhookBatchSize = 50
+)
+
+func NewWebhookService(repository port.WebhookRepository, sender port.WebhookSender) WebhookService {
+	return &webhookService{
+		repository: repository,
+		sender: sender,
+		maxAttempts: DefaultWebhookMaxAttempts,
+		baseBackoff: DefaultWebhookBaseBackoff,
+		maxBackoff: DefaultWebhookMaxBackoff,
+	}
+}
+
+type WebhookService interface {
+	CreateSubscription(domain.WebhookSubscription) (domain.WebhookSubscription, error)
+	GetSubscription(uuid.UUID) (domain.WebhookSubscription, error)
+	ListSubscriptions() ([]domain.WebhookSubscription, error)
+	UpdateSubscription(uuid.UUID, domain.WebhookSubscription) (uuid.UUID, error)
+	DeleteSubscription(uuid.UUID) (uuid.UUID, error)
+	ListDeliveries(uuid.UUID) ([]domain.WebhookDelivery, error)
+	Redeliver(uuid.UUID) (uuid.UUID, error)
+	Publish(domain.OutboxMessage) error
+	DeliverPending() (int, error)
+}
+
+type webhookService struct {
+	repository port.WebhookRepository
+	sender port.WebhookSender
+	maxAttempts int
+	baseBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// webhookEnvelope is the body posted to the subscribers
+type webhookEnvelope struct {
+	Id uuid.UUID `json:"id"`
+	Type string `json:"type"`
+	OccurredAt time.Time `json:"occurredAt"`
+	Data json.RawMessage `json:"data"`
+}
+
+// CreateSubscription generates a secret when none is informed, it is only returned here
+func (service *webhookService) CreateSubscription(dto domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	if err := validateEventTypes(dto.EventTypes); err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+
+	subscription := dto
+	subscription.Id = uuid.New()
+	subscription.Active = true
+	subscription.CreatedAt = time.Now()
+
+	if subscription.Secret == "" {
+		secret := make([]byte, 32)
+
+		if _, err := rand.Read(secret); err != nil {
+			return domain.WebhookSubscription{}, err
+		}
+
+		subscription.Secret = hex.EncodeToString(secret)
+	}
+
+	if _, err := service.repository.CreateSubscription(subscription); err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+
+	return subscription, nil
+}
+
+func (service *webhookService) GetSubscription(id uuid.UUID) (domain.WebhookSubscription, error) {
+	return service.repository.FindSubscription(id)
+}
+
+func (service *webhookService) ListSubscriptions() ([]domain.WebhookSubscription, error) {
+	return service.repository.ListSubscriptions()
+}
+
+// UpdateSubscription replaces the informed fields, an empty secret keeps the current one
+func (service *webhookService) UpdateSubscription(id uuid.UUID, dto domain.WebhookSubscription) (uuid.UUID, error) {
+	subscription, err := service.repository.FindSubscription(id)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if dto.Url != "" {
+		subscription.Url = dto.Url
+	}
+
+	if len(dto.EventTypes) > 0 {
+		if err := validateEventTypes(dto.EventTypes); err != nil {
+			return uuid.Nil, err
+		}
+
+		subscription.EventTypes = dto.EventTypes
+	}
+
+	if dto.Secret != "" {
+		subscription.Secret = dto.Secret
+	}
+
+	subscription.Active = dto.Active
+	subscription.UpdatedAt = time.Now()
+
+	return service.repository.UpdateSubscription(subscription)
+}
+
+func (service *webhookService) DeleteSubscription(id uuid.UUID) (uuid.UUID, error) {
+	return service.repository.DeleteSubscription(id)
+}
+
+func (service *webhookService) ListDeliveries(subscriptionId uuid.UUID) ([]domain.WebhookDelivery, error) {
+	if _, err := service.repository.FindSubscription(subscriptionId); err != nil {
+		return []domain.WebhookDelivery{}, err
+	}
+
+	return service.repository.ListDeliveries(subscriptionId, 100)
+}
+
+// Redeliver schedules the delivery again right away, whatever its current status is
+func (service *webhookService) Redeliver(id uuid.UUID) (uuid.UUID, error) {
+	delivery, err := service.repository.FindDelivery(id)
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	delivery.Status = domain.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+
+	if err := service.repository.UpdateDelivery(delivery); err != nil {
+		return uuid.Nil, err
+	}
+
+	return delivery.Id, nil
+}
+
+// Publish is called by the outbox relay and queues one delivery per interested subscription
+func (service *webhookService) Publish(message domain.OutboxMessage) error {
+	subscriptions, err := service.repository.ListSubscriptions()
+
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(webhookEnvelope{
+		Id: message.Id,
+		Type: message.Type,
+		OccurredAt: message.OccurredAt,
+		Data: message.Payload,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	for _, subscription := range subscriptions {
+		if !subscription.Accepts(message.Type) {
+			continue
+		}
+
+		err := service.repository.CreateDelivery(domain.WebhookDelivery{
+			Id: uuid.New(),
+			SubscriptionId: subscription.Id,
+			EventId: message.Id,
+			EventType: message.Type,
+			Payload: body,
+			Status: domain.WebhookDeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt: now,
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeliverPending sends the deliveries that are due and returns how many succeeded,
+// failures are retried with exponential backoff until they are marked dead
+func (service *webhookService) DeliverPending() (int, error) {
+	now := time.Now()
+
+	deliveries, err := service.repository.ListDueDeliveries(now, webhookBatchSize)
+
+	if err != nil {
+		return 0, err
+	}
+
+	subscriptions := map[uuid.UUID]domain.WebhookSubscription{}
+	succeeded := 0
+
+	for _, delivery := range deliveries {
+		subscription, found := subscriptions[delivery.SubscriptionId]
+
+		if !found {
+			subscription, err = service.repository.FindSubscription(delivery.SubscriptionId)
+
+			if err != nil {
+				return succeeded, err
+			}
+
+			subscriptions[delivery.SubscriptionId] = subscription
+		}
+
+		delivery.Attempts++
+
+		if !subscription.Active {
+			delivery.Status = domain.WebhookDeliveryDead
+			delivery.LastError = "Subscription is disabled"
+		} else {
+			service.send(subscription, &delivery)
+		}
+
+		if delivery.Status == domain.WebhookDeliverySucceeded {
+			succeeded++
+		}
+
+		if err := service.repository.UpdateDelivery(delivery); err != nil {
+			return succeeded, err
+		}
+	}
+
+	return succeeded, nil
+}
+
+func (service *webhookService) send(subscription domain.WebhookSubscription, delivery *domain.WebhookDelivery) {
+	now := time.Now()
+	timestamp := now.Unix()
+
+	statusCode, err := service.sender.Send(subscription.Url, map[string]string{
+		"Content-Type": "application/json",
+		"X-Webhook-Id": delivery.Id.String(),
+		"X-Webhook-Event-Id": delivery.EventId.String(),
+		"X-Webhook-Event": delivery.EventType,
+		"X-Webhook-Timestamp": strconv.FormatInt(timestamp, 10),
+		"X-Webhook-Signature": "sha256=" + domain.SignWebhook(subscription.Secret, timestamp, delivery.Payload),
+	}, delivery.Payload)
+
+	delivery.LastStatusCode = statusCode
+	delivery.LastError = ""
+
+	if err == nil && statusCode >= 200 && statusCode < 300 {
+		delivery.Status = domain.WebhookDeliverySucceeded
+		delivery.DeliveredAt = now
+
+		return
+	}
+
+	if err != nil {
+		delivery.LastError = err.Error()
+	} else {
+		delivery.LastError = fmt.Sprintf("Unexpected status code %d", statusCode)
+	}
+
+	if delivery.Attempts >= service.maxAttempts {
+		delivery.Status = domain.WebhookDeliveryDead
+
+		return
+	}
+
+	wait := service.baseBackoff
+
+	for i := 1; i < delivery.Attempts && wait < service.maxBackoff; i++ {
+		wait *= 2
+	}
+
+	if wait > service.maxBackoff {
+		wait = service.maxBackoff
+	}
+
+	delivery.NextAttemptAt = now.Add(wait)
+}
+
+func validateEventTypes(eventTypes []string) error {
+	if len(eventTypes) == 0 {
+		return errors.New("Inform at least one event type")
+	}
+
+	for _, eventType := range eventTypes {
+		if !domain.IsWebhookEventType(eventType) {
+			return errors.New("Invalid event type: " + eventType)
+		}
+	}
+
+	return nil
+}
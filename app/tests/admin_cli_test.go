@@ -0,0 +1,192 @@
+package test
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/command"
+	"github.com/PedroPereiraN/go-hexagonal/config"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var cliUserColumns = []string{
+	"id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt", "passwordChangedAt", "role", "passwordChangeRequired", "status", "statusReason", "statusChangedBy", "statusChangedAt", "suspendedUntil", "lastLoginAt",
+}
+
+// runCLI runs the admin cli with args against the stub database and returns what it printed
+func runCLI(t *testing.T, setup func(mock sqlmock.Sqlmock), args ...string) (string, error) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	setup(mock)
+	mock.ExpectClose()
+
+	opened := ""
+
+	cliApp := command.NewApp(config.Config{PasswordHistoryDepth: 3}, func(url string) (*sql.DB, error) {
+		opened = url
+
+		return db, nil
+	})
+
+	output := &bytes.Buffer{}
+	cliApp.Writer = output
+
+	err = cliApp.Run(append([]string{"admin", "--database-url", "postgres://cli-test"}, args...))
+
+	assert.EqualValues(t, "postgres://cli-test", opened)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	return output.String(), err
+}
+
+func TestAdminCLI(t *testing.T) {
+	t.Run("user_create", func(t *testing.T) {
+		var created uuid.UUID
+
+		output, err := runCLI(t, func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery("SELECT (.+) FROM users WHERE phone = (.+)").
+				WithArgs("00000000000").
+				WillReturnError(sql.ErrNoRows)
+			mock.ExpectQuery("SELECT (.+) FROM users WHERE email = (.+)").
+				WithArgs("john@email.com").
+				WillReturnError(sql.ErrNoRows)
+			mock.ExpectBegin()
+			mock.ExpectQuery("INSERT INTO users (.+)").
+				WithArgs(sqlmock.AnyArg(), "John Doe", sqlmock.AnyArg(), "john@email.com", "00000000000", sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+			mock.ExpectExec("INSERT INTO outbox").
+				WithArgs(sqlmock.AnyArg(), domain.UserCreatedEvent, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+		}, "user", "create", "--name", "John Doe", "--email", "john@email.com", "--phone", "00000000000", "--password", "secret@123")
+
+		assert.NoError(t, err)
+
+		created, err = uuid.Parse(strings.TrimSpace(output))
+
+		assert.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, created)
+	})
+
+	t.Run("user_create_password_policy", func(t *testing.T) {
+		// the password is refused before the database is reached
+		_, err := runCLI(t, func(mock sqlmock.Sqlmock) {}, "user", "create", "--name", "John Doe", "--email", "john@email.com", "--phone", "00000000000", "--password", "weak")
+
+		assert.Error(t, err)
+	})
+
+	t.Run("user_set_password", func(t *testing.T) {
+		userId := uuid.New()
+
+		hash, err := domain.CurrentPasswordHasher().Hash("secret@123")
+
+		assert.NoError(t, err)
+
+		output, err := runCLI(t, func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery("SELECT (.+) FROM users WHERE id = (.+) AND deletedAt IS NULL").
+				WithArgs(userId).
+				WillReturnRows(sqlmock.NewRows(cliUserColumns).AddRow(
+					userId, "John Doe", hash, "john@email.com", "00000000000",
+					"2025-01-02T03:04:05Z", nil, nil, nil, domain.RoleUser, false, domain.StatusActive, "", "", nil, nil, nil,
+				))
+			mock.ExpectQuery("SELECT hash FROM password_history WHERE userId = (.+) LIMIT (.+)").
+				WithArgs(userId, 2).
+				WillReturnRows(sqlmock.NewRows([]string{"hash"}))
+			mock.ExpectBegin()
+			mock.ExpectQuery("UPDATE users SET password = (.+) WHERE id = (.+) RETURNING id").
+				WithArgs(userId, sqlmock.AnyArg(), sqlmock.AnyArg(), false).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(userId))
+			mock.ExpectExec("INSERT INTO password_history").
+				WithArgs(sqlmock.AnyArg(), userId, hash, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec("DELETE FROM password_history").
+				WithArgs(userId, 2).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("INSERT INTO outbox").
+				WithArgs(sqlmock.AnyArg(), domain.UserPasswordChangedEvent, userId, sqlmock.AnyArg(), sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+		}, "user", "set-password", "--id", userId.String(), "--password", "another@456")
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, "User password updated successfully: " + userId.String() + "\n", output)
+	})
+
+	t.Run("user_set_password_invalid_id", func(t *testing.T) {
+		_, err := runCLI(t, func(mock sqlmock.Sqlmock) {}, "user", "set-password", "--id", "not-an-id", "--password", "another@456")
+
+		assert.Error(t, err)
+	})
+
+	t.Run("user_import_dry_run", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "users.csv")
+
+		err := os.WriteFile(file, []byte("name,email,phone,password\n" +
+			"John Doe,john@email.com,00000000000,secret@123\n" +
+			"Jane Doe,jane@email.com,11111111111,secret@123\n" +
+			"Bad,not-an-email,1,secret@123\n"), 0600)
+
+		assert.NoError(t, err)
+
+		output, err := runCLI(t, func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+
+			// the first row is created in its savepoint
+			mock.ExpectExec("SAVEPOINT nested_transaction").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("SELECT (.+) FROM users WHERE phone = (.+)").WithArgs("00000000000").WillReturnError(sql.ErrNoRows)
+			mock.ExpectQuery("SELECT (.+) FROM users WHERE email = (.+)").WithArgs("john@email.com").WillReturnError(sql.ErrNoRows)
+			mock.ExpectExec("SAVEPOINT nested_transaction").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("INSERT INTO users (.+)").
+				WithArgs(sqlmock.AnyArg(), "John Doe", sqlmock.AnyArg(), "john@email.com", "00000000000", sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+			mock.ExpectExec("INSERT INTO outbox").WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec("RELEASE SAVEPOINT nested_transaction").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("RELEASE SAVEPOINT nested_transaction").WillReturnResult(sqlmock.NewResult(0, 0))
+
+			// the second row is refused by the database and only its savepoint is rolled back
+			mock.ExpectExec("SAVEPOINT nested_transaction").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("SELECT (.+) FROM users WHERE phone = (.+)").WithArgs("11111111111").WillReturnError(sql.ErrNoRows)
+			mock.ExpectQuery("SELECT (.+) FROM users WHERE email = (.+)").WithArgs("jane@email.com").WillReturnError(sql.ErrNoRows)
+			mock.ExpectExec("SAVEPOINT nested_transaction").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("INSERT INTO users (.+)").WillReturnError(errors.New("pq: duplicate key value violates unique constraint"))
+			mock.ExpectExec("ROLLBACK TO SAVEPOINT nested_transaction").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("ROLLBACK TO SAVEPOINT nested_transaction").WillReturnResult(sqlmock.NewResult(0, 0))
+
+			// a dry run never commits
+			mock.ExpectRollback()
+		}, "user", "import", "--file", file, "--dry-run")
+
+		assert.NoError(t, err)
+		assert.Regexp(t, `2\s+valid\s`, output)
+		assert.Regexp(t, `3\s+failed\s+pq: duplicate key value violates unique constraint`, output)
+		assert.Regexp(t, `4\s+failed\s+Key: 'CreateUserModel.Email'`, output)
+		assert.Contains(t, output, "total 3, created 0, failed 2 (mode best-effort, dry run true)")
+	})
+
+	t.Run("migrate_status", func(t *testing.T) {
+		output, err := runCLI(t, func(mock sqlmock.Sqlmock) {
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("SELECT version, appliedAt FROM schema_migrations").
+				WillReturnRows(sqlmock.NewRows([]string{"version", "appliedAt"}).AddRow(1, "2025-01-02T03:04:05Z"))
+		}, "migrate", "status")
+
+		assert.NoError(t, err)
+
+		lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+
+		assert.Regexp(t, `^\s+1\s+create_users\s+2025-01-02T03:04:05Z$`, lines[0])
+		assert.Regexp(t, `^\s+2\s+create_idempotency_keys\s+pending$`, lines[1])
+	})
+}
@@ -0,0 +1,156 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/controller"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/middleware"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/server"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestAdminRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	uService := mocks.NewMockUserService(ctrl)
+	uController := controller.NewUserController(uService)
+	oController := controller.NewOAuthController(mocks.NewMockOAuthService(ctrl), uService, mocks.NewMockTwoFactorService(ctrl), mocks.NewMockAPIKeyService(ctrl))
+	akController := controller.NewAPIKeyController(mocks.NewMockAPIKeyService(ctrl))
+	sController := controller.NewSessionController(uService)
+	tfController := controller.NewTwoFactorController(mocks.NewMockTwoFactorService(ctrl))
+
+	tokens := authenticatorFunc(func(token string) (domain.Identity, error) {
+		switch token {
+		case "user":
+			return domain.Identity{UserId: uuid.New(), Email: "john@email.com", Role: domain.RoleUser}, nil
+		case "admin":
+			return domain.Identity{UserId: uuid.New(), Email: "admin@email.com", Role: domain.RoleAdmin}, nil
+		default:
+			return domain.Identity{}, errors.New("Invalid token")
+		}
+	})
+
+	apiKeys := authenticatorFunc(func(key string) (domain.Identity, error) {
+		if key == "user-key" {
+			return domain.Identity{UserId: uuid.New(), Email: "john@email.com", Role: domain.RoleUser, APIKeyId: uuid.New(), Scopes: domain.APIKeyScopes}, nil
+		}
+
+		if key == "admin-key" {
+			return domain.Identity{UserId: uuid.New(), Email: "admin@email.com", Role: domain.RoleAdmin, APIKeyId: uuid.New(), Scopes: []string{domain.ScopeUsersRead}}, nil
+		}
+
+		return domain.Identity{}, errors.New("Invalid API key")
+	})
+
+	// the services have no expectations, a handler reached by anyone but an admin fails the test
+	router := gin.New()
+	admin := server.AdminGroup(router, middleware.Authenticate(tokens, apiKeys))
+	admin.GET("/user/deleted", uController.ListDeleted)
+	admin.PATCH("/user/restore", uController.Restore)
+	admin.DELETE("/user/purge", uController.Purge)
+	admin.PATCH("/user/password", uController.ResetPassword)
+	admin.POST("/user/import", uController.Import)
+	admin.GET("/user/export", uController.Export)
+	admin.DELETE("/user/2fa", tfController.Reset)
+	admin.GET("/user/sessions", sController.List)
+	admin.DELETE("/user/sessions", sController.Revoke)
+	admin.GET("/user/api-keys", akController.List)
+	admin.DELETE("/user/api-keys", akController.Revoke)
+	admin.POST("/oauth/client", oController.CreateClient)
+	admin.GET("/oauth/client", oController.ListClients)
+	admin.DELETE("/oauth/client", oController.DeleteClient)
+	admin.PATCH("/user/password/expire", uController.RequirePasswordChange)
+	admin.PATCH("/user/role", uController.SetRole)
+	admin.PATCH("/user/suspend", uController.Suspend)
+	admin.PATCH("/user/reactivate", uController.Reactivate)
+	admin.GET("/user/status", uController.ListByStatus)
+
+	routes := [][2]string{
+		{"GET", "/admin/user/deleted"},
+		{"PATCH", "/admin/user/restore"},
+		{"DELETE", "/admin/user/purge"},
+		{"PATCH", "/admin/user/password"},
+		{"POST", "/admin/user/import"},
+		{"GET", "/admin/user/export"},
+		{"DELETE", "/admin/user/2fa"},
+		{"GET", "/admin/user/sessions"},
+		{"DELETE", "/admin/user/sessions"},
+		{"GET", "/admin/user/api-keys"},
+		{"DELETE", "/admin/user/api-keys"},
+		{"POST", "/admin/oauth/client"},
+		{"GET", "/admin/oauth/client"},
+		{"DELETE", "/admin/oauth/client"},
+		{"PATCH", "/admin/user/password/expire"},
+		{"PATCH", "/admin/user/role"},
+		{"PATCH", "/admin/user/suspend"},
+		{"PATCH", "/admin/user/reactivate"},
+		{"GET", "/admin/user/status"},
+	}
+
+	send := func(method string, path string, header string, value string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(method, path, strings.NewReader(`{"password":"password@456"}`))
+
+		if value != "" {
+			request.Header.Set(header, value)
+		}
+
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	t.Run("anonymous", func(t *testing.T) {
+		for _, route := range routes {
+			assert.EqualValues(t, http.StatusUnauthorized, send(route[0], route[1], "", "").Code, route[1])
+		}
+	})
+
+	t.Run("invalid_token", func(t *testing.T) {
+		for _, route := range routes {
+			assert.EqualValues(t, http.StatusUnauthorized, send(route[0], route[1], "Authorization", "Bearer forged").Code, route[1])
+		}
+	})
+
+	t.Run("user_token", func(t *testing.T) {
+		for _, route := range routes {
+			assert.EqualValues(t, http.StatusForbidden, send(route[0], route[1], "Authorization", "Bearer user").Code, route[1])
+		}
+	})
+
+	t.Run("user_api_key", func(t *testing.T) {
+		for _, route := range routes {
+			assert.EqualValues(t, http.StatusForbidden, send(route[0], route[1], "X-API-Key", "user-key").Code, route[1])
+		}
+	})
+
+	t.Run("admin_api_key", func(t *testing.T) {
+		for _, route := range routes {
+			recorder := send(route[0], route[1], "X-API-Key", "admin-key")
+
+			assert.EqualValues(t, http.StatusForbidden, recorder.Code, route[1])
+			assert.Contains(t, recorder.Body.String(), "API keys can't use the admin routes", route[1])
+		}
+	})
+
+	t.Run("admin_token", func(t *testing.T) {
+		userId := uuid.New()
+
+		uService.EXPECT().ResetPassword(userId, "password@456").Return(userId, nil)
+
+		recorder := send("PATCH", "/admin/user/password?id=" + userId.String(), "Authorization", "Bearer admin")
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+	})
+}
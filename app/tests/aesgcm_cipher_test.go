@@ -0,0 +1,60 @@
+package test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/cipher"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAESGCMCipher(t *testing.T) {
+	secretCipher, err := cipher.NewAESGCMCipher(bytes.Repeat([]byte{1}, 32))
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when creating the cipher", err)
+	}
+
+	t.Run("round_trip", func(t *testing.T) {
+		ciphertext, err := secretCipher.Encrypt([]byte("JBSWY3DPEHPK3PXP"))
+
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(ciphertext, "v1:"))
+		assert.NotContains(t, ciphertext, "JBSWY3DPEHPK3PXP")
+
+		plaintext, err := secretCipher.Decrypt(ciphertext)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, "JBSWY3DPEHPK3PXP", string(plaintext))
+	})
+
+	t.Run("random_nonce", func(t *testing.T) {
+		first, _ := secretCipher.Encrypt([]byte("secret"))
+		second, _ := secretCipher.Encrypt([]byte("secret"))
+
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("wrong_key", func(t *testing.T) {
+		ciphertext, _ := secretCipher.Encrypt([]byte("secret"))
+
+		other, _ := cipher.NewAESGCMCipher(bytes.Repeat([]byte{2}, 32))
+
+		_, err := other.Decrypt(ciphertext)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown_format", func(t *testing.T) {
+		_, err := secretCipher.Decrypt("plain")
+
+		assert.EqualError(t, err, "Unknown ciphertext format")
+	})
+
+	t.Run("invalid_key_size", func(t *testing.T) {
+		_, err := cipher.NewAESGCMCipher([]byte("short"))
+
+		assert.EqualError(t, err, "The encryption key must have 32 bytes")
+	})
+}
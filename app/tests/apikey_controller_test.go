@@ -0,0 +1,144 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/controller"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/middleware"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/model"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/tests/config"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestAPIKeyController(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	crtl := gomock.NewController(t)
+	defer crtl.Finish()
+	service := mocks.NewMockAPIKeyService(crtl)
+	controller := controller.NewAPIKeyController(service)
+
+	identity := domain.Identity{UserId: uuid.New(), Email: "john@email.com", SessionId: uuid.New()}
+
+	tokens := authenticatorFunc(func(token string) (domain.Identity, error) {
+		return identity, nil
+	})
+
+	apiKeys := authenticatorFunc(func(key string) (domain.Identity, error) {
+		return domain.Identity{UserId: identity.UserId, APIKeyId: uuid.New(), Scopes: domain.APIKeyScopes}, nil
+	})
+
+	router := gin.New()
+	me := router.Group("/v1/me", middleware.Authenticate(tokens, apiKeys))
+	me.POST("/api-keys", controller.CreateMine)
+	me.GET("/api-keys", controller.ListMine)
+	me.DELETE("/api-keys/:id", controller.RevokeMine)
+
+	send := func(method string, path string, body string, header string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+
+		if header == middleware.APIKeyHeader {
+			request.Header.Set(middleware.APIKeyHeader, "key")
+		} else {
+			request.Header.Set("Authorization", "Bearer token")
+		}
+
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	t.Run("create_missing_scopes", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusBadRequest, send("POST", "/v1/me/api-keys", `{"name":"job"}`, "").Code)
+	})
+
+	t.Run("create_invalid_scope", func(t *testing.T) {
+		service.EXPECT().Create(gomock.Any()).Return(domain.APIKey{}, "", errors.New("Invalid scope: admin"))
+
+		assert.EqualValues(t, http.StatusBadRequest, send("POST", "/v1/me/api-keys", `{"name":"job","scopes":["admin"]}`, "").Code)
+	})
+
+	t.Run("create_returns_key_once", func(t *testing.T) {
+		key := domain.APIKey{Id: uuid.New(), UserId: identity.UserId, Name: "job", Prefix: "a1b2c3d4", Scopes: []string{domain.ScopeUsersRead}}
+
+		service.EXPECT().Create(gomock.Any()).DoAndReturn(func(dto domain.APIKey) (domain.APIKey, string, error) {
+			assert.EqualValues(t, identity.UserId, dto.UserId)
+
+			return key, "gohex_a1b2c3d4_secret", nil
+		})
+
+		recorder := send("POST", "/v1/me/api-keys", `{"name":"job","scopes":["users:read"]}`, "")
+
+		var response model.APIKeyModel
+		json.Unmarshal(recorder.Body.Bytes(), &response)
+
+		assert.EqualValues(t, http.StatusCreated, recorder.Code)
+		assert.EqualValues(t, "gohex_a1b2c3d4_secret", response.Key)
+		assert.True(t, response.Active)
+	})
+
+	t.Run("api_key_cannot_create_keys", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusForbidden, send("POST", "/v1/me/api-keys", `{"name":"job","scopes":["users:read"]}`, middleware.APIKeyHeader).Code)
+	})
+
+	t.Run("list_mine_hides_key", func(t *testing.T) {
+		service.EXPECT().List(identity.UserId).Return([]domain.APIKey{{Id: uuid.New(), Name: "job", Prefix: "a1b2c3d4", Hash: "hash"}}, nil)
+
+		recorder := send("GET", "/v1/me/api-keys", "", "")
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+		assert.NotContains(t, recorder.Body.String(), `"key"`)
+		assert.NotContains(t, recorder.Body.String(), "hash")
+	})
+
+	t.Run("revoke_mine_not_found", func(t *testing.T) {
+		keyId := uuid.New()
+
+		service.EXPECT().Revoke(identity.UserId, keyId).Return(errors.New("sql: no rows in result set"))
+
+		assert.EqualValues(t, http.StatusNotFound, send("DELETE", "/v1/me/api-keys/" + keyId.String(), "", "").Code)
+	})
+
+	t.Run("admin_list_user_not_found", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+		userId := uuid.New()
+
+		service.EXPECT().List(userId).Return([]domain.APIKey{}, errors.New("sql: no rows in result set"))
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"id": {userId.String()}}, "GET", nil)
+
+		controller.List(context)
+
+		assert.EqualValues(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("admin_revoke", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+		userId := uuid.New()
+		keyId := uuid.New()
+
+		service.EXPECT().Revoke(userId, keyId).Return(nil)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"id": {userId.String()}, "keyId": {keyId.String()}}, "DELETE", nil)
+
+		controller.Revoke(context)
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+	})
+}
@@ -0,0 +1,93 @@
+package test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/repository"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyRepository(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	akRepository := repository.NewAPIKeyRepository(db)
+
+	columns := []string{"id", "userId", "name", "prefix", "hash", "scopes", "createdAt", "expiresAt", "lastUsedAt", "revokedAt"}
+
+	t.Run("create_without_expiration", func(t *testing.T) {
+		key := domain.APIKey{Id: uuid.New(), UserId: uuid.New(), Name: "billing job", Prefix: "a1b2c3d4", Hash: "hash", Scopes: []string{domain.ScopeUsersRead, domain.ScopeUsersWrite}, CreatedAt: time.Now()}
+
+		mock.ExpectExec("INSERT INTO api_keys").
+			WithArgs(key.Id, key.UserId, key.Name, key.Prefix, key.Hash, "users:read,users:write", key.CreatedAt, nil).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, akRepository.CreateAPIKey(key))
+	})
+
+	t.Run("find_by_prefix", func(t *testing.T) {
+		id := uuid.New()
+		now := time.Now()
+
+		mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE prefix = (.+)").
+			WithArgs("a1b2c3d4").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(id, uuid.New(), "billing job", "a1b2c3d4", "hash", "users:read,sessions", now, nil, nil, nil))
+
+		key, err := akRepository.FindAPIKeyByPrefix("a1b2c3d4")
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, []string{domain.ScopeUsersRead, domain.ScopeSessions}, key.Scopes)
+		assert.True(t, key.Active(now))
+	})
+
+	t.Run("list", func(t *testing.T) {
+		userId := uuid.New()
+		now := time.Now()
+
+		mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE userId = (.+) ORDER BY createdAt").
+			WithArgs(userId).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(uuid.New(), userId, "active", "a1b2c3d4", "hash", "users:read", now, nil, now, nil).
+				AddRow(uuid.New(), userId, "expired", "e5f6a7b8", "hash", "users:read", now, now.Add(-time.Hour), nil, nil))
+
+		keys, err := akRepository.ListAPIKeys(userId)
+
+		assert.NoError(t, err)
+		assert.Len(t, keys, 2)
+		assert.True(t, keys[0].Active(now))
+		assert.False(t, keys[1].Active(now))
+	})
+
+	t.Run("revoke_not_found", func(t *testing.T) {
+		userId := uuid.New()
+		id := uuid.New()
+
+		mock.ExpectQuery("UPDATE api_keys SET revokedAt = NOW\\(\\) WHERE id = (.+) AND userId = (.+) AND revokedAt IS NULL RETURNING id").
+			WithArgs(id, userId).
+			WillReturnError(sql.ErrNoRows)
+
+		assert.EqualError(t, akRepository.RevokeAPIKey(userId, id), "sql: no rows in result set")
+	})
+
+	t.Run("touch", func(t *testing.T) {
+		id := uuid.New()
+		now := time.Now()
+
+		mock.ExpectExec("UPDATE api_keys SET lastUsedAt = (.+) WHERE id = (.+)").
+			WithArgs(now, id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, akRepository.TouchAPIKey(id, now))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,170 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/services"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestAPIKeyService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repository := mocks.NewMockAPIKeyRepository(ctrl)
+	users := mocks.NewMockUserRepository(ctrl)
+	akService := service.NewAPIKeyService(repository, users)
+
+	user := domain.UserDomain{Id: uuid.New(), Email: "john@email.com"}
+
+	// create returns a stored key and its secret
+	create := func(t *testing.T) (domain.APIKey, string) {
+		var stored domain.APIKey
+
+		users.EXPECT().List(user.Id).Return(user, nil)
+		repository.EXPECT().CreateAPIKey(gomock.Any()).DoAndReturn(func(dto domain.APIKey) error {
+			stored = dto
+
+			return nil
+		})
+
+		_, secret, err := akService.Create(domain.APIKey{UserId: user.Id, Name: "billing job", Scopes: []string{domain.ScopeUsersRead}})
+
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when creating the key", err.Error())
+		}
+
+		return stored, secret
+	}
+
+	t.Run("create_invalid_scope", func(t *testing.T) {
+		_, _, err := akService.Create(domain.APIKey{UserId: user.Id, Name: "job", Scopes: []string{"admin"}})
+
+		assert.EqualError(t, err, "Invalid scope: admin")
+	})
+
+	t.Run("create_without_scopes", func(t *testing.T) {
+		_, _, err := akService.Create(domain.APIKey{UserId: user.Id, Name: "job"})
+
+		assert.EqualError(t, err, "Inform at least one scope")
+	})
+
+	t.Run("create_expired", func(t *testing.T) {
+		_, _, err := akService.Create(domain.APIKey{UserId: user.Id, Name: "job", Scopes: []string{domain.ScopeUsersRead}, ExpiresAt: time.Now().Add(-time.Hour)})
+
+		assert.EqualError(t, err, "Expiration must be in the future")
+	})
+
+	t.Run("create_user_not_found", func(t *testing.T) {
+		users.EXPECT().List(user.Id).Return(domain.UserDomain{}, errors.New("sql: no rows in result set"))
+
+		_, _, err := akService.Create(domain.APIKey{UserId: user.Id, Name: "job", Scopes: []string{domain.ScopeUsersRead}})
+
+		assert.EqualError(t, err, "sql: no rows in result set")
+	})
+
+	t.Run("create_stores_only_hash", func(t *testing.T) {
+		stored, secret := create(t)
+
+		assert.True(t, strings.HasPrefix(secret, "gohex_" + stored.Prefix + "_"))
+		assert.EqualValues(t, domain.HashAPIKey(secret), stored.Hash)
+		assert.NotContains(t, stored.Hash, secret)
+	})
+
+	t.Run("authenticate", func(t *testing.T) {
+		stored, secret := create(t)
+
+		repository.EXPECT().FindAPIKeyByPrefix(stored.Prefix).Return(stored, nil)
+		users.EXPECT().List(user.Id).Return(user, nil)
+		repository.EXPECT().TouchAPIKey(stored.Id, gomock.Any()).Return(nil)
+
+		identity, err := akService.Authenticate(secret)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, domain.Identity{UserId: user.Id, Email: user.Email, APIKeyId: stored.Id, Scopes: []string{domain.ScopeUsersRead}}, identity)
+		assert.False(t, identity.HasScope(domain.ScopeSessions))
+	})
+
+	t.Run("recently_used_is_not_touched", func(t *testing.T) {
+		stored, secret := create(t)
+		stored.LastUsedAt = time.Now()
+
+		repository.EXPECT().FindAPIKeyByPrefix(stored.Prefix).Return(stored, nil)
+		users.EXPECT().List(user.Id).Return(user, nil)
+
+		_, err := akService.Authenticate(secret)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("wrong_secret", func(t *testing.T) {
+		stored, secret := create(t)
+
+		repository.EXPECT().FindAPIKeyByPrefix(stored.Prefix).Return(stored, nil)
+
+		_, err := akService.Authenticate(secret[:len(secret) - 1] + "x")
+
+		assert.EqualError(t, err, "Invalid API key")
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		stored, secret := create(t)
+		stored.RevokedAt = time.Now()
+
+		repository.EXPECT().FindAPIKeyByPrefix(stored.Prefix).Return(stored, nil)
+
+		_, err := akService.Authenticate(secret)
+
+		assert.EqualError(t, err, "Invalid API key")
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		stored, secret := create(t)
+		stored.ExpiresAt = time.Now().Add(-time.Minute)
+
+		repository.EXPECT().FindAPIKeyByPrefix(stored.Prefix).Return(stored, nil)
+
+		_, err := akService.Authenticate(secret)
+
+		assert.EqualError(t, err, "Invalid API key")
+	})
+
+	t.Run("deleted_user", func(t *testing.T) {
+		stored, secret := create(t)
+
+		repository.EXPECT().FindAPIKeyByPrefix(stored.Prefix).Return(stored, nil)
+		users.EXPECT().List(user.Id).Return(domain.UserDomain{}, errors.New("sql: no rows in result set"))
+
+		_, err := akService.Authenticate(secret)
+
+		assert.EqualError(t, err, "Invalid API key")
+	})
+
+	t.Run("unknown_prefix", func(t *testing.T) {
+		repository.EXPECT().FindAPIKeyByPrefix("a1b2c3d4").Return(domain.APIKey{}, errors.New("sql: no rows in result set"))
+
+		_, err := akService.Authenticate("gohex_a1b2c3d4_secret")
+
+		assert.EqualError(t, err, "Invalid API key")
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := akService.Authenticate("not-a-key")
+
+		assert.EqualError(t, err, "Invalid API key")
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		id := uuid.New()
+
+		repository.EXPECT().RevokeAPIKey(user.Id, id).Return(nil)
+
+		assert.NoError(t, akService.Revoke(user.Id, id))
+	})
+}
@@ -0,0 +1,139 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/middleware"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// authenticatorFunc lets the tests decide who the caller is without signing tokens
+type authenticatorFunc func(string) (domain.Identity, error)
+
+func (authenticate authenticatorFunc) Authenticate(token string) (domain.Identity, error) {
+	return authenticate(token)
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	identity := domain.Identity{UserId: uuid.New(), Email: "john@email.com", SessionId: uuid.New()}
+	keyIdentity := domain.Identity{UserId: identity.UserId, Email: identity.Email, APIKeyId: uuid.New(), Scopes: []string{domain.ScopeUsersRead}}
+
+	tokens := authenticatorFunc(func(token string) (domain.Identity, error) {
+		switch token {
+		case "valid":
+			return identity, nil
+		case "admin":
+			return domain.Identity{UserId: uuid.New(), Email: "admin@email.com", Role: domain.RoleAdmin}, nil
+		case "revoked":
+			return domain.Identity{}, errors.New("Session has been revoked")
+		case "broken":
+			return domain.Identity{}, errors.New("connection refused")
+		default:
+			return domain.Identity{}, errors.New("Invalid token")
+		}
+	})
+
+	apiKeys := authenticatorFunc(func(key string) (domain.Identity, error) {
+		if key == "valid-key" {
+			return keyIdentity, nil
+		}
+
+		return domain.Identity{}, errors.New("Invalid API key")
+	})
+
+	router := gin.New()
+	router.Use(middleware.Authenticate(tokens, apiKeys))
+	router.GET("/me", func(c *gin.Context) {
+		current, _ := middleware.CurrentIdentity(c)
+
+		c.JSON(http.StatusOK, current.UserId)
+	})
+	router.GET("/users", middleware.RequireScope(domain.ScopeUsersRead), func(c *gin.Context) {
+		c.JSON(http.StatusOK, "users")
+	})
+	router.GET("/sessions", middleware.RequireScope(domain.ScopeSessions), func(c *gin.Context) {
+		c.JSON(http.StatusOK, "sessions")
+	})
+	router.GET("/logins", middleware.RequireRole(domain.RoleAdmin), func(c *gin.Context) {
+		c.JSON(http.StatusOK, "logins")
+	})
+
+	request := func(path string, header string, value string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest("GET", path, nil)
+
+		if value != "" {
+			request.Header.Set(header, value)
+		}
+
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	send := func(authorization string) *httptest.ResponseRecorder {
+		return request("/me", "Authorization", authorization)
+	}
+
+	t.Run("missing_header", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusUnauthorized, send("").Code)
+	})
+
+	t.Run("not_bearer", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusUnauthorized, send("Basic dXNlcjpwYXNz").Code)
+	})
+
+	t.Run("revoked_session", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusUnauthorized, send("Bearer revoked").Code)
+	})
+
+	t.Run("authenticator_error", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusInternalServerError, send("Bearer broken").Code)
+	})
+
+	t.Run("valid_token", func(t *testing.T) {
+		recorder := send("Bearer valid")
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `"` + identity.UserId.String() + `"`, recorder.Body.String())
+	})
+
+	t.Run("api_key_gives_same_user", func(t *testing.T) {
+		recorder := request("/me", middleware.APIKeyHeader, "valid-key")
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `"` + identity.UserId.String() + `"`, recorder.Body.String())
+	})
+
+	t.Run("invalid_api_key", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusUnauthorized, request("/me", middleware.APIKeyHeader, "other-key").Code)
+	})
+
+	t.Run("api_key_with_scope", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusOK, request("/users", middleware.APIKeyHeader, "valid-key").Code)
+	})
+
+	t.Run("api_key_without_scope", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusForbidden, request("/sessions", middleware.APIKeyHeader, "valid-key").Code)
+	})
+
+	t.Run("token_has_every_scope", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusOK, request("/sessions", "Authorization", "Bearer valid").Code)
+	})
+
+	t.Run("user_without_role", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusForbidden, request("/logins", "Authorization", "Bearer valid").Code)
+	})
+
+	t.Run("user_with_role", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusOK, request("/logins", "Authorization", "Bearer admin").Code)
+	})
+}
@@ -54,6 +54,35 @@ func TestUserController_Create(t *testing.T) {
 		assert.EqualValues(t, http.StatusBadRequest, recorder.Code)
 	})
 
+	t.Run("password_policy", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		params := []gin.Param{}
+
+		url := url.Values{}
+
+		model := model.CreateUserModel{
+			Email:    "test@email.com",
+			Password: "test",
+			Name:     "Test name",
+			Phone:    "00000000000",
+		}
+
+		body, _ := json.Marshal(model)
+		stringReader := io.NopCloser(strings.NewReader(string(body)))
+
+		config.MakeRequest(context, params, url, "POST", stringReader)
+
+		controller.Create(context)
+
+		assert.EqualValues(t, http.StatusBadRequest, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), `"rule":"min_length"`)
+		assert.Contains(t, recorder.Body.String(), `"rule":"special"`)
+		assert.Contains(t, recorder.Body.String(), `"rule":"user_data"`)
+	})
+
 	t.Run("service_error", func(t *testing.T) {
 		recorder := httptest.NewRecorder()
 
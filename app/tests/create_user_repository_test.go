@@ -27,7 +27,7 @@ func TestUserRepository_Create(t *testing.T) {
 			Name:  "INVALID_NAME",
 			Email: "INVALID_EMAIL",
 			Phone: "INVALID_PHONE000",
-			Password: "INVALID_PASSWORD",
+			Password: "Secret@123",
 		}
 
 		mock.ExpectQuery("INSERT INTO users (.+)").
@@ -67,6 +67,7 @@ func TestUserService_Create(t *testing.T) {
 
 		repository.EXPECT().FindUserByEmail(uDomain.Email).Return(domain.UserDomain{}, errors.New("sql: no rows in result set"))
 
+		expectTransaction(repository)
 		repository.EXPECT().Create(gomock.Any()).Return(uuid.Nil, errors.New("repository error"))
 
 		id, err := service.Create(uDomain)
@@ -88,7 +89,9 @@ func TestUserService_Create(t *testing.T) {
 
 		repository.EXPECT().FindUserByEmail(uDomain.Email).Return(domain.UserDomain{}, errors.New("sql: no rows in result set"))
 
+		expectTransaction(repository)
 		repository.EXPECT().Create(gomock.Any()).Return(uDomain.Id, nil)
+		repository.EXPECT().AddEvent(gomock.AssignableToTypeOf(domain.UserCreated{})).Return(nil)
 
 		id, err := service.Create(uDomain)
 
@@ -0,0 +1,65 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/services"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestUserService_Deactivate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repository := mocks.NewMockUserRepository(ctrl)
+	sessions := mocks.NewMockSessionRepository(ctrl)
+	uService := service.NewUserService(repository, service.WithSessions(sessions))
+
+	user, err := domain.CreateUser(uuid.Nil, "Test name", "test@email.com", "00000000000", "password@123", time.Time{}, time.Time{}, time.Time{})
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when creating the user", err.Error())
+	}
+
+	t.Run("wrong_password", func(t *testing.T) {
+		repository.EXPECT().List(user.Id).Return(user, nil)
+
+		id, err := uService.Deactivate(user.Id, "wrong@123")
+
+		assert.EqualValues(t, uuid.Nil, id)
+		assert.EqualError(t, err, "Wrong password")
+	})
+
+	t.Run("session_error_keeps_user", func(t *testing.T) {
+		repository.EXPECT().List(user.Id).Return(user, nil)
+		sessions.EXPECT().ListSessions(user.Id).Return(nil, errors.New("connection refused"))
+
+		id, err := uService.Deactivate(user.Id, "password@123")
+
+		assert.EqualValues(t, uuid.Nil, id)
+		assert.EqualError(t, err, "connection refused")
+	})
+
+	t.Run("deactivated", func(t *testing.T) {
+		first := domain.Session{Id: uuid.New(), UserId: user.Id}
+		second := domain.Session{Id: uuid.New(), UserId: user.Id}
+
+		repository.EXPECT().List(user.Id).Return(user, nil).Times(2)
+		sessions.EXPECT().ListSessions(user.Id).Return([]domain.Session{first, second}, nil)
+		sessions.EXPECT().RevokeSession(user.Id, first.Id).Return(nil)
+		sessions.EXPECT().RevokeSession(user.Id, second.Id).Return(errors.New("sql: no rows in result set"))
+		expectTransaction(repository)
+		repository.EXPECT().Delete(user.Id).Return(user.Id, nil)
+		repository.EXPECT().AddEvent(gomock.AssignableToTypeOf(domain.UserDeleted{})).Return(nil)
+
+		id, err := uService.Deactivate(user.Id, "password@123")
+
+		assert.EqualValues(t, user.Id, id)
+		assert.NoError(t, err)
+	})
+}
@@ -47,6 +47,7 @@ func TestUserService_Delete(t *testing.T) {
 		}
 
 		repository.EXPECT().List(userId).Return(foundUser, nil)
+		expectTransaction(repository)
 		repository.EXPECT().Delete(userId).Return(uuid.Nil, errors.New("Repository error"))
 
 		id, err := service.Delete(userId)
@@ -71,7 +72,9 @@ func TestUserService_Delete(t *testing.T) {
 		}
 
 		repository.EXPECT().List(userId).Return(foundUser, nil)
+		expectTransaction(repository)
 		repository.EXPECT().Delete(userId).Return(userId, nil)
+		repository.EXPECT().AddEvent(gomock.AssignableToTypeOf(domain.UserDeleted{})).Return(nil)
 		id, err := service.Delete(userId)
 
 		assert.EqualValues(t, userId, id)
@@ -0,0 +1,64 @@
+package test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/PedroPereiraN/go-hexagonal/config"
+	"github.com/PedroPereiraN/go-hexagonal/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptionKeys(t *testing.T) {
+	db, _, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	t.Run("totp_key_missing", func(t *testing.T) {
+		t.Setenv("TOTP_ENCRYPTION_KEY", "")
+
+		_, err := server.NewTwoFactorService(db, config.Load())
+
+		assert.EqualError(t, err, "TOTP_ENCRYPTION_KEY must be set to the base64 of a 32 byte key")
+	})
+
+	t.Run("totp_key_invalid", func(t *testing.T) {
+		t.Setenv("TOTP_ENCRYPTION_KEY", base64.StdEncoding.EncodeToString([]byte("too short")))
+
+		_, err := server.NewTwoFactorService(db, config.Load())
+
+		assert.EqualError(t, err, "TOTP_ENCRYPTION_KEY must be set to the base64 of a 32 byte key")
+	})
+
+	t.Run("totp_key", func(t *testing.T) {
+		t.Setenv("TOTP_ENCRYPTION_KEY", base64.StdEncoding.EncodeToString(make([]byte, 32)))
+
+		_, err := server.NewTwoFactorService(db, config.Load())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("signing_key_missing", func(t *testing.T) {
+		t.Setenv("JWT_SIGNING_ALGORITHM", "RS256")
+		t.Setenv("JWT_SIGNING_KEY_FILES", "")
+		t.Setenv("JWT_KEY_ENCRYPTION_KEY", "")
+
+		_, err := server.NewKeyRing(db, config.Load())
+
+		assert.EqualError(t, err, "JWT_KEY_ENCRYPTION_KEY must be set to the base64 of a 32 byte key")
+	})
+
+	t.Run("signing_key_not_needed_with_the_shared_secret", func(t *testing.T) {
+		t.Setenv("JWT_SIGNING_ALGORITHM", "HS256")
+		t.Setenv("JWT_KEY_ENCRYPTION_KEY", "")
+
+		_, err := server.NewKeyRing(db, config.Load())
+
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,67 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/controller"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/services"
+	"github.com/PedroPereiraN/go-hexagonal/tests/config"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestUserController_Export(t *testing.T) {
+	crtl := gomock.NewController(t)
+	defer crtl.Finish()
+	repository := mocks.NewMockUserRepository(crtl)
+	controller := controller.NewUserController(service.NewUserService(repository))
+
+	t.Run("invalid_date", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"createdFrom": {"TEST_ERROR"}}, "GET", nil)
+		controller.Export(context)
+
+		assert.EqualValues(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("invalid_format", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"format": {"xml"}}, "GET", nil)
+		controller.Export(context)
+
+		assert.EqualValues(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("export_csv", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		user := domain.UserDomain{Id: uuid.New(), Name: "Test name", Email: "test@email.com", Phone: "00000000000", Password: "hashedPass"}
+
+		repository.EXPECT().Iterate(domain.UserFilter{IncludeDeleted: true}).Return(&sliceUserIterator{users: []domain.UserDomain{user}}, nil)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"format": {"csv"}, "includeDeleted": {"true"}}, "GET", nil)
+		controller.Export(context)
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+		assert.True(t, strings.HasPrefix(recorder.Header().Get("Content-Disposition"), `attachment; filename="users-`))
+		assert.EqualValues(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+		assert.Contains(t, recorder.Body.String(), user.Id.String())
+		assert.NotContains(t, recorder.Body.String(), "hashedPass")
+	})
+}
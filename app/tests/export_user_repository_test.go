@@ -0,0 +1,67 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/repository"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserRepository_Iterate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	repository := repository.NewUserRepository(db)
+
+	t.Run("filters_and_streams_rows", func(t *testing.T) {
+		from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		first := uuid.New()
+		second := uuid.New()
+
+		mock.
+			ExpectQuery(`SELECT (.+) FROM users WHERE deletedAt IS NULL AND createdAt >= \$1 ORDER BY createdAt, id`).
+			WithArgs(from).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt", "passwordChangedAt", "role", "passwordChangeRequired", "status", "statusReason", "statusChangedBy", "statusChangedAt", "suspendedUntil", "lastLoginAt",
+			}).
+				AddRow(first, "First", "hashedPass", "first@email.com", "00000000000", "2025-01-02T03:04:05Z", nil, nil, nil, "user", false, "active", "", "", nil, nil, nil).
+				AddRow(second, "Second", "hashedPass", "second@email.com", "00000000001", "2025-01-03T03:04:05Z", nil, nil, nil, "user", false, "active", "", "", nil, nil, nil))
+
+		iterator, err := repository.Iterate(domain.UserFilter{CreatedFrom: from})
+		assert.NoError(t, err)
+
+		ids := []uuid.UUID{}
+
+		for iterator.Next() {
+			ids = append(ids, iterator.User().Id)
+		}
+
+		assert.NoError(t, iterator.Err())
+		assert.NoError(t, iterator.Close())
+		assert.EqualValues(t, []uuid.UUID{first, second}, ids)
+	})
+
+	t.Run("include_deleted", func(t *testing.T) {
+		mock.
+			ExpectQuery(`SELECT (.+) FROM users ORDER BY createdAt, id`).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt", "passwordChangedAt", "role", "passwordChangeRequired", "status", "statusReason", "statusChangedBy", "statusChangedAt", "suspendedUntil", "lastLoginAt",
+			}))
+
+		iterator, err := repository.Iterate(domain.UserFilter{IncludeDeleted: true})
+		assert.NoError(t, err)
+
+		assert.False(t, iterator.Next())
+		assert.NoError(t, iterator.Close())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
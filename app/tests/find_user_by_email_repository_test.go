@@ -42,11 +42,11 @@ func TestUserRepository_FindUserByEmail(t *testing.T) {
 		ExpectQuery("SELECT (.+) FROM users").
     WithArgs(userEmail).
     WillReturnRows(sqlmock.NewRows([]string{
-        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt",
+        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt", "passwordChangedAt", "role", "passwordChangeRequired", "status", "statusReason", "statusChangedBy", "statusChangedAt", "suspendedUntil", "lastLoginAt",
     }).AddRow(
         uuid.New(), "Test", "hashedPass", userEmail, "00000000000",
         "invalid-time-format",
-        nil, nil,
+        nil, nil, nil, "user", false, "active", "", "", nil, nil, nil,
     ))
 
 		uDomain, err := repository.FindUserByEmail(userEmail)
@@ -63,11 +63,11 @@ func TestUserRepository_FindUserByEmail(t *testing.T) {
 		ExpectQuery("SELECT (.+) FROM users").
     WithArgs(userEmail).
     WillReturnRows(sqlmock.NewRows([]string{
-        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt",
+        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt", "passwordChangedAt", "role", "passwordChangeRequired", "status", "statusReason", "statusChangedBy", "statusChangedAt", "suspendedUntil", "lastLoginAt",
     }).AddRow(
         uuid.New(), "Test", "hashedPass", userEmail, "00000000000",
         nil,
-        "invalid-time-format", nil,
+        "invalid-time-format", nil, nil, "user", false, "active", "", "", nil, nil, nil,
     ))
 
 		uDomain, err := repository.FindUserByEmail(userEmail)
@@ -84,11 +84,11 @@ func TestUserRepository_FindUserByEmail(t *testing.T) {
 		ExpectQuery("SELECT (.+) FROM users").
     WithArgs(userEmail).
     WillReturnRows(sqlmock.NewRows([]string{
-        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt",
+        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt", "passwordChangedAt", "role", "passwordChangeRequired", "status", "statusReason", "statusChangedBy", "statusChangedAt", "suspendedUntil", "lastLoginAt",
     }).AddRow(
         uuid.New(), "Test", "hashedPass", userEmail, "00000000000",
         nil,
-        nil, "invalid-time-format",
+        nil, "invalid-time-format", nil, "user", false, "active", "", "", nil, nil, nil,
     ))
 
 		uDomain, err := repository.FindUserByEmail(userEmail)
@@ -105,17 +105,19 @@ func TestUserRepository_FindUserByEmail(t *testing.T) {
 			Email: "test@email.com",
 			Phone: "00000000000",
 			Password: "hashedPass",
+			Role: domain.RoleUser,
+			Status: domain.UserStatus{State: domain.StatusActive},
 		}
 
 		mock.
 		ExpectQuery("SELECT (.+) FROM users").
     WithArgs(userData.Email).
     WillReturnRows(sqlmock.NewRows([]string{
-        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt",
+        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt", "passwordChangedAt", "role", "passwordChangeRequired", "status", "statusReason", "statusChangedBy", "statusChangedAt", "suspendedUntil", "lastLoginAt",
     }).AddRow(
         userData.Id, userData.Name,	userData.Password, userData.Email, userData.Phone,
         nil,
-        nil, nil,
+        nil, nil, nil, "user", false, "active", "", "", nil, nil, nil,
     ))
 
 		uDomain, err := repository.FindUserByEmail(userData.Email)
@@ -43,11 +43,11 @@ func TestUserRepository_FindUserByPhone(t *testing.T) {
 		ExpectQuery("SELECT (.+) FROM users").
     WithArgs(userPhone).
     WillReturnRows(sqlmock.NewRows([]string{
-        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt",
+        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt", "passwordChangedAt", "role", "passwordChangeRequired", "status", "statusReason", "statusChangedBy", "statusChangedAt", "suspendedUntil", "lastLoginAt",
     }).AddRow(
         uuid.New(), "Test", "hashedPass", "invalid@email.com", userPhone,
         "invalid-time-format",
-        nil, nil,
+        nil, nil, nil, "user", false, "active", "", "", nil, nil, nil,
     ))
 
 		uDomain, err := repository.FindUserByPhone(userPhone)
@@ -64,11 +64,11 @@ func TestUserRepository_FindUserByPhone(t *testing.T) {
 		ExpectQuery("SELECT (.+) FROM users").
     WithArgs(userPhone).
     WillReturnRows(sqlmock.NewRows([]string{
-        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt",
+        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt", "passwordChangedAt", "role", "passwordChangeRequired", "status", "statusReason", "statusChangedBy", "statusChangedAt", "suspendedUntil", "lastLoginAt",
     }).AddRow(
         uuid.New(), "Test", "hashedPass", "invalid@email.com", userPhone,
         nil,
-        "invalid-time-format", nil,
+        "invalid-time-format", nil, nil, "user", false, "active", "", "", nil, nil, nil,
     ))
 
 		uDomain, err := repository.FindUserByPhone(userPhone)
@@ -85,11 +85,11 @@ func TestUserRepository_FindUserByPhone(t *testing.T) {
 		ExpectQuery("SELECT (.+) FROM users").
     WithArgs(userPhone).
     WillReturnRows(sqlmock.NewRows([]string{
-        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt",
+        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt", "passwordChangedAt", "role", "passwordChangeRequired", "status", "statusReason", "statusChangedBy", "statusChangedAt", "suspendedUntil", "lastLoginAt",
     }).AddRow(
         uuid.New(), "Test", "hashedPass", "invalid@email.com", userPhone,
         nil,
-        nil, "invalid-time-format",
+        nil, "invalid-time-format", nil, "user", false, "active", "", "", nil, nil, nil,
     ))
 
 		uDomain, err := repository.FindUserByPhone(userPhone)
@@ -106,17 +106,19 @@ func TestUserRepository_FindUserByPhone(t *testing.T) {
 			Email: "test@email.com",
 			Phone: "00000000000",
 			Password: "hashedPass",
+			Role: domain.RoleUser,
+			Status: domain.UserStatus{State: domain.StatusActive},
 		}
 
 		mock.
 		ExpectQuery("SELECT (.+) FROM users").
     WithArgs(userData.Phone).
     WillReturnRows(sqlmock.NewRows([]string{
-        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt",
+        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt", "passwordChangedAt", "role", "passwordChangeRequired", "status", "statusReason", "statusChangedBy", "statusChangedAt", "suspendedUntil", "lastLoginAt",
     }).AddRow(
         userData.Id, userData.Name,	userData.Password, userData.Email, userData.Phone,
         nil,
-        nil, nil,
+        nil, nil, nil, "user", false, "active", "", "", nil, nil, nil,
     ))
 
 		uDomain, err := repository.FindUserByPhone(userData.Phone)
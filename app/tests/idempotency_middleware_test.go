@@ -0,0 +1,163 @@
+package test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/middleware"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/memory"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	router := gin.New()
+	router.Use(middleware.Idempotency(memory.NewIdempotencyRepository(), time.Hour, 100 * time.Millisecond))
+	router.POST("/user", func(c *gin.Context) {
+		calls.Add(1)
+		c.JSON(http.StatusOK, "created")
+	})
+	router.POST("/slow", func(c *gin.Context) {
+		<-release
+		c.JSON(http.StatusOK, "slow")
+	})
+	router.POST("/upload", func(c *gin.Context) {
+		calls.Add(1)
+		body, _ := io.ReadAll(c.Request.Body)
+		c.JSON(http.StatusOK, len(body))
+	})
+	router.POST("/fail", func(c *gin.Context) {
+		calls.Add(1)
+		c.JSON(http.StatusInternalServerError, "error")
+	})
+
+	send := func(path string, key string, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest("POST", path, strings.NewReader(body))
+
+		if key != "" {
+			request.Header.Set(middleware.IdempotencyKeyHeader, key)
+		}
+
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	t.Run("without_key", func(t *testing.T) {
+		calls.Store(0)
+
+		send("/user", "", `{"name":"test"}`)
+		send("/user", "", `{"name":"test"}`)
+
+		assert.EqualValues(t, 2, calls.Load())
+	})
+
+	t.Run("retry_is_replayed", func(t *testing.T) {
+		calls.Store(0)
+
+		first := send("/user", "key-1", `{"name":"test"}`)
+		second := send("/user", "key-1", `{"name":"test"}`)
+
+		assert.EqualValues(t, 1, calls.Load())
+		assert.EqualValues(t, http.StatusOK, second.Code)
+		assert.EqualValues(t, first.Body.String(), second.Body.String())
+		assert.EqualValues(t, "true", second.Header().Get("Idempotent-Replayed"))
+	})
+
+	t.Run("large_body", func(t *testing.T) {
+		calls.Store(0)
+
+		body := strings.Repeat("a", 3 << 20)
+
+		first := send("/upload", "key-upload", body)
+		second := send("/upload", "key-upload", body)
+
+		assert.EqualValues(t, 1, calls.Load())
+		assert.EqualValues(t, "3145728", first.Body.String())
+		assert.EqualValues(t, first.Body.String(), second.Body.String())
+
+		// the length is part of the fingerprint even past the hashed prefix
+		recorder := send("/upload", "key-upload", body + "a")
+
+		assert.EqualValues(t, http.StatusUnprocessableEntity, recorder.Code)
+	})
+
+	t.Run("different_body", func(t *testing.T) {
+		send("/user", "key-2", `{"name":"test"}`)
+		recorder := send("/user", "key-2", `{"name":"other"}`)
+
+		assert.EqualValues(t, http.StatusUnprocessableEntity, recorder.Code)
+	})
+
+	t.Run("concurrent_duplicate", func(t *testing.T) {
+		var wg sync.WaitGroup
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			send("/slow", "key-3", `{}`)
+		}()
+
+		time.Sleep(20 * time.Millisecond)
+		recorder := send("/slow", "key-3", `{}`)
+
+		close(release)
+		wg.Wait()
+
+		assert.EqualValues(t, http.StatusConflict, recorder.Code)
+	})
+
+	t.Run("server_error_is_not_stored", func(t *testing.T) {
+		calls.Store(0)
+
+		send("/fail", "key-4", `{}`)
+		send("/fail", "key-4", `{}`)
+
+		assert.EqualValues(t, 2, calls.Load())
+	})
+
+	// sendAs is send with the credential of a caller
+	sendAs := func(path string, key string, body string, header string, value string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest("POST", path, strings.NewReader(body))
+		request.Header.Set(middleware.IdempotencyKeyHeader, key)
+		request.Header.Set(header, value)
+
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	t.Run("key_belongs_to_the_caller", func(t *testing.T) {
+		calls.Store(0)
+
+		sendAs("/user", "key-5", `{"name":"test"}`, "Authorization", "Bearer first")
+		second := sendAs("/user", "key-5", `{"name":"test"}`, "Authorization", "Bearer second")
+		other := sendAs("/user", "key-5", `{"name":"other"}`, middleware.APIKeyHeader, "api-key")
+
+		assert.EqualValues(t, 3, calls.Load())
+		assert.Empty(t, second.Header().Get("Idempotent-Replayed"))
+		assert.EqualValues(t, http.StatusOK, other.Code)
+	})
+
+	t.Run("key_belongs_to_the_path", func(t *testing.T) {
+		calls.Store(0)
+
+		send("/user", "key-6", `{}`)
+		send("/fail", "key-6", `{}`)
+
+		assert.EqualValues(t, 2, calls.Load())
+	})
+}
@@ -0,0 +1,80 @@
+package test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/repository"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyRepository_Reserve(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	repository := repository.NewIdempotencyRepository(db)
+
+	record := domain.IdempotencyRecord{
+		Key: "key",
+		Fingerprint: "fingerprint",
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	t.Run("key_reserved", func(t *testing.T) {
+		mock.
+			ExpectQuery("INSERT INTO idempotency_keys (.+) ON CONFLICT (.+) DO NOTHING").
+			WithArgs(record.Key, record.Fingerprint, domain.IdempotencyPending, record.CreatedAt, record.ExpiresAt).
+			WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow(record.Key))
+
+		result, reserved, err := repository.Reserve(record)
+
+		assert.True(t, reserved)
+		assert.EqualValues(t, domain.IdempotencyPending, result.Status)
+		assert.NoError(t, err)
+	})
+
+	t.Run("key_already_taken", func(t *testing.T) {
+		mock.
+			ExpectQuery("INSERT INTO idempotency_keys (.+) ON CONFLICT (.+) DO NOTHING").
+			WillReturnError(sql.ErrNoRows)
+
+		mock.
+			ExpectQuery("SELECT (.+) FROM idempotency_keys WHERE key = (.+)").
+			WithArgs(record.Key).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"key", "fingerprint", "status", "responseStatus", "responseContentType", "responseBody", "createdAt", "expiresAt",
+			}).AddRow(
+				record.Key, record.Fingerprint, domain.IdempotencyCompleted, 200, "application/json", []byte(`"ok"`),
+				nil, "2999-01-01T00:00:00Z",
+			))
+
+		result, reserved, err := repository.Reserve(record)
+
+		assert.False(t, reserved)
+		assert.EqualValues(t, domain.IdempotencyCompleted, result.Status)
+		assert.EqualValues(t, 200, result.ResponseStatus)
+		assert.EqualValues(t, []byte(`"ok"`), result.ResponseBody)
+		assert.NoError(t, err)
+	})
+
+	t.Run("complete", func(t *testing.T) {
+		mock.
+			ExpectExec("UPDATE idempotency_keys SET (.+) WHERE key = (.+)").
+			WithArgs(record.Key, domain.IdempotencyCompleted, 201, "application/json", []byte(`"ok"`)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repository.Complete(record.Key, 201, "application/json", []byte(`"ok"`))
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
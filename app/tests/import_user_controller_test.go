@@ -0,0 +1,99 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/controller"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/model"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/tests/config"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestUserController_Import(t *testing.T) {
+	crtl := gomock.NewController(t)
+	defer crtl.Finish()
+	service := mocks.NewMockUserService(crtl)
+	controller := controller.NewUserController(service)
+
+	multipartFile := func(filename string, content string) (string, io.ReadCloser) {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("file", filename)
+		part.Write([]byte(content))
+		writer.Close()
+
+		return writer.FormDataContentType(), io.NopCloser(body)
+	}
+
+	t.Run("invalid_mode", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"mode": {"TEST_ERROR"}}, "POST", nil)
+		controller.Import(context)
+
+		assert.EqualValues(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("unknown_format", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		contentType, body := multipartFile("users.txt", "")
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{}, "POST", body)
+		context.Request.Header.Set("Content-Type", contentType)
+		controller.Import(context)
+
+		assert.EqualValues(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("import_success", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		contentType, body := multipartFile("users.csv", "name,email,phone,password\nTest name,test@email.com,00000000000,password@123\n")
+
+		id := uuid.New()
+
+		service.EXPECT().Import(gomock.Any(), domain.ImportAllOrNothing, false).DoAndReturn(func(rows domain.ImportRowReader, mode string, dryRun bool) (domain.ImportReport, error) {
+			row, err := rows.Next()
+
+			assert.NoError(t, err)
+			assert.EqualValues(t, "test@email.com", row.Email)
+
+			return domain.ImportReport{
+				Mode: mode,
+				Total: 1,
+				Created: 1,
+				Rows: []domain.ImportResult{{Row: 2, Id: id, Status: domain.ImportRowCreated}},
+			}, nil
+		})
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"mode": {domain.ImportAllOrNothing}}, "POST", body)
+		context.Request.Header.Set("Content-Type", contentType)
+		controller.Import(context)
+
+		var report model.ImportReportModel
+		json.Unmarshal(recorder.Body.Bytes(), &report)
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+		assert.EqualValues(t, 1, report.Created)
+		assert.EqualValues(t, id.String(), report.Rows[0].Id)
+	})
+}
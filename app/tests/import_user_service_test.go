@@ -0,0 +1,108 @@
+package test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/services"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+type sliceRowReader struct {
+	rows []domain.ImportRow
+}
+
+func (reader *sliceRowReader) Next() (domain.ImportRow, error) {
+	if len(reader.rows) == 0 {
+		return domain.ImportRow{}, io.EOF
+	}
+
+	row := reader.rows[0]
+	reader.rows = reader.rows[1:]
+
+	return row, nil
+}
+
+func TestUserService_Import(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repository := mocks.NewMockUserRepository(ctrl)
+	service := service.NewUserService(repository)
+
+	validRow := domain.ImportRow{Row: 2, Name: "Test name", Email: "test@email.com", Phone: "00000000000", Password: "password@123"}
+	invalidRow := domain.ImportRow{Row: 3, Problem: "invalid email"}
+
+	newReader := func() domain.ImportRowReader {
+		return &sliceRowReader{rows: []domain.ImportRow{validRow, invalidRow}}
+	}
+
+	expectCreate := func(id uuid.UUID) {
+		expectTransaction(repository)
+		repository.EXPECT().FindUserByPhone(validRow.Phone).Return(domain.UserDomain{}, errors.New("sql: no rows in result set"))
+		repository.EXPECT().FindUserByEmail(validRow.Email).Return(domain.UserDomain{}, errors.New("sql: no rows in result set"))
+		expectTransaction(repository)
+		repository.EXPECT().Create(gomock.Any()).Return(id, nil)
+		repository.EXPECT().AddEvent(gomock.AssignableToTypeOf(domain.UserCreated{})).Return(nil)
+	}
+
+	t.Run("invalid_mode", func(t *testing.T) {
+		_, err := service.Import(newReader(), "sometimes", false)
+
+		assert.EqualError(t, err, "Invalid import mode")
+	})
+
+	t.Run("best_effort", func(t *testing.T) {
+		id := uuid.New()
+		expectCreate(id)
+
+		report, err := service.Import(newReader(), domain.ImportBestEffort, false)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, report.Total)
+		assert.EqualValues(t, 1, report.Created)
+		assert.EqualValues(t, 1, report.Failed)
+		assert.EqualValues(t, domain.ImportResult{Row: 2, Id: id, Status: domain.ImportRowCreated}, report.Rows[0])
+		assert.EqualValues(t, domain.ImportResult{Row: 3, Status: domain.ImportRowFailed, Error: "invalid email"}, report.Rows[1])
+	})
+
+	t.Run("all_or_nothing_rolls_back", func(t *testing.T) {
+		expectTransaction(repository)
+		expectCreate(uuid.New())
+
+		report, err := service.Import(newReader(), domain.ImportAllOrNothing, false)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, report.Created)
+		assert.EqualValues(t, 1, report.Failed)
+		assert.EqualValues(t, domain.ImportResult{Row: 2, Status: domain.ImportRowRolledBack}, report.Rows[0])
+	})
+
+	t.Run("dry_run", func(t *testing.T) {
+		expectTransaction(repository)
+		expectCreate(uuid.New())
+
+		report, err := service.Import(newReader(), domain.ImportBestEffort, true)
+
+		assert.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.EqualValues(t, 0, report.Created)
+		assert.EqualValues(t, domain.ImportRowValid, report.Rows[0].Status)
+	})
+
+	t.Run("all_or_nothing_success", func(t *testing.T) {
+		id := uuid.New()
+		expectTransaction(repository)
+		expectCreate(id)
+
+		report, err := service.Import(&sliceRowReader{rows: []domain.ImportRow{validRow}}, domain.ImportAllOrNothing, false)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, report.Created)
+		assert.EqualValues(t, id, report.Rows[0].Id)
+	})
+}
@@ -0,0 +1,40 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/services"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestUserService_IssueToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repository := mocks.NewMockUserRepository(ctrl)
+	service := service.NewUserService(repository)
+
+	t.Run("user_not_found", func(t *testing.T) {
+		userId := uuid.New()
+
+		repository.EXPECT().List(userId).Return(domain.UserDomain{}, errors.New("sql: no rows in result set"))
+		token, err := service.IssueToken(userId, domain.Client{})
+
+		assert.EqualValues(t, "", token)
+		assert.EqualError(t, err, "sql: no rows in result set")
+	})
+
+	t.Run("issue_token_success", func(t *testing.T) {
+		userId := uuid.New()
+
+		repository.EXPECT().List(userId).Return(domain.UserDomain{Id: userId, Email: "test@email.com"}, nil)
+		token, err := service.IssueToken(userId, domain.Client{})
+
+		assert.NotEmpty(t, token)
+		assert.NoError(t, err)
+	})
+}
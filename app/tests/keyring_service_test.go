@@ -0,0 +1,366 @@
+package test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/cipher"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/services"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestStaticKeyRing(t *testing.T) {
+	generate := func(t *testing.T, algorithm string) crypto.Signer {
+		key, err := domain.GenerateSigningKey(algorithm)
+
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when generating the key", err.Error())
+		}
+
+		return key
+	}
+
+	for _, algorithm := range domain.AsymmetricSigningAlgorithms {
+		t.Run("sign_and_parse_" + algorithm, func(t *testing.T) {
+			keyRing, err := service.NewStaticKeyRing(generate(t, algorithm))
+
+			assert.NoError(t, err)
+			assert.EqualValues(t, []string{algorithm}, keyRing.Algorithms())
+
+			token, err := keyRing.Sign(jwt.MapClaims{"sub": "john", "exp": time.Now().Add(time.Minute).Unix()})
+
+			assert.NoError(t, err)
+
+			claims := jwt.MapClaims{}
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+
+			assert.NoError(t, err)
+			assert.EqualValues(t, algorithm, parsed.Method.Alg())
+			assert.EqualValues(t, keyRing.PublicKeys()[0].Kid, parsed.Header["kid"])
+
+			assert.NoError(t, keyRing.Parse(token, claims, jwt.WithExpirationRequired()))
+			assert.EqualValues(t, "john", claims["sub"])
+
+			other, err := service.NewStaticKeyRing(generate(t, algorithm))
+
+			assert.NoError(t, err)
+			assert.Error(t, other.Parse(token, jwt.MapClaims{}))
+		})
+	}
+
+	t.Run("previous_keys_only_verify", func(t *testing.T) {
+		current := generate(t, domain.SigningES256)
+		previous := generate(t, domain.SigningEdDSA)
+
+		oldKeyRing, _ := service.NewStaticKeyRing(previous)
+		keyRing, err := service.NewStaticKeyRing(current, previous)
+
+		assert.NoError(t, err)
+		assert.Len(t, keyRing.PublicKeys(), 2)
+		assert.EqualValues(t, []string{domain.SigningES256, domain.SigningEdDSA}, keyRing.Algorithms())
+
+		token, _ := oldKeyRing.Sign(jwt.MapClaims{"sub": "john"})
+
+		assert.NoError(t, keyRing.Parse(token, jwt.MapClaims{}))
+
+		token, _ = keyRing.Sign(jwt.MapClaims{"sub": "john"})
+		parsed, _, _ := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+
+		assert.EqualValues(t, domain.SigningES256, parsed.Method.Alg())
+	})
+
+	t.Run("public_key_as_hmac_secret", func(t *testing.T) {
+		private := generate(t, domain.SigningRS256)
+		keyRing, _ := service.NewStaticKeyRing(private)
+
+		der, _ := x509.MarshalPKIXPublicKey(private.Public())
+		forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "john"})
+		forged.Header["kid"] = keyRing.PublicKeys()[0].Kid
+
+		token, _ := forged.SignedString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+		assert.Error(t, keyRing.Parse(token, jwt.MapClaims{}))
+	})
+
+	t.Run("without_keys", func(t *testing.T) {
+		_, err := service.NewStaticKeyRing()
+
+		assert.EqualError(t, err, "Inform at least one signing key")
+	})
+
+	t.Run("user_tokens", func(t *testing.T) {
+		keyRing, _ := service.NewStaticKeyRing(generate(t, domain.SigningRS256))
+
+		defer service.SetKeyRing(service.CurrentKeyRing())
+		service.SetKeyRing(keyRing)
+
+		users := mocks.NewMockUserRepository(gomock.NewController(t))
+		uService := service.NewUserService(users, service.WithIssuer("https://id.example.com"))
+		id := uuid.New()
+
+		users.EXPECT().List(id).Return(domain.UserDomain{Id: id, Email: "john@email.com"}, nil).Times(2)
+
+		token, err := uService.IssueToken(id, domain.Client{})
+
+		assert.NoError(t, err)
+
+		identity, err := uService.Authenticate(token)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, id, identity.UserId)
+
+		claims := jwt.MapClaims{}
+		jwt.NewParser().ParseUnverified(token, claims)
+
+		assert.EqualValues(t, "https://id.example.com", claims["iss"])
+		assert.NotNil(t, claims["iat"])
+
+		legacy, _ := service.NewHMACKeyRing([]byte("super-secret")).Sign(jwt.MapClaims{"id": id.String(), "exp": time.Now().Add(time.Minute).Unix()})
+
+		_, err = uService.Authenticate(legacy)
+
+		assert.EqualError(t, err, "Invalid token")
+	})
+}
+
+func TestRotatingKeyRing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repository := mocks.NewMockSigningKeyRepository(ctrl)
+
+	keyCipher, err := cipher.NewAESGCMCipher(bytes.Repeat([]byte{1}, 32))
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when creating the cipher", err.Error())
+	}
+
+	rotation := domain.KeyRotation{Interval: 30 * 24 * time.Hour, Overlap: 48 * time.Hour}
+
+	// stored returns a key as it's read from the repository
+	stored := func(t *testing.T, createdAt time.Time, retiresAt time.Time) domain.SigningKey {
+		private, _ := domain.GenerateSigningKey(domain.SigningES256)
+		key, _ := domain.NewSigningKey(private)
+		der, _ := x509.MarshalPKCS8PrivateKey(private)
+
+		encrypted, err := keyCipher.Encrypt(der)
+
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when encrypting the key", err.Error())
+		}
+
+		return domain.SigningKey{
+			Id: key.Id,
+			Algorithm: key.Algorithm,
+			EncryptedKey: encrypted,
+			CreatedAt: createdAt,
+			RetiresAt: retiresAt,
+			ExpiresAt: retiresAt.Add(rotation.Overlap),
+		}
+	}
+
+	kid := func(token string) any {
+		parsed, _, _ := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+
+		return parsed.Header["kid"]
+	}
+
+	t.Run("invalid_algorithm", func(t *testing.T) {
+		_, err := service.NewKeyRing(repository, keyCipher, domain.SigningHS256, rotation)
+
+		assert.EqualError(t, err, "Unsupported signing algorithm: HS256")
+	})
+
+	t.Run("first_key", func(t *testing.T) {
+		keyRing, _ := service.NewKeyRing(repository, keyCipher, domain.SigningES256, rotation)
+		now := time.Now()
+
+		var created domain.SigningKey
+
+		repository.EXPECT().DeleteExpiredSigningKeys(now).Return(int64(0), nil)
+		repository.EXPECT().ListSigningKeys(now).Return([]domain.SigningKey{}, nil)
+		repository.EXPECT().CreateSigningKey(gomock.Any()).DoAndReturn(func(key domain.SigningKey) error {
+			created = key
+
+			return nil
+		})
+		repository.EXPECT().ListSigningKeys(now).DoAndReturn(func(time.Time) ([]domain.SigningKey, error) {
+			return []domain.SigningKey{created}, nil
+		})
+
+		assert.NoError(t, keyRing.Rotate(now))
+		assert.EqualValues(t, domain.SigningES256, created.Algorithm)
+		assert.EqualValues(t, now.Add(rotation.Interval), created.RetiresAt)
+		assert.EqualValues(t, now.Add(rotation.Interval + rotation.Overlap), created.ExpiresAt)
+
+		// only the ciphertext of the private key is stored
+		_, err := x509.ParsePKCS8PrivateKey([]byte(created.EncryptedKey))
+
+		assert.Error(t, err)
+
+		token, err := keyRing.Sign(jwt.MapClaims{"sub": "john"})
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, created.Id, kid(token))
+		assert.NoError(t, keyRing.Parse(token, jwt.MapClaims{}))
+	})
+
+	t.Run("current_key_not_about_to_retire", func(t *testing.T) {
+		keyRing, _ := service.NewKeyRing(repository, keyCipher, domain.SigningES256, rotation)
+		now := time.Now()
+		current := stored(t, now.Add(-time.Hour), now.Add(rotation.Interval))
+
+		repository.EXPECT().DeleteExpiredSigningKeys(now).Return(int64(0), nil)
+		repository.EXPECT().ListSigningKeys(now).Return([]domain.SigningKey{current}, nil)
+
+		assert.NoError(t, keyRing.Rotate(now))
+		assert.Len(t, keyRing.PublicKeys(), 1)
+	})
+
+	t.Run("next_key_published_before_it_signs", func(t *testing.T) {
+		keyRing, _ := service.NewKeyRing(repository, keyCipher, domain.SigningES256, rotation)
+		now := time.Now()
+		current := stored(t, now.Add(-rotation.Interval), now.Add(time.Hour))
+
+		var created domain.SigningKey
+
+		repository.EXPECT().DeleteExpiredSigningKeys(now).Return(int64(1), nil)
+		repository.EXPECT().ListSigningKeys(now).Return([]domain.SigningKey{current}, nil)
+		repository.EXPECT().CreateSigningKey(gomock.Any()).DoAndReturn(func(key domain.SigningKey) error {
+			created = key
+
+			return nil
+		})
+		repository.EXPECT().ListSigningKeys(now).DoAndReturn(func(time.Time) ([]domain.SigningKey, error) {
+			return []domain.SigningKey{current, created}, nil
+		})
+
+		assert.NoError(t, keyRing.Rotate(now))
+		assert.EqualValues(t, current.RetiresAt.Add(rotation.Interval), created.RetiresAt)
+		assert.Len(t, keyRing.PublicKeys(), 2)
+
+		token, err := keyRing.Sign(jwt.MapClaims{"sub": "john"})
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, current.Id, kid(token))
+	})
+
+	t.Run("retired_key_still_verifies", func(t *testing.T) {
+		keyRing, _ := service.NewKeyRing(repository, keyCipher, domain.SigningES256, rotation)
+		now := time.Now()
+		retired := stored(t, now.Add(-rotation.Interval), now.Add(-time.Hour))
+		current := stored(t, now.Add(-rotation.Overlap), now.Add(rotation.Interval))
+
+		repository.EXPECT().DeleteExpiredSigningKeys(now).Return(int64(0), nil)
+		repository.EXPECT().ListSigningKeys(now).Return([]domain.SigningKey{retired, current}, nil)
+
+		assert.NoError(t, keyRing.Rotate(now))
+
+		token, _ := keyRing.Sign(jwt.MapClaims{"sub": "john"})
+
+		assert.EqualValues(t, current.Id, kid(token))
+		assert.Len(t, keyRing.PublicKeys(), 2)
+	})
+
+	t.Run("undecryptable_key", func(t *testing.T) {
+		keyRing, _ := service.NewKeyRing(repository, keyCipher, domain.SigningES256, rotation)
+		now := time.Now()
+		key := stored(t, now, now.Add(rotation.Interval))
+		key.EncryptedKey = "v1:AAAA"
+
+		repository.EXPECT().DeleteExpiredSigningKeys(now).Return(int64(0), nil)
+		repository.EXPECT().ListSigningKeys(now).Return([]domain.SigningKey{key}, nil)
+
+		assert.EqualError(t, keyRing.Rotate(now), "Can't decrypt the signing key " + key.Id)
+
+		_, err := keyRing.Sign(jwt.MapClaims{"sub": "john"})
+
+		assert.EqualError(t, err, "No signing key available")
+	})
+}
+
+func TestParseSigningKeyPEM(t *testing.T) {
+	encode := func(blockType string, der []byte) []byte {
+		return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	}
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	edKey, _ := domain.GenerateSigningKey(domain.SigningEdDSA)
+
+	t.Run("formats", func(t *testing.T) {
+		ecDER, _ := x509.MarshalECPrivateKey(ecKey)
+		edDER, _ := x509.MarshalPKCS8PrivateKey(edKey)
+
+		cases := map[string][]byte{
+			domain.SigningRS256: encode("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)),
+			domain.SigningES256: encode("EC PRIVATE KEY", ecDER),
+			domain.SigningEdDSA: encode("PRIVATE KEY", edDER),
+		}
+
+		for algorithm, data := range cases {
+			key, err := domain.ParseSigningKeyPEM(data)
+
+			assert.NoError(t, err)
+
+			signingKey, err := domain.NewSigningKey(key)
+
+			assert.NoError(t, err)
+			assert.EqualValues(t, algorithm, signingKey.Algorithm)
+		}
+	})
+
+	t.Run("weak_keys", func(t *testing.T) {
+		weak, _ := rsa.GenerateKey(rand.Reader, 1024)
+
+		_, err := domain.ParseSigningKeyPEM(encode("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(weak)))
+
+		assert.EqualError(t, err, "RSA signing keys must have at least 2048 bits")
+
+		p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		der, _ := x509.MarshalECPrivateKey(p384)
+
+		_, err = domain.ParseSigningKeyPEM(encode("EC PRIVATE KEY", der))
+
+		assert.EqualError(t, err, "EC signing keys must use the P-256 curve")
+	})
+
+	t.Run("not_a_private_key", func(t *testing.T) {
+		_, err := domain.ParseSigningKeyPEM([]byte("not a pem"))
+
+		assert.EqualError(t, err, "No PEM block found")
+
+		der, _ := x509.MarshalPKIXPublicKey(ecKey.Public())
+
+		_, err = domain.ParseSigningKeyPEM(encode("PUBLIC KEY", der))
+
+		assert.EqualError(t, err, "Unsupported PEM block: PUBLIC KEY")
+	})
+
+	t.Run("thumbprint", func(t *testing.T) {
+		// RFC 7638 section 3.1
+		n := "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw"
+		modulus, _ := jwt.NewParser().DecodeSegment(n)
+
+		key := &rsa.PrivateKey{PublicKey: rsa.PublicKey{N: new(big.Int).SetBytes(modulus), E: 65537}}
+
+		signingKey, err := domain.NewSigningKey(key)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", signingKey.Id)
+	})
+}
@@ -3,6 +3,7 @@ package test
 import (
 	"errors"
 	"testing"
+	"time"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/PedroPereiraN/go-hexagonal/adapter/output/repository"
 	"github.com/PedroPereiraN/go-hexagonal/domain"
@@ -44,11 +45,11 @@ func TestUserRepository_List(t *testing.T) {
 		ExpectQuery("SELECT (.+) FROM users").
     WithArgs(userId).
     WillReturnRows(sqlmock.NewRows([]string{
-        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt",
+        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt", "passwordChangedAt", "role", "passwordChangeRequired", "status", "statusReason", "statusChangedBy", "statusChangedAt", "suspendedUntil", "lastLoginAt",
     }).AddRow(
         userId, "Test", "hashedPass", "invalid@email.com", "00000000000",
         "invalid-time-format",
-        nil, nil,
+        nil, nil, nil, "user", false, "active", "", "", nil, nil, nil,
     ))
 
 		uDomain, err := repository.List(userId)
@@ -65,11 +66,11 @@ func TestUserRepository_List(t *testing.T) {
 		ExpectQuery("SELECT (.+) FROM users").
     WithArgs(userId).
     WillReturnRows(sqlmock.NewRows([]string{
-        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt",
+        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt", "passwordChangedAt", "role", "passwordChangeRequired", "status", "statusReason", "statusChangedBy", "statusChangedAt", "suspendedUntil", "lastLoginAt",
     }).AddRow(
         userId, "Test", "hashedPass", "invalid@email.com", "00000000000",
         nil,
-        "invalid-time-format", nil,
+        "invalid-time-format", nil, nil, "user", false, "active", "", "", nil, nil, nil,
     ))
 
 		uDomain, err := repository.List(userId)
@@ -86,11 +87,11 @@ func TestUserRepository_List(t *testing.T) {
 		ExpectQuery("SELECT (.+) FROM users").
     WithArgs(userId).
     WillReturnRows(sqlmock.NewRows([]string{
-        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt",
+        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt", "passwordChangedAt", "role", "passwordChangeRequired", "status", "statusReason", "statusChangedBy", "statusChangedAt", "suspendedUntil", "lastLoginAt",
     }).AddRow(
         userId, "Test", "hashedPass", "invalid@email.com", "00000000000",
         nil,
-        nil, "invalid-time-format",
+        nil, "invalid-time-format", nil, "user", false, "active", "", "", nil, nil, nil,
     ))
 
 		uDomain, err := repository.List(userId)
@@ -107,17 +108,19 @@ func TestUserRepository_List(t *testing.T) {
 			Email: "test@email.com",
 			Phone: "00000000000",
 			Password: "hashedPass",
+			Role: domain.RoleUser,
+			Status: domain.UserStatus{State: domain.StatusActive},
 		}
 
 		mock.
 		ExpectQuery("SELECT (.+) FROM users").
     WithArgs(userData.Id).
     WillReturnRows(sqlmock.NewRows([]string{
-        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt",
+        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt", "passwordChangedAt", "role", "passwordChangeRequired", "status", "statusReason", "statusChangedBy", "statusChangedAt", "suspendedUntil", "lastLoginAt",
     }).AddRow(
         userData.Id, userData.Name,	userData.Password, userData.Email, userData.Phone,
         nil,
-        nil, nil,
+        nil, nil, nil, "user", false, "active", "", "", nil, nil, nil,
     ))
 
 		uDomain, err := repository.List(userData.Id)
@@ -125,4 +128,26 @@ func TestUserRepository_List(t *testing.T) {
 		assert.EqualValues(t, userData, uDomain)
 		assert.NoError(t, err)
 	})
+
+	t.Run("list_user_with_password_changed_at", func(t *testing.T) {
+		userId := uuid.New()
+
+		mock.
+		ExpectQuery("SELECT (.+), passwordChangedAt, role, passwordChangeRequired, status, statusReason, statusChangedBy, statusChangedAt, suspendedUntil, lastLoginAt FROM users").
+    WithArgs(userId).
+    WillReturnRows(sqlmock.NewRows([]string{
+        "id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt", "passwordChangedAt", "role", "passwordChangeRequired", "status", "statusReason", "statusChangedBy", "statusChangedAt", "suspendedUntil", "lastLoginAt",
+    }).AddRow(
+        userId, "Test", "hashedPass", "test@email.com", "00000000000",
+        nil,
+        nil, nil, "2025-01-02T03:04:05Z", "admin", true, "active", "", "", nil, nil, nil,
+    ))
+
+		uDomain, err := repository.List(userId)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), uDomain.PasswordChangedAt)
+		assert.EqualValues(t, domain.RoleAdmin, uDomain.Role)
+		assert.True(t, uDomain.PasswordChangeRequired)
+	})
 }
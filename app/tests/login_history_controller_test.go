@@ -0,0 +1,100 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/controller"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/middleware"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestLoginHistoryController(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	uService := mocks.NewMockUserService(ctrl)
+	sController := controller.NewSessionController(uService)
+
+	identity := domain.Identity{UserId: uuid.New(), Email: "admin@email.com", SessionId: uuid.New(), Role: domain.RoleAdmin}
+
+	router := gin.New()
+	authenticator := authenticatorFunc(func(token string) (domain.Identity, error) {
+		return identity, nil
+	})
+
+	v1 := router.Group("/v1", middleware.Authenticate(authenticator, authenticator))
+	v1.GET("/me/logins", sController.ListMyLogins)
+	v1.GET("/users/:id/logins", middleware.RequireRole(domain.RoleAdmin), sController.ListLogins)
+
+	send := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest("GET", path, nil)
+		request.Header.Set("Authorization", "Bearer token")
+
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	userId := uuid.New()
+
+	t.Run("list_mine", func(t *testing.T) {
+		attemptId := uuid.New()
+
+		uService.EXPECT().ListLogins(identity.UserId, 0).Return([]domain.LoginAttempt{
+			{Id: attemptId, UserId: identity.UserId, Outcome: domain.LoginFailed, FailureReason: "Wrong password", IP: "127.0.0.1", UserAgent: "curl/8.0", CreatedAt: now},
+		}, nil)
+
+		recorder := send("/v1/me/logins")
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `[{"id":"` + attemptId.String() + `","outcome":"failure","failureReason":"Wrong password","ip":"127.0.0.1","userAgent":"curl/8.0","createdAt":"2025-01-02T03:04:05Z"}]`, recorder.Body.String())
+	})
+
+	t.Run("list_mine_invalid_limit", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusBadRequest, send("/v1/me/logins?limit=many").Code)
+	})
+
+	t.Run("list_mine_limit_too_big", func(t *testing.T) {
+		uService.EXPECT().ListLogins(identity.UserId, 500).Return([]domain.LoginAttempt{}, errors.New("Invalid limit"))
+
+		assert.EqualValues(t, http.StatusBadRequest, send("/v1/me/logins?limit=500").Code)
+	})
+
+	t.Run("list_invalid_id", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusBadRequest, send("/v1/users/invalid/logins").Code)
+	})
+
+	t.Run("list_user_not_found", func(t *testing.T) {
+		uService.EXPECT().ListLogins(userId, 10).Return([]domain.LoginAttempt{}, errors.New("sql: no rows in result set"))
+
+		assert.EqualValues(t, http.StatusNotFound, send("/v1/users/" + userId.String() + "/logins?limit=10").Code)
+	})
+
+	t.Run("list_empty", func(t *testing.T) {
+		uService.EXPECT().ListLogins(userId, 0).Return([]domain.LoginAttempt{}, nil)
+
+		recorder := send("/v1/users/" + userId.String() + "/logins")
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `[]`, recorder.Body.String())
+	})
+
+	t.Run("list_requires_admin", func(t *testing.T) {
+		identity.Role = domain.RoleUser
+		defer func() { identity.Role = domain.RoleAdmin }()
+
+		assert.EqualValues(t, http.StatusForbidden, send("/v1/users/" + userId.String() + "/logins").Code)
+	})
+}
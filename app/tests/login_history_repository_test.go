@@ -0,0 +1,128 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/repository"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginHistoryRepository(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	lhRepository := repository.NewLoginHistoryRepository(db)
+
+	userId := uuid.New()
+	now := time.Now().UTC()
+
+	t.Run("add_cuts_user_agent", func(t *testing.T) {
+		attempt := domain.LoginAttempt{
+			Id: uuid.New(),
+			UserId: userId,
+			Outcome: domain.LoginFailed,
+			FailureReason: "Wrong password",
+			IP: "127.0.0.1",
+			UserAgent: strings.Repeat("a", 300),
+			CreatedAt: now,
+		}
+
+		mock.
+			ExpectExec("INSERT INTO login_attempts").
+			WithArgs(attempt.Id, userId, domain.LoginFailed, "Wrong password", "127.0.0.1", strings.Repeat("a", 255), now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, lhRepository.AddLoginAttempt(attempt))
+	})
+
+	t.Run("add_unknown_user", func(t *testing.T) {
+		attempt := domain.LoginAttempt{
+			Id: uuid.New(),
+			Outcome: domain.LoginFailed,
+			FailureReason: domain.LoginUnknownUser,
+			IP: "127.0.0.1",
+			UserAgent: "curl/8.0",
+			CreatedAt: now,
+		}
+
+		mock.
+			ExpectExec("INSERT INTO login_attempts").
+			WithArgs(attempt.Id, nil, domain.LoginFailed, domain.LoginUnknownUser, "127.0.0.1", "curl/8.0", now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, lhRepository.AddLoginAttempt(attempt))
+	})
+
+	t.Run("list_error", func(t *testing.T) {
+		mock.
+			ExpectQuery("SELECT (.+) FROM login_attempts WHERE userId = (.+) ORDER BY createdAt DESC LIMIT (.+)").
+			WithArgs(userId, 20).
+			WillReturnError(errors.New("database error"))
+
+		attempts, err := lhRepository.ListLoginAttempts(userId, 20)
+
+		assert.Empty(t, attempts)
+		assert.EqualError(t, err, "database error")
+	})
+
+	t.Run("list", func(t *testing.T) {
+		id := uuid.New()
+
+		rows := sqlmock.NewRows([]string{"id", "userId", "outcome", "failureReason", "ip", "userAgent", "createdAt"}).
+			AddRow(id, userId, domain.LoginSucceeded, "", "127.0.0.1", "curl/8.0", now)
+
+		mock.
+			ExpectQuery("SELECT (.+) FROM login_attempts WHERE userId = (.+) ORDER BY createdAt DESC LIMIT (.+)").
+			WithArgs(userId, 20).
+			WillReturnRows(rows)
+
+		attempts, err := lhRepository.ListLoginAttempts(userId, 20)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, []domain.LoginAttempt{{
+			Id: id,
+			UserId: userId,
+			Outcome: domain.LoginSucceeded,
+			IP: "127.0.0.1",
+			UserAgent: "curl/8.0",
+			CreatedAt: now,
+		}}, attempts)
+	})
+
+	t.Run("delete_before", func(t *testing.T) {
+		before := now.Add(-24 * time.Hour)
+
+		mock.
+			ExpectExec("DELETE FROM login_attempts WHERE createdAt < (.+)").
+			WithArgs(before).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+
+		deleted, err := lhRepository.DeleteLoginAttempts(before)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, 3, deleted)
+	})
+
+	t.Run("update_last_login", func(t *testing.T) {
+		uRepository := repository.NewUserRepository(db)
+
+		mock.
+			ExpectExec("UPDATE users SET lastLoginAt = (.+) WHERE id = (.+)").
+			WithArgs(userId, now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, uRepository.UpdateLastLogin(userId, now))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
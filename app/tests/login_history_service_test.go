@@ -0,0 +1,186 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/services"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestUserService_LoginHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repository := mocks.NewMockUserRepository(ctrl)
+	tfRepository := mocks.NewMockTwoFactorRepository(ctrl)
+	history := mocks.NewMockLoginHistoryRepository(ctrl)
+	uService := service.NewUserService(repository, service.WithTwoFactor(tfRepository), service.WithLoginHistory(history, 24 * time.Hour))
+
+	user, err := domain.CreateUser(uuid.New(), "Test name", "test@email.com", "00000000000", "password@123", time.Time{}, time.Time{}, time.Time{})
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when creating the user", err.Error())
+	}
+
+	client := domain.Client{IP: "127.0.0.1", UserAgent: "curl/8.0"}
+
+	t.Run("unknown_email", func(t *testing.T) {
+		repository.EXPECT().FindUserByEmail("unknown@email.com").Return(domain.UserDomain{}, errors.New("sql: no rows in result set"))
+		history.EXPECT().AddLoginAttempt(gomock.Any()).DoAndReturn(func(attempt domain.LoginAttempt) error {
+			assert.EqualValues(t, uuid.Nil, attempt.UserId)
+			assert.EqualValues(t, domain.LoginFailed, attempt.Outcome)
+			assert.EqualValues(t, domain.LoginUnknownUser, attempt.FailureReason)
+			assert.EqualValues(t, client.IP, attempt.IP)
+
+			return nil
+		})
+
+		_, err := uService.Login("unknown@email.com", "password@123", client)
+
+		assert.EqualError(t, err, "sql: no rows in result set")
+	})
+
+	t.Run("wrong_password", func(t *testing.T) {
+		repository.EXPECT().FindUserByEmail(user.Email).Return(user, nil)
+		history.EXPECT().AddLoginAttempt(gomock.Any()).DoAndReturn(func(attempt domain.LoginAttempt) error {
+			assert.EqualValues(t, user.Id, attempt.UserId)
+			assert.EqualValues(t, domain.LoginFailed, attempt.Outcome)
+			assert.EqualValues(t, "Wrong password", attempt.FailureReason)
+			assert.EqualValues(t, client.IP, attempt.IP)
+			assert.EqualValues(t, client.UserAgent, attempt.UserAgent)
+
+			return nil
+		})
+
+		_, err := uService.Login(user.Email, "wrong@123", client)
+
+		assert.EqualError(t, err, "Wrong password")
+	})
+
+	t.Run("suspended_account", func(t *testing.T) {
+		suspended := user
+		suspended.Status = domain.UserStatus{State: domain.StatusSuspended, Reason: "spam"}
+
+		repository.EXPECT().FindUserByEmail(user.Email).Return(suspended, nil)
+		history.EXPECT().AddLoginAttempt(gomock.Any()).DoAndReturn(func(attempt domain.LoginAttempt) error {
+			assert.EqualValues(t, domain.LoginFailed, attempt.Outcome)
+			assert.EqualValues(t, "Account is suspended", attempt.FailureReason)
+
+			return nil
+		})
+
+		_, err := uService.Login(user.Email, "password@123", client)
+
+		assert.EqualError(t, err, "Account is suspended")
+	})
+
+	t.Run("second_factor_required", func(t *testing.T) {
+		repository.EXPECT().FindUserByEmail(user.Email).Return(user, nil)
+		tfRepository.EXPECT().FindTwoFactor(user.Id).Return(domain.TwoFactor{UserId: user.Id, ConfirmedAt: time.Now()}, nil)
+		history.EXPECT().AddLoginAttempt(gomock.Any()).DoAndReturn(func(attempt domain.LoginAttempt) error {
+			assert.EqualValues(t, domain.LoginChallenged, attempt.Outcome)
+			assert.Empty(t, attempt.FailureReason)
+
+			return nil
+		})
+
+		result, err := uService.Login(user.Email, "password@123", client)
+
+		assert.NoError(t, err)
+		assert.True(t, result.TwoFactorRequired)
+	})
+
+	t.Run("success_updates_last_login", func(t *testing.T) {
+		repository.EXPECT().FindUserByEmail(user.Email).Return(user, nil)
+		tfRepository.EXPECT().FindTwoFactor(user.Id).Return(domain.TwoFactor{}, errors.New("sql: no rows in result set"))
+		repository.EXPECT().UpdateLastLogin(user.Id, gomock.Any()).DoAndReturn(func(id uuid.UUID, lastLoginAt time.Time) error {
+			assert.WithinDuration(t, time.Now(), lastLoginAt, time.Minute)
+
+			return nil
+		})
+		history.EXPECT().AddLoginAttempt(gomock.Any()).DoAndReturn(func(attempt domain.LoginAttempt) error {
+			assert.EqualValues(t, domain.LoginSucceeded, attempt.Outcome)
+
+			return nil
+		})
+
+		result, err := uService.Login(user.Email, "password@123", client)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, result.Token)
+	})
+
+	t.Run("recording_failure_does_not_fail_login", func(t *testing.T) {
+		repository.EXPECT().FindUserByEmail(user.Email).Return(user, nil)
+		tfRepository.EXPECT().FindTwoFactor(user.Id).Return(domain.TwoFactor{}, errors.New("sql: no rows in result set"))
+		repository.EXPECT().UpdateLastLogin(user.Id, gomock.Any()).Return(errors.New("database error"))
+		history.EXPECT().AddLoginAttempt(gomock.Any()).Return(errors.New("database error"))
+
+		result, err := uService.Login(user.Email, "password@123", client)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, result.Token)
+	})
+
+	t.Run("finish_login_after_second_factor", func(t *testing.T) {
+		repository.EXPECT().List(user.Id).Return(user, nil)
+		repository.EXPECT().UpdateLastLogin(user.Id, gomock.Any()).Return(nil)
+		history.EXPECT().AddLoginAttempt(gomock.Any()).DoAndReturn(func(attempt domain.LoginAttempt) error {
+			assert.EqualValues(t, domain.LoginSucceeded, attempt.Outcome)
+			assert.EqualValues(t, client.IP, attempt.IP)
+
+			return nil
+		})
+
+		result, err := uService.FinishLogin(user.Id, client)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, result.Token)
+	})
+
+	t.Run("list_invalid_limit", func(t *testing.T) {
+		attempts, err := uService.ListLogins(user.Id, service.MaxLoginHistoryLimit + 1)
+
+		assert.Empty(t, attempts)
+		assert.EqualError(t, err, "Invalid limit")
+	})
+
+	t.Run("list_user_not_found", func(t *testing.T) {
+		repository.EXPECT().List(user.Id).Return(domain.UserDomain{}, errors.New("sql: no rows in result set"))
+
+		attempts, err := uService.ListLogins(user.Id, 0)
+
+		assert.Empty(t, attempts)
+		assert.EqualError(t, err, "sql: no rows in result set")
+	})
+
+	t.Run("list_default_limit", func(t *testing.T) {
+		attempt := domain.LoginAttempt{Id: uuid.New(), UserId: user.Id, Outcome: domain.LoginSucceeded}
+
+		repository.EXPECT().List(user.Id).Return(user, nil)
+		history.EXPECT().ListLoginAttempts(user.Id, service.DefaultLoginHistoryLimit).Return([]domain.LoginAttempt{attempt}, nil)
+
+		attempts, err := uService.ListLogins(user.Id, 0)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, []domain.LoginAttempt{attempt}, attempts)
+	})
+
+	t.Run("purge_keeps_retention", func(t *testing.T) {
+		history.EXPECT().DeleteLoginAttempts(gomock.Any()).DoAndReturn(func(before time.Time) (int64, error) {
+			assert.WithinDuration(t, time.Now().Add(-24 * time.Hour), before, time.Minute)
+
+			return 2, nil
+		})
+
+		deleted, err := uService.PurgeLoginHistory()
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, deleted)
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"testing"
 	"github.com/PedroPereiraN/go-hexagonal/adapter/input/controller"
 	"github.com/PedroPereiraN/go-hexagonal/adapter/input/model"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
 	"github.com/PedroPereiraN/go-hexagonal/tests/config"
 	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
 	"github.com/gin-gonic/gin"
@@ -68,7 +69,7 @@ func TestUserController_Login(t *testing.T) {
 		body, _ := json.Marshal(model)
 		stringReader := io.NopCloser(strings.NewReader(string(body)))
 
-		service.EXPECT().Login(model.Email, model.Password).Return("", errors.New("Invalid user values"))
+		service.EXPECT().Login(model.Email, model.Password, gomock.Any()).Return(domain.LoginResult{}, errors.New("Invalid user values"))
 
 		config.MakeRequest(context, params, url, "POST", stringReader)
 
@@ -94,12 +95,36 @@ func TestUserController_Login(t *testing.T) {
 		body, _ := json.Marshal(model)
 		stringReader := io.NopCloser(strings.NewReader(string(body)))
 
-		service.EXPECT().Login(model.Email, gomock.Any()).Return("super-token",nil)
+		service.EXPECT().Login(model.Email, gomock.Any(), gomock.Any()).Return(domain.LoginResult{Token: "super-token"},nil)
 
 		config.MakeRequest(context, params, url, "POST", stringReader)
 
 		controller.Login(context)
 
 		assert.EqualValues(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{"token":"super-token","twoFactorRequired":false}`, recorder.Body.String())
+	})
+
+	t.Run("two_factor_required", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		model := model.UserLoginModel{
+			Email: "test@email.com",
+			Password: "password@123",
+		}
+
+		body, _ := json.Marshal(model)
+		stringReader := io.NopCloser(strings.NewReader(string(body)))
+
+		service.EXPECT().Login(model.Email, gomock.Any(), gomock.Any()).Return(domain.LoginResult{TwoFactorRequired: true, ChallengeToken: "challenge"}, nil)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{}, "POST", stringReader)
+
+		controller.Login(context)
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{"twoFactorRequired":true,"challengeToken":"challenge"}`, recorder.Body.String())
 	})
 }
@@ -2,6 +2,7 @@ package test
 
 import (
 	"errors"
+	"strings"
 	"testing"
 	"time"
 	"github.com/PedroPereiraN/go-hexagonal/domain"
@@ -24,9 +25,9 @@ func TestUserService_Login(t *testing.T) {
 		newPassword := "password@123"
 
 		repository.EXPECT().FindUserByEmail(userEmail).Return(domain.UserDomain{}, errors.New("User not found"))
-		token, err := service.Login(userEmail, newPassword)
+		result, err := service.Login(userEmail, newPassword, domain.Client{})
 
-		assert.EqualValues(t, "", token)
+		assert.EqualValues(t, domain.LoginResult{}, result)
 
 		assert.EqualError(t, err, "User not found")
 	})
@@ -37,9 +38,9 @@ func TestUserService_Login(t *testing.T) {
 		newPassword := "password@123"
 
 		repository.EXPECT().FindUserByEmail(userEmail).Return(domain.UserDomain{Password: "@123"}, nil)
-		token, err := service.Login(userEmail, newPassword)
+		result, err := service.Login(userEmail, newPassword, domain.Client{})
 
-		assert.EqualValues(t, "", token)
+		assert.EqualValues(t, domain.LoginResult{}, result)
 
 		assert.EqualError(t, err, "Wrong password")
 	})
@@ -56,8 +57,116 @@ func TestUserService_Login(t *testing.T) {
 		}
 
 		repository.EXPECT().FindUserByEmail(userEmail).Return(uDomain, nil)
-		_, err = service.Login(userEmail, newPassword)
+		repository.EXPECT().UpdateLastLogin(uDomain.Id, gomock.Any()).Return(nil)
+		_, err = service.Login(userEmail, newPassword, domain.Client{})
 
 		assert.NoError(t, err)
 	})
+
+	t.Run("outdated_hash_is_rehashed", func(t *testing.T) {
+
+		userEmail := "test@email.com"
+		newPassword := "password@123"
+
+		bcryptHash, err := domain.NewBcryptHasher(4).Hash(newPassword)
+
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when hashing the password", err.Error())
+		}
+
+		uDomain := domain.UserDomain{Id: uuid.New(), Email: userEmail, Password: bcryptHash}
+
+		repository.EXPECT().FindUserByEmail(userEmail).Return(uDomain, nil)
+		repository.EXPECT().UpdatePassword(uDomain.Id, gomock.Any()).DoAndReturn(func(id uuid.UUID, dto domain.UserDomain) (uuid.UUID, error) {
+			assert.True(t, strings.HasPrefix(dto.Password, "$argon2id$"))
+			assert.False(t, domain.PasswordNeedsRehash(dto.Password))
+
+			return id, nil
+		})
+		repository.EXPECT().UpdateLastLogin(uDomain.Id, gomock.Any()).Return(nil)
+
+		_, err = service.Login(userEmail, newPassword, domain.Client{})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("rehash_failure_does_not_fail_login", func(t *testing.T) {
+
+		userEmail := "test@email.com"
+		newPassword := "password@123"
+
+		bcryptHash, _ := domain.NewBcryptHasher(4).Hash(newPassword)
+
+		uDomain := domain.UserDomain{Id: uuid.New(), Email: userEmail, Password: bcryptHash}
+
+		repository.EXPECT().FindUserByEmail(userEmail).Return(uDomain, nil)
+		repository.EXPECT().UpdatePassword(uDomain.Id, gomock.Any()).Return(uuid.Nil, errors.New("repository error"))
+		repository.EXPECT().UpdateLastLogin(uDomain.Id, gomock.Any()).Return(nil)
+
+		result, err := service.Login(userEmail, newPassword, domain.Client{})
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, result.Token)
+	})
+}
+
+func TestUserService_LoginTwoFactor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repository := mocks.NewMockUserRepository(ctrl)
+	tfRepository := mocks.NewMockTwoFactorRepository(ctrl)
+	service := service.NewUserService(repository, service.WithTwoFactor(tfRepository))
+
+	userEmail := "test@email.com"
+	password := "password@123"
+
+	uDomain, err := domain.CreateUser(uuid.New(), "", userEmail, "00000000000", password, time.Time{}, time.Time{}, time.Time{})
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening creating a new user struct", err.Error())
+	}
+
+	t.Run("not_enrolled_returns_token", func(t *testing.T) {
+		repository.EXPECT().FindUserByEmail(userEmail).Return(uDomain, nil)
+		tfRepository.EXPECT().FindTwoFactor(uDomain.Id).Return(domain.TwoFactor{}, errors.New("sql: no rows in result set"))
+		repository.EXPECT().UpdateLastLogin(uDomain.Id, gomock.Any()).Return(nil)
+
+		result, err := service.Login(userEmail, password, domain.Client{})
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, result.Token)
+		assert.False(t, result.TwoFactorRequired)
+	})
+
+	t.Run("unconfirmed_enrolment_returns_token", func(t *testing.T) {
+		repository.EXPECT().FindUserByEmail(userEmail).Return(uDomain, nil)
+		tfRepository.EXPECT().FindTwoFactor(uDomain.Id).Return(domain.TwoFactor{UserId: uDomain.Id}, nil)
+		repository.EXPECT().UpdateLastLogin(uDomain.Id, gomock.Any()).Return(nil)
+
+		result, err := service.Login(userEmail, password, domain.Client{})
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, result.Token)
+	})
+
+	t.Run("enabled_returns_challenge", func(t *testing.T) {
+		repository.EXPECT().FindUserByEmail(userEmail).Return(uDomain, nil)
+		tfRepository.EXPECT().FindTwoFactor(uDomain.Id).Return(domain.TwoFactor{UserId: uDomain.Id, ConfirmedAt: time.Now()}, nil)
+
+		result, err := service.Login(userEmail, password, domain.Client{})
+
+		assert.NoError(t, err)
+		assert.Empty(t, result.Token)
+		assert.True(t, result.TwoFactorRequired)
+		assert.NotEmpty(t, result.ChallengeToken)
+	})
+
+	t.Run("repository_error", func(t *testing.T) {
+		repository.EXPECT().FindUserByEmail(userEmail).Return(uDomain, nil)
+		tfRepository.EXPECT().FindTwoFactor(uDomain.Id).Return(domain.TwoFactor{}, errors.New("repository error"))
+
+		_, err := service.Login(userEmail, password, domain.Client{})
+
+		assert.EqualError(t, err, "repository error")
+	})
 }
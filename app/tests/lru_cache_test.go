@@ -0,0 +1,48 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	t.Run("evicts_least_recently_used", func(t *testing.T) {
+		lru := cache.NewLRU(2, time.Minute)
+
+		lru.Set("a", []byte("1"))
+		lru.Set("b", []byte("2"))
+		lru.Get("a")
+		lru.Set("c", []byte("3"))
+
+		_, found := lru.Get("b")
+		assert.False(t, found)
+
+		value, found := lru.Get("a")
+		assert.True(t, found)
+		assert.EqualValues(t, "1", string(value))
+	})
+
+	t.Run("expires_after_ttl", func(t *testing.T) {
+		lru := cache.NewLRU(2, 10 * time.Millisecond)
+
+		lru.Set("a", []byte("1"))
+
+		time.Sleep(20 * time.Millisecond)
+
+		_, found := lru.Get("a")
+		assert.False(t, found)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		lru := cache.NewLRU(2, time.Minute)
+
+		lru.Set("a", []byte("1"))
+		lru.Delete("a", "missing")
+
+		_, found := lru.Get("a")
+		assert.False(t, found)
+	})
+}
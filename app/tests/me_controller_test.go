@@ -0,0 +1,190 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/controller"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/middleware"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestMeController(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	crtl := gomock.NewController(t)
+	defer crtl.Finish()
+	service := mocks.NewMockUserService(crtl)
+	controller := controller.NewUserController(service)
+
+	identity := domain.Identity{UserId: uuid.New(), Email: "john@email.com", SessionId: uuid.New()}
+
+	tokens := authenticatorFunc(func(token string) (domain.Identity, error) {
+		return identity, nil
+	})
+
+	apiKeys := authenticatorFunc(func(key string) (domain.Identity, error) {
+		return domain.Identity{UserId: identity.UserId, APIKeyId: uuid.New(), Scopes: []string{domain.ScopeUsersRead}}, nil
+	})
+
+	router := gin.New()
+	me := router.Group("/v1/me", middleware.Authenticate(tokens, apiKeys))
+	me.GET("", middleware.RequireScope(domain.ScopeUsersRead), controller.Me)
+	me.PATCH("", middleware.RequireScope(domain.ScopeUsersWrite), controller.UpdateMe)
+	me.DELETE("", controller.DeactivateMe)
+	me.POST("/password", controller.ChangeMyPassword)
+
+	send := func(method string, path string, body string, header string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+
+		if header == middleware.APIKeyHeader {
+			request.Header.Set(middleware.APIKeyHeader, "key")
+		} else {
+			request.Header.Set("Authorization", "Bearer token")
+		}
+
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	user := domain.UserDomain{
+		Id: identity.UserId,
+		Name: "John Doe",
+		Email: "john@email.com",
+		Phone: "00000000000",
+		Password: "$2a$10$hash",
+		CreatedAt: time.Now().UTC(),
+	}
+
+	t.Run("me_without_credentials", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", "/v1/me", nil))
+
+		assert.EqualValues(t, http.StatusUnauthorized, recorder.Code)
+	})
+
+	t.Run("me_hides_password", func(t *testing.T) {
+		service.EXPECT().List(identity.UserId).Return(user, nil)
+
+		recorder := send("GET", "/v1/me", "", "")
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), `"id":"` + identity.UserId.String() + `","name":"John Doe","email":"john@email.com","phone":"00000000000"`)
+		assert.NotContains(t, recorder.Body.String(), "hash")
+	})
+
+	t.Run("me_with_api_key", func(t *testing.T) {
+		service.EXPECT().List(identity.UserId).Return(user, nil)
+
+		assert.EqualValues(t, http.StatusOK, send("GET", "/v1/me", "", middleware.APIKeyHeader).Code)
+	})
+
+	t.Run("me_not_found", func(t *testing.T) {
+		service.EXPECT().List(identity.UserId).Return(domain.UserDomain{}, errors.New("sql: no rows in result set"))
+
+		assert.EqualValues(t, http.StatusNotFound, send("GET", "/v1/me", "", "").Code)
+	})
+
+	t.Run("update_me_invalid_email", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusBadRequest, send("PATCH", "/v1/me", `{"email":"invalid"}`, "").Code)
+	})
+
+	t.Run("update_me_api_key_without_scope", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusForbidden, send("PATCH", "/v1/me", `{"name":"Johnny"}`, middleware.APIKeyHeader).Code)
+	})
+
+	t.Run("update_me_email_taken", func(t *testing.T) {
+		service.EXPECT().Update(identity.UserId, gomock.Any()).Return(uuid.Nil, errors.New("Email is already registered"))
+
+		assert.EqualValues(t, http.StatusConflict, send("PATCH", "/v1/me", `{"email":"jane@email.com"}`, "").Code)
+	})
+
+	t.Run("update_me", func(t *testing.T) {
+		updated := user
+		updated.Name = "Johnny"
+
+		service.EXPECT().Update(identity.UserId, gomock.Any()).DoAndReturn(func(id uuid.UUID, dto domain.UserDomain) (uuid.UUID, error) {
+			assert.EqualValues(t, "Johnny", dto.Name)
+			assert.EqualValues(t, "", dto.Email)
+
+			return id, nil
+		})
+		service.EXPECT().List(identity.UserId).Return(updated, nil)
+
+		recorder := send("PATCH", "/v1/me", `{"name":"Johnny"}`, "")
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), `"name":"Johnny"`)
+	})
+
+	t.Run("change_password_missing_current", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusBadRequest, send("POST", "/v1/me/password", `{"password":"new-password@456"}`, "").Code)
+	})
+
+	t.Run("change_password_with_api_key", func(t *testing.T) {
+		recorder := send("POST", "/v1/me/password", `{"currentPassword":"password@123","password":"new-password@456"}`, middleware.APIKeyHeader)
+
+		assert.EqualValues(t, http.StatusForbidden, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "API keys can't change the password")
+	})
+
+	t.Run("change_password_wrong_current", func(t *testing.T) {
+		service.EXPECT().UpdatePassword(identity.UserId, "wrong@123", "new-password@456").Return(uuid.Nil, errors.New("Wrong password"))
+
+		assert.EqualValues(t, http.StatusForbidden, send("POST", "/v1/me/password", `{"currentPassword":"wrong@123","password":"new-password@456"}`, "").Code)
+	})
+
+	t.Run("change_password_policy", func(t *testing.T) {
+		service.EXPECT().UpdatePassword(identity.UserId, "password@123", "short").Return(uuid.Nil, &domain.PasswordPolicyError{
+			Violations: []domain.PasswordViolation{{Rule: "min_length", Message: "Password must have at least 8 characters"}},
+		})
+
+		recorder := send("POST", "/v1/me/password", `{"currentPassword":"password@123","password":"short"}`, "")
+
+		assert.EqualValues(t, http.StatusBadRequest, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), `"rule":"min_length"`)
+	})
+
+	t.Run("change_password", func(t *testing.T) {
+		service.EXPECT().UpdatePassword(identity.UserId, "password@123", "new-password@456").Return(identity.UserId, nil)
+
+		assert.EqualValues(t, http.StatusOK, send("POST", "/v1/me/password", `{"currentPassword":"password@123","password":"new-password@456"}`, "").Code)
+	})
+
+	t.Run("deactivate_missing_password", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusBadRequest, send("DELETE", "/v1/me", `{}`, "").Code)
+	})
+
+	t.Run("deactivate_with_api_key", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusForbidden, send("DELETE", "/v1/me", `{"password":"password@123"}`, middleware.APIKeyHeader).Code)
+	})
+
+	t.Run("deactivate_wrong_password", func(t *testing.T) {
+		service.EXPECT().Deactivate(identity.UserId, "wrong@123").Return(uuid.Nil, errors.New("Wrong password"))
+
+		assert.EqualValues(t, http.StatusForbidden, send("DELETE", "/v1/me", `{"password":"wrong@123"}`, "").Code)
+	})
+
+	t.Run("deactivate", func(t *testing.T) {
+		service.EXPECT().Deactivate(identity.UserId, "password@123").Return(identity.UserId, nil)
+
+		recorder := send("DELETE", "/v1/me", `{"password":"password@123"}`, "")
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), identity.UserId.String())
+	})
+}
@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ports/output/login.go
+//
+// Generated by this command:
+//
+//	mockgen --source=ports/output/login.go --destination=./tests/mocks/login_mock.go --package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/PedroPereiraN/go-hexagonal/domain"
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLoginHistoryRepository is a mock of LoginHistoryRepository interface.
+type MockLoginHistoryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginHistoryRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockLoginHistoryRepositoryMockRecorder is the mock recorder for MockLoginHistoryRepository.
+type MockLoginHistoryRepositoryMockRecorder struct {
+	mock *MockLoginHistoryRepository
+}
+
+// NewMockLoginHistoryRepository creates a new mock instance.
+func NewMockLoginHistoryRepository(ctrl *gomock.Controller) *MockLoginHistoryRepository {
+	mock := &MockLoginHistoryRepository{ctrl: ctrl}
+	mock.recorder = &MockLoginHistoryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginHistoryRepository) EXPECT() *MockLoginHistoryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddLoginAttempt mocks base method.
+func (m *MockLoginHistoryRepository) AddLoginAttempt(arg0 domain.LoginAttempt) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddLoginAttempt", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddLoginAttempt indicates an expected call of AddLoginAttempt.
+func (mr *MockLoginHistoryRepositoryMockRecorder) AddLoginAttempt(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLoginAttempt", reflect.TypeOf((*MockLoginHistoryRepository)(nil).AddLoginAttempt), arg0)
+}
+
+// DeleteLoginAttempts mocks base method.
+func (m *MockLoginHistoryRepository) DeleteLoginAttempts(arg0 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLoginAttempts", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteLoginAttempts indicates an expected call of DeleteLoginAttempts.
+func (mr *MockLoginHistoryRepositoryMockRecorder) DeleteLoginAttempts(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLoginAttempts", reflect.TypeOf((*MockLoginHistoryRepository)(nil).DeleteLoginAttempts), arg0)
+}
+
+// ListLoginAttempts mocks base method.
+func (m *MockLoginHistoryRepository) ListLoginAttempts(userId uuid.UUID, limit int) ([]domain.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLoginAttempts", userId, limit)
+	ret0, _ := ret[0].([]domain.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLoginAttempts indicates an expected call of ListLoginAttempts.
+func (mr *MockLoginHistoryRepositoryMockRecorder) ListLoginAttempts(userId, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLoginAttempts", reflect.TypeOf((*MockLoginHistoryRepository)(nil).ListLoginAttempts), userId, limit)
+}
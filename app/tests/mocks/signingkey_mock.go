@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ports/output/signingkey.go
+//
+// Generated by this command:
+//
+//	mockgen --source=ports/output/signingkey.go --destination=./tests/mocks/signingkey_mock.go --package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/PedroPereiraN/go-hexagonal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSigningKeyRepository is a mock of SigningKeyRepository interface.
+type MockSigningKeyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSigningKeyRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockSigningKeyRepositoryMockRecorder is the mock recorder for MockSigningKeyRepository.
+type MockSigningKeyRepositoryMockRecorder struct {
+	mock *MockSigningKeyRepository
+}
+
+// NewMockSigningKeyRepository creates a new mock instance.
+func NewMockSigningKeyRepository(ctrl *gomock.Controller) *MockSigningKeyRepository {
+	mock := &MockSigningKeyRepository{ctrl: ctrl}
+	mock.recorder = &MockSigningKeyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSigningKeyRepository) EXPECT() *MockSigningKeyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateSigningKey mocks base method.
+func (m *MockSigningKeyRepository) CreateSigningKey(arg0 domain.SigningKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSigningKey", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSigningKey indicates an expected call of CreateSigningKey.
+func (mr *MockSigningKeyRepositoryMockRecorder) CreateSigningKey(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSigningKey", reflect.TypeOf((*MockSigningKeyRepository)(nil).CreateSigningKey), arg0)
+}
+
+// DeleteExpiredSigningKeys mocks base method.
+func (m *MockSigningKeyRepository) DeleteExpiredSigningKeys(now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredSigningKeys", now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredSigningKeys indicates an expected call of DeleteExpiredSigningKeys.
+func (mr *MockSigningKeyRepositoryMockRecorder) DeleteExpiredSigningKeys(now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredSigningKeys", reflect.TypeOf((*MockSigningKeyRepository)(nil).DeleteExpiredSigningKeys), now)
+}
+
+// ListSigningKeys mocks base method.
+func (m *MockSigningKeyRepository) ListSigningKeys(now time.Time) ([]domain.SigningKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSigningKeys", now)
+	ret0, _ := ret[0].([]domain.SigningKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSigningKeys indicates an expected call of ListSigningKeys.
+func (mr *MockSigningKeyRepositoryMockRecorder) ListSigningKeys(now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSigningKeys", reflect.TypeOf((*MockSigningKeyRepository)(nil).ListSigningKeys), now)
+}
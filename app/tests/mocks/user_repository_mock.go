@@ -1,21 +1,30 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: ./adapter/output/repository/user.repository.go
+// Source: adapter/output/repository/user.repository.go
+//
+// Generated by this command:
+//
+//	mockgen --source=adapter/output/repository/user.repository.go --destination=./tests/mocks/user_repository_mock.go --package=mocks
+//
 
-// Package mock_repository is a generated GoMock package.
+// Package mocks is a generated GoMock package.
 package mocks
 
 import (
+	sql "database/sql"
 	reflect "reflect"
+	time "time"
 
 	domain "github.com/PedroPereiraN/go-hexagonal/domain"
-	gomock "go.uber.org/mock/gomock"
+	port "github.com/PedroPereiraN/go-hexagonal/ports/output"
 	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
 )
 
 // MockUserRepository is a mock of UserRepository interface.
 type MockUserRepository struct {
 	ctrl     *gomock.Controller
 	recorder *MockUserRepositoryMockRecorder
+	isgomock struct{}
 }
 
 // MockUserRepositoryMockRecorder is the mock recorder for MockUserRepository.
@@ -35,6 +44,34 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddEvent mocks base method.
+func (m *MockUserRepository) AddEvent(arg0 domain.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddEvent", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddEvent indicates an expected call of AddEvent.
+func (mr *MockUserRepositoryMockRecorder) AddEvent(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddEvent", reflect.TypeOf((*MockUserRepository)(nil).AddEvent), arg0)
+}
+
+// AddPasswordHistory mocks base method.
+func (m *MockUserRepository) AddPasswordHistory(arg0 uuid.UUID, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPasswordHistory", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddPasswordHistory indicates an expected call of AddPasswordHistory.
+func (mr *MockUserRepositoryMockRecorder) AddPasswordHistory(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPasswordHistory", reflect.TypeOf((*MockUserRepository)(nil).AddPasswordHistory), arg0, arg1, arg2)
+}
+
 // Create mocks base method.
 func (m *MockUserRepository) Create(arg0 domain.UserDomain) (uuid.UUID, error) {
 	m.ctrl.T.Helper()
@@ -45,7 +82,7 @@ func (m *MockUserRepository) Create(arg0 domain.UserDomain) (uuid.UUID, error) {
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockUserRepositoryMockRecorder) Create(arg0 interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) Create(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserRepository)(nil).Create), arg0)
 }
@@ -74,11 +111,26 @@ func (m *MockUserRepository) Delete(arg0 uuid.UUID) (uuid.UUID, error) {
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockUserRepositoryMockRecorder) Delete(arg0 interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) Delete(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserRepository)(nil).Delete), arg0)
 }
 
+// FindDeletedUser mocks base method.
+func (m *MockUserRepository) FindDeletedUser(arg0 uuid.UUID) (domain.UserDomain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDeletedUser", arg0)
+	ret0, _ := ret[0].(domain.UserDomain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDeletedUser indicates an expected call of FindDeletedUser.
+func (mr *MockUserRepositoryMockRecorder) FindDeletedUser(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDeletedUser", reflect.TypeOf((*MockUserRepository)(nil).FindDeletedUser), arg0)
+}
+
 // FindUserByEmail mocks base method.
 func (m *MockUserRepository) FindUserByEmail(arg0 string) (domain.UserDomain, error) {
 	m.ctrl.T.Helper()
@@ -89,7 +141,7 @@ func (m *MockUserRepository) FindUserByEmail(arg0 string) (domain.UserDomain, er
 }
 
 // FindUserByEmail indicates an expected call of FindUserByEmail.
-func (mr *MockUserRepositoryMockRecorder) FindUserByEmail(arg0 interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) FindUserByEmail(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserByEmail", reflect.TypeOf((*MockUserRepository)(nil).FindUserByEmail), arg0)
 }
@@ -104,11 +156,26 @@ func (m *MockUserRepository) FindUserByPhone(arg0 string) (domain.UserDomain, er
 }
 
 // FindUserByPhone indicates an expected call of FindUserByPhone.
-func (mr *MockUserRepositoryMockRecorder) FindUserByPhone(arg0 interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) FindUserByPhone(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserByPhone", reflect.TypeOf((*MockUserRepository)(nil).FindUserByPhone), arg0)
 }
 
+// Iterate mocks base method.
+func (m *MockUserRepository) Iterate(arg0 domain.UserFilter) (domain.UserIterator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Iterate", arg0)
+	ret0, _ := ret[0].(domain.UserIterator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Iterate indicates an expected call of Iterate.
+func (mr *MockUserRepositoryMockRecorder) Iterate(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Iterate", reflect.TypeOf((*MockUserRepository)(nil).Iterate), arg0)
+}
+
 // List mocks base method.
 func (m *MockUserRepository) List(arg0 uuid.UUID) (domain.UserDomain, error) {
 	m.ctrl.T.Helper()
@@ -119,7 +186,7 @@ func (m *MockUserRepository) List(arg0 uuid.UUID) (domain.UserDomain, error) {
 }
 
 // List indicates an expected call of List.
-func (mr *MockUserRepositoryMockRecorder) List(arg0 interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) List(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockUserRepository)(nil).List), arg0)
 }
@@ -139,6 +206,139 @@ func (mr *MockUserRepositoryMockRecorder) ListAll() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAll", reflect.TypeOf((*MockUserRepository)(nil).ListAll))
 }
 
+// ListByStatus mocks base method.
+func (m *MockUserRepository) ListByStatus(arg0 string) ([]domain.UserDomain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByStatus", arg0)
+	ret0, _ := ret[0].([]domain.UserDomain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByStatus indicates an expected call of ListByStatus.
+func (mr *MockUserRepositoryMockRecorder) ListByStatus(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByStatus", reflect.TypeOf((*MockUserRepository)(nil).ListByStatus), arg0)
+}
+
+// ListDeleted mocks base method.
+func (m *MockUserRepository) ListDeleted() ([]domain.UserDomain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeleted")
+	ret0, _ := ret[0].([]domain.UserDomain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeleted indicates an expected call of ListDeleted.
+func (mr *MockUserRepositoryMockRecorder) ListDeleted() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeleted", reflect.TypeOf((*MockUserRepository)(nil).ListDeleted))
+}
+
+// ListPasswordHistory mocks base method.
+func (m *MockUserRepository) ListPasswordHistory(arg0 uuid.UUID, arg1 int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPasswordHistory", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPasswordHistory indicates an expected call of ListPasswordHistory.
+func (mr *MockUserRepositoryMockRecorder) ListPasswordHistory(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPasswordHistory", reflect.TypeOf((*MockUserRepository)(nil).ListPasswordHistory), arg0, arg1)
+}
+
+// PrunePasswordHistory mocks base method.
+func (m *MockUserRepository) PrunePasswordHistory(arg0 uuid.UUID, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrunePasswordHistory", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PrunePasswordHistory indicates an expected call of PrunePasswordHistory.
+func (mr *MockUserRepositoryMockRecorder) PrunePasswordHistory(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrunePasswordHistory", reflect.TypeOf((*MockUserRepository)(nil).PrunePasswordHistory), arg0, arg1)
+}
+
+// Purge mocks base method.
+func (m *MockUserRepository) Purge(arg0 time.Time) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", arg0)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockUserRepositoryMockRecorder) Purge(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockUserRepository)(nil).Purge), arg0)
+}
+
+// RequirePasswordChange mocks base method.
+func (m *MockUserRepository) RequirePasswordChange(arg0 uuid.UUID) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequirePasswordChange", arg0)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequirePasswordChange indicates an expected call of RequirePasswordChange.
+func (mr *MockUserRepositoryMockRecorder) RequirePasswordChange(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequirePasswordChange", reflect.TypeOf((*MockUserRepository)(nil).RequirePasswordChange), arg0)
+}
+
+// Restore mocks base method.
+func (m *MockUserRepository) Restore(arg0 uuid.UUID) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockUserRepositoryMockRecorder) Restore(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockUserRepository)(nil).Restore), arg0)
+}
+
+// Search mocks base method.
+func (m *MockUserRepository) Search(arg0 domain.UserSearch) (domain.UserSearchPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", arg0)
+	ret0, _ := ret[0].(domain.UserSearchPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockUserRepositoryMockRecorder) Search(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockUserRepository)(nil).Search), arg0)
+}
+
+// Transaction mocks base method.
+func (m *MockUserRepository) Transaction(arg0 func(port.UserRepository) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transaction", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transaction indicates an expected call of Transaction.
+func (mr *MockUserRepositoryMockRecorder) Transaction(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transaction", reflect.TypeOf((*MockUserRepository)(nil).Transaction), arg0)
+}
+
 // Update mocks base method.
 func (m *MockUserRepository) Update(arg0 uuid.UUID, arg1 domain.UserDomain) (uuid.UUID, error) {
 	m.ctrl.T.Helper()
@@ -149,11 +349,25 @@ func (m *MockUserRepository) Update(arg0 uuid.UUID, arg1 domain.UserDomain) (uui
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockUserRepositoryMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) Update(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUserRepository)(nil).Update), arg0, arg1)
 }
 
+// UpdateLastLogin mocks base method.
+func (m *MockUserRepository) UpdateLastLogin(arg0 uuid.UUID, arg1 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLastLogin", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLastLogin indicates an expected call of UpdateLastLogin.
+func (mr *MockUserRepositoryMockRecorder) UpdateLastLogin(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLastLogin", reflect.TypeOf((*MockUserRepository)(nil).UpdateLastLogin), arg0, arg1)
+}
+
 // UpdatePassword mocks base method.
 func (m *MockUserRepository) UpdatePassword(arg0 uuid.UUID, arg1 domain.UserDomain) (uuid.UUID, error) {
 	m.ctrl.T.Helper()
@@ -164,7 +378,162 @@ func (m *MockUserRepository) UpdatePassword(arg0 uuid.UUID, arg1 domain.UserDoma
 }
 
 // UpdatePassword indicates an expected call of UpdatePassword.
-func (mr *MockUserRepositoryMockRecorder) UpdatePassword(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) UpdatePassword(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUserRepository)(nil).UpdatePassword), arg0, arg1)
 }
+
+// UpdateRole mocks base method.
+func (m *MockUserRepository) UpdateRole(arg0 uuid.UUID, arg1 string) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRole", arg0, arg1)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRole indicates an expected call of UpdateRole.
+func (mr *MockUserRepositoryMockRecorder) UpdateRole(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRole", reflect.TypeOf((*MockUserRepository)(nil).UpdateRole), arg0, arg1)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockUserRepository) UpdateStatus(arg0 uuid.UUID, arg1 domain.UserStatus) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", arg0, arg1)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockUserRepositoryMockRecorder) UpdateStatus(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockUserRepository)(nil).UpdateStatus), arg0, arg1)
+}
+
+// Mockexecutor is a mock of executor interface.
+type Mockexecutor struct {
+	ctrl     *gomock.Controller
+	recorder *MockexecutorMockRecorder
+	isgomock struct{}
+}
+
+// MockexecutorMockRecorder is the mock recorder for Mockexecutor.
+type MockexecutorMockRecorder struct {
+	mock *Mockexecutor
+}
+
+// NewMockexecutor creates a new mock instance.
+func NewMockexecutor(ctrl *gomock.Controller) *Mockexecutor {
+	mock := &Mockexecutor{ctrl: ctrl}
+	mock.recorder = &MockexecutorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockexecutor) EXPECT() *MockexecutorMockRecorder {
+	return m.recorder
+}
+
+// Exec mocks base method.
+func (m *Mockexecutor) Exec(query string, args ...any) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Exec", varargs...)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exec indicates an expected call of Exec.
+func (mr *MockexecutorMockRecorder) Exec(query any, args ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exec", reflect.TypeOf((*Mockexecutor)(nil).Exec), varargs...)
+}
+
+// Query mocks base method.
+func (m *Mockexecutor) Query(query string, args ...any) (*sql.Rows, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Query", varargs...)
+	ret0, _ := ret[0].(*sql.Rows)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockexecutorMockRecorder) Query(query any, args ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*Mockexecutor)(nil).Query), varargs...)
+}
+
+// QueryRow mocks base method.
+func (m *Mockexecutor) QueryRow(query string, args ...any) *sql.Row {
+	m.ctrl.T.Helper()
+	varargs := []any{query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "QueryRow", varargs...)
+	ret0, _ := ret[0].(*sql.Row)
+	return ret0
+}
+
+// QueryRow indicates an expected call of QueryRow.
+func (mr *MockexecutorMockRecorder) QueryRow(query any, args ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryRow", reflect.TypeOf((*Mockexecutor)(nil).QueryRow), varargs...)
+}
+
+// MockrowScanner is a mock of rowScanner interface.
+type MockrowScanner struct {
+	ctrl     *gomock.Controller
+	recorder *MockrowScannerMockRecorder
+	isgomock struct{}
+}
+
+// MockrowScannerMockRecorder is the mock recorder for MockrowScanner.
+type MockrowScannerMockRecorder struct {
+	mock *MockrowScanner
+}
+
+// NewMockrowScanner creates a new mock instance.
+func NewMockrowScanner(ctrl *gomock.Controller) *MockrowScanner {
+	mock := &MockrowScanner{ctrl: ctrl}
+	mock.recorder = &MockrowScannerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockrowScanner) EXPECT() *MockrowScannerMockRecorder {
+	return m.recorder
+}
+
+// Scan mocks base method.
+func (m *MockrowScanner) Scan(dest ...any) error {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range dest {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Scan", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Scan indicates an expected call of Scan.
+func (mr *MockrowScannerMockRecorder) Scan(dest ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scan", reflect.TypeOf((*MockrowScanner)(nil).Scan), dest...)
+}
@@ -1,16 +1,17 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: ./services/user.service.go
+// Source: services/user.service.go
 //
 // Generated by this command:
 //
-//	mockgen --source=./services/user.service.go --destination=./tests/mocks/user_service_mock.go
+//	mockgen --source=services/user.service.go --destination=./tests/mocks/user_service_mock.go --package=mocks
 //
 
-// Package mock_service is a generated GoMock package.
+// Package mocks is a generated GoMock package.
 package mocks
 
 import (
 	reflect "reflect"
+	time "time"
 
 	domain "github.com/PedroPereiraN/go-hexagonal/domain"
 	uuid "github.com/google/uuid"
@@ -41,6 +42,36 @@ func (m *MockUserService) EXPECT() *MockUserServiceMockRecorder {
 	return m.recorder
 }
 
+// Authenticate mocks base method.
+func (m *MockUserService) Authenticate(arg0 string) (domain.Identity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", arg0)
+	ret0, _ := ret[0].(domain.Identity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockUserServiceMockRecorder) Authenticate(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockUserService)(nil).Authenticate), arg0)
+}
+
+// ChangeExpiredPassword mocks base method.
+func (m *MockUserService) ChangeExpiredPassword(arg0, arg1 string, arg2 domain.Client) (domain.LoginResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeExpiredPassword", arg0, arg1, arg2)
+	ret0, _ := ret[0].(domain.LoginResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeExpiredPassword indicates an expected call of ChangeExpiredPassword.
+func (mr *MockUserServiceMockRecorder) ChangeExpiredPassword(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeExpiredPassword", reflect.TypeOf((*MockUserService)(nil).ChangeExpiredPassword), arg0, arg1, arg2)
+}
+
 // Create mocks base method.
 func (m *MockUserService) Create(arg0 domain.UserDomain) (uuid.UUID, error) {
 	m.ctrl.T.Helper()
@@ -56,6 +87,21 @@ func (mr *MockUserServiceMockRecorder) Create(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserService)(nil).Create), arg0)
 }
 
+// Deactivate mocks base method.
+func (m *MockUserService) Deactivate(arg0 uuid.UUID, arg1 string) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deactivate", arg0, arg1)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Deactivate indicates an expected call of Deactivate.
+func (mr *MockUserServiceMockRecorder) Deactivate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deactivate", reflect.TypeOf((*MockUserService)(nil).Deactivate), arg0, arg1)
+}
+
 // Delete mocks base method.
 func (m *MockUserService) Delete(arg0 uuid.UUID) (uuid.UUID, error) {
 	m.ctrl.T.Helper()
@@ -71,6 +117,66 @@ func (mr *MockUserServiceMockRecorder) Delete(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserService)(nil).Delete), arg0)
 }
 
+// Export mocks base method.
+func (m *MockUserService) Export(arg0 domain.UserFilter) (domain.UserIterator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", arg0)
+	ret0, _ := ret[0].(domain.UserIterator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockUserServiceMockRecorder) Export(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockUserService)(nil).Export), arg0)
+}
+
+// FinishLogin mocks base method.
+func (m *MockUserService) FinishLogin(arg0 uuid.UUID, arg1 domain.Client) (domain.LoginResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishLogin", arg0, arg1)
+	ret0, _ := ret[0].(domain.LoginResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishLogin indicates an expected call of FinishLogin.
+func (mr *MockUserServiceMockRecorder) FinishLogin(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishLogin", reflect.TypeOf((*MockUserService)(nil).FinishLogin), arg0, arg1)
+}
+
+// Import mocks base method.
+func (m *MockUserService) Import(arg0 domain.ImportRowReader, arg1 string, arg2 bool) (domain.ImportReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", arg0, arg1, arg2)
+	ret0, _ := ret[0].(domain.ImportReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockUserServiceMockRecorder) Import(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockUserService)(nil).Import), arg0, arg1, arg2)
+}
+
+// IssueToken mocks base method.
+func (m *MockUserService) IssueToken(arg0 uuid.UUID, arg1 domain.Client) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueToken", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueToken indicates an expected call of IssueToken.
+func (mr *MockUserServiceMockRecorder) IssueToken(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueToken", reflect.TypeOf((*MockUserService)(nil).IssueToken), arg0, arg1)
+}
+
 // List mocks base method.
 func (m *MockUserService) List(arg0 uuid.UUID) (domain.UserDomain, error) {
 	m.ctrl.T.Helper()
@@ -101,19 +207,228 @@ func (mr *MockUserServiceMockRecorder) ListAll() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAll", reflect.TypeOf((*MockUserService)(nil).ListAll))
 }
 
+// ListByStatus mocks base method.
+func (m *MockUserService) ListByStatus(arg0 string) ([]domain.UserDomain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByStatus", arg0)
+	ret0, _ := ret[0].([]domain.UserDomain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByStatus indicates an expected call of ListByStatus.
+func (mr *MockUserServiceMockRecorder) ListByStatus(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByStatus", reflect.TypeOf((*MockUserService)(nil).ListByStatus), arg0)
+}
+
+// ListDeleted mocks base method.
+func (m *MockUserService) ListDeleted() ([]domain.UserDomain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeleted")
+	ret0, _ := ret[0].([]domain.UserDomain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeleted indicates an expected call of ListDeleted.
+func (mr *MockUserServiceMockRecorder) ListDeleted() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeleted", reflect.TypeOf((*MockUserService)(nil).ListDeleted))
+}
+
+// ListLogins mocks base method.
+func (m *MockUserService) ListLogins(arg0 uuid.UUID, arg1 int) ([]domain.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLogins", arg0, arg1)
+	ret0, _ := ret[0].([]domain.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLogins indicates an expected call of ListLogins.
+func (mr *MockUserServiceMockRecorder) ListLogins(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLogins", reflect.TypeOf((*MockUserService)(nil).ListLogins), arg0, arg1)
+}
+
+// ListSessions mocks base method.
+func (m *MockUserService) ListSessions(arg0 uuid.UUID) ([]domain.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessions", arg0)
+	ret0, _ := ret[0].([]domain.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockUserServiceMockRecorder) ListSessions(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockUserService)(nil).ListSessions), arg0)
+}
+
 // Login mocks base method.
-func (m *MockUserService) Login(arg0, arg1 string) (string, error) {
+func (m *MockUserService) Login(arg0, arg1 string, arg2 domain.Client) (domain.LoginResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Login", arg0, arg1)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "Login", arg0, arg1, arg2)
+	ret0, _ := ret[0].(domain.LoginResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Login indicates an expected call of Login.
-func (mr *MockUserServiceMockRecorder) Login(arg0, arg1 any) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) Login(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUserService)(nil).Login), arg0, arg1, arg2)
+}
+
+// Purge mocks base method.
+func (m *MockUserService) Purge(arg0 bool) ([]domain.UserDomain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", arg0)
+	ret0, _ := ret[0].([]domain.UserDomain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockUserServiceMockRecorder) Purge(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockUserService)(nil).Purge), arg0)
+}
+
+// PurgeLoginHistory mocks base method.
+func (m *MockUserService) PurgeLoginHistory() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeLoginHistory")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeLoginHistory indicates an expected call of PurgeLoginHistory.
+func (mr *MockUserServiceMockRecorder) PurgeLoginHistory() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeLoginHistory", reflect.TypeOf((*MockUserService)(nil).PurgeLoginHistory))
+}
+
+// Reactivate mocks base method.
+func (m *MockUserService) Reactivate(arg0 uuid.UUID, arg1, arg2 string) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reactivate", arg0, arg1, arg2)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reactivate indicates an expected call of Reactivate.
+func (mr *MockUserServiceMockRecorder) Reactivate(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reactivate", reflect.TypeOf((*MockUserService)(nil).Reactivate), arg0, arg1, arg2)
+}
+
+// RequirePasswordChange mocks base method.
+func (m *MockUserService) RequirePasswordChange(arg0 uuid.UUID) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequirePasswordChange", arg0)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequirePasswordChange indicates an expected call of RequirePasswordChange.
+func (mr *MockUserServiceMockRecorder) RequirePasswordChange(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequirePasswordChange", reflect.TypeOf((*MockUserService)(nil).RequirePasswordChange), arg0)
+}
+
+// ResetPassword mocks base method.
+func (m *MockUserService) ResetPassword(arg0 uuid.UUID, arg1 string) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", arg0, arg1)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockUserServiceMockRecorder) ResetPassword(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockUserService)(nil).ResetPassword), arg0, arg1)
+}
+
+// Restore mocks base method.
+func (m *MockUserService) Restore(arg0 uuid.UUID) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockUserServiceMockRecorder) Restore(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockUserService)(nil).Restore), arg0)
+}
+
+// RevokeSession mocks base method.
+func (m *MockUserService) RevokeSession(arg0, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockUserServiceMockRecorder) RevokeSession(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockUserService)(nil).RevokeSession), arg0, arg1)
+}
+
+// Search mocks base method.
+func (m *MockUserService) Search(arg0 string, arg1, arg2 int) (domain.UserSearchPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", arg0, arg1, arg2)
+	ret0, _ := ret[0].(domain.UserSearchPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockUserServiceMockRecorder) Search(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockUserService)(nil).Search), arg0, arg1, arg2)
+}
+
+// SetRole mocks base method.
+func (m *MockUserService) SetRole(arg0 uuid.UUID, arg1 string) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRole", arg0, arg1)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetRole indicates an expected call of SetRole.
+func (mr *MockUserServiceMockRecorder) SetRole(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRole", reflect.TypeOf((*MockUserService)(nil).SetRole), arg0, arg1)
+}
+
+// Suspend mocks base method.
+func (m *MockUserService) Suspend(arg0 uuid.UUID, arg1, arg2 string, arg3 time.Time) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Suspend", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Suspend indicates an expected call of Suspend.
+func (mr *MockUserServiceMockRecorder) Suspend(arg0, arg1, arg2, arg3 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUserService)(nil).Login), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Suspend", reflect.TypeOf((*MockUserService)(nil).Suspend), arg0, arg1, arg2, arg3)
 }
 
 // Update mocks base method.
@@ -132,16 +447,16 @@ func (mr *MockUserServiceMockRecorder) Update(arg0, arg1 any) *gomock.Call {
 }
 
 // UpdatePassword mocks base method.
-func (m *MockUserService) UpdatePassword(arg0 uuid.UUID, arg1 string) (uuid.UUID, error) {
+func (m *MockUserService) UpdatePassword(arg0 uuid.UUID, arg1, arg2 string) (uuid.UUID, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdatePassword", arg0, arg1)
+	ret := m.ctrl.Call(m, "UpdatePassword", arg0, arg1, arg2)
 	ret0, _ := ret[0].(uuid.UUID)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdatePassword indicates an expected call of UpdatePassword.
-func (mr *MockUserServiceMockRecorder) UpdatePassword(arg0, arg1 any) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) UpdatePassword(arg0, arg1, arg2 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUserService)(nil).UpdatePassword), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUserService)(nil).UpdatePassword), arg0, arg1, arg2)
 }
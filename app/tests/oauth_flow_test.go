@@ -0,0 +1,598 @@
+package test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/controller"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/middleware"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/model"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/memory"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/pkg/authclient"
+	"github.com/PedroPereiraN/go-hexagonal/server"
+	"github.com/PedroPereiraN/go-hexagonal/services"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// TestOAuthFlow runs the authorization server over http like a relying party would
+func TestOAuthFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	users := mocks.NewMockUserRepository(ctrl)
+
+	email := "john@email.com"
+	password := "password@123"
+
+	uDomain, err := domain.CreateUser(uuid.New(), "John", email, "00000000000", password, time.Time{}, time.Time{}, time.Time{})
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when creating a new user struct", err.Error())
+	}
+
+	users.EXPECT().FindUserByEmail(email).Return(uDomain, nil).AnyTimes()
+	users.EXPECT().List(uDomain.Id).Return(uDomain, nil).AnyTimes()
+	users.EXPECT().UpdateLastLogin(uDomain.Id, gomock.Any()).Return(nil).AnyTimes()
+
+	// relying parties verify the ID tokens with the published key instead of a shared secret
+	signingKey, err := domain.GenerateSigningKey(domain.SigningES256)
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when generating the signing key", err.Error())
+	}
+
+	keyRing, err := service.NewStaticKeyRing(signingKey)
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when creating the key ring", err.Error())
+	}
+
+	defer service.SetKeyRing(service.CurrentKeyRing())
+	service.SetKeyRing(keyRing)
+
+	uService := service.NewUserService(users)
+	issuer := "https://id.example.com"
+	oService := service.NewOAuthService(memory.NewOAuthRepository(), users, issuer)
+	apiKeys := mocks.NewMockAPIKeyService(ctrl)
+	oController := controller.NewOAuthController(oService, uService, mocks.NewMockTwoFactorService(ctrl), apiKeys)
+	oidcController := controller.NewOIDCController(issuer, oService, keyRing)
+
+	router := gin.New()
+	router.GET("/oauth/authorize", oController.AuthorizePage)
+	router.POST("/oauth/authorize", oController.Authorize)
+	router.POST("/oauth/token", oController.Token)
+	router.POST("/oauth/revoke", oController.Revoke)
+	router.POST("/oauth/introspect", oController.Introspect)
+	router.GET("/.well-known/openid-configuration", oidcController.Discovery)
+	router.GET("/.well-known/jwks.json", oidcController.JWKS)
+	router.GET("/userinfo", oidcController.UserInfo)
+
+	// only admins register clients
+	admins := authenticatorFunc(func(token string) (domain.Identity, error) {
+		if token == "admin" {
+			return domain.Identity{UserId: uuid.New(), Email: "admin@email.com", Role: domain.RoleAdmin}, nil
+		}
+
+		return domain.Identity{}, errors.New("Invalid token")
+	})
+
+	server.AdminGroup(router, middleware.Authenticate(admins, apiKeys)).POST("/oauth/client", oController.CreateClient)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	// the redirects point to the relying party, they are only inspected
+	httpClient := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	redirectURI := "https://app.example.com/callback"
+	verifier := strings.Repeat("verifier", 6)
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	createClient := func(t *testing.T, body string) model.OAuthClientModel {
+		request, _ := http.NewRequest(http.MethodPost, server.URL + "/admin/oauth/client", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "Bearer admin")
+
+		response, err := httpClient.Do(request)
+
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when creating the client", err.Error())
+		}
+
+		defer response.Body.Close()
+
+		assert.EqualValues(t, http.StatusCreated, response.StatusCode)
+
+		var result model.OAuthClientModel
+
+		json.NewDecoder(response.Body).Decode(&result)
+
+		return result
+	}
+
+	get := func(t *testing.T, path string) *http.Response {
+		response, err := httpClient.Get(server.URL + path)
+
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when getting %s", err.Error(), path)
+		}
+
+		return response
+	}
+
+	postForm := func(t *testing.T, path string, form url.Values, basic ...string) *http.Response {
+		request, _ := http.NewRequest(http.MethodPost, server.URL + path, strings.NewReader(form.Encode()))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		if len(basic) == 2 {
+			request.SetBasicAuth(basic[0], basic[1])
+		}
+
+		response, err := httpClient.Do(request)
+
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when posting to %s", err.Error(), path)
+		}
+
+		return response
+	}
+
+	token := func(t *testing.T, form url.Values, basic ...string) (int, map[string]any) {
+		response := postForm(t, "/oauth/token", form, basic...)
+		defer response.Body.Close()
+
+		assert.EqualValues(t, "no-store", response.Header.Get("Cache-Control"))
+
+		body := map[string]any{}
+
+		json.NewDecoder(response.Body).Decode(&body)
+
+		return response.StatusCode, body
+	}
+
+	public := createClient(t, `{"name": "Single page app", "redirectUris": ["` + redirectURI + `"], "scopes": ["profile", "email"], "public": true}`)
+
+	assert.Empty(t, public.Secret)
+
+	authorizeParams := url.Values{
+		"response_type": {"code"},
+		"client_id": {public.Id},
+		"redirect_uri": {redirectURI},
+		"scope": {"profile"},
+		"state": {"xyz"},
+		"code_challenge": {challenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	// authorize logs in on the page and returns the code, params replace the default ones
+	authorize := func(t *testing.T, params ...url.Values) string {
+		form := url.Values{"action": {"approve"}, "email": {email}, "password": {password}}
+
+		for key, values := range authorizeParams {
+			form[key] = values
+		}
+
+		for _, extra := range params {
+			for key, values := range extra {
+				form[key] = values
+			}
+		}
+
+		response := postForm(t, "/oauth/authorize", form)
+		defer response.Body.Close()
+
+		assert.EqualValues(t, http.StatusFound, response.StatusCode)
+
+		location, _ := url.Parse(response.Header.Get("Location"))
+
+		assert.EqualValues(t, "app.example.com", location.Host)
+		assert.EqualValues(t, "xyz", location.Query().Get("state"))
+
+		return location.Query().Get("code")
+	}
+
+	t.Run("authorize_page", func(t *testing.T) {
+		response := get(t, "/oauth/authorize?" + authorizeParams.Encode())
+		defer response.Body.Close()
+
+		body, _ := io.ReadAll(response.Body)
+
+		assert.EqualValues(t, http.StatusOK, response.StatusCode)
+		assert.Contains(t, string(body), "Single page app wants to access your account")
+		assert.Contains(t, string(body), `name="password"`)
+	})
+
+	t.Run("authorize_unknown_redirect_uri_is_not_followed", func(t *testing.T) {
+		params := url.Values{"response_type": {"code"}, "client_id": {public.Id}, "redirect_uri": {"https://evil.example.com"}}
+
+		response := get(t, "/oauth/authorize?" + params.Encode())
+		defer response.Body.Close()
+
+		assert.EqualValues(t, http.StatusBadRequest, response.StatusCode)
+		assert.Empty(t, response.Header.Get("Location"))
+	})
+
+	t.Run("authorize_public_client_without_pkce", func(t *testing.T) {
+		params := url.Values{"response_type": {"code"}, "client_id": {public.Id}, "state": {"xyz"}}
+
+		response := get(t, "/oauth/authorize?" + params.Encode())
+		defer response.Body.Close()
+
+		location, _ := url.Parse(response.Header.Get("Location"))
+
+		assert.EqualValues(t, http.StatusFound, response.StatusCode)
+		assert.EqualValues(t, domain.OAuthInvalidRequest, location.Query().Get("error"))
+		assert.EqualValues(t, "xyz", location.Query().Get("state"))
+	})
+
+	t.Run("authorize_wrong_password", func(t *testing.T) {
+		form := url.Values{"action": {"approve"}, "email": {email}, "password": {"wrong@123"}}
+
+		for key, values := range authorizeParams {
+			form[key] = values
+		}
+
+		response := postForm(t, "/oauth/authorize", form)
+		defer response.Body.Close()
+
+		body, _ := io.ReadAll(response.Body)
+
+		assert.EqualValues(t, http.StatusUnauthorized, response.StatusCode)
+		assert.Contains(t, string(body), "Invalid email or password")
+	})
+
+	t.Run("authorize_denied", func(t *testing.T) {
+		form := url.Values{"action": {"deny"}}
+
+		for key, values := range authorizeParams {
+			form[key] = values
+		}
+
+		response := postForm(t, "/oauth/authorize", form)
+		defer response.Body.Close()
+
+		location, _ := url.Parse(response.Header.Get("Location"))
+
+		assert.EqualValues(t, http.StatusFound, response.StatusCode)
+		assert.EqualValues(t, domain.OAuthAccessDenied, location.Query().Get("error"))
+	})
+
+	t.Run("authorization_code_is_single_use", func(t *testing.T) {
+		code := authorize(t)
+
+		exchange := url.Values{
+			"grant_type": {domain.GrantAuthorizationCode},
+			"client_id": {public.Id},
+			"code": {code},
+			"redirect_uri": {redirectURI},
+			"code_verifier": {verifier},
+		}
+
+		status, body := token(t, exchange)
+
+		assert.EqualValues(t, http.StatusOK, status)
+		assert.EqualValues(t, "Bearer", body["token_type"])
+		assert.EqualValues(t, "profile", body["scope"])
+		assert.NotEmpty(t, body["refresh_token"])
+
+		// OAuth access tokens are for the relying parties, not for the /v1 routes
+		_, err := uService.Authenticate(body["access_token"].(string))
+
+		assert.EqualError(t, err, "Invalid token")
+
+		status, replayed := token(t, exchange)
+
+		assert.EqualValues(t, http.StatusBadRequest, status)
+		assert.EqualValues(t, domain.OAuthInvalidGrant, replayed["error"])
+	})
+
+	t.Run("refresh_rotation_and_revoke", func(t *testing.T) {
+		status, body := token(t, url.Values{
+			"grant_type": {domain.GrantAuthorizationCode},
+			"client_id": {public.Id},
+			"code": {authorize(t)},
+			"code_verifier": {verifier},
+		})
+
+		assert.EqualValues(t, http.StatusOK, status)
+
+		status, refreshed := token(t, url.Values{"grant_type": {domain.GrantRefreshToken}, "client_id": {public.Id}, "refresh_token": {body["refresh_token"].(string)}})
+
+		assert.EqualValues(t, http.StatusOK, status)
+		assert.NotEqual(t, body["refresh_token"], refreshed["refresh_token"])
+
+		response := postForm(t, "/oauth/revoke", url.Values{"client_id": {public.Id}, "token": {refreshed["refresh_token"].(string)}})
+		response.Body.Close()
+
+		assert.EqualValues(t, http.StatusOK, response.StatusCode)
+
+		status, body = token(t, url.Values{"grant_type": {domain.GrantRefreshToken}, "client_id": {public.Id}, "refresh_token": {refreshed["refresh_token"].(string)}})
+
+		assert.EqualValues(t, http.StatusBadRequest, status)
+		assert.EqualValues(t, domain.OAuthInvalidGrant, body["error"])
+	})
+
+	t.Run("wrong_code_verifier", func(t *testing.T) {
+		status, body := token(t, url.Values{
+			"grant_type": {domain.GrantAuthorizationCode},
+			"client_id": {public.Id},
+			"code": {authorize(t)},
+			"code_verifier": {strings.Repeat("x", 43)},
+		})
+
+		assert.EqualValues(t, http.StatusBadRequest, status)
+		assert.EqualValues(t, domain.OAuthInvalidGrant, body["error"])
+	})
+
+	t.Run("client_credentials", func(t *testing.T) {
+		confidential := createClient(t, `{"name": "Reports", "grantTypes": ["client_credentials"], "scopes": ["reports"]}`)
+
+		assert.NotEmpty(t, confidential.Secret)
+
+		status, body := token(t, url.Values{"grant_type": {domain.GrantClientCredentials}}, confidential.Id, confidential.Secret)
+
+		assert.EqualValues(t, http.StatusOK, status)
+		assert.EqualValues(t, "reports", body["scope"])
+		assert.Nil(t, body["refresh_token"])
+
+		status, body = token(t, url.Values{"grant_type": {domain.GrantClientCredentials}}, confidential.Id, "wrong")
+
+		assert.EqualValues(t, http.StatusUnauthorized, status)
+		assert.EqualValues(t, domain.OAuthInvalidClient, body["error"])
+
+		status, body = token(t, url.Values{"grant_type": {domain.GrantClientCredentials}, "client_id": {public.Id}})
+
+		assert.EqualValues(t, http.StatusBadRequest, status)
+		assert.EqualValues(t, domain.OAuthUnauthorizedClient, body["error"])
+	})
+
+	t.Run("verified_by_authclient", func(t *testing.T) {
+		tokenVerifier, err := authclient.NewVerifier(authclient.Config{JWKSURL: server.URL + "/.well-known/jwks.json", Issuer: issuer})
+
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when creating the verifier", err.Error())
+		}
+
+		status, body := token(t, url.Values{
+			"grant_type": {domain.GrantAuthorizationCode},
+			"client_id": {public.Id},
+			"code": {authorize(t)},
+			"code_verifier": {verifier},
+		})
+
+		assert.EqualValues(t, http.StatusOK, status)
+
+		claims, err := tokenVerifier.Verify(body["access_token"].(string))
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, authclient.TokenAccess, claims.Type())
+		assert.EqualValues(t, public.Id, claims.ClientId)
+
+		userId, ok := claims.User()
+
+		assert.True(t, ok)
+		assert.EqualValues(t, uDomain.Id, userId)
+	})
+
+		t.Run("introspect", func(t *testing.T) {
+		gateway := createClient(t, `{"name": "Gateway", "grantTypes": ["client_credentials"], "scopes": ["reports"]}`)
+
+		introspect := func(t *testing.T, token string, apiKey string, basic ...string) (int, map[string]any) {
+			request, _ := http.NewRequest(http.MethodPost, server.URL + "/oauth/introspect", strings.NewReader(url.Values{"token": {token}}.Encode()))
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			if apiKey != "" {
+				request.Header.Set("X-API-Key", apiKey)
+			}
+
+			if len(basic) == 2 {
+				request.SetBasicAuth(basic[0], basic[1])
+			}
+
+			response, err := httpClient.Do(request)
+
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when introspecting", err.Error())
+			}
+
+			defer response.Body.Close()
+
+			body := map[string]any{}
+
+			json.NewDecoder(response.Body).Decode(&body)
+
+			return response.StatusCode, body
+		}
+
+		_, tokens := token(t, url.Values{
+			"grant_type": {domain.GrantAuthorizationCode},
+			"client_id": {public.Id},
+			"code": {authorize(t)},
+			"code_verifier": {verifier},
+		})
+
+		status, body := introspect(t, tokens["access_token"].(string), "", gateway.Id, gateway.Secret)
+
+		assert.EqualValues(t, http.StatusOK, status)
+		assert.EqualValues(t, true, body["active"])
+		assert.EqualValues(t, domain.OAuthAccessToken, body["token_type"])
+		assert.EqualValues(t, public.Id, body["client_id"])
+		assert.EqualValues(t, uDomain.Id.String(), body["sub"])
+		assert.EqualValues(t, email, body["username"])
+		assert.EqualValues(t, "profile", body["scope"])
+		assert.EqualValues(t, issuer, body["iss"])
+		assert.NotEmpty(t, body["jti"])
+		assert.NotEmpty(t, body["exp"])
+
+		status, body = introspect(t, tokens["refresh_token"].(string), "", gateway.Id, gateway.Secret)
+
+		assert.EqualValues(t, http.StatusOK, status)
+		assert.EqualValues(t, domain.OAuthRefreshToken, body["token_type"])
+		assert.Nil(t, body["jti"])
+
+		response := postForm(t, "/oauth/revoke", url.Values{"client_id": {public.Id}, "token": {tokens["refresh_token"].(string)}})
+		response.Body.Close()
+
+		status, body = introspect(t, tokens["access_token"].(string), "", gateway.Id, gateway.Secret)
+
+		assert.EqualValues(t, http.StatusOK, status)
+		assert.EqualValues(t, map[string]any{"active": false}, body)
+
+		status, body = introspect(t, "unknown", "", gateway.Id, gateway.Secret)
+
+		assert.EqualValues(t, http.StatusOK, status)
+		assert.EqualValues(t, map[string]any{"active": false}, body)
+
+		// public clients can't prove who they are
+		status, body = introspect(t, "unknown", "", public.Id, "")
+
+		assert.EqualValues(t, http.StatusUnauthorized, status)
+		assert.EqualValues(t, domain.OAuthInvalidClient, body["error"])
+
+		status, _ = introspect(t, "unknown", "")
+
+		assert.EqualValues(t, http.StatusUnauthorized, status)
+
+		apiKeys.EXPECT().Authenticate("gohex_gateway").Return(domain.Identity{APIKeyId: uuid.New(), Scopes: []string{domain.ScopeTokensIntrospect}}, nil)
+
+		status, body = introspect(t, "unknown", "gohex_gateway")
+
+		assert.EqualValues(t, http.StatusOK, status)
+		assert.EqualValues(t, false, body["active"])
+
+		apiKeys.EXPECT().Authenticate("gohex_reader").Return(domain.Identity{APIKeyId: uuid.New(), Scopes: []string{domain.ScopeUsersRead}}, nil)
+
+		status, body = introspect(t, "unknown", "gohex_reader")
+
+		assert.EqualValues(t, http.StatusForbidden, status)
+		assert.EqualValues(t, "insufficient_scope", body["error"])
+
+		apiKeys.EXPECT().Authenticate("gohex_revoked").Return(domain.Identity{}, errors.New("Invalid API key"))
+
+		status, body = introspect(t, "unknown", "gohex_revoked")
+
+		assert.EqualValues(t, http.StatusUnauthorized, status)
+		assert.EqualValues(t, domain.OAuthInvalidClient, body["error"])
+	})
+
+		t.Run("discovery", func(t *testing.T) {
+		response := get(t, "/.well-known/openid-configuration")
+		defer response.Body.Close()
+
+		var configuration model.OIDCConfigurationModel
+
+		json.NewDecoder(response.Body).Decode(&configuration)
+
+		assert.EqualValues(t, http.StatusOK, response.StatusCode)
+		assert.EqualValues(t, issuer, configuration.Issuer)
+		assert.EqualValues(t, issuer + "/oauth/token", configuration.TokenEndpoint)
+		assert.EqualValues(t, issuer + "/userinfo", configuration.UserInfoEndpoint)
+		assert.EqualValues(t, issuer + "/.well-known/jwks.json", configuration.JWKSURI)
+		assert.EqualValues(t, []string{domain.SigningES256}, configuration.IDTokenSigningAlgValuesSupported)
+	})
+
+	t.Run("openid_connect", func(t *testing.T) {
+		oidcClient := createClient(t, `{"name": "Relying party", "redirectUris": ["` + redirectURI + `"], "scopes": ["openid", "profile", "email"]}`)
+
+		code := authorize(t, url.Values{"client_id": {oidcClient.Id}, "scope": {"openid email"}, "nonce": {"n-0S6_WzA2Mj"}})
+
+		status, body := token(t, url.Values{"grant_type": {domain.GrantAuthorizationCode}, "code": {code}, "code_verifier": {verifier}}, oidcClient.Id, oidcClient.Secret)
+
+		assert.EqualValues(t, http.StatusOK, status)
+
+		response := get(t, "/.well-known/jwks.json")
+
+		var jwks model.JWKSModel
+
+		json.NewDecoder(response.Body).Decode(&jwks)
+		response.Body.Close()
+
+		assert.Len(t, jwks.Keys, 1)
+		assert.EqualValues(t, "EC", jwks.Keys[0].Kty)
+
+		claims := jwt.MapClaims{}
+
+		_, err := jwt.ParseWithClaims(body["id_token"].(string), claims, func(token *jwt.Token) (any, error) {
+			assert.EqualValues(t, jwks.Keys[0].Kid, token.Header["kid"])
+
+			x, _ := base64.RawURLEncoding.DecodeString(jwks.Keys[0].X)
+			y, _ := base64.RawURLEncoding.DecodeString(jwks.Keys[0].Y)
+
+			return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+		}, jwt.WithValidMethods([]string{domain.SigningES256}))
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, issuer, claims["iss"])
+		assert.EqualValues(t, oidcClient.Id, claims["aud"])
+		assert.EqualValues(t, uDomain.Id.String(), claims["sub"])
+		assert.EqualValues(t, "n-0S6_WzA2Mj", claims["nonce"])
+		assert.EqualValues(t, email, claims["email"])
+
+		request, _ := http.NewRequest(http.MethodGet, server.URL + "/userinfo", nil)
+		request.Header.Set("Authorization", "Bearer " + body["access_token"].(string))
+
+		response, err = httpClient.Do(request)
+
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when calling userinfo", err.Error())
+		}
+
+		defer response.Body.Close()
+
+		userInfo := map[string]any{}
+
+		json.NewDecoder(response.Body).Decode(&userInfo)
+
+		assert.EqualValues(t, http.StatusOK, response.StatusCode)
+		assert.EqualValues(t, map[string]any{"sub": uDomain.Id.String(), "email": email, "email_verified": false}, userInfo)
+	})
+
+	t.Run("userinfo_without_openid_scope", func(t *testing.T) {
+		status, body := token(t, url.Values{
+			"grant_type": {domain.GrantAuthorizationCode},
+			"client_id": {public.Id},
+			"code": {authorize(t)},
+			"code_verifier": {verifier},
+		})
+
+		assert.EqualValues(t, http.StatusOK, status)
+		assert.Nil(t, body["id_token"])
+
+		request, _ := http.NewRequest(http.MethodGet, server.URL + "/userinfo", nil)
+		request.Header.Set("Authorization", "Bearer " + body["access_token"].(string))
+
+		response, err := httpClient.Do(request)
+
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when calling userinfo", err.Error())
+		}
+
+		response.Body.Close()
+
+		assert.EqualValues(t, http.StatusForbidden, response.StatusCode)
+	})
+}
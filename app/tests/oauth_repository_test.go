@@ -0,0 +1,147 @@
+package test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/repository"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOAuthRepository(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	oRepository := repository.NewOAuthRepository(db)
+
+	tokenColumns := []string{"id", "grantId", "tokenType", "hash", "clientId", "userId", "scope", "createdAt", "expiresAt", "revokedAt"}
+
+	t.Run("create_client", func(t *testing.T) {
+		client := domain.OAuthClient{
+			Id: "client",
+			Name: "web app",
+			RedirectURIs: []string{"https://app.example.com/callback", "http://localhost:3000/callback"},
+			GrantTypes: []string{domain.GrantAuthorizationCode, domain.GrantRefreshToken},
+			Scopes: []string{"profile", "email"},
+			CreatedAt: time.Now(),
+		}
+
+		mock.ExpectExec("INSERT INTO oauth_clients").
+			WithArgs("client", "web app", "", "https://app.example.com/callback http://localhost:3000/callback", "authorization_code refresh_token", "profile email", client.CreatedAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, oRepository.CreateClient(client))
+	})
+
+	t.Run("find_client", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM oauth_clients WHERE id = (.+)").
+			WithArgs("client").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "secretHash", "redirectUris", "grantTypes", "scopes", "createdAt"}).
+				AddRow("client", "web app", "hash", "https://app.example.com/callback", "authorization_code", "", time.Now()))
+
+		client, err := oRepository.FindClient("client")
+
+		assert.NoError(t, err)
+		assert.True(t, client.Confidential())
+		assert.EqualValues(t, []string{"https://app.example.com/callback"}, client.RedirectURIs)
+		assert.Empty(t, client.Scopes)
+	})
+
+	t.Run("delete_client_not_found", func(t *testing.T) {
+		mock.ExpectQuery("DELETE FROM oauth_clients WHERE id = (.+) RETURNING id").
+			WithArgs("client").
+			WillReturnError(sql.ErrNoRows)
+
+		assert.EqualError(t, oRepository.DeleteClient("client"), "sql: no rows in result set")
+	})
+
+	t.Run("use_authorization_code", func(t *testing.T) {
+		userId := uuid.New()
+		now := time.Now()
+
+		mock.ExpectQuery("UPDATE oauth_authorization_codes SET usedAt = NOW\\(\\) WHERE codeHash = (.+) AND usedAt IS NULL RETURNING").
+			WithArgs("hash").
+			WillReturnRows(sqlmock.NewRows([]string{"codeHash", "clientId", "userId", "redirectUri", "scope", "codeChallenge", "codeChallengeMethod", "nonce", "createdAt", "expiresAt"}).
+				AddRow("hash", "client", userId, "https://app.example.com/callback", "openid", "challenge", "S256", "n-0S6_WzA2Mj", now, now.Add(time.Minute)))
+
+		code, err := oRepository.UseAuthorizationCode("hash")
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, userId, code.UserId)
+		assert.EqualValues(t, "challenge", code.CodeChallenge)
+		assert.EqualValues(t, "n-0S6_WzA2Mj", code.Nonce)
+	})
+
+	t.Run("use_authorization_code_twice", func(t *testing.T) {
+		mock.ExpectQuery("UPDATE oauth_authorization_codes SET usedAt = NOW\\(\\)").
+			WithArgs("hash").
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := oRepository.UseAuthorizationCode("hash")
+
+		assert.EqualError(t, err, "sql: no rows in result set")
+	})
+
+	t.Run("create_client_credentials_token", func(t *testing.T) {
+		token := domain.OAuthToken{Id: uuid.New(), GrantId: uuid.New(), Type: domain.OAuthAccessToken, ClientId: "client", Scope: "profile", CreatedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)}
+
+		mock.ExpectExec("INSERT INTO oauth_tokens").
+			WithArgs(token.Id, token.GrantId, domain.OAuthAccessToken, nil, "client", nil, "profile", token.CreatedAt, token.ExpiresAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, oRepository.CreateToken(token))
+	})
+
+	t.Run("find_token_by_hash", func(t *testing.T) {
+		userId := uuid.New()
+		now := time.Now()
+
+		mock.ExpectQuery("SELECT (.+) FROM oauth_tokens WHERE hash = (.+)").
+			WithArgs("hash").
+			WillReturnRows(sqlmock.NewRows(tokenColumns).AddRow(uuid.New(), uuid.New(), domain.OAuthRefreshToken, "hash", "client", userId, "profile", now, now.Add(time.Hour), now))
+
+		token, err := oRepository.FindTokenByHash("hash")
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, userId, token.UserId)
+		assert.False(t, token.Active(now))
+	})
+
+	t.Run("find_client_credentials_token", func(t *testing.T) {
+		id := uuid.New()
+		now := time.Now()
+
+		mock.ExpectQuery("SELECT (.+) FROM oauth_tokens WHERE id = (.+)").
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows(tokenColumns).AddRow(id, uuid.New(), domain.OAuthAccessToken, nil, "client", nil, "", now, now.Add(time.Hour), nil))
+
+		token, err := oRepository.FindToken(id)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, uuid.Nil, token.UserId)
+		assert.True(t, token.Active(now))
+	})
+
+	t.Run("revoke_grant", func(t *testing.T) {
+		grantId := uuid.New()
+
+		mock.ExpectExec("UPDATE oauth_tokens SET revokedAt = NOW\\(\\) WHERE grantId = (.+) AND revokedAt IS NULL").
+			WithArgs(grantId).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		assert.NoError(t, oRepository.RevokeGrant(grantId))
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,499 @@
+package test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/services"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestOAuthService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repository := mocks.NewMockOAuthRepository(ctrl)
+	users := mocks.NewMockUserRepository(ctrl)
+	oService := service.NewOAuthService(repository, users, "https://id.example.com")
+
+	user := domain.UserDomain{Id: uuid.New(), Email: "john@email.com"}
+	suspended := domain.UserDomain{Id: user.Id, Email: user.Email, Status: domain.UserStatus{State: domain.StatusSuspended, Reason: "spam"}}
+	passwordChanged := domain.UserDomain{Id: user.Id, Email: user.Email, PasswordChangedAt: time.Now().Add(-time.Minute)}
+	client := domain.OAuthClient{
+		Id: "client",
+		Name: "web app",
+		SecretHash: domain.HashOAuthSecret("secret"),
+		RedirectURIs: []string{"https://app.example.com/callback"},
+		GrantTypes: []string{domain.GrantAuthorizationCode, domain.GrantRefreshToken},
+		Scopes: []string{"profile", "email"},
+	}
+
+	oauthErrorCode := func(err error) string {
+		var oauthErr *domain.OAuthError
+
+		if !errors.As(err, &oauthErr) {
+			return ""
+		}
+
+		return oauthErr.Code
+	}
+
+	t.Run("create_client_invalid_redirect_uri", func(t *testing.T) {
+		_, _, err := oService.CreateClient(domain.OAuthClient{Name: "web app", RedirectURIs: []string{"/callback"}}, true)
+
+		assert.EqualError(t, err, "Invalid redirect uri: /callback")
+	})
+
+	t.Run("create_client_invalid_scope", func(t *testing.T) {
+		_, _, err := oService.CreateClient(domain.OAuthClient{Name: "web app", RedirectURIs: []string{"https://app.example.com/callback"}, Scopes: []string{"pro file"}}, true)
+
+		assert.EqualError(t, err, "Invalid scope: pro file")
+	})
+
+	t.Run("create_public_client_credentials", func(t *testing.T) {
+		_, _, err := oService.CreateClient(domain.OAuthClient{Name: "spa", GrantTypes: []string{domain.GrantClientCredentials}}, false)
+
+		assert.EqualError(t, err, "Public clients can't use client_credentials")
+	})
+
+	t.Run("create_confidential_client", func(t *testing.T) {
+		var stored domain.OAuthClient
+
+		repository.EXPECT().CreateClient(gomock.Any()).DoAndReturn(func(dto domain.OAuthClient) error {
+			stored = dto
+
+			return nil
+		})
+
+		result, secret, err := oService.CreateClient(domain.OAuthClient{Name: "web app", RedirectURIs: []string{"https://app.example.com/callback"}}, true)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, secret)
+		assert.EqualValues(t, domain.HashOAuthSecret(secret), stored.SecretHash)
+		assert.EqualValues(t, []string{domain.GrantAuthorizationCode, domain.GrantRefreshToken}, result.GrantTypes)
+	})
+
+	t.Run("validate_authorization_defaults", func(t *testing.T) {
+		repository.EXPECT().FindClient("client").Return(client, nil)
+
+		request, _, err := oService.ValidateAuthorization(domain.AuthorizationRequest{ResponseType: "code", ClientId: "client"})
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, "https://app.example.com/callback", request.RedirectURI)
+		assert.EqualValues(t, "profile email", request.Scope)
+	})
+
+	t.Run("validate_authorization_scope_not_allowed", func(t *testing.T) {
+		repository.EXPECT().FindClient("client").Return(client, nil)
+
+		request, _, err := oService.ValidateAuthorization(domain.AuthorizationRequest{ResponseType: "code", ClientId: "client", Scope: "admin"})
+
+		assert.EqualValues(t, domain.OAuthInvalidScope, oauthErrorCode(err))
+		assert.NotEmpty(t, request.RedirectURI)
+	})
+
+	t.Run("validate_authorization_plain_pkce", func(t *testing.T) {
+		repository.EXPECT().FindClient("client").Return(client, nil)
+
+		_, _, err := oService.ValidateAuthorization(domain.AuthorizationRequest{ResponseType: "code", ClientId: "client", CodeChallenge: "challenge", CodeChallengeMethod: "plain"})
+
+		assert.EqualValues(t, domain.OAuthInvalidRequest, oauthErrorCode(err))
+	})
+
+	t.Run("token_wrong_secret", func(t *testing.T) {
+		repository.EXPECT().FindClient("client").Return(client, nil)
+
+		_, err := oService.Token(domain.TokenRequest{GrantType: domain.GrantAuthorizationCode, ClientId: "client", ClientSecret: "wrong", Code: "code"})
+
+		assert.EqualValues(t, domain.OAuthInvalidClient, oauthErrorCode(err))
+	})
+
+	t.Run("token_expired_code", func(t *testing.T) {
+		repository.EXPECT().FindClient("client").Return(client, nil)
+		repository.EXPECT().UseAuthorizationCode(domain.HashOAuthSecret("code")).Return(domain.AuthorizationCode{ClientId: "client", UserId: user.Id, ExpiresAt: time.Now().Add(-time.Second)}, nil)
+
+		_, err := oService.Token(domain.TokenRequest{GrantType: domain.GrantAuthorizationCode, ClientId: "client", ClientSecret: "secret", Code: "code"})
+
+		assert.EqualValues(t, domain.OAuthInvalidGrant, oauthErrorCode(err))
+	})
+
+	t.Run("token_code_of_deleted_user", func(t *testing.T) {
+		repository.EXPECT().FindClient("client").Return(client, nil)
+		repository.EXPECT().UseAuthorizationCode(gomock.Any()).Return(domain.AuthorizationCode{ClientId: "client", UserId: user.Id, ExpiresAt: time.Now().Add(time.Minute)}, nil)
+		users.EXPECT().List(user.Id).Return(domain.UserDomain{}, sql.ErrNoRows)
+
+		_, err := oService.Token(domain.TokenRequest{GrantType: domain.GrantAuthorizationCode, ClientId: "client", ClientSecret: "secret", Code: "code"})
+
+		assert.EqualValues(t, domain.OAuthInvalidGrant, oauthErrorCode(err))
+	})
+
+	t.Run("exchange_code_issues_id_token", func(t *testing.T) {
+		user := domain.UserDomain{Id: user.Id, Name: "John", Email: "john@email.com", Phone: "00000000000"}
+
+		repository.EXPECT().FindClient("client").Return(client, nil)
+		repository.EXPECT().UseAuthorizationCode(gomock.Any()).Return(domain.AuthorizationCode{ClientId: "client", UserId: user.Id, Scope: "openid email", Nonce: "n-0S6_WzA2Mj", ExpiresAt: time.Now().Add(time.Minute)}, nil)
+		users.EXPECT().List(user.Id).Return(user, nil)
+		repository.EXPECT().CreateToken(gomock.Any()).Return(nil).Times(2)
+
+		response, err := oService.Token(domain.TokenRequest{GrantType: domain.GrantAuthorizationCode, ClientId: "client", ClientSecret: "secret", Code: "code"})
+
+		assert.NoError(t, err)
+
+		claims := jwt.MapClaims{}
+
+		_, _, err = jwt.NewParser().ParseUnverified(response.IDToken, claims)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, "https://id.example.com", claims["iss"])
+		assert.EqualValues(t, "client", claims["aud"])
+		assert.EqualValues(t, user.Id.String(), claims["sub"])
+		assert.EqualValues(t, "n-0S6_WzA2Mj", claims["nonce"])
+		assert.EqualValues(t, "john@email.com", claims["email"])
+		assert.EqualValues(t, false, claims["email_verified"])
+		assert.NotContains(t, claims, "name")
+		assert.NotContains(t, claims, "phone_number")
+	})
+
+	t.Run("exchange_code_without_openid", func(t *testing.T) {
+		repository.EXPECT().FindClient("client").Return(client, nil)
+		repository.EXPECT().UseAuthorizationCode(gomock.Any()).Return(domain.AuthorizationCode{ClientId: "client", UserId: user.Id, Scope: "email", ExpiresAt: time.Now().Add(time.Minute)}, nil)
+		users.EXPECT().List(user.Id).Return(user, nil)
+		repository.EXPECT().CreateToken(gomock.Any()).Return(nil).Times(2)
+
+		response, err := oService.Token(domain.TokenRequest{GrantType: domain.GrantAuthorizationCode, ClientId: "client", ClientSecret: "secret", Code: "code"})
+
+		assert.NoError(t, err)
+		assert.Empty(t, response.IDToken)
+	})
+
+	t.Run("userinfo_of_client_credentials_token", func(t *testing.T) {
+		machine := domain.OAuthClient{Id: "machine", SecretHash: domain.HashOAuthSecret("secret"), GrantTypes: []string{domain.GrantClientCredentials}, Scopes: []string{domain.ScopeOpenID}}
+
+		var stored domain.OAuthToken
+
+		repository.EXPECT().FindClient("machine").Return(machine, nil)
+		repository.EXPECT().CreateToken(gomock.Any()).DoAndReturn(func(token domain.OAuthToken) error {
+			stored = token
+
+			return nil
+		})
+
+		response, err := oService.Token(domain.TokenRequest{GrantType: domain.GrantClientCredentials, ClientId: "machine", ClientSecret: "secret"})
+
+		assert.NoError(t, err)
+		assert.Empty(t, response.IDToken)
+
+		repository.EXPECT().FindToken(stored.Id).Return(stored, nil)
+
+		_, err = oService.UserInfo(response.AccessToken)
+
+		assert.EqualError(t, err, "Invalid token")
+	})
+
+	t.Run("userinfo_of_user_token", func(t *testing.T) {
+		token := domain.OAuthToken{Id: uuid.New(), Type: domain.OAuthAccessToken, ClientId: "client", UserId: user.Id, Scope: "openid phone", ExpiresAt: time.Now().Add(time.Hour)}
+		user := domain.UserDomain{Id: user.Id, Name: "John", Email: "john@email.com", Phone: "00000000000"}
+
+		repository.EXPECT().FindClient("client").Return(client, nil)
+		repository.EXPECT().FindTokenByHash(gomock.Any()).Return(domain.OAuthToken{Id: uuid.New(), Type: domain.OAuthRefreshToken, ClientId: "client", UserId: user.Id, Scope: "openid phone", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		repository.EXPECT().RevokeToken(gomock.Any()).Return(nil)
+		users.EXPECT().List(user.Id).Return(user, nil).Times(2)
+		repository.EXPECT().CreateToken(gomock.Any()).DoAndReturn(func(issued domain.OAuthToken) error {
+			if issued.Type == domain.OAuthAccessToken {
+				token.Id = issued.Id
+			}
+
+			return nil
+		}).Times(2)
+
+		response, err := oService.Token(domain.TokenRequest{GrantType: domain.GrantRefreshToken, ClientId: "client", ClientSecret: "secret", RefreshToken: "refresh"})
+
+		assert.NoError(t, err)
+
+		repository.EXPECT().FindToken(gomock.Any()).Return(token, nil)
+
+		claims, err := oService.UserInfo(response.AccessToken)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, map[string]any{"sub": user.Id.String(), "phone_number": "00000000000", "phone_number_verified": false}, claims)
+	})
+
+	t.Run("userinfo_of_revoked_token", func(t *testing.T) {
+		repository.EXPECT().FindClient("client").Return(client, nil)
+		repository.EXPECT().FindTokenByHash(gomock.Any()).Return(domain.OAuthToken{Id: uuid.New(), Type: domain.OAuthRefreshToken, ClientId: "client", UserId: user.Id, Scope: "openid", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		repository.EXPECT().RevokeToken(gomock.Any()).Return(nil)
+		users.EXPECT().List(user.Id).Return(user, nil)
+		repository.EXPECT().CreateToken(gomock.Any()).Return(nil).Times(2)
+
+		response, err := oService.Token(domain.TokenRequest{GrantType: domain.GrantRefreshToken, ClientId: "client", ClientSecret: "secret", RefreshToken: "refresh"})
+
+		assert.NoError(t, err)
+
+		repository.EXPECT().FindToken(gomock.Any()).Return(domain.OAuthToken{Type: domain.OAuthAccessToken, UserId: user.Id, Scope: "openid", ExpiresAt: time.Now().Add(time.Hour), RevokedAt: time.Now()}, nil)
+
+		_, err = oService.UserInfo(response.AccessToken)
+
+		assert.EqualError(t, err, "Invalid token")
+	})
+
+	t.Run("userinfo_of_suspended_user", func(t *testing.T) {
+		repository.EXPECT().FindClient("client").Return(client, nil)
+		repository.EXPECT().FindTokenByHash(gomock.Any()).Return(domain.OAuthToken{Id: uuid.New(), Type: domain.OAuthRefreshToken, ClientId: "client", UserId: user.Id, Scope: "openid", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		repository.EXPECT().RevokeToken(gomock.Any()).Return(nil)
+		users.EXPECT().List(user.Id).Return(user, nil)
+		repository.EXPECT().CreateToken(gomock.Any()).Return(nil).Times(2)
+
+		response, err := oService.Token(domain.TokenRequest{GrantType: domain.GrantRefreshToken, ClientId: "client", ClientSecret: "secret", RefreshToken: "refresh"})
+
+		assert.NoError(t, err)
+
+		// suspended after the token was issued
+		repository.EXPECT().FindToken(gomock.Any()).Return(domain.OAuthToken{Type: domain.OAuthAccessToken, UserId: user.Id, Scope: "openid", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		users.EXPECT().List(user.Id).Return(suspended, nil)
+
+		_, err = oService.UserInfo(response.AccessToken)
+
+		assert.EqualError(t, err, "Invalid token")
+	})
+
+	t.Run("userinfo_of_token_issued_before_password_change", func(t *testing.T) {
+		repository.EXPECT().FindClient("client").Return(client, nil)
+		repository.EXPECT().FindTokenByHash(gomock.Any()).Return(domain.OAuthToken{Id: uuid.New(), Type: domain.OAuthRefreshToken, ClientId: "client", UserId: user.Id, Scope: "openid", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		repository.EXPECT().RevokeToken(gomock.Any()).Return(nil)
+		users.EXPECT().List(user.Id).Return(user, nil)
+		repository.EXPECT().CreateToken(gomock.Any()).Return(nil).Times(2)
+
+		response, err := oService.Token(domain.TokenRequest{GrantType: domain.GrantRefreshToken, ClientId: "client", ClientSecret: "secret", RefreshToken: "refresh"})
+
+		assert.NoError(t, err)
+
+		repository.EXPECT().FindToken(gomock.Any()).Return(domain.OAuthToken{Type: domain.OAuthAccessToken, UserId: user.Id, Scope: "openid", CreatedAt: time.Now().Add(-time.Hour), ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		users.EXPECT().List(user.Id).Return(passwordChanged, nil)
+
+		_, err = oService.UserInfo(response.AccessToken)
+
+		assert.EqualError(t, err, "Invalid token")
+	})
+
+	t.Run("userinfo_of_token_from_other_issuer", func(t *testing.T) {
+		other := service.NewOAuthService(repository, users, "https://other.example.com")
+		machine := domain.OAuthClient{Id: "machine", SecretHash: domain.HashOAuthSecret("secret"), GrantTypes: []string{domain.GrantClientCredentials}}
+
+		repository.EXPECT().FindClient("machine").Return(machine, nil)
+		repository.EXPECT().CreateToken(gomock.Any()).Return(nil)
+
+		response, _ := other.Token(domain.TokenRequest{GrantType: domain.GrantClientCredentials, ClientId: "machine", ClientSecret: "secret"})
+
+		_, err := oService.UserInfo(response.AccessToken)
+
+		assert.EqualError(t, err, "Invalid token")
+	})
+
+	t.Run("refresh_narrows_scope", func(t *testing.T) {
+		stored := domain.OAuthToken{Id: uuid.New(), GrantId: uuid.New(), Type: domain.OAuthRefreshToken, ClientId: "client", UserId: user.Id, Scope: "profile email", ExpiresAt: time.Now().Add(time.Hour)}
+		issued := []domain.OAuthToken{}
+
+		repository.EXPECT().FindClient("client").Return(client, nil)
+		repository.EXPECT().FindTokenByHash(domain.HashOAuthSecret("refresh")).Return(stored, nil)
+		repository.EXPECT().RevokeToken(stored.Id).Return(nil)
+		users.EXPECT().List(user.Id).Return(user, nil)
+		repository.EXPECT().CreateToken(gomock.Any()).DoAndReturn(func(token domain.OAuthToken) error {
+			issued = append(issued, token)
+
+			return nil
+		}).Times(2)
+
+		response, err := oService.Token(domain.TokenRequest{GrantType: domain.GrantRefreshToken, ClientId: "client", ClientSecret: "secret", RefreshToken: "refresh", Scope: "email"})
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, "email", response.Scope)
+		assert.EqualValues(t, "email", issued[0].Scope)
+		assert.EqualValues(t, "profile email", issued[1].Scope)
+		assert.EqualValues(t, stored.GrantId, issued[1].GrantId)
+	})
+
+	t.Run("refresh_of_suspended_user", func(t *testing.T) {
+		stored := domain.OAuthToken{Id: uuid.New(), GrantId: uuid.New(), Type: domain.OAuthRefreshToken, ClientId: "client", UserId: user.Id, Scope: "profile", ExpiresAt: time.Now().Add(time.Hour)}
+
+		repository.EXPECT().FindClient("client").Return(client, nil)
+		repository.EXPECT().FindTokenByHash(gomock.Any()).Return(stored, nil)
+		repository.EXPECT().RevokeToken(stored.Id).Return(nil)
+		users.EXPECT().List(user.Id).Return(suspended, nil)
+
+		_, err := oService.Token(domain.TokenRequest{GrantType: domain.GrantRefreshToken, ClientId: "client", ClientSecret: "secret", RefreshToken: "refresh"})
+
+		assert.EqualValues(t, domain.OAuthInvalidGrant, oauthErrorCode(err))
+	})
+
+	t.Run("refresh_after_password_change", func(t *testing.T) {
+		stored := domain.OAuthToken{Id: uuid.New(), GrantId: uuid.New(), Type: domain.OAuthRefreshToken, ClientId: "client", UserId: user.Id, Scope: "profile", CreatedAt: time.Now().Add(-time.Hour), ExpiresAt: time.Now().Add(time.Hour)}
+
+		repository.EXPECT().FindClient("client").Return(client, nil)
+		repository.EXPECT().FindTokenByHash(gomock.Any()).Return(stored, nil)
+		repository.EXPECT().RevokeToken(stored.Id).Return(nil)
+		users.EXPECT().List(user.Id).Return(passwordChanged, nil)
+
+		_, err := oService.Token(domain.TokenRequest{GrantType: domain.GrantRefreshToken, ClientId: "client", ClientSecret: "secret", RefreshToken: "refresh"})
+
+		assert.EqualValues(t, domain.OAuthInvalidGrant, oauthErrorCode(err))
+	})
+
+	t.Run("refresh_widens_scope", func(t *testing.T) {
+		stored := domain.OAuthToken{Id: uuid.New(), GrantId: uuid.New(), Type: domain.OAuthRefreshToken, ClientId: "client", UserId: user.Id, Scope: "profile", ExpiresAt: time.Now().Add(time.Hour)}
+
+		repository.EXPECT().FindClient("client").Return(client, nil)
+		repository.EXPECT().FindTokenByHash(gomock.Any()).Return(stored, nil)
+
+		_, err := oService.Token(domain.TokenRequest{GrantType: domain.GrantRefreshToken, ClientId: "client", ClientSecret: "secret", RefreshToken: "refresh", Scope: "profile email"})
+
+		assert.EqualValues(t, domain.OAuthInvalidScope, oauthErrorCode(err))
+	})
+
+	t.Run("reused_refresh_token_revokes_grant", func(t *testing.T) {
+		stored := domain.OAuthToken{Id: uuid.New(), GrantId: uuid.New(), Type: domain.OAuthRefreshToken, ClientId: "client", UserId: user.Id, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: time.Now()}
+
+		repository.EXPECT().FindClient("client").Return(client, nil)
+		repository.EXPECT().FindTokenByHash(gomock.Any()).Return(stored, nil)
+		repository.EXPECT().RevokeGrant(stored.GrantId).Return(nil)
+
+		_, err := oService.Token(domain.TokenRequest{GrantType: domain.GrantRefreshToken, ClientId: "client", ClientSecret: "secret", RefreshToken: "refresh"})
+
+		assert.EqualValues(t, domain.OAuthInvalidGrant, oauthErrorCode(err))
+	})
+
+	t.Run("refresh_token_of_other_client", func(t *testing.T) {
+		repository.EXPECT().FindClient("client").Return(client, nil)
+		repository.EXPECT().FindTokenByHash(gomock.Any()).Return(domain.OAuthToken{Type: domain.OAuthRefreshToken, ClientId: "other", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+		_, err := oService.Token(domain.TokenRequest{GrantType: domain.GrantRefreshToken, ClientId: "client", ClientSecret: "secret", RefreshToken: "refresh"})
+
+		assert.EqualValues(t, domain.OAuthInvalidGrant, oauthErrorCode(err))
+	})
+
+	t.Run("unsupported_grant", func(t *testing.T) {
+		repository.EXPECT().FindClient("client").Return(client, nil)
+
+		_, err := oService.Token(domain.TokenRequest{GrantType: "password", ClientId: "client", ClientSecret: "secret"})
+
+		assert.EqualValues(t, domain.OAuthUnsupportedGrantType, oauthErrorCode(err))
+	})
+
+	t.Run("revoke_token_of_other_client_is_ignored", func(t *testing.T) {
+		repository.EXPECT().FindClient("client").Return(client, nil)
+		repository.EXPECT().FindTokenByHash(gomock.Any()).Return(domain.OAuthToken{GrantId: uuid.New(), ClientId: "other"}, nil)
+
+		assert.NoError(t, oService.Revoke("client", "secret", "refresh"))
+	})
+
+	t.Run("revoke_unknown_token", func(t *testing.T) {
+		repository.EXPECT().FindClient("client").Return(client, nil)
+		repository.EXPECT().FindTokenByHash(gomock.Any()).Return(domain.OAuthToken{}, sql.ErrNoRows)
+
+		assert.NoError(t, oService.Revoke("client", "secret", "not-a-token"))
+	})
+
+	t.Run("authenticate_public_client", func(t *testing.T) {
+		repository.EXPECT().FindClient("spa").Return(domain.OAuthClient{Id: "spa"}, nil)
+
+		_, err := oService.AuthenticateClient("spa", "")
+
+		assert.EqualValues(t, domain.OAuthInvalidClient, oauthErrorCode(err))
+	})
+
+	t.Run("introspect_refresh_token", func(t *testing.T) {
+		stored := domain.OAuthToken{Id: uuid.New(), Type: domain.OAuthRefreshToken, ClientId: "client", UserId: user.Id, Scope: "profile", CreatedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)}
+
+		repository.EXPECT().FindTokenByHash(domain.HashOAuthSecret("refresh")).Return(stored, nil)
+		users.EXPECT().List(user.Id).Return(user, nil)
+
+		result, err := oService.Introspect("refresh")
+
+		assert.NoError(t, err)
+		assert.True(t, result.Active)
+		assert.EqualValues(t, user.Id.String(), result.Subject)
+		assert.EqualValues(t, user.Email, result.Username)
+		assert.EqualValues(t, "", result.TokenId)
+	})
+
+	t.Run("introspect_token_of_deleted_user", func(t *testing.T) {
+		stored := domain.OAuthToken{Id: uuid.New(), Type: domain.OAuthRefreshToken, ClientId: "client", UserId: user.Id, ExpiresAt: time.Now().Add(time.Hour)}
+
+		repository.EXPECT().FindTokenByHash(gomock.Any()).Return(stored, nil)
+		users.EXPECT().List(user.Id).Return(domain.UserDomain{}, sql.ErrNoRows)
+
+		result, err := oService.Introspect("refresh")
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, domain.TokenIntrospection{}, result)
+	})
+
+	t.Run("introspect_token_of_suspended_user", func(t *testing.T) {
+		stored := domain.OAuthToken{Id: uuid.New(), Type: domain.OAuthRefreshToken, ClientId: "client", UserId: user.Id, ExpiresAt: time.Now().Add(time.Hour)}
+
+		repository.EXPECT().FindTokenByHash(gomock.Any()).Return(stored, nil)
+		users.EXPECT().List(user.Id).Return(suspended, nil)
+
+		result, err := oService.Introspect("refresh")
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, domain.TokenIntrospection{}, result)
+	})
+
+	t.Run("introspect_token_issued_before_password_change", func(t *testing.T) {
+		stored := domain.OAuthToken{Id: uuid.New(), Type: domain.OAuthRefreshToken, ClientId: "client", UserId: user.Id, CreatedAt: time.Now().Add(-time.Hour), ExpiresAt: time.Now().Add(time.Hour)}
+
+		repository.EXPECT().FindTokenByHash(gomock.Any()).Return(stored, nil)
+		users.EXPECT().List(user.Id).Return(passwordChanged, nil)
+
+		result, err := oService.Introspect("refresh")
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, domain.TokenIntrospection{}, result)
+	})
+
+	t.Run("introspect_expired_token", func(t *testing.T) {
+		stored := domain.OAuthToken{Id: uuid.New(), Type: domain.OAuthRefreshToken, ClientId: "client", UserId: user.Id, ExpiresAt: time.Now().Add(-time.Minute)}
+
+		repository.EXPECT().FindTokenByHash(gomock.Any()).Return(stored, nil)
+
+		result, err := oService.Introspect("refresh")
+
+		assert.NoError(t, err)
+		assert.False(t, result.Active)
+	})
+
+	t.Run("introspect_client_credentials_token", func(t *testing.T) {
+		machine := domain.OAuthClient{Id: "machine", SecretHash: domain.HashOAuthSecret("secret"), GrantTypes: []string{domain.GrantClientCredentials}, Scopes: []string{"reports"}}
+
+		var stored domain.OAuthToken
+
+		repository.EXPECT().FindClient("machine").Return(machine, nil)
+		repository.EXPECT().CreateToken(gomock.Any()).DoAndReturn(func(issued domain.OAuthToken) error {
+			stored = issued
+
+			return nil
+		})
+
+		response, err := oService.Token(domain.TokenRequest{GrantType: domain.GrantClientCredentials, ClientId: "machine", ClientSecret: "secret"})
+
+		assert.NoError(t, err)
+
+		repository.EXPECT().FindTokenByHash(gomock.Any()).Return(domain.OAuthToken{}, sql.ErrNoRows)
+		repository.EXPECT().FindToken(stored.Id).Return(stored, nil)
+
+		result, err := oService.Introspect(response.AccessToken)
+
+		assert.NoError(t, err)
+		assert.True(t, result.Active)
+		assert.EqualValues(t, "machine", result.Subject)
+		assert.EqualValues(t, "reports", result.Scope)
+		assert.EqualValues(t, "", result.Username)
+		assert.EqualValues(t, stored.Id.String(), result.TokenId)
+	})
+}
@@ -0,0 +1,84 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/services"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestOutboxRelay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repository := mocks.NewMockOutboxRepository(ctrl)
+	publisher := mocks.NewMockEventPublisher(ctrl)
+	relay := service.NewOutboxRelay(repository, publisher, time.Second)
+
+	firstUser := uuid.New()
+	secondUser := uuid.New()
+
+	t.Run("repository_error", func(t *testing.T) {
+		repository.EXPECT().ClaimPending(relay.BatchSize, gomock.Any(), relay.Lease).Return([]domain.OutboxMessage{}, errors.New("repository error"))
+
+		published, err := relay.RunOnce()
+
+		assert.EqualValues(t, 0, published)
+		assert.EqualError(t, err, "repository error")
+	})
+
+	t.Run("failure_holds_back_the_same_user", func(t *testing.T) {
+		failing := domain.OutboxMessage{Id: uuid.New(), UserId: firstUser, Attempts: 2}
+		heldBack := domain.OutboxMessage{Id: uuid.New(), UserId: firstUser}
+		other := domain.OutboxMessage{Id: uuid.New(), UserId: secondUser}
+
+		repository.EXPECT().ClaimPending(relay.BatchSize, gomock.Any(), relay.Lease).Return([]domain.OutboxMessage{failing, heldBack, other}, nil)
+
+		gomock.InOrder(
+			publisher.EXPECT().Publish(failing).Return(errors.New("broker down")),
+			repository.EXPECT().MarkFailed(failing.Id, "broker down", gomock.Any()).DoAndReturn(func(id uuid.UUID, reason string, nextAttemptAt time.Time) error {
+				// third attempt, base backoff doubled twice
+				assert.WithinDuration(t, time.Now().Add(4 * time.Second), nextAttemptAt, time.Second)
+
+				return nil
+			}),
+			publisher.EXPECT().Publish(other).Return(nil),
+			repository.EXPECT().MarkPublished(other.Id).Return(nil),
+		)
+
+		published, err := relay.RunOnce()
+
+		assert.EqualValues(t, 1, published)
+		assert.NoError(t, err)
+	})
+
+	t.Run("waits_for_next_attempt", func(t *testing.T) {
+		waiting := domain.OutboxMessage{Id: uuid.New(), UserId: firstUser, NextAttemptAt: time.Now().Add(time.Minute)}
+		heldBack := domain.OutboxMessage{Id: uuid.New(), UserId: firstUser}
+
+		repository.EXPECT().ClaimPending(relay.BatchSize, gomock.Any(), relay.Lease).Return([]domain.OutboxMessage{waiting, heldBack}, nil)
+
+		published, err := relay.RunOnce()
+
+		assert.EqualValues(t, 0, published)
+		assert.NoError(t, err)
+	})
+
+	t.Run("gives_up_after_max_attempts", func(t *testing.T) {
+		message := domain.OutboxMessage{Id: uuid.New(), UserId: firstUser, Attempts: relay.MaxAttempts - 1}
+
+		repository.EXPECT().ClaimPending(relay.BatchSize, gomock.Any(), relay.Lease).Return([]domain.OutboxMessage{message}, nil)
+		publisher.EXPECT().Publish(message).Return(errors.New("broker down"))
+		repository.EXPECT().MarkDead(message.Id, "broker down").Return(nil)
+
+		published, err := relay.RunOnce()
+
+		assert.EqualValues(t, 0, published)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,65 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/repository"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutboxRepository(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	t.Run("add_event_inside_transaction", func(t *testing.T) {
+		userId := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO outbox").
+			WithArgs(sqlmock.AnyArg(), domain.UserDeletedEvent, userId, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repository.NewUserRepository(db).Transaction(func(tx port.UserRepository) error {
+			return tx.AddEvent(domain.UserDeleted{UserId: userId, DeletedAt: time.Now()})
+		})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("claim_pending", func(t *testing.T) {
+		messageId := uuid.New()
+		userId := uuid.New()
+
+		now := time.Now()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("SELECT pg_advisory_xact_lock").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("UPDATE outbox SET claimedUntil = (.+) FROM outbox message WHERE publishedAt IS NULL AND deadAt IS NULL AND NOT EXISTS (.+)waiting.nextAttemptAt > (.+) OR waiting.claimedUntil > (.+) ORDER BY sequence LIMIT (.+) FROM claimed ORDER BY sequence").
+			WithArgs(10, now, now.Add(time.Minute)).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "type", "userId", "payload", "occurredAt", "attempts", "nextAttemptAt", "lastError",
+			}).AddRow(messageId, domain.UserCreatedEvent, userId, []byte(`{}`), "2025-01-02T03:04:05Z", 1, "2025-01-02T03:05:05Z", "broker down"))
+		mock.ExpectCommit()
+
+		messages, err := repository.NewOutboxRepository(db).ClaimPending(10, now, time.Minute)
+
+		assert.NoError(t, err)
+		assert.Len(t, messages, 1)
+		assert.EqualValues(t, messageId, messages[0].Id)
+		assert.EqualValues(t, 1, messages[0].Attempts)
+		assert.EqualValues(t, "broker down", messages[0].LastError)
+		assert.EqualValues(t, time.Date(2025, 1, 2, 3, 5, 5, 0, time.UTC), messages[0].NextAttemptAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
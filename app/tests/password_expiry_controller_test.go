@@ -0,0 +1,159 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/controller"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/model"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/tests/config"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestUserController_PasswordExpiry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	uService := mocks.NewMockUserService(ctrl)
+	uController := controller.NewUserController(uService)
+
+	userId := uuid.New()
+
+	jsonBody := func(value any) io.ReadCloser {
+		body, _ := json.Marshal(value)
+
+		return io.NopCloser(strings.NewReader(string(body)))
+	}
+
+	t.Run("login_with_expired_password", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		uService.EXPECT().Login("test@email.com", "password@123", gomock.Any()).Return(domain.LoginResult{PasswordExpired: true, PasswordChangeToken: "change-token"}, nil)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{}, "POST", jsonBody(model.UserLoginModel{Email: "test@email.com", Password: "password@123"}))
+		uController.Login(context)
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{"twoFactorRequired":false,"passwordExpired":true,"passwordChangeToken":"change-token"}`, recorder.Body.String())
+	})
+
+	t.Run("change_expired_password_invalid_token", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		uService.EXPECT().ChangeExpiredPassword("change-token", "password@456", gomock.Any()).Return(domain.LoginResult{}, errors.New("Invalid password change token"))
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{}, "POST", jsonBody(model.ExpiredPasswordModel{PasswordChangeToken: "change-token", Password: "password@456"}))
+		uController.ChangeExpiredPassword(context)
+
+		assert.EqualValues(t, http.StatusUnauthorized, recorder.Code)
+	})
+
+	t.Run("change_expired_password_reused", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		uService.EXPECT().ChangeExpiredPassword("change-token", "password@123", gomock.Any()).Return(domain.LoginResult{}, errors.New("New password must be different from the current password"))
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{}, "POST", jsonBody(model.ExpiredPasswordModel{PasswordChangeToken: "change-token", Password: "password@123"}))
+		uController.ChangeExpiredPassword(context)
+
+		assert.EqualValues(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("change_expired_password_suspended", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		uService.EXPECT().ChangeExpiredPassword("change-token", "password@456", gomock.Any()).Return(domain.LoginResult{}, errors.New("Account is suspended"))
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{}, "POST", jsonBody(model.ExpiredPasswordModel{PasswordChangeToken: "change-token", Password: "password@456"}))
+		uController.ChangeExpiredPassword(context)
+
+		assert.EqualValues(t, http.StatusForbidden, recorder.Code)
+	})
+
+	t.Run("change_expired_password", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		uService.EXPECT().ChangeExpiredPassword("change-token", "password@456", gomock.Any()).Return(domain.LoginResult{UserId: userId, Token: "super-token"}, nil)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{}, "POST", jsonBody(model.ExpiredPasswordModel{PasswordChangeToken: "change-token", Password: "password@456"}))
+		uController.ChangeExpiredPassword(context)
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{"token":"super-token","twoFactorRequired":false}`, recorder.Body.String())
+	})
+
+	t.Run("set_invalid_role", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		uService.EXPECT().SetRole(userId, "root").Return(uuid.Nil, errors.New("Invalid role"))
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"id": {userId.String()}}, "PATCH", jsonBody(model.UserRoleModel{Role: "root"}))
+		uController.SetRole(context)
+
+		assert.EqualValues(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("set_role_user_not_found", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		uService.EXPECT().SetRole(userId, domain.RoleAdmin).Return(uuid.Nil, errors.New("sql: no rows in result set"))
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"id": {userId.String()}}, "PATCH", jsonBody(model.UserRoleModel{Role: domain.RoleAdmin}))
+		uController.SetRole(context)
+
+		assert.EqualValues(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("set_role", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		uService.EXPECT().SetRole(userId, domain.RoleAdmin).Return(userId, nil)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"id": {userId.String()}}, "PATCH", jsonBody(model.UserRoleModel{Role: domain.RoleAdmin}))
+		uController.SetRole(context)
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("require_password_change_user_not_found", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		uService.EXPECT().RequirePasswordChange(userId).Return(uuid.Nil, errors.New("sql: no rows in result set"))
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"id": {userId.String()}}, "PATCH", nil)
+		uController.RequirePasswordChange(context)
+
+		assert.EqualValues(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("require_password_change", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		uService.EXPECT().RequirePasswordChange(userId).Return(userId, nil)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"id": {userId.String()}}, "PATCH", nil)
+		uController.RequirePasswordChange(context)
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+	})
+}
@@ -0,0 +1,67 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/repository"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserRepository_PasswordExpiry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	repository := repository.NewUserRepository(db)
+
+	t.Run("update_role_user_not_found", func(t *testing.T) {
+		userId := uuid.New()
+
+		mock.
+			ExpectQuery("UPDATE users SET role = (.+) WHERE id = (.+) AND deletedAt IS NULL").
+			WithArgs(userId, domain.RoleAdmin, sqlmock.AnyArg()).
+			WillReturnError(errors.New("sql: no rows in result set"))
+
+		id, err := repository.UpdateRole(userId, domain.RoleAdmin)
+
+		assert.EqualValues(t, uuid.Nil, id)
+		assert.EqualError(t, err, "sql: no rows in result set")
+	})
+
+	t.Run("update_role", func(t *testing.T) {
+		userId := uuid.New()
+
+		mock.
+			ExpectQuery("UPDATE users SET role = (.+) WHERE id = (.+) AND deletedAt IS NULL").
+			WithArgs(userId, domain.RoleAdmin, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(userId))
+
+		id, err := repository.UpdateRole(userId, domain.RoleAdmin)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, userId, id)
+	})
+
+	t.Run("require_password_change", func(t *testing.T) {
+		userId := uuid.New()
+
+		mock.
+			ExpectQuery("UPDATE users SET passwordChangeRequired = true WHERE id = (.+) AND deletedAt IS NULL").
+			WithArgs(userId).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(userId))
+
+		id, err := repository.RequirePasswordChange(userId)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, userId, id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,178 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/services"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestUserService_PasswordExpiry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repository := mocks.NewMockUserRepository(ctrl)
+	uService := service.NewUserService(repository, service.WithPasswordExpiry(domain.PasswordExpiry{domain.RoleAdmin: 24 * time.Hour}))
+
+	user, err := domain.CreateUser(uuid.Nil, "Test name", "test@email.com", "00000000000", "password@123", time.Time{}, time.Time{}, time.Time{})
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when creating the user", err.Error())
+	}
+
+	user.Role = domain.RoleAdmin
+	user.CreatedAt = time.Now().Add(-48 * time.Hour)
+
+	changeToken := ""
+
+	t.Run("user_role_never_expires", func(t *testing.T) {
+		member := user
+		member.Role = domain.RoleUser
+
+		repository.EXPECT().FindUserByEmail(user.Email).Return(member, nil)
+		repository.EXPECT().UpdateLastLogin(user.Id, gomock.Any()).Return(nil)
+
+		result, err := uService.Login(user.Email, "password@123", domain.Client{})
+
+		assert.NoError(t, err)
+		assert.False(t, result.PasswordExpired)
+		assert.NotEmpty(t, result.Token)
+	})
+
+	t.Run("recently_changed_password", func(t *testing.T) {
+		changed := user
+		changed.PasswordChangedAt = time.Now().Add(-time.Hour)
+
+		repository.EXPECT().FindUserByEmail(user.Email).Return(changed, nil)
+		repository.EXPECT().UpdateLastLogin(user.Id, gomock.Any()).Return(nil)
+
+		result, err := uService.Login(user.Email, "password@123", domain.Client{})
+
+		assert.NoError(t, err)
+		assert.False(t, result.PasswordExpired)
+		assert.NotEmpty(t, result.Token)
+	})
+
+	t.Run("change_required_by_admin", func(t *testing.T) {
+		required := user
+		required.Role = domain.RoleUser
+		required.PasswordChangeRequired = true
+
+		repository.EXPECT().FindUserByEmail(user.Email).Return(required, nil)
+
+		result, err := uService.Login(user.Email, "password@123", domain.Client{})
+
+		assert.NoError(t, err)
+		assert.True(t, result.PasswordExpired)
+		assert.Empty(t, result.Token)
+	})
+
+	t.Run("expired_password", func(t *testing.T) {
+		repository.EXPECT().FindUserByEmail(user.Email).Return(user, nil)
+
+		result, err := uService.Login(user.Email, "password@123", domain.Client{})
+
+		assert.NoError(t, err)
+		assert.True(t, result.PasswordExpired)
+		assert.EqualValues(t, uuid.Nil, result.UserId)
+		assert.Empty(t, result.Token)
+		assert.NotEmpty(t, result.PasswordChangeToken)
+
+		changeToken = result.PasswordChangeToken
+	})
+
+	t.Run("change_token_is_not_an_access_token", func(t *testing.T) {
+		_, err := uService.Authenticate(changeToken)
+
+		assert.EqualError(t, err, "Invalid token")
+	})
+
+	t.Run("invalid_change_token", func(t *testing.T) {
+		result, err := uService.ChangeExpiredPassword("invalid-token", "password@456", domain.Client{})
+
+		assert.EqualValues(t, domain.LoginResult{}, result)
+		assert.EqualError(t, err, "Invalid password change token")
+	})
+
+	t.Run("password_policy_still_applies", func(t *testing.T) {
+		repository.EXPECT().List(user.Id).Return(user, nil)
+
+		result, err := uService.ChangeExpiredPassword(changeToken, "password@123", domain.Client{})
+
+		assert.EqualValues(t, domain.LoginResult{}, result)
+		assert.EqualError(t, err, "New password must be different from the current password")
+	})
+
+	t.Run("repository_error", func(t *testing.T) {
+		repository.EXPECT().List(user.Id).Return(domain.UserDomain{}, errors.New("connection refused"))
+
+		result, err := uService.ChangeExpiredPassword(changeToken, "password@456", domain.Client{})
+
+		assert.EqualValues(t, domain.LoginResult{}, result)
+		assert.EqualError(t, err, "connection refused")
+	})
+
+	t.Run("suspended_before_the_change", func(t *testing.T) {
+		suspended := user
+		suspended.Status = domain.UserStatus{State: domain.StatusSuspended, Reason: "spam"}
+
+		repository.EXPECT().List(user.Id).Return(suspended, nil)
+
+		result, err := uService.ChangeExpiredPassword(changeToken, "password@456", domain.Client{})
+
+		assert.EqualValues(t, domain.LoginResult{}, result)
+		assert.EqualError(t, err, "Account is suspended")
+	})
+
+	t.Run("change_expired_password", func(t *testing.T) {
+		repository.EXPECT().List(user.Id).Return(user, nil)
+		expectTransaction(repository)
+		repository.EXPECT().UpdatePassword(user.Id, gomock.Any()).DoAndReturn(func(id uuid.UUID, changed domain.UserDomain) (uuid.UUID, error) {
+			assert.False(t, changed.PasswordChangeRequired)
+			assert.False(t, changed.PasswordChangedAt.IsZero())
+
+			return id, nil
+		})
+		repository.EXPECT().AddEvent(gomock.Any()).Return(nil)
+		repository.EXPECT().UpdateLastLogin(user.Id, gomock.Any()).Return(nil)
+
+		result, err := uService.ChangeExpiredPassword(changeToken, "password@456", domain.Client{})
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, user.Id, result.UserId)
+		assert.NotEmpty(t, result.Token)
+	})
+
+	t.Run("change_token_used_twice", func(t *testing.T) {
+		changed := user
+		changed.PasswordChangedAt = time.Now().Add(time.Second)
+
+		repository.EXPECT().List(user.Id).Return(changed, nil)
+
+		result, err := uService.ChangeExpiredPassword(changeToken, "password@789", domain.Client{})
+
+		assert.EqualValues(t, domain.LoginResult{}, result)
+		assert.EqualError(t, err, "Invalid password change token")
+	})
+
+	t.Run("set_invalid_role", func(t *testing.T) {
+		id, err := uService.SetRole(user.Id, "root")
+
+		assert.EqualValues(t, uuid.Nil, id)
+		assert.EqualError(t, err, "Invalid role")
+	})
+
+	t.Run("set_role", func(t *testing.T) {
+		repository.EXPECT().UpdateRole(user.Id, domain.RoleAdmin).Return(user.Id, nil)
+
+		id, err := uService.SetRole(user.Id, domain.RoleAdmin)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, user.Id, id)
+	})
+}
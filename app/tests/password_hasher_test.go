@@ -0,0 +1,99 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordHasher(t *testing.T) {
+	params := domain.Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+	argon := domain.NewArgon2idHasher(params)
+
+	t.Run("argon2id_phc_format", func(t *testing.T) {
+		hash, err := argon.Hash("password@123")
+
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+		valid, err := argon.Verify(hash, "password@123")
+		assert.NoError(t, err)
+		assert.True(t, valid)
+
+		valid, err = argon.Verify(hash, "password@124")
+		assert.NoError(t, err)
+		assert.False(t, valid)
+	})
+
+	t.Run("argon2id_needs_rehash_when_params_change", func(t *testing.T) {
+		hash, _ := argon.Hash("password@123")
+
+		assert.False(t, argon.NeedsRehash(hash))
+
+		stronger := params
+		stronger.Iterations = 3
+
+		assert.True(t, domain.NewArgon2idHasher(stronger).NeedsRehash(hash))
+	})
+
+	t.Run("argon2id_rejects_malformed_hash", func(t *testing.T) {
+		_, err := argon.Verify("$argon2id$v=19$m=1024$bad", "password@123")
+
+		assert.EqualError(t, err, "Invalid argon2id hash")
+	})
+
+	t.Run("argon2id_rejects_oversized_hash", func(t *testing.T) {
+		salt := "c2FsdHNhbHRzYWx0c2FsdA"
+		key := "a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U"
+
+		for _, hash := range []string{
+			"$argon2id$v=19$m=4194304,t=2,p=1$" + salt + "$" + key,
+			"$argon2id$v=19$m=19456,t=1000,p=1$" + salt + "$" + key,
+			"$argon2id$v=19$m=19456,t=2,p=64$" + salt + "$" + key,
+			"$argon2id$v=19$m=19456,t=2,p=0$" + salt + "$" + key,
+			"$argon2id$v=19$m=19456,t=2,p=1$" + salt + "$" + strings.Repeat("a2V5", 30),
+		} {
+			valid, err := argon.Verify(hash, "password@123")
+
+			assert.False(t, valid)
+			assert.EqualError(t, err, "Argon2id parameters out of range", hash)
+		}
+	})
+
+	t.Run("bcrypt", func(t *testing.T) {
+		bcrypt := domain.NewBcryptHasher(4)
+
+		hash, err := bcrypt.Hash("password@123")
+		assert.NoError(t, err)
+
+		valid, err := bcrypt.Verify(hash, "password@123")
+		assert.NoError(t, err)
+		assert.True(t, valid)
+
+		valid, err = bcrypt.Verify(hash, "wrong")
+		assert.NoError(t, err)
+		assert.False(t, valid)
+
+		assert.False(t, bcrypt.NeedsRehash(hash))
+		assert.True(t, domain.NewBcryptHasher(5).NeedsRehash(hash))
+	})
+
+	t.Run("verify_any_known_algorithm", func(t *testing.T) {
+		domain.SetPasswordHasher(argon)
+		defer domain.SetPasswordHasher(domain.NewArgon2idHasher(domain.DefaultArgon2idParams()))
+
+		bcryptHash, _ := domain.NewBcryptHasher(4).Hash("password@123")
+
+		valid, err := domain.VerifyPassword(bcryptHash, "password@123")
+
+		assert.NoError(t, err)
+		assert.True(t, valid)
+		assert.True(t, domain.PasswordNeedsRehash(bcryptHash))
+
+		_, err = domain.VerifyPassword("plain", "plain")
+
+		assert.EqualError(t, err, "Unknown password hash format")
+	})
+}
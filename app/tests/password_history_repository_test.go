@@ -0,0 +1,72 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserRepository_PasswordHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	uRepository := repository.NewUserRepository(db)
+
+	t.Run("add", func(t *testing.T) {
+		userId := uuid.New()
+		replacedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		mock.ExpectExec("INSERT INTO password_history").
+			WithArgs(sqlmock.AnyArg(), userId, "old-hash", replacedAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, uRepository.AddPasswordHistory(userId, "old-hash", replacedAt))
+	})
+
+	t.Run("list_most_recent_first", func(t *testing.T) {
+		userId := uuid.New()
+
+		mock.ExpectQuery("SELECT hash FROM password_history WHERE userId = (.+) ORDER BY replacedAt DESC LIMIT (.+)").
+			WithArgs(userId, 4).
+			WillReturnRows(sqlmock.NewRows([]string{"hash"}).AddRow("second-hash").AddRow("first-hash"))
+
+		hashes, err := uRepository.ListPasswordHistory(userId, 4)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, []string{"second-hash", "first-hash"}, hashes)
+	})
+
+	t.Run("list_error", func(t *testing.T) {
+		userId := uuid.New()
+
+		mock.ExpectQuery("SELECT hash FROM password_history").
+			WithArgs(userId, 4).
+			WillReturnError(errors.New("connection refused"))
+
+		hashes, err := uRepository.ListPasswordHistory(userId, 4)
+
+		assert.EqualError(t, err, "connection refused")
+		assert.Empty(t, hashes)
+	})
+
+	t.Run("prune_keeps_most_recent", func(t *testing.T) {
+		userId := uuid.New()
+
+		mock.ExpectExec("DELETE FROM password_history WHERE userId = (.+) AND id NOT IN \\(\\s*SELECT id FROM password_history WHERE userId = (.+) ORDER BY replacedAt DESC LIMIT (.+)\\s*\\)").
+			WithArgs(userId, 4).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		assert.NoError(t, uRepository.PrunePasswordHistory(userId, 4))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,95 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/services"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestUserService_PasswordHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repository := mocks.NewMockUserRepository(ctrl)
+	uService := service.NewUserService(repository, service.WithPasswordHistory(3))
+
+	user, err := domain.CreateUser(uuid.Nil, "Test name", "test@email.com", "00000000000", "password@123", time.Time{}, time.Time{}, time.Time{})
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when creating the user", err.Error())
+	}
+
+	previous, err := domain.HashPassword("password@000")
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when hashing the password", err.Error())
+	}
+
+	t.Run("history_error", func(t *testing.T) {
+		repository.EXPECT().List(user.Id).Return(user, nil)
+		repository.EXPECT().ListPasswordHistory(user.Id, 2).Return(nil, errors.New("connection refused"))
+
+		id, err := uService.ResetPassword(user.Id, "password@456")
+
+		assert.EqualValues(t, uuid.Nil, id)
+		assert.EqualError(t, err, "connection refused")
+	})
+
+	t.Run("previous_password_reused", func(t *testing.T) {
+		repository.EXPECT().List(user.Id).Return(user, nil)
+		repository.EXPECT().ListPasswordHistory(user.Id, 2).Return([]string{previous}, nil)
+
+		id, err := uService.UpdatePassword(user.Id, "password@123", "password@000")
+
+		assert.EqualValues(t, uuid.Nil, id)
+		assert.EqualError(t, err, "New password was used recently, choose another one")
+	})
+
+	t.Run("replaced_password_is_kept", func(t *testing.T) {
+		repository.EXPECT().List(user.Id).Return(user, nil)
+		repository.EXPECT().ListPasswordHistory(user.Id, 2).Return([]string{previous}, nil)
+		expectTransaction(repository)
+		repository.EXPECT().UpdatePassword(user.Id, gomock.Any()).Return(user.Id, nil)
+		repository.EXPECT().AddPasswordHistory(user.Id, user.Password, gomock.Any()).Return(nil)
+		repository.EXPECT().PrunePasswordHistory(user.Id, 2).Return(nil)
+		repository.EXPECT().AddEvent(gomock.AssignableToTypeOf(domain.UserPasswordChanged{})).Return(nil)
+
+		id, err := uService.ResetPassword(user.Id, "password@456")
+
+		assert.EqualValues(t, user.Id, id)
+		assert.NoError(t, err)
+	})
+
+	t.Run("history_write_error_fails_the_change", func(t *testing.T) {
+		repository.EXPECT().List(user.Id).Return(user, nil)
+		repository.EXPECT().ListPasswordHistory(user.Id, 2).Return([]string{}, nil)
+		expectTransaction(repository)
+		repository.EXPECT().UpdatePassword(user.Id, gomock.Any()).Return(user.Id, nil)
+		repository.EXPECT().AddPasswordHistory(user.Id, user.Password, gomock.Any()).Return(errors.New("connection refused"))
+
+		id, err := uService.ResetPassword(user.Id, "password@456")
+
+		assert.EqualValues(t, uuid.Nil, id)
+		assert.EqualError(t, err, "connection refused")
+	})
+
+	t.Run("depth_of_one_keeps_no_history", func(t *testing.T) {
+		withoutHistory := service.NewUserService(repository, service.WithPasswordHistory(1))
+
+		repository.EXPECT().List(user.Id).Return(user, nil)
+		expectTransaction(repository)
+		repository.EXPECT().UpdatePassword(user.Id, gomock.Any()).Return(user.Id, nil)
+		repository.EXPECT().AddEvent(gomock.AssignableToTypeOf(domain.UserPasswordChanged{})).Return(nil)
+
+		id, err := withoutHistory.ResetPassword(user.Id, "password@456")
+
+		assert.EqualValues(t, user.Id, id)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,121 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func violatedRules(err error) []string {
+	var policyErr *domain.PasswordPolicyError
+
+	if !errors.As(err, &policyErr) {
+		return nil
+	}
+
+	rules := []string{}
+
+	for _, violation := range policyErr.Violations {
+		rules = append(rules, violation.Rule)
+	}
+
+	return rules
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	breached, err := domain.LoadBreachedPasswords(strings.NewReader("# top leaked\nPassword@1\n\nqwerty!\n"))
+
+	assert.NoError(t, err)
+
+	policy := domain.PasswordPolicy{
+		MinLength: 8,
+		MaxLength: 20,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit: true,
+		RequireSpecial: true,
+		SpecialCharacters: domain.DefaultSpecialCharacters,
+		MaxRepeated: 2,
+		ForbidUserData: true,
+		Breached: breached,
+	}
+
+	t.Run("valid_password", func(t *testing.T) {
+		assert.NoError(t, policy.Validate("Correct#Horse9", "John Doe", "john@test.com"))
+	})
+
+	t.Run("reports_every_failed_rule", func(t *testing.T) {
+		err := policy.Validate("aaa", "John Doe", "john@test.com")
+
+		assert.EqualValues(t, []string{
+			domain.PasswordRuleMinLength,
+			domain.PasswordRuleUppercase,
+			domain.PasswordRuleDigit,
+			domain.PasswordRuleSpecial,
+			domain.PasswordRuleRepeated,
+		}, violatedRules(err))
+	})
+
+	t.Run("max_length", func(t *testing.T) {
+		assert.EqualValues(t, []string{domain.PasswordRuleMaxLength}, violatedRules(policy.Validate("Abcdefgh1!" + strings.Repeat("xy", 6), "", "")))
+	})
+
+	t.Run("user_data", func(t *testing.T) {
+		assert.EqualValues(t, []string{domain.PasswordRuleUserData}, violatedRules(policy.Validate("Johnny#2024", "John Doe", "jd@test.com")))
+		assert.EqualValues(t, []string{domain.PasswordRuleUserData}, violatedRules(policy.Validate("Xsmith#2024", "Jo", "x.smith@test.com")))
+	})
+
+	t.Run("breached", func(t *testing.T) {
+		assert.EqualValues(t, []string{domain.PasswordRuleBreached}, violatedRules(policy.Validate("Password@1", "", "")))
+	})
+
+	t.Run("create_user_applies_current_policy", func(t *testing.T) {
+		domain.SetPasswordPolicy(policy)
+		defer domain.SetPasswordPolicy(domain.DefaultPasswordPolicy())
+
+		_, err := domain.CreateUser(uuid.Nil, "John Doe", "john@test.com", "00000000000", "weak", time.Time{}, time.Time{}, time.Time{})
+
+		assert.NotEmpty(t, violatedRules(err))
+
+		user, err := domain.CreateUser(uuid.Nil, "John Doe", "john@test.com", "00000000000", "Correct#Horse9", time.Time{}, time.Time{}, time.Time{})
+
+		assert.NoError(t, err)
+		assert.True(t, domain.IsPasswordHash(user.Password))
+
+		assert.NotEmpty(t, violatedRules(user.UpdatePassword("JohnDoe#12345")))
+	})
+
+	t.Run("create_user_hashes_hash_looking_input", func(t *testing.T) {
+		// a client can't skip the policy or store a chosen hash by sending a value that looks hashed
+		domain.SetPasswordPolicy(domain.PasswordPolicy{MinLength: 80})
+		defer domain.SetPasswordPolicy(domain.DefaultPasswordPolicy())
+
+		_, err := domain.CreateUser(uuid.Nil, "John Doe", "john@test.com", "00000000000", "$argon2id$v=19$m=1,t=1,p=1$c2FsdA$aGFzaA", time.Time{}, time.Time{}, time.Time{})
+
+		assert.NotEmpty(t, violatedRules(err))
+
+		domain.SetPasswordPolicy(domain.DefaultPasswordPolicy())
+
+		hash := "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"
+
+		user, err := domain.CreateUser(uuid.Nil, "John Doe", "john@test.com", "00000000000", hash, time.Time{}, time.Time{}, time.Time{})
+
+		assert.NoError(t, err)
+		assert.NotEqual(t, hash, user.Password)
+
+		ok, err := domain.VerifyPassword(user.Password, hash)
+
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		restored, err := domain.RestoreUser(user.Id, user.Name, user.Email, user.Phone, user.Password, user.CreatedAt, time.Time{}, time.Time{})
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, user.Password, restored.Password)
+	})
+}
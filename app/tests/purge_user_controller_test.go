@@ -0,0 +1,77 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/controller"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/model"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/tests/config"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestUserController_Purge(t *testing.T) {
+	crtl := gomock.NewController(t)
+	defer crtl.Finish()
+	service := mocks.NewMockUserService(crtl)
+	controller := controller.NewUserController(service)
+
+	t.Run("dry_run_is_invalid", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		url := url.Values{"dryRun": {"TEST_ERROR"}}
+
+		config.MakeRequest(context, []gin.Param{}, url, "DELETE", nil)
+		controller.Purge(context)
+
+		assert.EqualValues(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("service_error", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		service.EXPECT().Purge(false).Return([]domain.UserDomain{}, errors.New("INTERNAL ERROR"))
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{}, "DELETE", nil)
+		controller.Purge(context)
+
+		assert.EqualValues(t, http.StatusInternalServerError, recorder.Code)
+	})
+
+	t.Run("dry_run_report", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		url := url.Values{"dryRun": {"true"}}
+
+		users := []domain.UserDomain{{Id: uuid.New(), Email: "test@email.com", DeletedAt: time.Now()}}
+
+		service.EXPECT().Purge(true).Return(users, nil)
+
+		config.MakeRequest(context, []gin.Param{}, url, "DELETE", nil)
+		controller.Purge(context)
+
+		var report model.PurgeReportModel
+		json.Unmarshal(recorder.Body.Bytes(), &report)
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+		assert.True(t, report.DryRun)
+		assert.EqualValues(t, 1, report.Count)
+		assert.EqualValues(t, users[0].Id.String(), report.Users[0].Id)
+	})
+}
@@ -0,0 +1,73 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserRepository_Purge(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	repository := repository.NewUserRepository(db)
+
+	t.Run("list_deleted_users", func(t *testing.T) {
+		userId := uuid.New()
+
+		mock.
+			ExpectQuery("SELECT (.+) FROM users WHERE deletedAt IS NOT NULL").
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt", "passwordChangedAt", "role", "passwordChangeRequired", "status", "statusReason", "statusChangedBy", "statusChangedAt", "suspendedUntil", "lastLoginAt",
+			}).AddRow(
+				userId, "Test", "hashedPass", "test@email.com", "00000000000",
+				nil, nil, "2025-01-02T03:04:05Z", nil, "user", false, "active", "", "", nil, nil, nil,
+			))
+
+		users, err := repository.ListDeleted()
+
+		assert.Len(t, users, 1)
+		assert.EqualValues(t, userId, users[0].Id)
+		assert.NoError(t, err)
+	})
+
+	t.Run("purge_error", func(t *testing.T) {
+		cutoff := time.Now()
+
+		mock.
+			ExpectQuery("DELETE FROM users WHERE deletedAt IS NOT NULL AND deletedAt < (.+)").
+			WithArgs(cutoff).
+			WillReturnError(errors.New("repository error"))
+
+		ids, err := repository.Purge(cutoff)
+
+		assert.EqualValues(t, []uuid.UUID{}, ids)
+		assert.EqualError(t, err, "repository error")
+	})
+
+	t.Run("purge_success", func(t *testing.T) {
+		cutoff := time.Now()
+		first := uuid.New()
+		second := uuid.New()
+
+		mock.
+			ExpectQuery("DELETE FROM users WHERE deletedAt IS NOT NULL AND deletedAt < (.+)").
+			WithArgs(cutoff).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(first).AddRow(second))
+
+		ids, err := repository.Purge(cutoff)
+
+		assert.EqualValues(t, []uuid.UUID{first, second}, ids)
+		assert.NoError(t, err)
+	})
+}
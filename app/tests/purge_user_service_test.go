@@ -0,0 +1,57 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/services"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestUserService_Purge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repository := mocks.NewMockUserRepository(ctrl)
+	service := service.NewUserService(repository, service.WithPurgeRetention(24 * time.Hour))
+
+	expired := domain.UserDomain{Id: uuid.New(), DeletedAt: time.Now().Add(-48 * time.Hour)}
+	recent := domain.UserDomain{Id: uuid.New(), DeletedAt: time.Now().Add(-time.Hour)}
+
+	t.Run("repository_error", func(t *testing.T) {
+		repository.EXPECT().ListDeleted().Return([]domain.UserDomain{}, errors.New("repository error"))
+		users, err := service.Purge(false)
+
+		assert.EqualValues(t, []domain.UserDomain{}, users)
+		assert.EqualError(t, err, "repository error")
+	})
+
+	t.Run("dry_run", func(t *testing.T) {
+		repository.EXPECT().ListDeleted().Return([]domain.UserDomain{expired, recent}, nil)
+		users, err := service.Purge(true)
+
+		assert.EqualValues(t, []domain.UserDomain{expired}, users)
+		assert.NoError(t, err)
+	})
+
+	t.Run("nothing_to_purge", func(t *testing.T) {
+		repository.EXPECT().ListDeleted().Return([]domain.UserDomain{recent}, nil)
+		users, err := service.Purge(false)
+
+		assert.Empty(t, users)
+		assert.NoError(t, err)
+	})
+
+	t.Run("purge_success", func(t *testing.T) {
+		repository.EXPECT().ListDeleted().Return([]domain.UserDomain{expired, recent}, nil)
+		repository.EXPECT().Purge(gomock.Any()).Return([]uuid.UUID{expired.Id}, nil)
+		users, err := service.Purge(false)
+
+		assert.EqualValues(t, []domain.UserDomain{expired}, users)
+		assert.NoError(t, err)
+	})
+}
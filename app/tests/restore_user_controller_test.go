@@ -0,0 +1,133 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/controller"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/tests/config"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestUserController_Restore(t *testing.T) {
+	crtl := gomock.NewController(t)
+	defer crtl.Finish()
+	service := mocks.NewMockUserService(crtl)
+	controller := controller.NewUserController(service)
+
+	t.Run("id_is_invalid", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		url := url.Values{"id": {"TEST_ERROR"}}
+
+		config.MakeRequest(context, []gin.Param{}, url, "PATCH", nil)
+		controller.Restore(context)
+
+		assert.EqualValues(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("user_not_found", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		userId := uuid.New()
+		url := url.Values{"id": {userId.String()}}
+
+		service.EXPECT().Restore(userId).Return(uuid.Nil, errors.New("sql: no rows in result set"))
+
+		config.MakeRequest(context, []gin.Param{}, url, "PATCH", nil)
+		controller.Restore(context)
+
+		assert.EqualValues(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("email_reused", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		userId := uuid.New()
+		url := url.Values{"id": {userId.String()}}
+
+		service.EXPECT().Restore(userId).Return(uuid.Nil, errors.New("Email is already registered"))
+
+		config.MakeRequest(context, []gin.Param{}, url, "PATCH", nil)
+		controller.Restore(context)
+
+		assert.EqualValues(t, http.StatusConflict, recorder.Code)
+	})
+
+	t.Run("user_restored", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		userId := uuid.New()
+		url := url.Values{"id": {userId.String()}}
+
+		service.EXPECT().Restore(userId).Return(userId, nil)
+
+		config.MakeRequest(context, []gin.Param{}, url, "PATCH", nil)
+		controller.Restore(context)
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+	})
+}
+
+func TestUserController_ListDeleted(t *testing.T) {
+	crtl := gomock.NewController(t)
+	defer crtl.Finish()
+	service := mocks.NewMockUserService(crtl)
+	controller := controller.NewUserController(service)
+
+	t.Run("repository_error", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		service.EXPECT().ListDeleted().Return([]domain.UserDomain{}, errors.New("repository error"))
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{}, "GET", nil)
+		controller.ListDeleted(context)
+
+		assert.EqualValues(t, http.StatusInternalServerError, recorder.Code)
+	})
+
+	t.Run("password_is_not_sent", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		userId := uuid.New()
+		deletedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		service.EXPECT().ListDeleted().Return([]domain.UserDomain{{
+			Id: userId,
+			Name: "Test name",
+			Email: "test@email.com",
+			Phone: "00000000000",
+			Password: "$argon2id$v=19$m=65536,t=3,p=2$salt$hash",
+			CreatedAt: deletedAt,
+			DeletedAt: deletedAt,
+		}}, nil)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{}, "GET", nil)
+		controller.ListDeleted(context)
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+		assert.NotContains(t, recorder.Body.String(), "argon2id")
+		assert.JSONEq(t, `[{"id":"` + userId.String() + `","name":"Test name","email":"test@email.com","phone":"00000000000","createdAt":"2025-01-02T03:04:05Z","deletedAt":"2025-01-02T03:04:05Z"}]`, recorder.Body.String())
+	})
+}
@@ -0,0 +1,132 @@
+package test
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/repository"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserRepository_Restore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	repository := repository.NewUserRepository(db)
+
+	t.Run("deleted_user_not_found", func(t *testing.T) {
+		userId := uuid.New()
+
+		mock.
+			ExpectQuery("SELECT (.+) FROM users WHERE id = (.+) AND deletedAt IS NOT NULL").
+			WithArgs(userId).
+			WillReturnError(errors.New("sql: no rows in result set"))
+
+		uDomain, err := repository.FindDeletedUser(userId)
+
+		assert.EqualValues(t, domain.UserDomain{}, uDomain)
+		assert.EqualError(t, err, "sql: no rows in result set")
+	})
+
+	t.Run("deleted_user_found", func(t *testing.T) {
+		userId := uuid.New()
+
+		mock.
+			ExpectQuery("SELECT (.+) FROM users WHERE id = (.+) AND deletedAt IS NOT NULL").
+			WithArgs(userId).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt", "passwordChangedAt", "role", "passwordChangeRequired", "status", "statusReason", "statusChangedBy", "statusChangedAt", "suspendedUntil", "lastLoginAt",
+			}).AddRow(
+				userId, "Test", "hashedPass", "test@email.com", "00000000000",
+				nil, nil, "2025-01-02T03:04:05Z", nil, "user", false, "active", "", "", nil, nil, nil,
+			))
+
+		uDomain, err := repository.FindDeletedUser(userId)
+
+		assert.EqualValues(t, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), uDomain.DeletedAt)
+		assert.NoError(t, err)
+	})
+
+	t.Run("user_not_found", func(t *testing.T) {
+		userId := uuid.New()
+
+		mock.
+			ExpectQuery("UPDATE users SET deletedAt = NULL(.+) WHERE id = (.+)").
+			WithArgs(userId, sqlmock.AnyArg()).
+			WillReturnError(errors.New("sql: no rows in result set"))
+
+		id, err := repository.Restore(userId)
+
+		assert.EqualValues(t, uuid.Nil, id)
+		assert.EqualError(t, err, "sql: no rows in result set")
+	})
+
+	t.Run("restore_user_success", func(t *testing.T) {
+		userId := uuid.New()
+
+		mock.
+			ExpectQuery("UPDATE users SET deletedAt = NULL(.+) WHERE id = (.+)").
+			WithArgs(userId, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(userId))
+
+		id, err := repository.Restore(userId)
+
+		assert.EqualValues(t, userId, id)
+		assert.NoError(t, err)
+	})
+}
+
+// utcTime matches a time argument in UTC, the columns are timestamps without a time zone
+// and are read back as UTC
+type utcTime struct{}
+
+func (utcTime) Match(value driver.Value) bool {
+	instant, ok := value.(time.Time)
+
+	return ok && instant.Location() == time.UTC
+}
+
+func TestUserRepository_DeleteRestoreUTC(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	local := time.Local
+	time.Local = time.FixedZone("UTC-3", -3 * 60 * 60)
+	defer func() { time.Local = local }()
+
+	repository := repository.NewUserRepository(db)
+	userId := uuid.New()
+
+	mock.
+		ExpectQuery("UPDATE users SET deletedAt = (.+) WHERE id = (.+)").
+		WithArgs(userId, utcTime{}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(userId))
+
+	mock.
+		ExpectQuery("UPDATE users SET deletedAt = NULL(.+) WHERE id = (.+)").
+		WithArgs(userId, utcTime{}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(userId))
+
+	_, err = repository.Delete(userId)
+	assert.NoError(t, err)
+
+	_, err = repository.Restore(userId)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
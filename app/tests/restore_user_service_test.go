@@ -0,0 +1,89 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/services"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestUserService_Restore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repository := mocks.NewMockUserRepository(ctrl)
+	service := service.NewUserService(repository, service.WithRestoreGracePeriod(24 * time.Hour))
+
+	deletedUser := func(deletedAt time.Time) domain.UserDomain {
+		return domain.UserDomain{
+			Id: uuid.New(),
+			Name: "Test name",
+			Email: "test@email.com",
+			Phone: "00000000000",
+			DeletedAt: deletedAt,
+		}
+	}
+
+	t.Run("user_not_found", func(t *testing.T) {
+		userId := uuid.New()
+
+		repository.EXPECT().FindDeletedUser(userId).Return(domain.UserDomain{}, errors.New("sql: no rows in result set"))
+		id, err := service.Restore(userId)
+
+		assert.EqualValues(t, uuid.Nil, id)
+		assert.EqualError(t, err, "sql: no rows in result set")
+	})
+
+	t.Run("grace_period_expired", func(t *testing.T) {
+		uDomain := deletedUser(time.Now().Add(-48 * time.Hour))
+
+		repository.EXPECT().FindDeletedUser(uDomain.Id).Return(uDomain, nil)
+		id, err := service.Restore(uDomain.Id)
+
+		assert.EqualValues(t, uuid.Nil, id)
+		assert.EqualError(t, err, "Restore grace period has expired")
+	})
+
+	t.Run("phone_reused", func(t *testing.T) {
+		uDomain := deletedUser(time.Now().Add(-time.Hour))
+
+		repository.EXPECT().FindDeletedUser(uDomain.Id).Return(uDomain, nil)
+		repository.EXPECT().FindUserByPhone(uDomain.Phone).Return(domain.UserDomain{Id: uuid.New()}, nil)
+		id, err := service.Restore(uDomain.Id)
+
+		assert.EqualValues(t, uuid.Nil, id)
+		assert.EqualError(t, err, "Phone is already registered")
+	})
+
+	t.Run("email_reused", func(t *testing.T) {
+		uDomain := deletedUser(time.Now().Add(-time.Hour))
+
+		repository.EXPECT().FindDeletedUser(uDomain.Id).Return(uDomain, nil)
+		repository.EXPECT().FindUserByPhone(uDomain.Phone).Return(domain.UserDomain{}, errors.New("sql: no rows in result set"))
+		repository.EXPECT().FindUserByEmail(uDomain.Email).Return(domain.UserDomain{Id: uuid.New()}, nil)
+		id, err := service.Restore(uDomain.Id)
+
+		assert.EqualValues(t, uuid.Nil, id)
+		assert.EqualError(t, err, "Email is already registered")
+	})
+
+	t.Run("restore_user_success", func(t *testing.T) {
+		uDomain := deletedUser(time.Now().Add(-time.Hour))
+
+		repository.EXPECT().FindDeletedUser(uDomain.Id).Return(uDomain, nil)
+		repository.EXPECT().FindUserByPhone(uDomain.Phone).Return(domain.UserDomain{}, errors.New("sql: no rows in result set"))
+		repository.EXPECT().FindUserByEmail(uDomain.Email).Return(domain.UserDomain{}, errors.New("sql: no rows in result set"))
+		expectTransaction(repository)
+		repository.EXPECT().Restore(uDomain.Id).Return(uDomain.Id, nil)
+		repository.EXPECT().AddEvent(gomock.AssignableToTypeOf(domain.UserRestored{})).Return(nil)
+		id, err := service.Restore(uDomain.Id)
+
+		assert.EqualValues(t, uDomain.Id, id)
+		assert.NoError(t, err)
+	})
+}
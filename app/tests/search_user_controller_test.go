@@ -0,0 +1,71 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/controller"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/tests/config"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestUserController_Search(t *testing.T) {
+	crtl := gomock.NewController(t)
+	defer crtl.Finish()
+	service := mocks.NewMockUserService(crtl)
+	controller := controller.NewUserController(service)
+
+	t.Run("invalid_limit", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"q": {"john"}, "limit": {"ten"}}, "GET", nil)
+		controller.Search(context)
+
+		assert.EqualValues(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("query_too_short", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		service.EXPECT().Search("j", 0, 0).Return(domain.UserSearchPage{}, errors.New("Search query must have at least 2 characters"))
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"q": {"j"}}, "GET", nil)
+		controller.Search(context)
+
+		assert.EqualValues(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("results", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		service.EXPECT().Search("doe", 10, 20).Return(domain.UserSearchPage{
+			Hits: []domain.UserSearchHit{
+				{User: domain.UserDomain{Id: uuid.New(), Name: "John Doe"}, Score: 0.8, Field: domain.SearchFieldName, Highlight: "John <mark>Doe</mark>"},
+			},
+			Total: 21,
+			Limit: 10,
+			Offset: 20,
+		}, nil)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"q": {"doe"}, "limit": {"10"}, "offset": {"20"}}, "GET", nil)
+		controller.Search(context)
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), `"total":21`)
+		assert.NotContains(t, recorder.Body.String(), "password")
+	})
+}
@@ -0,0 +1,54 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/repository"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserRepository_Search(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	userRepository := repository.NewUserRepository(db)
+
+	t.Run("ranks_by_best_field", func(t *testing.T) {
+		userId := uuid.New()
+
+		mock.ExpectQuery("SELECT (.+) FROM \\(\\s*SELECT (.+) FROM users WHERE deletedAt IS NULL (.+) LIMIT \\$7 OFFSET \\$8").
+			WithArgs("50%_off", `50\%\_off%`, `%50\%\_off%`, "", "%%", domain.SearchSimilarityThreshold, 20, 40).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt", "nameScore", "emailScore", "phoneScore", "total",
+			}).AddRow(userId, "Test", "hash", "50%_off@test.com", "00000000000", "2025-01-02T03:04:05Z", nil, nil, 0.1, 0.9, 0, 41))
+
+		page, err := userRepository.Search(domain.UserSearch{Query: "50%_off", Limit: 20, Offset: 40})
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, 41, page.Total)
+		assert.EqualValues(t, domain.SearchFieldEmail, page.Hits[0].Field)
+		assert.EqualValues(t, 0.9, page.Hits[0].Score)
+	})
+
+	t.Run("phone_digits", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM users").
+			WithArgs("(11) 987", sqlmock.AnyArg(), sqlmock.AnyArg(), "11987", "%11987%", domain.SearchSimilarityThreshold, 20, 0).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt", "nameScore", "emailScore", "phoneScore", "total",
+			}))
+
+		page, err := userRepository.Search(domain.UserSearch{Query: "(11) 987", Limit: 20})
+
+		assert.NoError(t, err)
+		assert.Empty(t, page.Hits)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
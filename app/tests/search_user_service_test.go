@@ -0,0 +1,65 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/services"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestUserService_Search(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repository := mocks.NewMockUserRepository(ctrl)
+	service := service.NewUserService(repository)
+
+	t.Run("query_too_short", func(t *testing.T) {
+		_, err := service.Search(" a ", 0, 0)
+
+		assert.EqualError(t, err, "Search query must have at least 2 characters")
+	})
+
+	t.Run("repository_error", func(t *testing.T) {
+		repository.EXPECT().Search(gomock.Any()).Return(domain.UserSearchPage{}, errors.New("repository error"))
+
+		_, err := service.Search("john", 0, 0)
+
+		assert.EqualError(t, err, "repository error")
+	})
+
+	t.Run("limit_is_capped_and_results_highlighted", func(t *testing.T) {
+		repository.EXPECT().Search(domain.UserSearch{Query: "doe", Limit: 100, Offset: 0}).Return(domain.UserSearchPage{
+			Hits: []domain.UserSearchHit{
+				{User: domain.UserDomain{Id: uuid.New(), Name: "John Doe", Password: "hash"}, Score: 0.8, Field: domain.SearchFieldName},
+				{User: domain.UserDomain{Id: uuid.New(), Email: "jane.doe@test.com", Password: "hash"}, Score: 0.8, Field: domain.SearchFieldEmail},
+			},
+			Total: 2,
+			Limit: 100,
+		}, nil)
+
+		page, err := service.Search(" doe ", 500, -1)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, "John <mark>Doe</mark>", page.Hits[0].Highlight)
+		assert.EqualValues(t, "jane.<mark>doe</mark>@test.com", page.Hits[1].Highlight)
+		assert.Empty(t, page.Hits[0].User.Password)
+	})
+
+	t.Run("phone_highlights_digits", func(t *testing.T) {
+		repository.EXPECT().Search(domain.UserSearch{Query: "(119) 87", Limit: 20}).Return(domain.UserSearchPage{
+			Hits: []domain.UserSearchHit{
+				{User: domain.UserDomain{Id: uuid.New(), Phone: "11987654321"}, Score: 0.8, Field: domain.SearchFieldPhone},
+			},
+		}, nil)
+
+		page, err := service.Search("(119) 87", 0, 0)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, "<mark>11987</mark>654321", page.Hits[0].Highlight)
+	})
+}
@@ -0,0 +1,128 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/controller"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/middleware"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/tests/config"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestSessionController(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	crtl := gomock.NewController(t)
+	defer crtl.Finish()
+	service := mocks.NewMockUserService(crtl)
+	controller := controller.NewSessionController(service)
+
+	identity := domain.Identity{UserId: uuid.New(), Email: "john@email.com", SessionId: uuid.New()}
+
+	router := gin.New()
+	authenticator := authenticatorFunc(func(token string) (domain.Identity, error) {
+		return identity, nil
+	})
+
+	me := router.Group("/v1/me", middleware.Authenticate(authenticator, authenticator))
+	me.GET("/sessions", controller.ListMine)
+	me.DELETE("/sessions/:id", controller.RevokeMine)
+
+	send := func(method string, path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(method, path, nil)
+		request.Header.Set("Authorization", "Bearer token")
+
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	t.Run("list_mine_flags_current", func(t *testing.T) {
+		now := time.Now().UTC()
+		other := uuid.New()
+
+		service.EXPECT().ListSessions(identity.UserId).Return([]domain.Session{
+			{Id: identity.SessionId, UserId: identity.UserId, UserAgent: "curl", IP: "127.0.0.1", CreatedAt: now, LastSeenAt: now, ExpiresAt: now},
+			{Id: other, UserId: identity.UserId, UserAgent: "firefox", IP: "10.0.0.1", CreatedAt: now, LastSeenAt: now, ExpiresAt: now},
+		}, nil)
+
+		recorder := send("GET", "/v1/me/sessions")
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), `"id":"` + identity.SessionId.String() + `","userAgent":"curl","ip":"127.0.0.1"`)
+		assert.Contains(t, recorder.Body.String(), `"current":true`)
+		assert.Contains(t, recorder.Body.String(), `"current":false`)
+	})
+
+	t.Run("revoke_mine_invalid_id", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusBadRequest, send("DELETE", "/v1/me/sessions/invalid").Code)
+	})
+
+	t.Run("revoke_mine_not_found", func(t *testing.T) {
+		sessionId := uuid.New()
+
+		service.EXPECT().RevokeSession(identity.UserId, sessionId).Return(errors.New("sql: no rows in result set"))
+
+		assert.EqualValues(t, http.StatusNotFound, send("DELETE", "/v1/me/sessions/" + sessionId.String()).Code)
+	})
+
+	t.Run("revoke_mine", func(t *testing.T) {
+		sessionId := uuid.New()
+
+		service.EXPECT().RevokeSession(identity.UserId, sessionId).Return(nil)
+
+		assert.EqualValues(t, http.StatusOK, send("DELETE", "/v1/me/sessions/" + sessionId.String()).Code)
+	})
+
+	t.Run("admin_list_user_not_found", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+		userId := uuid.New()
+
+		service.EXPECT().ListSessions(userId).Return([]domain.Session{}, errors.New("sql: no rows in result set"))
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"id": {userId.String()}}, "GET", nil)
+
+		controller.List(context)
+
+		assert.EqualValues(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("admin_revoke_invalid_session_id", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"id": {uuid.New().String()}, "sessionId": {"invalid"}}, "DELETE", nil)
+
+		controller.Revoke(context)
+
+		assert.EqualValues(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("admin_revoke", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+		userId := uuid.New()
+		sessionId := uuid.New()
+
+		service.EXPECT().RevokeSession(userId, sessionId).Return(nil)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"id": {userId.String()}, "sessionId": {sessionId.String()}}, "DELETE", nil)
+
+		controller.Revoke(context)
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+	})
+}
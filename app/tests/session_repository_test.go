@@ -0,0 +1,93 @@
+package test
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/repository"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionRepository(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	sRepository := repository.NewSessionRepository(db)
+
+	columns := []string{"id", "userId", "userAgent", "ip", "createdAt", "lastSeenAt", "expiresAt", "revokedAt"}
+
+	t.Run("create_cuts_long_user_agent", func(t *testing.T) {
+		now := time.Now()
+		session := domain.Session{Id: uuid.New(), UserId: uuid.New(), UserAgent: strings.Repeat("a", 300), IP: "127.0.0.1", CreatedAt: now, LastSeenAt: now, ExpiresAt: now.Add(time.Hour)}
+
+		mock.ExpectExec("INSERT INTO sessions").
+			WithArgs(session.Id, session.UserId, strings.Repeat("a", 255), session.IP, session.CreatedAt, session.LastSeenAt, session.ExpiresAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, sRepository.CreateSession(session))
+	})
+
+	t.Run("find_revoked", func(t *testing.T) {
+		id := uuid.New()
+		now := time.Now()
+
+		mock.ExpectQuery("SELECT (.+) FROM sessions WHERE id = (.+)").
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(id, uuid.New(), "curl", "127.0.0.1", now, now, now.Add(time.Hour), now))
+
+		session, err := sRepository.FindSession(id)
+
+		assert.NoError(t, err)
+		assert.False(t, session.Active(now))
+	})
+
+	t.Run("list_active", func(t *testing.T) {
+		userId := uuid.New()
+		now := time.Now()
+
+		mock.ExpectQuery("SELECT (.+) FROM sessions WHERE userId = (.+) AND revokedAt IS NULL AND expiresAt > NOW\\(\\)").
+			WithArgs(userId).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(uuid.New(), userId, "curl", "127.0.0.1", now, now, now.Add(time.Hour), nil).
+				AddRow(uuid.New(), userId, "firefox", "10.0.0.1", now, now, now.Add(time.Hour), nil))
+
+		sessions, err := sRepository.ListSessions(userId)
+
+		assert.NoError(t, err)
+		assert.Len(t, sessions, 2)
+		assert.True(t, sessions[0].Active(now))
+	})
+
+	t.Run("revoke_not_found", func(t *testing.T) {
+		userId := uuid.New()
+		id := uuid.New()
+
+		mock.ExpectQuery("UPDATE sessions SET revokedAt = NOW\\(\\) WHERE id = (.+) AND userId = (.+) AND revokedAt IS NULL RETURNING id").
+			WithArgs(id, userId).
+			WillReturnError(sql.ErrNoRows)
+
+		assert.EqualError(t, sRepository.RevokeSession(userId, id), "sql: no rows in result set")
+	})
+
+	t.Run("touch", func(t *testing.T) {
+		id := uuid.New()
+		now := time.Now()
+
+		mock.ExpectExec("UPDATE sessions SET lastSeenAt = (.+) WHERE id = (.+)").
+			WithArgs(now, id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, sRepository.TouchSession(id, now))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,218 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/services"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestUserService_Sessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repository := mocks.NewMockUserRepository(ctrl)
+	sessions := mocks.NewMockSessionRepository(ctrl)
+	service := service.NewUserService(repository, service.WithSessions(sessions))
+
+	password := "password@123"
+
+	uDomain, err := domain.CreateUser(uuid.New(), "John Doe", "john@email.com", "00000000000", password, time.Time{}, time.Time{}, time.Time{})
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening creating a new user struct", err.Error())
+	}
+
+	client := domain.Client{IP: "127.0.0.1", UserAgent: "curl/8.0"}
+
+	// login returns the token and the session it created
+	login := func(t *testing.T) (string, domain.Session) {
+		var session domain.Session
+
+		repository.EXPECT().FindUserByEmail(uDomain.Email).Return(uDomain, nil)
+		sessions.EXPECT().CreateSession(gomock.Any()).DoAndReturn(func(dto domain.Session) error {
+			session = dto
+
+			return nil
+		})
+		repository.EXPECT().UpdateLastLogin(uDomain.Id, gomock.Any()).Return(nil)
+
+		result, err := service.Login(uDomain.Email, password, client)
+
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected on login", err.Error())
+		}
+
+		return result.Token, session
+	}
+
+	t.Run("login_creates_session", func(t *testing.T) {
+		token, session := login(t)
+
+		assert.EqualValues(t, uDomain.Id, session.UserId)
+		assert.EqualValues(t, client.IP, session.IP)
+		assert.EqualValues(t, client.UserAgent, session.UserAgent)
+		assert.WithinDuration(t, time.Now().Add(24 * time.Hour), session.ExpiresAt, time.Minute)
+
+		repository.EXPECT().List(uDomain.Id).Return(uDomain, nil)
+		sessions.EXPECT().FindSession(session.Id).Return(session, nil)
+
+		identity, err := service.Authenticate(token)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, domain.Identity{UserId: uDomain.Id, Email: uDomain.Email, SessionId: session.Id}, identity)
+	})
+
+	t.Run("session_create_error", func(t *testing.T) {
+		repository.EXPECT().FindUserByEmail(uDomain.Email).Return(uDomain, nil)
+		sessions.EXPECT().CreateSession(gomock.Any()).Return(errors.New("repository error"))
+
+		_, err := service.Login(uDomain.Email, password, client)
+
+		assert.EqualError(t, err, "repository error")
+	})
+
+	t.Run("revoked_session_is_rejected", func(t *testing.T) {
+		token, session := login(t)
+
+		session.RevokedAt = time.Now()
+
+		repository.EXPECT().List(uDomain.Id).Return(uDomain, nil)
+		sessions.EXPECT().FindSession(session.Id).Return(session, nil)
+
+		_, err := service.Authenticate(token)
+
+		assert.EqualError(t, err, "Session has been revoked")
+	})
+
+	t.Run("missing_session_is_rejected", func(t *testing.T) {
+		token, session := login(t)
+
+		repository.EXPECT().List(uDomain.Id).Return(uDomain, nil)
+		sessions.EXPECT().FindSession(session.Id).Return(domain.Session{}, errors.New("sql: no rows in result set"))
+
+		_, err := service.Authenticate(token)
+
+		assert.EqualError(t, err, "Session has been revoked")
+	})
+
+	t.Run("session_of_another_user_is_rejected", func(t *testing.T) {
+		token, session := login(t)
+
+		session.UserId = uuid.New()
+
+		repository.EXPECT().List(uDomain.Id).Return(uDomain, nil)
+		sessions.EXPECT().FindSession(session.Id).Return(session, nil)
+
+		_, err := service.Authenticate(token)
+
+		assert.EqualError(t, err, "Session has been revoked")
+	})
+
+	t.Run("last_seen_is_touched", func(t *testing.T) {
+		token, session := login(t)
+
+		session.LastSeenAt = time.Now().Add(-time.Hour)
+
+		repository.EXPECT().List(uDomain.Id).Return(uDomain, nil)
+		sessions.EXPECT().FindSession(session.Id).Return(session, nil)
+		sessions.EXPECT().TouchSession(session.Id, gomock.Any()).Return(errors.New("repository error"))
+
+		_, err := service.Authenticate(token)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("token_without_session_is_rejected", func(t *testing.T) {
+		withoutSessions := mocks.NewMockUserRepository(ctrl)
+
+		withoutSessions.EXPECT().List(uDomain.Id).Return(uDomain, nil)
+
+		token, _ := serviceWithoutSessions(withoutSessions).IssueToken(uDomain.Id, client)
+
+		repository.EXPECT().List(uDomain.Id).Return(uDomain, nil)
+
+		_, err := service.Authenticate(token)
+
+		assert.EqualError(t, err, "Invalid token")
+	})
+
+	t.Run("token_issued_before_password_change_is_rejected", func(t *testing.T) {
+		token, _ := login(t)
+
+		changed := uDomain
+		changed.PasswordChangedAt = time.Now().Add(time.Minute)
+
+		repository.EXPECT().List(uDomain.Id).Return(changed, nil)
+
+		_, err := service.Authenticate(token)
+
+		assert.EqualError(t, err, "Invalid token")
+	})
+
+	t.Run("token_issued_after_password_change_is_accepted", func(t *testing.T) {
+		token, session := login(t)
+
+		// the column only keeps seconds, a login right after the change has the same iat
+		changed := uDomain
+		changed.PasswordChangedAt = time.Now().Truncate(time.Second)
+
+		repository.EXPECT().List(uDomain.Id).Return(changed, nil)
+		sessions.EXPECT().FindSession(session.Id).Return(session, nil)
+
+		_, err := service.Authenticate(token)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("token_of_deleted_user_is_rejected", func(t *testing.T) {
+		token, _ := login(t)
+
+		repository.EXPECT().List(uDomain.Id).Return(domain.UserDomain{}, errors.New("sql: no rows in result set"))
+
+		_, err := service.Authenticate(token)
+
+		assert.EqualError(t, err, "Invalid token")
+	})
+
+	t.Run("invalid_token", func(t *testing.T) {
+		_, err := service.Authenticate("not-a-token")
+
+		assert.EqualError(t, err, "Invalid token")
+	})
+
+	t.Run("list_sessions_user_not_found", func(t *testing.T) {
+		repository.EXPECT().List(uDomain.Id).Return(domain.UserDomain{}, errors.New("sql: no rows in result set"))
+
+		_, err := service.ListSessions(uDomain.Id)
+
+		assert.EqualError(t, err, "sql: no rows in result set")
+	})
+
+	t.Run("list_sessions", func(t *testing.T) {
+		repository.EXPECT().List(uDomain.Id).Return(uDomain, nil)
+		sessions.EXPECT().ListSessions(uDomain.Id).Return([]domain.Session{{Id: uuid.New()}}, nil)
+
+		result, err := service.ListSessions(uDomain.Id)
+
+		assert.NoError(t, err)
+		assert.Len(t, result, 1)
+	})
+
+	t.Run("revoke_session", func(t *testing.T) {
+		sessionId := uuid.New()
+
+		sessions.EXPECT().RevokeSession(uDomain.Id, sessionId).Return(nil)
+
+		assert.NoError(t, service.RevokeSession(uDomain.Id, sessionId))
+	})
+}
+
+func serviceWithoutSessions(repository *mocks.MockUserRepository) service.UserService {
+	return service.NewUserService(repository)
+}
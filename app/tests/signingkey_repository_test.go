@@ -0,0 +1,68 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/repository"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSigningKeyRepository(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	skRepository := repository.NewSigningKeyRepository(db)
+
+	columns := []string{"id", "algorithm", "privateKey", "createdAt", "retiresAt", "expiresAt"}
+
+	t.Run("create", func(t *testing.T) {
+		now := time.Now()
+		key := domain.SigningKey{Id: "kid", Algorithm: domain.SigningRS256, EncryptedKey: "v1:encrypted", CreatedAt: now, RetiresAt: now.Add(time.Hour), ExpiresAt: now.Add(2 * time.Hour)}
+
+		mock.ExpectExec("INSERT INTO signing_keys (.+) ON CONFLICT \\(id\\) DO NOTHING").
+			WithArgs(key.Id, key.Algorithm, key.EncryptedKey, key.CreatedAt, key.RetiresAt, key.ExpiresAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, skRepository.CreateSigningKey(key))
+	})
+
+	t.Run("list_not_expired", func(t *testing.T) {
+		now := time.Now()
+
+		mock.ExpectQuery("SELECT (.+) FROM signing_keys WHERE expiresAt > (.+) ORDER BY createdAt").
+			WithArgs(now).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("current", domain.SigningRS256, "v1:current", now.Add(-time.Hour), now.Add(time.Hour), now.Add(2 * time.Hour)).
+				AddRow("next", domain.SigningRS256, "v1:next", now, now.Add(3 * time.Hour), now.Add(4 * time.Hour)))
+
+		keys, err := skRepository.ListSigningKeys(now)
+
+		assert.NoError(t, err)
+		assert.Len(t, keys, 2)
+		assert.EqualValues(t, "v1:next", keys[1].EncryptedKey)
+		assert.Nil(t, keys[1].Key)
+	})
+
+	t.Run("delete_expired", func(t *testing.T) {
+		now := time.Now()
+
+		mock.ExpectExec("DELETE FROM signing_keys WHERE expiresAt <= (.+)").
+			WithArgs(now).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		deleted, err := skRepository.DeleteExpiredSigningKeys(now)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, deleted)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,14 @@
+package test
+
+import (
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// expectTransaction makes the mocked repository run the transaction callback against itself
+func expectTransaction(repository *mocks.MockUserRepository) *gomock.Call {
+	return repository.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(port.UserRepository) error) error {
+		return fn(repository)
+	})
+}
@@ -0,0 +1,57 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/repository"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserRepository_Transaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	t.Run("nested_transaction_uses_a_savepoint", func(t *testing.T) {
+		refused := uuid.New()
+		created := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("SAVEPOINT nested_transaction").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("INSERT INTO users (.+)").WillReturnError(errors.New("pq: duplicate key value violates unique constraint"))
+		mock.ExpectExec("ROLLBACK TO SAVEPOINT nested_transaction").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("SAVEPOINT nested_transaction").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("INSERT INTO users (.+)").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(created))
+		mock.ExpectExec("RELEASE SAVEPOINT nested_transaction").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err := repository.NewUserRepository(db).Transaction(func(tx port.UserRepository) error {
+			err := tx.Transaction(func(row port.UserRepository) error {
+				_, err := row.Create(domain.UserDomain{Id: refused, Name: "Test name", Email: "test@email.com", Phone: "00000000000"})
+
+				return err
+			})
+
+			assert.EqualError(t, err, "pq: duplicate key value violates unique constraint")
+
+			// the outer transaction can still be used after the failed savepoint
+			return tx.Transaction(func(row port.UserRepository) error {
+				_, err := row.Create(domain.UserDomain{Id: created, Name: "Other name", Email: "other@email.com", Phone: "11111111111"})
+
+				return err
+			})
+		})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,198 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/controller"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/middleware"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/model"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/tests/config"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestTwoFactorController(t *testing.T) {
+	crtl := gomock.NewController(t)
+	defer crtl.Finish()
+	service := mocks.NewMockTwoFactorService(crtl)
+	controller := controller.NewTwoFactorController(service)
+
+	userId := uuid.New()
+
+	// enrolment and confirmation only act on the authenticated user, never on an id of the request
+	tokens := authenticatorFunc(func(token string) (domain.Identity, error) {
+		if token == "valid" {
+			return domain.Identity{UserId: userId, Email: "john@email.com", SessionId: uuid.New()}, nil
+		}
+
+		return domain.Identity{}, errors.New("Invalid token")
+	})
+
+	apiKeys := authenticatorFunc(func(key string) (domain.Identity, error) {
+		return domain.Identity{UserId: userId, APIKeyId: uuid.New(), Scopes: domain.APIKeyScopes}, nil
+	})
+
+	router := gin.New()
+	me := router.Group("/v1/me", middleware.Authenticate(tokens, apiKeys))
+	me.POST("/2fa/enroll", controller.Enroll)
+	me.POST("/2fa/confirm", controller.Confirm)
+
+	send := func(path string, body any, header string, value string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		encoded, _ := json.Marshal(body)
+		request := httptest.NewRequest("POST", path, strings.NewReader(string(encoded)))
+		request.Header.Set("Content-Type", "application/json")
+
+		if value != "" {
+			request.Header.Set(header, value)
+		}
+
+		router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	t.Run("enroll_anonymous", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusUnauthorized, send("/v1/me/2fa/enroll", nil, "", "").Code)
+	})
+
+	t.Run("enroll_with_api_key", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusForbidden, send("/v1/me/2fa/enroll", nil, middleware.APIKeyHeader, "key").Code)
+	})
+
+	t.Run("enroll_ignores_other_user_id", func(t *testing.T) {
+		service.EXPECT().Enroll(userId).Return(domain.TwoFactorEnrollment{}, errors.New("Two-factor is already enabled"))
+
+		recorder := send("/v1/me/2fa/enroll?id=" + uuid.New().String(), nil, "Authorization", "Bearer valid")
+
+		assert.EqualValues(t, http.StatusConflict, recorder.Code)
+	})
+
+	t.Run("enroll_success", func(t *testing.T) {
+		service.EXPECT().Enroll(userId).Return(domain.TwoFactorEnrollment{Secret: "JBSWY3DPEHPK3PXP", URI: "otpauth://totp/go-hexagonal:john", QRCode: []byte("png")}, nil)
+
+		recorder := send("/v1/me/2fa/enroll", nil, "Authorization", "Bearer valid")
+
+		var response model.TwoFactorEnrollmentModel
+		json.Unmarshal(recorder.Body.Bytes(), &response)
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+		assert.EqualValues(t, "JBSWY3DPEHPK3PXP", response.Secret)
+		assert.EqualValues(t, "data:image/png;base64,cG5n", response.QrCode)
+	})
+
+	t.Run("confirm_invalid_token", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusUnauthorized, send("/v1/me/2fa/confirm", model.TwoFactorCodeModel{Code: "123456"}, "Authorization", "Bearer forged").Code)
+	})
+
+	t.Run("confirm_with_api_key", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusForbidden, send("/v1/me/2fa/confirm", model.TwoFactorCodeModel{Code: "123456"}, middleware.APIKeyHeader, "key").Code)
+	})
+
+	t.Run("confirm_invalid_code", func(t *testing.T) {
+		service.EXPECT().Confirm(userId, "000000").Return(nil, errors.New("Invalid two-factor code"))
+
+		recorder := send("/v1/me/2fa/confirm", model.TwoFactorCodeModel{Code: "000000"}, "Authorization", "Bearer valid")
+
+		assert.EqualValues(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("confirm_not_enrolled", func(t *testing.T) {
+		service.EXPECT().Confirm(userId, "123456").Return(nil, errors.New("sql: no rows in result set"))
+
+		recorder := send("/v1/me/2fa/confirm?id=" + uuid.New().String(), model.TwoFactorCodeModel{Code: "123456"}, "Authorization", "Bearer valid")
+
+		assert.EqualValues(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("confirm_success", func(t *testing.T) {
+		service.EXPECT().Confirm(userId, "123456").Return([]string{"abcde-fghij"}, nil)
+
+		recorder := send("/v1/me/2fa/confirm", model.TwoFactorCodeModel{Code: "123456"}, "Authorization", "Bearer valid")
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{"recoveryCodes":["abcde-fghij"]}`, recorder.Body.String())
+	})
+
+	t.Run("login_missing_code", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		body, _ := json.Marshal(model.TwoFactorLoginModel{ChallengeToken: "challenge"})
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{}, "POST", io.NopCloser(strings.NewReader(string(body))))
+
+		controller.Login(context)
+
+		assert.EqualValues(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("login_invalid_code", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		body, _ := json.Marshal(model.TwoFactorLoginModel{ChallengeToken: "challenge", Code: "000000"})
+
+		service.EXPECT().CompleteLogin("challenge", "000000", gomock.Any()).Return(domain.LoginResult{}, errors.New("Invalid two-factor code"))
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{}, "POST", io.NopCloser(strings.NewReader(string(body))))
+
+		controller.Login(context)
+
+		assert.EqualValues(t, http.StatusUnauthorized, recorder.Code)
+	})
+
+	t.Run("login_success", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		body, _ := json.Marshal(model.TwoFactorLoginModel{ChallengeToken: "challenge", Code: "123456"})
+
+		service.EXPECT().CompleteLogin("challenge", "123456", gomock.Any()).Return(domain.LoginResult{UserId: userId, Token: "super-token"}, nil)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{}, "POST", io.NopCloser(strings.NewReader(string(body))))
+
+		controller.Login(context)
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{"token":"super-token","twoFactorRequired":false}`, recorder.Body.String())
+	})
+
+	t.Run("reset_not_found", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		service.EXPECT().Reset(userId).Return(errors.New("sql: no rows in result set"))
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"id": {userId.String()}}, "DELETE", nil)
+
+		controller.Reset(context)
+
+		assert.EqualValues(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("reset_success", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		service.EXPECT().Reset(userId).Return(nil)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"id": {userId.String()}}, "DELETE", nil)
+
+		controller.Reset(context)
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+	})
+}
@@ -0,0 +1,113 @@
+package test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/repository"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTwoFactorRepository(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	tfRepository := repository.NewTwoFactorRepository(db)
+
+	t.Run("find_unconfirmed", func(t *testing.T) {
+		userId := uuid.New()
+
+		mock.ExpectQuery("SELECT (.+) FROM user_two_factor WHERE userId = (.+)").
+			WithArgs(userId).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"userId", "secret", "confirmedAt", "lastUsedStep", "createdAt",
+			}).AddRow(userId, "v1:secret", nil, 0, time.Now()))
+
+		twoFactor, err := tfRepository.FindTwoFactor(userId)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, "v1:secret", twoFactor.Secret)
+		assert.False(t, twoFactor.Enabled())
+	})
+
+	t.Run("find_not_found", func(t *testing.T) {
+		userId := uuid.New()
+
+		mock.ExpectQuery("SELECT (.+) FROM user_two_factor WHERE userId = (.+)").
+			WithArgs(userId).
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := tfRepository.FindTwoFactor(userId)
+
+		assert.EqualError(t, err, "sql: no rows in result set")
+	})
+
+	t.Run("save_upserts", func(t *testing.T) {
+		twoFactor := domain.TwoFactor{UserId: uuid.New(), Secret: "v1:secret", ConfirmedAt: time.Now(), LastUsedStep: 42, CreatedAt: time.Now()}
+
+		mock.ExpectExec("INSERT INTO user_two_factor (.+) ON CONFLICT \\(userId\\) DO UPDATE").
+			WithArgs(twoFactor.UserId, twoFactor.Secret, sqlmock.AnyArg(), twoFactor.LastUsedStep, twoFactor.CreatedAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, tfRepository.SaveTwoFactor(twoFactor))
+	})
+
+	t.Run("delete_removes_recovery_codes", func(t *testing.T) {
+		userId := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("DELETE FROM user_recovery_codes WHERE userId = (.+)").WithArgs(userId).WillReturnResult(sqlmock.NewResult(0, 10))
+		mock.ExpectExec("DELETE FROM user_two_factor WHERE userId = (.+)").WithArgs(userId).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, tfRepository.DeleteTwoFactor(userId))
+	})
+
+	t.Run("replace_recovery_codes", func(t *testing.T) {
+		userId := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("DELETE FROM user_recovery_codes WHERE userId = (.+)").WithArgs(userId).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO user_recovery_codes").WithArgs(userId, "hash-1").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO user_recovery_codes").WithArgs(userId, "hash-2").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, tfRepository.ReplaceRecoveryCodes(userId, []string{"hash-1", "hash-2"}))
+	})
+
+	t.Run("use_recovery_code", func(t *testing.T) {
+		userId := uuid.New()
+
+		mock.ExpectQuery("UPDATE user_recovery_codes SET usedAt = NOW\\(\\) (.+) AND usedAt IS NULL RETURNING codeHash").
+			WithArgs(userId, "hash-1").
+			WillReturnRows(sqlmock.NewRows([]string{"codeHash"}).AddRow("hash-1"))
+
+		used, err := tfRepository.UseRecoveryCode(userId, "hash-1")
+
+		assert.NoError(t, err)
+		assert.True(t, used)
+	})
+
+	t.Run("use_spent_recovery_code", func(t *testing.T) {
+		userId := uuid.New()
+
+		mock.ExpectQuery("UPDATE user_recovery_codes SET usedAt = NOW\\(\\)").
+			WithArgs(userId, "hash-1").
+			WillReturnError(sql.ErrNoRows)
+
+		used, err := tfRepository.UseRecoveryCode(userId, "hash-1")
+
+		assert.NoError(t, err)
+		assert.False(t, used)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,225 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/cipher"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/services"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/google/uuid"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestTwoFactorService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	users := mocks.NewMockUserRepository(ctrl)
+	tfRepository := mocks.NewMockTwoFactorRepository(ctrl)
+	secretCipher, _ := cipher.NewAESGCMCipher(bytes.Repeat([]byte{1}, 32))
+	uService := service.NewUserService(users, service.WithTwoFactor(tfRepository))
+	tfService := service.NewTwoFactorService(tfRepository, users, uService, secretCipher, "go-hexagonal")
+
+	password := "password@123"
+
+	uDomain, err := domain.CreateUser(uuid.New(), "John Doe", "john@email.com", "00000000000", password, time.Time{}, time.Time{}, time.Time{})
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening creating a new user struct", err.Error())
+	}
+
+	secret := "JBSWY3DPEHPK3PXP"
+	encrypted, _ := secretCipher.Encrypt([]byte(secret))
+	enabled := domain.TwoFactor{UserId: uDomain.Id, Secret: encrypted, ConfirmedAt: time.Now(), CreatedAt: time.Now()}
+
+	// challenge goes through the password step like a client would
+	challenge := func(t *testing.T) string {
+		users.EXPECT().FindUserByEmail(uDomain.Email).Return(uDomain, nil)
+		tfRepository.EXPECT().FindTwoFactor(uDomain.Id).Return(enabled, nil)
+
+		result, err := uService.Login(uDomain.Email, password, domain.Client{})
+
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected on the password step", err.Error())
+		}
+
+		return result.ChallengeToken
+	}
+
+	t.Run("enroll_user_not_found", func(t *testing.T) {
+		users.EXPECT().List(uDomain.Id).Return(domain.UserDomain{}, errors.New("sql: no rows in result set"))
+
+		_, err := tfService.Enroll(uDomain.Id)
+
+		assert.EqualError(t, err, "sql: no rows in result set")
+	})
+
+	t.Run("enroll_already_enabled", func(t *testing.T) {
+		users.EXPECT().List(uDomain.Id).Return(uDomain, nil)
+		tfRepository.EXPECT().FindTwoFactor(uDomain.Id).Return(enabled, nil)
+
+		_, err := tfService.Enroll(uDomain.Id)
+
+		assert.EqualError(t, err, "Two-factor is already enabled")
+	})
+
+	t.Run("enroll_success", func(t *testing.T) {
+		var saved domain.TwoFactor
+
+		users.EXPECT().List(uDomain.Id).Return(uDomain, nil)
+		tfRepository.EXPECT().FindTwoFactor(uDomain.Id).Return(domain.TwoFactor{}, errors.New("sql: no rows in result set"))
+		tfRepository.EXPECT().SaveTwoFactor(gomock.Any()).DoAndReturn(func(dto domain.TwoFactor) error {
+			saved = dto
+
+			return nil
+		})
+
+		result, err := tfService.Enroll(uDomain.Id)
+
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(result.URI, "otpauth://totp/go-hexagonal:john@email.com?"))
+		assert.True(t, bytes.HasPrefix(result.QRCode, []byte("\x89PNG")))
+		assert.False(t, saved.Enabled())
+		assert.NotEqual(t, result.Secret, saved.Secret)
+
+		plain, err := secretCipher.Decrypt(saved.Secret)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, result.Secret, string(plain))
+	})
+
+	t.Run("confirm_invalid_code", func(t *testing.T) {
+		tfRepository.EXPECT().FindTwoFactor(uDomain.Id).Return(domain.TwoFactor{UserId: uDomain.Id, Secret: encrypted}, nil)
+
+		_, err := tfService.Confirm(uDomain.Id, "000000")
+
+		assert.EqualError(t, err, "Invalid two-factor code")
+	})
+
+	t.Run("confirm_already_enabled", func(t *testing.T) {
+		tfRepository.EXPECT().FindTwoFactor(uDomain.Id).Return(enabled, nil)
+
+		_, err := tfService.Confirm(uDomain.Id, "000000")
+
+		assert.EqualError(t, err, "Two-factor is already enabled")
+	})
+
+	t.Run("confirm_success", func(t *testing.T) {
+		code, _ := totp.GenerateCode(secret, time.Now())
+		var hashes []string
+
+		tfRepository.EXPECT().FindTwoFactor(uDomain.Id).Return(domain.TwoFactor{UserId: uDomain.Id, Secret: encrypted}, nil)
+		tfRepository.EXPECT().ReplaceRecoveryCodes(uDomain.Id, gomock.Any()).DoAndReturn(func(userId uuid.UUID, values []string) error {
+			hashes = values
+
+			return nil
+		})
+		tfRepository.EXPECT().SaveTwoFactor(gomock.Any()).DoAndReturn(func(dto domain.TwoFactor) error {
+			assert.True(t, dto.Enabled())
+			assert.NotZero(t, dto.LastUsedStep)
+
+			return nil
+		})
+
+		codes, err := tfService.Confirm(uDomain.Id, code)
+
+		assert.NoError(t, err)
+		assert.Len(t, codes, domain.RecoveryCodeCount)
+		assert.EqualValues(t, domain.HashRecoveryCode(codes[0]), hashes[0])
+		assert.NotContains(t, hashes, codes[0])
+	})
+
+	t.Run("complete_login_with_totp", func(t *testing.T) {
+		challengeToken := challenge(t)
+		code, _ := totp.GenerateCode(secret, time.Now())
+
+		tfRepository.EXPECT().FindTwoFactor(uDomain.Id).Return(enabled, nil)
+		tfRepository.EXPECT().SaveTwoFactor(gomock.Any()).Return(nil)
+		users.EXPECT().List(uDomain.Id).Return(uDomain, nil)
+		users.EXPECT().UpdateLastLogin(uDomain.Id, gomock.Any()).Return(nil)
+
+		result, err := tfService.CompleteLogin(challengeToken, code, domain.Client{})
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, result.Token)
+		assert.EqualValues(t, uDomain.Id, result.UserId)
+	})
+
+	t.Run("complete_login_replayed_code", func(t *testing.T) {
+		challengeToken := challenge(t)
+		code, _ := totp.GenerateCode(secret, time.Now())
+
+		used := enabled
+		used.LastUsedStep = time.Now().Unix() / 30 + 1
+
+		tfRepository.EXPECT().FindTwoFactor(uDomain.Id).Return(used, nil)
+
+		_, err := tfService.CompleteLogin(challengeToken, code, domain.Client{})
+
+		assert.EqualError(t, err, "Invalid two-factor code")
+	})
+
+	t.Run("complete_login_with_recovery_code", func(t *testing.T) {
+		challengeToken := challenge(t)
+
+		tfRepository.EXPECT().FindTwoFactor(uDomain.Id).Return(enabled, nil)
+		tfRepository.EXPECT().UseRecoveryCode(uDomain.Id, domain.HashRecoveryCode("abcde-fghij")).Return(true, nil)
+		users.EXPECT().List(uDomain.Id).Return(uDomain, nil)
+		users.EXPECT().UpdateLastLogin(uDomain.Id, gomock.Any()).Return(nil)
+
+		result, err := tfService.CompleteLogin(challengeToken, "ABCDE FGHIJ", domain.Client{})
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, result.Token)
+	})
+
+	t.Run("complete_login_with_spent_recovery_code", func(t *testing.T) {
+		challengeToken := challenge(t)
+
+		tfRepository.EXPECT().FindTwoFactor(uDomain.Id).Return(enabled, nil)
+		tfRepository.EXPECT().UseRecoveryCode(uDomain.Id, gomock.Any()).Return(false, nil)
+
+		_, err := tfService.CompleteLogin(challengeToken, "abcde-fghij", domain.Client{})
+
+		assert.EqualError(t, err, "Invalid two-factor code")
+	})
+
+	t.Run("complete_login_after_reset", func(t *testing.T) {
+		challengeToken := challenge(t)
+
+		tfRepository.EXPECT().FindTwoFactor(uDomain.Id).Return(domain.TwoFactor{}, errors.New("sql: no rows in result set"))
+
+		_, err := tfService.CompleteLogin(challengeToken, "123456", domain.Client{})
+
+		assert.EqualError(t, err, "Invalid challenge token")
+	})
+
+	t.Run("access_token_is_not_a_challenge", func(t *testing.T) {
+		users.EXPECT().List(uDomain.Id).Return(uDomain, nil)
+
+		token, _ := uService.IssueToken(uDomain.Id, domain.Client{})
+
+		_, err := tfService.CompleteLogin(token, "123456", domain.Client{})
+
+		assert.EqualError(t, err, "Invalid challenge token")
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		users.EXPECT().List(uDomain.Id).Return(uDomain, nil)
+		tfRepository.EXPECT().DeleteTwoFactor(uDomain.Id).Return(nil)
+
+		assert.NoError(t, tfService.Reset(uDomain.Id))
+	})
+
+	t.Run("reset_user_not_found", func(t *testing.T) {
+		users.EXPECT().List(uDomain.Id).Return(domain.UserDomain{}, errors.New("sql: no rows in result set"))
+
+		assert.EqualError(t, tfService.Reset(uDomain.Id), "sql: no rows in result set")
+	})
+}
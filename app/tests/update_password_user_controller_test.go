@@ -11,6 +11,7 @@ import (
 	"testing"
 	"github.com/PedroPereiraN/go-hexagonal/adapter/input/controller"
 	"github.com/PedroPereiraN/go-hexagonal/adapter/input/model"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
 	"github.com/PedroPereiraN/go-hexagonal/tests/config"
 	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
 	"github.com/gin-gonic/gin"
@@ -50,18 +51,24 @@ func TestUserController_UpdatePassword(t *testing.T) {
 
 		url := url.Values{"id": {uuid.New().String()}}
 
-		model := model.UpdateUserPasswordModel{
+		model := model.ChangePasswordModel{
+			CurrentPassword: "password@123",
 			Password: "123",
 		}
 
 		body, _ := json.Marshal(model)
 		stringReader := io.NopCloser(strings.NewReader(string(body)))
 
+		service.EXPECT().UpdatePassword(gomock.Any(), "password@123", "123").Return(uuid.Nil, &domain.PasswordPolicyError{
+			Violations: []domain.PasswordViolation{{Rule: domain.PasswordRuleMinLength, Message: "Password must have at least 6 characters"}},
+		})
+
 		config.MakeRequest(context, params, url, "PUT", stringReader)
 
 		controller.UpdatePassword(context)
 
 		assert.EqualValues(t, http.StatusBadRequest, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), `"rule":"min_length"`)
 	})
 
 	t.Run("service_error", func(t *testing.T) {
@@ -76,14 +83,15 @@ func TestUserController_UpdatePassword(t *testing.T) {
 
 		url := url.Values{"id": {userId.String()}}
 
-		model := model.UpdateUserPasswordModel{
-			Password: "password@123",
+		model := model.ChangePasswordModel{
+			CurrentPassword: "password@123",
+			Password: "password@456",
 		}
 
 		body, _ := json.Marshal(model)
 		stringReader := io.NopCloser(strings.NewReader(string(body)))
 
-		service.EXPECT().UpdatePassword(userId, gomock.Any()).Return(uuid.Nil, errors.New("Invalid user values"))
+		service.EXPECT().UpdatePassword(userId, "password@123", "password@456").Return(uuid.Nil, errors.New("Invalid user values"))
 
 		config.MakeRequest(context, params, url, "POST", stringReader)
 
@@ -103,14 +111,15 @@ func TestUserController_UpdatePassword(t *testing.T) {
 
 		url := url.Values{"id": {userId.String()}}
 
-		model := model.UpdateUserPasswordModel{
-			Password: "password@123",
+		model := model.ChangePasswordModel{
+			CurrentPassword: "password@123",
+			Password: "password@456",
 		}
 
 		body, _ := json.Marshal(model)
 		stringReader := io.NopCloser(strings.NewReader(string(body)))
 
-		service.EXPECT().UpdatePassword(userId, gomock.Any()).Return(userId, nil)
+		service.EXPECT().UpdatePassword(userId, "password@123", "password@456").Return(userId, nil)
 
 		config.MakeRequest(context, params, url, "PUT", stringReader)
 
@@ -118,4 +127,83 @@ func TestUserController_UpdatePassword(t *testing.T) {
 
 		assert.EqualValues(t, http.StatusOK, recorder.Code)
 	})
+
+	t.Run("missing_current_password", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		url := url.Values{"id": {uuid.New().String()}}
+
+		config.MakeRequest(context, []gin.Param{}, url, "PATCH", io.NopCloser(strings.NewReader(`{"password":"password@456"}`)))
+
+		controller.UpdatePassword(context)
+
+		assert.EqualValues(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("wrong_current_password", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		userId := uuid.New()
+
+		url := url.Values{"id": {userId.String()}}
+
+		service.EXPECT().UpdatePassword(userId, "wrong@123", "password@456").Return(uuid.Nil, errors.New("Wrong password"))
+
+		config.MakeRequest(context, []gin.Param{}, url, "PATCH", io.NopCloser(strings.NewReader(`{"currentPassword":"wrong@123","password":"password@456"}`)))
+
+		controller.UpdatePassword(context)
+
+		assert.EqualValues(t, http.StatusForbidden, recorder.Code)
+	})
+}
+
+func TestUserController_ResetPassword(t *testing.T) {
+	crtl := gomock.NewController(t)
+	defer crtl.Finish()
+	service := mocks.NewMockUserService(crtl)
+	controller := controller.NewUserController(service)
+
+	send := func(userId string, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"id": {userId}}, "PATCH", io.NopCloser(strings.NewReader(body)))
+
+		controller.ResetPassword(context)
+
+		return recorder
+	}
+
+	t.Run("invalid_id", func(t *testing.T) {
+		assert.EqualValues(t, http.StatusBadRequest, send("invalid", `{"password":"password@456"}`).Code)
+	})
+
+	t.Run("user_not_found", func(t *testing.T) {
+		userId := uuid.New()
+
+		service.EXPECT().ResetPassword(userId, "password@456").Return(uuid.Nil, errors.New("sql: no rows in result set"))
+
+		assert.EqualValues(t, http.StatusNotFound, send(userId.String(), `{"password":"password@456"}`).Code)
+	})
+
+	t.Run("current_password_reused", func(t *testing.T) {
+		userId := uuid.New()
+
+		service.EXPECT().ResetPassword(userId, "password@123").Return(uuid.Nil, errors.New("New password must be different from the current password"))
+
+		assert.EqualValues(t, http.StatusBadRequest, send(userId.String(), `{"password":"password@123"}`).Code)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		userId := uuid.New()
+
+		service.EXPECT().ResetPassword(userId, "password@456").Return(userId, nil)
+
+		assert.EqualValues(t, http.StatusOK, send(userId.String(), `{"password":"password@456"}`).Code)
+	})
 }
@@ -3,6 +3,7 @@ package test
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/PedroPereiraN/go-hexagonal/adapter/output/repository"
@@ -35,6 +36,8 @@ func TestUserRepository_UpdatePassword(t *testing.T) {
     WithArgs(
 			uDomain.Id,
 			sqlmock.AnyArg(),
+			sqlmock.AnyArg(),
+			sqlmock.AnyArg(),
 		).
 		WillReturnError(errors.New("sql: no rows in result set"))
 
@@ -57,6 +60,8 @@ func TestUserRepository_UpdatePassword(t *testing.T) {
     WithArgs(
 			uDomain.Id,
 			sqlmock.AnyArg(),
+			sqlmock.AnyArg(),
+			sqlmock.AnyArg(),
 		).
 		WillReturnError(errors.New("database update failed"))
 
@@ -67,6 +72,32 @@ func TestUserRepository_UpdatePassword(t *testing.T) {
 	})
 
 	t.Run("update_user_password_success", func(t *testing.T) {
+		uDomain := domain.UserDomain{
+			Id:    uuid.New(),
+			Name:  "Test name",
+			Email: "test@email.com",
+			Phone: "00000000000",
+			PasswordChangedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		}
+
+		mock.ExpectQuery("UPDATE users SET password = (.+), passwordChangedAt = COALESCE(.+), passwordChangeRequired = (.+) WHERE id = (.+)").
+    WithArgs(
+			uDomain.Id,
+			sqlmock.AnyArg(),
+			uDomain.PasswordChangedAt,
+			false,
+		).
+    WillReturnRows(
+        sqlmock.NewRows([]string{"id"}).AddRow(uDomain.Id),
+    )
+
+		id, err := repository.UpdatePassword(uDomain.Id, uDomain)
+
+		assert.EqualValues(t, uDomain.Id, id)
+		assert.NoError(t, err)
+	})
+
+	t.Run("rehash_keeps_password_changed_at", func(t *testing.T) {
 		uDomain := domain.UserDomain{
 			Id:    uuid.New(),
 			Name:  "Test name",
@@ -78,6 +109,8 @@ func TestUserRepository_UpdatePassword(t *testing.T) {
     WithArgs(
 			uDomain.Id,
 			sqlmock.AnyArg(),
+			nil,
+			false,
 		).
     WillReturnRows(
         sqlmock.NewRows([]string{"id"}).AddRow(uDomain.Id),
@@ -87,5 +120,6 @@ func TestUserRepository_UpdatePassword(t *testing.T) {
 
 		assert.EqualValues(t, uDomain.Id, id)
 		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
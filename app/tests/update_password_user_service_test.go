@@ -3,6 +3,7 @@ package test
 import (
 	"errors"
 	"testing"
+	"time"
 	"github.com/PedroPereiraN/go-hexagonal/domain"
 	"github.com/PedroPereiraN/go-hexagonal/services"
 	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
@@ -17,28 +18,49 @@ func TestUserService_UpdatePassword(t *testing.T) {
 	repository := mocks.NewMockUserRepository(ctrl)
 	service := service.NewUserService(repository)
 
+	user, err := domain.CreateUser(uuid.Nil, "Test name", "test@email.com", "00000000000", "password@123", time.Time{}, time.Time{}, time.Time{})
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when creating the user", err.Error())
+	}
+
 	t.Run("user_not_found", func(t *testing.T) {
 
 		userId := uuid.New()
-		newPassword := "password@123"
+		newPassword := "password@456"
 
 		repository.EXPECT().List(userId).Return(domain.UserDomain{}, errors.New("User not found"))
-		id, err := service.UpdatePassword(userId, newPassword)
+		id, err := service.UpdatePassword(userId, "password@123", newPassword)
 
 		assert.EqualValues(t, uuid.Nil, id)
 
 		assert.EqualError(t, err, "User not found")
 	})
 
-	t.Run("repository_error", func(t *testing.T) {
+	t.Run("wrong_current_password", func(t *testing.T) {
+		repository.EXPECT().List(user.Id).Return(user, nil)
 
-		userId := uuid.New()
-		newPassword := "password@123"
+		id, err := service.UpdatePassword(user.Id, "wrong@123", "password@456")
+
+		assert.EqualValues(t, uuid.Nil, id)
+		assert.EqualError(t, err, "Wrong password")
+	})
+
+	t.Run("current_password_reused", func(t *testing.T) {
+		repository.EXPECT().List(user.Id).Return(user, nil)
 
-		repository.EXPECT().List(userId).Return(domain.UserDomain{}, nil)
-		repository.EXPECT().UpdatePassword(userId, gomock.Any()).Return(uuid.Nil, errors.New("repository error"))
+		id, err := service.UpdatePassword(user.Id, "password@123", "password@123")
 
-		id, err := service.UpdatePassword(userId, newPassword)
+		assert.EqualValues(t, uuid.Nil, id)
+		assert.EqualError(t, err, "New password must be different from the current password")
+	})
+
+	t.Run("repository_error", func(t *testing.T) {
+		repository.EXPECT().List(user.Id).Return(user, nil)
+		expectTransaction(repository)
+		repository.EXPECT().UpdatePassword(user.Id, gomock.Any()).Return(uuid.Nil, errors.New("repository error"))
+
+		id, err := service.UpdatePassword(user.Id, "password@123", "password@456")
 
 		assert.EqualValues(t, uuid.Nil, id)
 
@@ -46,17 +68,58 @@ func TestUserService_UpdatePassword(t *testing.T) {
 	})
 
 	t.Run("password_update_successfuly", func(t *testing.T) {
+		before := time.Now()
 
-		userId := uuid.New()
-		newPassword := "password@123"
+		repository.EXPECT().List(user.Id).Return(user, nil)
+		expectTransaction(repository)
+		repository.EXPECT().UpdatePassword(user.Id, gomock.Any()).DoAndReturn(func(id uuid.UUID, updated domain.UserDomain) (uuid.UUID, error) {
+			valid, _ := domain.VerifyPassword(updated.Password, "password@456")
+
+			assert.True(t, valid)
+			assert.False(t, updated.PasswordChangedAt.Before(before))
+
+			return id, nil
+		})
+		repository.EXPECT().AddEvent(gomock.AssignableToTypeOf(domain.UserPasswordChanged{})).Return(nil)
 
-		repository.EXPECT().List(userId).Return(domain.UserDomain{}, nil)
-		repository.EXPECT().UpdatePassword(userId, gomock.Any()).Return(userId, nil)
+		id, err := service.UpdatePassword(user.Id, "password@123", "password@456")
+
+		assert.EqualValues(t, user.Id, id)
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestUserService_ResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repository := mocks.NewMockUserRepository(ctrl)
+	uService := service.NewUserService(repository)
+
+	user, err := domain.CreateUser(uuid.Nil, "Test name", "test@email.com", "00000000000", "password@123", time.Time{}, time.Time{}, time.Time{})
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when creating the user", err.Error())
+	}
+
+	t.Run("current_password_reused", func(t *testing.T) {
+		repository.EXPECT().List(user.Id).Return(user, nil)
+
+		id, err := uService.ResetPassword(user.Id, "password@123")
+
+		assert.EqualValues(t, uuid.Nil, id)
+		assert.EqualError(t, err, "New password must be different from the current password")
+	})
 
-		id, err := service.UpdatePassword(userId, newPassword)
+	t.Run("reset_without_current_password", func(t *testing.T) {
+		repository.EXPECT().List(user.Id).Return(user, nil)
+		expectTransaction(repository)
+		repository.EXPECT().UpdatePassword(user.Id, gomock.Any()).Return(user.Id, nil)
+		repository.EXPECT().AddEvent(gomock.AssignableToTypeOf(domain.UserPasswordChanged{})).Return(nil)
 
-		assert.EqualValues(t, userId, id)
+		id, err := uService.ResetPassword(user.Id, "password@456")
 
+		assert.EqualValues(t, user.Id, id)
 		assert.NoError(t, err)
 	})
 }
@@ -93,6 +93,7 @@ func TestUserService_Update(t *testing.T) {
 
 		repository.EXPECT().FindUserByEmail(uDomain.Email).Return(domain.UserDomain{}, errors.New("sql: no rows in result set"))
 
+		expectTransaction(repository)
 		repository.EXPECT().Update(userId, gomock.Any()).Return(uuid.Nil, errors.New("repository error"))
 
 		id, err := service.Update(userId, uDomain)
@@ -117,11 +118,73 @@ func TestUserService_Update(t *testing.T) {
 
 		repository.EXPECT().FindUserByEmail(uDomain.Email).Return(domain.UserDomain{}, errors.New("sql: no rows in result set"))
 
+		expectTransaction(repository)
 		repository.EXPECT().Update(userId, gomock.Any()).Return(uDomain.Id, nil)
+		repository.EXPECT().AddEvent(gomock.AssignableToTypeOf(domain.UserUpdated{})).Return(nil)
 
 		id, err := service.Update(userId, uDomain)
 
 		assert.EqualValues(t, uDomain.Id, id)
 		assert.NoError(t, err)
 	})
+
+	t.Run("update_keeps_own_email_and_phone", func(t *testing.T) {
+		userId := uuid.New()
+
+		uDomain := domain.UserDomain{
+			Id:    userId,
+			Name:  "Test name",
+			Email: "test@email.com",
+			Phone: "00000000000",
+		}
+
+		repository.EXPECT().List(userId).Return(uDomain, nil)
+		repository.EXPECT().FindUserByPhone(uDomain.Phone).Return(uDomain, nil)
+		repository.EXPECT().FindUserByEmail(uDomain.Email).Return(uDomain, nil)
+
+		expectTransaction(repository)
+		repository.EXPECT().Update(userId, gomock.Any()).Return(userId, nil)
+		repository.EXPECT().AddEvent(gomock.AssignableToTypeOf(domain.UserUpdated{})).Return(nil)
+
+		id, err := service.Update(userId, uDomain)
+
+		assert.EqualValues(t, userId, id)
+		assert.NoError(t, err)
+	})
+
+	t.Run("event_carries_only_changed_fields", func(t *testing.T) {
+		userId := uuid.New()
+
+		current := domain.UserDomain{
+			Id:    userId,
+			Name:  "Test name",
+			Email: "test@email.com",
+			Phone: "00000000000",
+		}
+
+		uDomain := current
+		uDomain.Name = "Other name"
+
+		repository.EXPECT().List(userId).Return(current, nil)
+		repository.EXPECT().FindUserByPhone(uDomain.Phone).Return(current, nil)
+		repository.EXPECT().FindUserByEmail(uDomain.Email).Return(current, nil)
+
+		expectTransaction(repository)
+		repository.EXPECT().Update(userId, gomock.Any()).Return(userId, nil)
+		repository.EXPECT().AddEvent(gomock.Any()).DoAndReturn(func(event domain.Event) error {
+			updated := event.(domain.UserUpdated)
+
+			assert.EqualValues(t, userId, updated.UserId)
+			assert.EqualValues(t, "Other name", updated.Name)
+			assert.Empty(t, updated.Email)
+			assert.Empty(t, updated.Phone)
+
+			return nil
+		})
+
+		id, err := service.Update(userId, uDomain)
+
+		assert.EqualValues(t, userId, id)
+		assert.NoError(t, err)
+	})
 }
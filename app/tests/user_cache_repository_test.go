@@ -0,0 +1,189 @@
+package test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/cache"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/ports/output"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestUserCacheRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := domain.UserDomain{
+		Id: uuid.New(),
+		Name: "test",
+		Email: "test@test.com",
+		Phone: "00000000000",
+		Password: "hash",
+	}
+
+	t.Run("list_is_cached", func(t *testing.T) {
+		next := mocks.NewMockUserRepository(ctrl)
+		repository := cache.NewUserRepository(next, cache.NewLRU(10, time.Minute))
+
+		next.EXPECT().List(user.Id).Return(user, nil).Times(1)
+
+		first, err := repository.List(user.Id)
+		assert.NoError(t, err)
+
+		second, err := repository.List(user.Id)
+		assert.NoError(t, err)
+
+		assert.EqualValues(t, first.Email, second.Email)
+		assert.EqualValues(t, domain.CacheStats{Hits: 1, Misses: 1}, repository.Stats())
+	})
+
+	t.Run("email_and_phone_share_the_entry", func(t *testing.T) {
+		next := mocks.NewMockUserRepository(ctrl)
+		repository := cache.NewUserRepository(next, cache.NewLRU(10, time.Minute))
+
+		next.EXPECT().FindUserByEmail(user.Email).Return(user, nil).Times(1)
+
+		_, err := repository.FindUserByEmail(user.Email)
+		assert.NoError(t, err)
+
+		byPhone, err := repository.FindUserByPhone(user.Phone)
+		assert.NoError(t, err)
+		assert.EqualValues(t, user.Id, byPhone.Id)
+
+		byId, err := repository.List(user.Id)
+		assert.NoError(t, err)
+		assert.EqualValues(t, user.Id, byId.Id)
+	})
+
+	t.Run("not_found_is_not_cached", func(t *testing.T) {
+		next := mocks.NewMockUserRepository(ctrl)
+		repository := cache.NewUserRepository(next, cache.NewLRU(10, time.Minute))
+
+		next.EXPECT().FindUserByEmail("new@test.com").Return(domain.UserDomain{}, errors.New("sql: no rows in result set")).Times(2)
+
+		_, err := repository.FindUserByEmail("new@test.com")
+		assert.EqualError(t, err, "sql: no rows in result set")
+
+		_, err = repository.FindUserByEmail("new@test.com")
+		assert.EqualError(t, err, "sql: no rows in result set")
+	})
+
+	t.Run("update_invalidates_every_key", func(t *testing.T) {
+		next := mocks.NewMockUserRepository(ctrl)
+		repository := cache.NewUserRepository(next, cache.NewLRU(10, time.Minute))
+
+		updated := user
+		updated.Email = "changed@test.com"
+
+		next.EXPECT().FindUserByEmail(user.Email).Return(user, nil)
+		next.EXPECT().Update(user.Id, gomock.Any()).Return(user.Id, nil)
+		next.EXPECT().FindUserByEmail(user.Email).Return(domain.UserDomain{}, errors.New("sql: no rows in result set"))
+		next.EXPECT().List(user.Id).Return(updated, nil)
+
+		_, err := repository.FindUserByEmail(user.Email)
+		assert.NoError(t, err)
+
+		_, err = repository.Update(user.Id, updated)
+		assert.NoError(t, err)
+
+		_, err = repository.FindUserByEmail(user.Email)
+		assert.EqualError(t, err, "sql: no rows in result set")
+
+		result, err := repository.List(user.Id)
+		assert.NoError(t, err)
+		assert.EqualValues(t, "changed@test.com", result.Email)
+	})
+
+	t.Run("stale_alias_is_a_miss", func(t *testing.T) {
+		next := mocks.NewMockUserRepository(ctrl)
+		repository := cache.NewUserRepository(next, cache.NewLRU(10, time.Minute))
+
+		updated := user
+		updated.Email = "changed@test.com"
+
+		// the old email still points to the id, which now holds the new email
+		next.EXPECT().FindUserByEmail(user.Email).Return(user, nil)
+		next.EXPECT().UpdatePassword(user.Id, gomock.Any()).Return(user.Id, nil)
+		next.EXPECT().List(user.Id).Return(updated, nil)
+		next.EXPECT().FindUserByEmail(user.Email).Return(domain.UserDomain{}, errors.New("sql: no rows in result set"))
+
+		repository.FindUserByEmail(user.Email)
+		repository.UpdatePassword(user.Id, updated)
+		repository.List(user.Id)
+
+		_, err := repository.FindUserByEmail(user.Email)
+		assert.EqualError(t, err, "sql: no rows in result set")
+	})
+
+	t.Run("transaction_bypasses_and_invalidates", func(t *testing.T) {
+		next := mocks.NewMockUserRepository(ctrl)
+		repository := cache.NewUserRepository(next, cache.NewLRU(10, time.Minute))
+
+		next.EXPECT().List(user.Id).Return(user, nil)
+		repository.List(user.Id)
+
+		next.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(port.UserRepository) error) error {
+			return fn(next)
+		})
+		next.EXPECT().Delete(user.Id).Return(user.Id, nil)
+		next.EXPECT().List(user.Id).Return(domain.UserDomain{}, errors.New("sql: no rows in result set")).Times(2)
+
+		err := repository.Transaction(func(tx port.UserRepository) error {
+			if _, err := tx.Delete(user.Id); err != nil {
+				return err
+			}
+
+			_, err := tx.List(user.Id)
+
+			return err
+		})
+
+		assert.EqualError(t, err, "sql: no rows in result set")
+
+		_, err = repository.List(user.Id)
+		assert.EqualError(t, err, "sql: no rows in result set")
+	})
+
+	t.Run("concurrent_misses_share_one_query", func(t *testing.T) {
+		next := mocks.NewMockUserRepository(ctrl)
+		repository := cache.NewUserRepository(next, cache.NewLRU(10, time.Minute))
+
+		release := make(chan struct{})
+
+		next.EXPECT().List(user.Id).DoAndReturn(func(id uuid.UUID) (domain.UserDomain, error) {
+			<-release
+
+			return user, nil
+		}).Times(1)
+
+		var wg sync.WaitGroup
+
+		for range 10 {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				result, err := repository.List(user.Id)
+
+				assert.NoError(t, err)
+				assert.EqualValues(t, user.Id, result.Id)
+			}()
+		}
+
+		// give the goroutines time to join the in flight load
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		stats := repository.Stats()
+
+		assert.EqualValues(t, 10, stats.Hits + stats.Misses)
+	})
+}
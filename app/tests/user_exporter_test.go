@@ -0,0 +1,97 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/exporter"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type sliceUserIterator struct {
+	users []domain.UserDomain
+	current domain.UserDomain
+	closed bool
+}
+
+func (iterator *sliceUserIterator) Next() bool {
+	if len(iterator.users) == 0 {
+		return false
+	}
+
+	iterator.current = iterator.users[0]
+	iterator.users = iterator.users[1:]
+
+	return true
+}
+
+func (iterator *sliceUserIterator) User() domain.UserDomain {
+	return iterator.current
+}
+
+func (iterator *sliceUserIterator) Err() error {
+	return nil
+}
+
+func (iterator *sliceUserIterator) Close() error {
+	iterator.closed = true
+
+	return nil
+}
+
+func TestUserExporter(t *testing.T) {
+	user := domain.UserDomain{
+		Id: uuid.MustParse("7f1c6c1e-2a8e-4d2a-9f3e-1b2c3d4e5f60"),
+		Name: "Test name",
+		Email: "test@email.com",
+		Phone: "00000000000",
+		Password: "hashedPass",
+		CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	export := func(format string, users ...domain.UserDomain) string {
+		buffer := &bytes.Buffer{}
+		writer, err := exporter.NewUserWriter(format, buffer)
+		assert.NoError(t, err)
+
+		iterator := &sliceUserIterator{users: users}
+		_, err = exporter.Export(iterator, writer, nil)
+
+		assert.NoError(t, err)
+		assert.True(t, iterator.closed)
+
+		return buffer.String()
+	}
+
+	t.Run("invalid_format", func(t *testing.T) {
+		_, err := exporter.NewUserWriter("xml", &bytes.Buffer{})
+
+		assert.EqualError(t, err, "Invalid export format, use csv, ndjson or json")
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		assert.EqualValues(t,
+			"id,name,email,phone,createdAt,updatedAt,deletedAt\n" +
+			"7f1c6c1e-2a8e-4d2a-9f3e-1b2c3d4e5f60,Test name,test@email.com,00000000000,2025-01-02T03:04:05Z,,\n",
+			export(exporter.FormatCSV, user),
+		)
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		assert.EqualValues(t,
+			`{"id":"7f1c6c1e-2a8e-4d2a-9f3e-1b2c3d4e5f60","name":"Test name","email":"test@email.com","phone":"00000000000","createdAt":"2025-01-02T03:04:05Z"}` + "\n",
+			export(exporter.FormatNDJSON, user),
+		)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		output := export(exporter.FormatJSON, user, user)
+
+		assert.True(t, output[0] == '[' && output[len(output) - 1] == ']')
+		assert.NotContains(t, output, "hashedPass")
+		assert.EqualValues(t, "[]", export(exporter.FormatJSON))
+	})
+}
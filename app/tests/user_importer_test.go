@@ -0,0 +1,100 @@
+package test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/importer"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func readAll(t *testing.T, reader domain.ImportRowReader) []domain.ImportRow {
+	rows := []domain.ImportRow{}
+
+	for {
+		row, err := reader.Next()
+
+		if err == io.EOF {
+			return rows
+		}
+
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when reading the rows", err)
+		}
+
+		rows = append(rows, row)
+	}
+}
+
+func TestUserImporter(t *testing.T) {
+	t.Run("invalid_format", func(t *testing.T) {
+		_, err := importer.NewUserReader("xml", strings.NewReader(""))
+
+		assert.EqualError(t, err, "Invalid import format, use csv or ndjson")
+	})
+
+	t.Run("csv_missing_columns", func(t *testing.T) {
+		_, err := importer.NewUserReader(importer.FormatCSV, strings.NewReader("name,email\n"))
+
+		assert.EqualError(t, err, "The csv header must contain the columns name, email, phone, password")
+	})
+
+	t.Run("csv_rows", func(t *testing.T) {
+		file := "email,name,phone,password\n" +
+			"test@email.com,Test name,00000000000,password@123\n" +
+			"invalid,Test name,00000000000,password@123\n" +
+			"short,line\n"
+
+		reader, err := importer.NewUserReader(importer.FormatCSV, strings.NewReader(file))
+		assert.NoError(t, err)
+
+		rows := readAll(t, reader)
+
+		assert.Len(t, rows, 3)
+		assert.EqualValues(t, domain.ImportRow{Row: 2, Name: "Test name", Email: "test@email.com", Phone: "00000000000", Password: "password@123"}, rows[0])
+		assert.EqualValues(t, 3, rows[1].Row)
+		assert.Contains(t, rows[1].Problem, "Email")
+		assert.EqualValues(t, 4, rows[2].Row)
+		assert.NotEmpty(t, rows[2].Problem)
+	})
+
+	t.Run("column_lengths", func(t *testing.T) {
+		// the users columns are varchar(100), a longer value would fail in the database instead
+		file := "email,name,phone,password\n" +
+			strings.Repeat("a", 60) + "@" + strings.Repeat("b", 40) + ".com,Test name,00000000000,password@123\n" +
+			"test@email.com," + strings.Repeat("a", 101) + ",00000000000,password@123\n"
+
+		reader, err := importer.NewUserReader(importer.FormatCSV, strings.NewReader(file))
+		assert.NoError(t, err)
+
+		rows := readAll(t, reader)
+
+		assert.Len(t, rows, 2)
+		assert.Contains(t, rows[0].Problem, "'Email' failed on the 'max' tag")
+		assert.Contains(t, rows[1].Problem, "'Name' failed on the 'max' tag")
+	})
+
+	t.Run("ndjson_rows", func(t *testing.T) {
+		file := `{"name":"Test name","email":"test@email.com","phone":"00000000000","password":"password@123"}` + "\n" +
+			"\n" +
+			"{not json}\n"
+
+		reader, err := importer.NewUserReader(importer.FormatNDJSON, strings.NewReader(file))
+		assert.NoError(t, err)
+
+		rows := readAll(t, reader)
+
+		assert.Len(t, rows, 2)
+		assert.EqualValues(t, "", rows[0].Problem)
+		assert.EqualValues(t, 3, rows[1].Row)
+		assert.NotEmpty(t, rows[1].Problem)
+	})
+
+	t.Run("detect_format", func(t *testing.T) {
+		assert.EqualValues(t, importer.FormatCSV, importer.DetectFormat("users.CSV", ""))
+		assert.EqualValues(t, importer.FormatNDJSON, importer.DetectFormat("", "application/x-ndjson"))
+		assert.EqualValues(t, "", importer.DetectFormat("users.txt", "text/plain"))
+	})
+}
@@ -0,0 +1,56 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchUsers(t *testing.T) {
+	users := []domain.UserDomain{
+		{Id: uuid.New(), Name: "Maria Silva", Email: "maria@test.com", Phone: "11987654321"},
+		{Id: uuid.New(), Name: "Mario Souza", Email: "mario.souza@test.com", Phone: "21912345678"},
+		{Id: uuid.New(), Name: "Pedro Alves", Email: "pedro@test.com", Phone: "31955554444"},
+		{Id: uuid.New(), Name: "Ana Maria", Email: "ana@test.com", Phone: "41900001111"},
+	}
+
+	t.Run("prefix_ranks_above_substring", func(t *testing.T) {
+		page := domain.SearchUsers(users, domain.UserSearch{Query: "maria", Limit: 10})
+
+		assert.EqualValues(t, 2, page.Total)
+		assert.EqualValues(t, "Maria Silva", page.Hits[0].User.Name)
+		assert.EqualValues(t, "Ana Maria", page.Hits[1].User.Name)
+		assert.Greater(t, page.Hits[0].Score, page.Hits[1].Score)
+	})
+
+	t.Run("tolerates_typos", func(t *testing.T) {
+		page := domain.SearchUsers(users, domain.UserSearch{Query: "Pedor Alves", Limit: 10})
+
+		assert.EqualValues(t, 1, page.Total)
+		assert.EqualValues(t, domain.SearchFieldName, page.Hits[0].Field)
+	})
+
+	t.Run("matches_phone_digits", func(t *testing.T) {
+		page := domain.SearchUsers(users, domain.UserSearch{Query: "9123-4567", Limit: 10})
+
+		assert.EqualValues(t, 1, page.Total)
+		assert.EqualValues(t, domain.SearchFieldPhone, page.Hits[0].Field)
+	})
+
+	t.Run("paginates", func(t *testing.T) {
+		page := domain.SearchUsers(users, domain.UserSearch{Query: "test.com", Limit: 2, Offset: 2})
+
+		assert.EqualValues(t, 4, page.Total)
+		assert.Len(t, page.Hits, 2)
+	})
+
+	t.Run("highlight", func(t *testing.T) {
+		assert.EqualValues(t, "<mark>Mar</mark>ia", domain.Highlight("Maria", "mar"))
+		assert.Empty(t, domain.Highlight("Maria", "xyz"))
+		assert.EqualValues(t, "&lt;script&gt;<mark>alert</mark>&lt;/script&gt;", domain.Highlight("<script>alert</script>", "ALERT"))
+		assert.EqualValues(t, "Ìsis <mark>İnan</mark>ç", domain.Highlight("Ìsis İnanç", "inan"))
+		assert.EqualValues(t, "ÉMİ<mark>LE</mark>", domain.Highlight("ÉMİLE", "le"))
+	})
+}
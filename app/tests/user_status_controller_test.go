@@ -0,0 +1,194 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/controller"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/middleware"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/model"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/tests/config"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestUserController_Status(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	uService := mocks.NewMockUserService(ctrl)
+	uController := controller.NewUserController(uService)
+
+	userId := uuid.New()
+
+	jsonBody := func(value any) io.ReadCloser {
+		body, _ := json.Marshal(value)
+
+		return io.NopCloser(strings.NewReader(string(body)))
+	}
+
+	// the actor is the admin making the request, never a field of the body
+	authenticate := middleware.Authenticate(authenticatorFunc(func(string) (domain.Identity, error) {
+		return domain.Identity{UserId: uuid.New(), Email: "admin@email.com", Role: domain.RoleAdmin}, nil
+	}), nil)
+
+	authenticateAdmin := func(context *gin.Context) {
+		context.Request.Header.Set("Authorization", "Bearer admin")
+		authenticate(context)
+	}
+
+	t.Run("suspend_without_identity", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"id": {userId.String()}}, "PATCH", jsonBody(model.SuspendUserModel{Reason: "spam"}))
+		uController.Suspend(context)
+
+		assert.EqualValues(t, http.StatusUnauthorized, recorder.Code)
+	})
+
+	t.Run("suspend_without_reason", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"id": {userId.String()}}, "PATCH", jsonBody(model.SuspendUserModel{}))
+		authenticateAdmin(context)
+		uController.Suspend(context)
+
+		assert.EqualValues(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("suspend_user_not_found", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		uService.EXPECT().Suspend(userId, "spam", "admin@email.com", time.Time{}).Return(uuid.Nil, errors.New("sql: no rows in result set"))
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"id": {userId.String()}}, "PATCH", jsonBody(model.SuspendUserModel{Reason: "spam"}))
+		authenticateAdmin(context)
+		uController.Suspend(context)
+
+		assert.EqualValues(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("suspend_invalid_transition", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		uService.EXPECT().Suspend(userId, "spam", "admin@email.com", time.Time{}).Return(uuid.Nil, errors.New("Invalid status transition"))
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"id": {userId.String()}}, "PATCH", jsonBody(model.SuspendUserModel{Reason: "spam"}))
+		authenticateAdmin(context)
+		uController.Suspend(context)
+
+		assert.EqualValues(t, http.StatusConflict, recorder.Code)
+	})
+
+	t.Run("suspend_until_past", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+		until := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		uService.EXPECT().Suspend(userId, "spam", "admin@email.com", until).Return(uuid.Nil, errors.New("Suspension must end in the future"))
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"id": {userId.String()}}, "PATCH", jsonBody(model.SuspendUserModel{Reason: "spam", Until: &until}))
+		authenticateAdmin(context)
+		uController.Suspend(context)
+
+		assert.EqualValues(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("suspend", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		uService.EXPECT().Suspend(userId, "spam", "admin@email.com", time.Time{}).Return(userId, nil)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"id": {userId.String()}}, "PATCH", jsonBody(model.SuspendUserModel{Reason: "spam"}))
+		authenticateAdmin(context)
+		uController.Suspend(context)
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("reactivate_invalid_transition", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		uService.EXPECT().Reactivate(userId, "", "admin@email.com").Return(uuid.Nil, errors.New("Invalid status transition"))
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"id": {userId.String()}}, "PATCH", jsonBody(model.ReactivateUserModel{}))
+		authenticateAdmin(context)
+		uController.Reactivate(context)
+
+		assert.EqualValues(t, http.StatusConflict, recorder.Code)
+	})
+
+	t.Run("reactivate", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		uService.EXPECT().Reactivate(userId, "appeal accepted", "admin@email.com").Return(userId, nil)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"id": {userId.String()}}, "PATCH", jsonBody(model.ReactivateUserModel{Reason: "appeal accepted"}))
+		authenticateAdmin(context)
+		uController.Reactivate(context)
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("list_invalid_status", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		uService.EXPECT().ListByStatus("banned").Return([]domain.UserDomain{}, errors.New("Invalid status"))
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"status": {"banned"}}, "GET", nil)
+		authenticateAdmin(context)
+		uController.ListByStatus(context)
+
+		assert.EqualValues(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("list_by_status", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		uService.EXPECT().ListByStatus(domain.StatusSuspended).Return([]domain.UserDomain{{
+			Id: userId,
+			Password: "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy",
+			Status: domain.UserStatus{State: domain.StatusSuspended, Reason: "spam", ChangedBy: "admin@email.com"},
+		}}, nil)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"status": {domain.StatusSuspended}}, "GET", nil)
+		authenticateAdmin(context)
+		uController.ListByStatus(context)
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), `"statusReason":"spam"`)
+		assert.NotContains(t, recorder.Body.String(), "$2a$")
+		assert.NotContains(t, recorder.Body.String(), "password")
+	})
+
+	t.Run("login_suspended_user", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context := config.GetTestGinContext(recorder)
+
+		uService.EXPECT().Login("test@email.com", "password@123", gomock.Any()).Return(domain.LoginResult{}, errors.New("Account is suspended"))
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{}, "POST", jsonBody(model.UserLoginModel{Email: "test@email.com", Password: "password@123"}))
+		uController.Login(context)
+
+		assert.EqualValues(t, http.StatusForbidden, recorder.Code)
+	})
+}
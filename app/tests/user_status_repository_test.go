@@ -0,0 +1,86 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/repository"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserRepository_Status(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	repository := repository.NewUserRepository(db)
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	t.Run("update_status_user_not_found", func(t *testing.T) {
+		userId := uuid.New()
+
+		mock.
+			ExpectQuery("UPDATE users SET status = (.+) WHERE id = (.+) AND deletedAt IS NULL").
+			WithArgs(userId, domain.StatusSuspended, "spam", "admin@email.com", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnError(errors.New("sql: no rows in result set"))
+
+		id, err := repository.UpdateStatus(userId, domain.UserStatus{State: domain.StatusSuspended, Reason: "spam", ChangedBy: "admin@email.com", ChangedAt: now})
+
+		assert.EqualValues(t, uuid.Nil, id)
+		assert.EqualError(t, err, "sql: no rows in result set")
+	})
+
+	t.Run("update_status", func(t *testing.T) {
+		userId := uuid.New()
+
+		mock.
+			ExpectQuery("UPDATE users SET status = (.+) WHERE id = (.+) AND deletedAt IS NULL").
+			WithArgs(userId, domain.StatusSuspended, "spam", "admin@email.com", now, now.Add(time.Hour)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(userId))
+
+		id, err := repository.UpdateStatus(userId, domain.UserStatus{State: domain.StatusSuspended, Reason: "spam", ChangedBy: "admin@email.com", ChangedAt: now, SuspendedUntil: now.Add(time.Hour)})
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, userId, id)
+	})
+
+	t.Run("list_by_status_error", func(t *testing.T) {
+		mock.
+			ExpectQuery("SELECT (.+) FROM users WHERE CASE WHEN status = 'suspended'").
+			WithArgs(domain.StatusSuspended, sqlmock.AnyArg()).
+			WillReturnError(errors.New("database error"))
+
+		users, err := repository.ListByStatus(domain.StatusSuspended)
+
+		assert.Empty(t, users)
+		assert.EqualError(t, err, "database error")
+	})
+
+	t.Run("list_by_status", func(t *testing.T) {
+		userId := uuid.New()
+
+		rows := sqlmock.NewRows([]string{"id", "name", "password", "email", "phone", "createdAt", "updatedAt", "deletedAt", "passwordChangedAt", "role", "passwordChangeRequired", "status", "statusReason", "statusChangedBy", "statusChangedAt", "suspendedUntil", "lastLoginAt"}).
+			AddRow(userId, "Test name", "hash", "test@email.com", "00000000000", now, nil, nil, nil, "user", false, "suspended", "spam", "admin@email.com", now, nil, nil)
+
+		mock.
+			ExpectQuery("SELECT (.+) FROM users WHERE CASE WHEN status = 'suspended'").
+			WithArgs(domain.StatusSuspended, sqlmock.AnyArg()).
+			WillReturnRows(rows)
+
+		users, err := repository.ListByStatus(domain.StatusSuspended)
+
+		assert.NoError(t, err)
+		assert.Len(t, users, 1)
+		assert.EqualValues(t, domain.UserStatus{State: domain.StatusSuspended, Reason: "spam", ChangedBy: "admin@email.com", ChangedAt: now}, users[0].Status)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,196 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/services"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestUserService_Status(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repository := mocks.NewMockUserRepository(ctrl)
+	sessions := mocks.NewMockSessionRepository(ctrl)
+	uService := service.NewUserService(repository, service.WithSessions(sessions))
+
+	user, err := domain.CreateUser(uuid.Nil, "Test name", "test@email.com", "00000000000", "password@123", time.Time{}, time.Time{}, time.Time{})
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when creating the user", err.Error())
+	}
+
+	suspended := user
+	suspended.Status = domain.UserStatus{State: domain.StatusSuspended, Reason: "spam", ChangedBy: "admin@email.com"}
+
+	t.Run("suspend_user_not_found", func(t *testing.T) {
+		repository.EXPECT().List(user.Id).Return(domain.UserDomain{}, errors.New("sql: no rows in result set"))
+
+		id, err := uService.Suspend(user.Id, "spam", "admin@email.com", time.Time{})
+
+		assert.EqualValues(t, uuid.Nil, id)
+		assert.EqualError(t, err, "sql: no rows in result set")
+	})
+
+	t.Run("suspend_pending_user", func(t *testing.T) {
+		pending := user
+		pending.Status = domain.UserStatus{State: domain.StatusPending}
+
+		repository.EXPECT().List(user.Id).Return(pending, nil)
+
+		id, err := uService.Suspend(user.Id, "spam", "admin@email.com", time.Time{})
+
+		assert.EqualValues(t, uuid.Nil, id)
+		assert.EqualError(t, err, "Invalid status transition")
+	})
+
+	t.Run("suspend_revokes_sessions", func(t *testing.T) {
+		until := time.Now().Add(24 * time.Hour)
+		sessionId := uuid.New()
+
+		repository.EXPECT().List(user.Id).Return(user, nil)
+		expectTransaction(repository)
+		repository.EXPECT().UpdateStatus(user.Id, gomock.Any()).DoAndReturn(func(id uuid.UUID, status domain.UserStatus) (uuid.UUID, error) {
+			assert.EqualValues(t, domain.StatusSuspended, status.State)
+			assert.EqualValues(t, "spam", status.Reason)
+			assert.EqualValues(t, "admin@email.com", status.ChangedBy)
+			assert.EqualValues(t, until, status.SuspendedUntil)
+
+			return id, nil
+		})
+		repository.EXPECT().AddEvent(gomock.Any()).DoAndReturn(func(event domain.Event) error {
+			changed := event.(domain.UserStatusChanged)
+
+			assert.EqualValues(t, domain.StatusSuspended, changed.Status)
+			assert.EqualValues(t, until, *changed.SuspendedUntil)
+
+			return nil
+		})
+		sessions.EXPECT().ListSessions(user.Id).Return([]domain.Session{{Id: sessionId, UserId: user.Id}}, nil)
+		sessions.EXPECT().RevokeSession(user.Id, sessionId).Return(nil)
+
+		id, err := uService.Suspend(user.Id, "spam", "admin@email.com", until)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, user.Id, id)
+	})
+
+	t.Run("reactivate_active_user", func(t *testing.T) {
+		repository.EXPECT().List(user.Id).Return(user, nil)
+
+		id, err := uService.Reactivate(user.Id, "", "admin@email.com")
+
+		assert.EqualValues(t, uuid.Nil, id)
+		assert.EqualError(t, err, "Invalid status transition")
+	})
+
+	t.Run("reactivate_event_error", func(t *testing.T) {
+		repository.EXPECT().List(user.Id).Return(suspended, nil)
+		expectTransaction(repository)
+		repository.EXPECT().UpdateStatus(user.Id, gomock.Any()).Return(user.Id, nil)
+		repository.EXPECT().AddEvent(gomock.Any()).Return(errors.New("outbox error"))
+
+		id, err := uService.Reactivate(user.Id, "appeal accepted", "admin@email.com")
+
+		assert.EqualValues(t, uuid.Nil, id)
+		assert.EqualError(t, err, "outbox error")
+	})
+
+	t.Run("reactivate", func(t *testing.T) {
+		repository.EXPECT().List(user.Id).Return(suspended, nil)
+		expectTransaction(repository)
+		repository.EXPECT().UpdateStatus(user.Id, gomock.Any()).DoAndReturn(func(id uuid.UUID, status domain.UserStatus) (uuid.UUID, error) {
+			assert.EqualValues(t, domain.StatusActive, status.State)
+			assert.EqualValues(t, "appeal accepted", status.Reason)
+
+			return id, nil
+		})
+		repository.EXPECT().AddEvent(gomock.Any()).Return(nil)
+
+		id, err := uService.Reactivate(user.Id, "appeal accepted", "admin@email.com")
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, user.Id, id)
+	})
+
+	t.Run("list_invalid_status", func(t *testing.T) {
+		users, err := uService.ListByStatus("banned")
+
+		assert.Empty(t, users)
+		assert.EqualError(t, err, "Invalid status")
+	})
+
+	t.Run("list_by_status", func(t *testing.T) {
+		repository.EXPECT().ListByStatus(domain.StatusSuspended).Return([]domain.UserDomain{suspended}, nil)
+
+		users, err := uService.ListByStatus(domain.StatusSuspended)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, []domain.UserDomain{suspended}, users)
+	})
+
+	t.Run("login_wrong_password_hides_status", func(t *testing.T) {
+		repository.EXPECT().FindUserByEmail(user.Email).Return(suspended, nil)
+
+		result, err := uService.Login(user.Email, "wrong@123", domain.Client{})
+
+		assert.EqualValues(t, domain.LoginResult{}, result)
+		assert.EqualError(t, err, "Wrong password")
+	})
+
+	t.Run("login_suspended_user", func(t *testing.T) {
+		repository.EXPECT().FindUserByEmail(user.Email).Return(suspended, nil)
+
+		result, err := uService.Login(user.Email, "password@123", domain.Client{})
+
+		assert.EqualValues(t, domain.LoginResult{}, result)
+		assert.EqualError(t, err, "Account is suspended")
+	})
+
+	t.Run("login_after_suspension_ended", func(t *testing.T) {
+		ended := suspended
+		ended.Status.SuspendedUntil = time.Now().Add(-time.Minute)
+
+		repository.EXPECT().FindUserByEmail(user.Email).Return(ended, nil)
+		sessions.EXPECT().CreateSession(gomock.Any()).Return(nil)
+		repository.EXPECT().UpdateLastLogin(user.Id, gomock.Any()).Return(nil)
+
+		result, err := uService.Login(user.Email, "password@123", domain.Client{})
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, result.Token)
+	})
+
+	t.Run("finish_login_of_suspended_user", func(t *testing.T) {
+		repository.EXPECT().List(user.Id).Return(suspended, nil)
+
+		result, err := uService.FinishLogin(user.Id, domain.Client{})
+
+		assert.EqualValues(t, domain.LoginResult{}, result)
+		assert.EqualError(t, err, "Account is suspended")
+	})
+
+	t.Run("token_of_suspended_user", func(t *testing.T) {
+		withoutSessions := service.NewUserService(repository)
+
+		repository.EXPECT().List(user.Id).Return(user, nil)
+
+		token, err := withoutSessions.IssueToken(user.Id, domain.Client{})
+
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when issuing the token", err.Error())
+		}
+
+		repository.EXPECT().List(user.Id).Return(suspended, nil)
+
+		_, err = withoutSessions.Authenticate(token)
+
+		assert.EqualError(t, err, "Account is suspended")
+	})
+}
@@ -0,0 +1,97 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserStatus(t *testing.T) {
+	now := time.Now()
+
+	t.Run("empty_state_is_active", func(t *testing.T) {
+		assert.EqualValues(t, domain.StatusActive, domain.UserStatus{}.Current(now))
+		assert.NoError(t, domain.UserStatus{}.CanLogin(now))
+	})
+
+	t.Run("suspension_ends_on_its_own", func(t *testing.T) {
+		status := domain.UserStatus{State: domain.StatusSuspended, SuspendedUntil: now.Add(time.Hour)}
+
+		assert.EqualValues(t, domain.StatusSuspended, status.Current(now))
+		assert.EqualError(t, status.CanLogin(now), "Account is suspended")
+		assert.EqualValues(t, domain.StatusActive, status.Current(now.Add(time.Hour)))
+		assert.NoError(t, status.CanLogin(now.Add(time.Hour)))
+	})
+
+	t.Run("suspension_without_end", func(t *testing.T) {
+		status := domain.UserStatus{State: domain.StatusSuspended}
+
+		assert.EqualValues(t, domain.StatusSuspended, status.Current(now.Add(24 * 365 * time.Hour)))
+	})
+
+	t.Run("only_active_accounts_login", func(t *testing.T) {
+		assert.EqualError(t, domain.UserStatus{State: domain.StatusPending}.CanLogin(now), "Account is pending activation")
+		assert.EqualError(t, domain.UserStatus{State: domain.StatusDeactivated}.CanLogin(now), "Account is deactivated")
+	})
+
+	t.Run("transitions", func(t *testing.T) {
+		cases := []struct {
+			from string
+			to string
+			allowed bool
+		}{
+			{domain.StatusPending, domain.StatusActive, true},
+			{domain.StatusPending, domain.StatusSuspended, false},
+			{domain.StatusActive, domain.StatusSuspended, true},
+			{domain.StatusActive, domain.StatusActive, false},
+			{domain.StatusActive, domain.StatusPending, false},
+			{domain.StatusSuspended, domain.StatusActive, true},
+			{domain.StatusSuspended, domain.StatusSuspended, true},
+			{domain.StatusDeactivated, domain.StatusActive, true},
+			{domain.StatusDeactivated, domain.StatusSuspended, false},
+		}
+
+		for _, transition := range cases {
+			user := domain.UserDomain{Status: domain.UserStatus{State: transition.from}}
+
+			err := user.ChangeStatus(transition.to, "reason", "admin@email.com", time.Time{}, now)
+
+			if transition.allowed {
+				assert.NoError(t, err, transition.from + " -> " + transition.to)
+				assert.EqualValues(t, transition.to, user.Status.State)
+			} else {
+				assert.EqualError(t, err, "Invalid status transition", transition.from + " -> " + transition.to)
+				assert.EqualValues(t, transition.from, user.Status.State)
+			}
+		}
+	})
+
+	t.Run("expired_suspension_can_be_suspended_again_as_active", func(t *testing.T) {
+		user := domain.UserDomain{Status: domain.UserStatus{State: domain.StatusSuspended, SuspendedUntil: now.Add(-time.Minute)}}
+
+		assert.EqualError(t, user.ChangeStatus(domain.StatusActive, "", "admin@email.com", time.Time{}, now), "Invalid status transition")
+		assert.NoError(t, user.ChangeStatus(domain.StatusSuspended, "spam", "admin@email.com", time.Time{}, now))
+	})
+
+	t.Run("change_status", func(t *testing.T) {
+		user := domain.UserDomain{}
+		until := now.Add(24 * time.Hour)
+
+		assert.EqualError(t, user.ChangeStatus("banned", "spam", "admin@email.com", time.Time{}, now), "Invalid status")
+		assert.EqualError(t, user.ChangeStatus(domain.StatusSuspended, "", "admin@email.com", time.Time{}, now), "Suspension reason is required")
+		assert.EqualError(t, user.ChangeStatus(domain.StatusSuspended, "spam", "admin@email.com", now.Add(-time.Hour), now), "Suspension must end in the future")
+
+		assert.NoError(t, user.ChangeStatus(domain.StatusSuspended, "spam", "admin@email.com", until, now))
+		assert.EqualValues(t, domain.UserStatus{
+			State: domain.StatusSuspended,
+			Reason: "spam",
+			ChangedBy: "admin@email.com",
+			ChangedAt: now,
+			SuspendedUntil: until,
+		}, user.Status)
+
+		assert.EqualError(t, user.ChangeStatus(domain.StatusActive, "", "admin@email.com", until, now), "Suspension must end in the future")
+	})
+}
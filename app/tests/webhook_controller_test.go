@@ -0,0 +1,141 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/input/controller"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/tests/config"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestWebhookController(t *testing.T) {
+	crtl := gomock.NewController(t)
+	defer crtl.Finish()
+	service := mocks.NewMockWebhookService(crtl)
+	controller := controller.NewWebhookController(service)
+
+	t.Run("create_invalid_url", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		body := io.NopCloser(strings.NewReader(`{"url":"not an url","eventTypes":["user.created"]}`))
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{}, "POST", body)
+		controller.Create(context)
+
+		assert.EqualValues(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("create_returns_secret", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		body := io.NopCloser(strings.NewReader(`{"url":"https://partner.com/hook","eventTypes":["user.created"]}`))
+
+		service.EXPECT().CreateSubscription(gomock.Any()).Return(domain.WebhookSubscription{
+			Id: uuid.New(),
+			Url: "https://partner.com/hook",
+			EventTypes: []string{domain.UserCreatedEvent},
+			Secret: "generated",
+			Active: true,
+		}, nil)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{}, "POST", body)
+		controller.Create(context)
+
+		var response map[string]any
+
+		assert.EqualValues(t, http.StatusCreated, recorder.Code)
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.EqualValues(t, "generated", response["secret"])
+	})
+
+	t.Run("create_unknown_event", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		body := io.NopCloser(strings.NewReader(`{"url":"https://partner.com/hook","eventTypes":["user.exploded"]}`))
+
+		service.EXPECT().CreateSubscription(gomock.Any()).Return(domain.WebhookSubscription{}, errors.New("Invalid event type: user.exploded"))
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{}, "POST", body)
+		controller.Create(context)
+
+		assert.EqualValues(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("list_hides_secret", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		service.EXPECT().ListSubscriptions().Return([]domain.WebhookSubscription{{Id: uuid.New(), Secret: "hidden"}}, nil)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{}, "GET", nil)
+		controller.List(context)
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+		assert.NotContains(t, recorder.Body.String(), "hidden")
+	})
+
+	t.Run("delete_not_found", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		id := uuid.New()
+
+		service.EXPECT().DeleteSubscription(id).Return(uuid.Nil, errors.New("sql: no rows in result set"))
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"id": {id.String()}}, "DELETE", nil)
+		controller.Delete(context)
+
+		assert.EqualValues(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("list_deliveries", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		id := uuid.New()
+
+		service.EXPECT().ListDeliveries(id).Return([]domain.WebhookDelivery{{Id: uuid.New(), SubscriptionId: id, Status: domain.WebhookDeliveryDead, Payload: []byte(`{"type":"user.created"}`)}}, nil)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"subscriptionId": {id.String()}}, "GET", nil)
+		controller.ListDeliveries(context)
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), `"status":"dead"`)
+	})
+
+	t.Run("redeliver", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		context := config.GetTestGinContext(recorder)
+
+		id := uuid.New()
+
+		service.EXPECT().Redeliver(id).Return(id, nil)
+
+		config.MakeRequest(context, []gin.Param{}, url.Values{"id": {id.String()}}, "POST", nil)
+		controller.Redeliver(context)
+
+		assert.EqualValues(t, http.StatusOK, recorder.Code)
+	})
+}
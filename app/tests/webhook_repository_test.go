@@ -0,0 +1,81 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/repository"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookRepository(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	webhookRepository := repository.NewWebhookRepository(db)
+
+	t.Run("find_subscription", func(t *testing.T) {
+		id := uuid.New()
+
+		mock.ExpectQuery("SELECT (.+) FROM webhook_subscriptions WHERE id = (.+)").
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "url", "eventTypes", "secret", "active", "createdAt", "updatedAt",
+			}).AddRow(id, "http://localhost/hook", "user.created,user.deleted", "secret", true, "2025-01-02T03:04:05Z", nil))
+
+		subscription, err := webhookRepository.FindSubscription(id)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, []string{domain.UserCreatedEvent, domain.UserDeletedEvent}, subscription.EventTypes)
+		assert.True(t, subscription.UpdatedAt.IsZero())
+	})
+
+	t.Run("create_delivery_ignores_duplicates", func(t *testing.T) {
+		delivery := domain.WebhookDelivery{Id: uuid.New(), SubscriptionId: uuid.New(), EventId: uuid.New(), EventType: domain.UserCreatedEvent, Payload: []byte(`{}`), Status: domain.WebhookDeliveryPending}
+
+		mock.ExpectExec("INSERT INTO webhook_deliveries (.+) ON CONFLICT \\(subscriptionId, eventId\\) DO NOTHING").
+			WithArgs(delivery.Id, delivery.SubscriptionId, delivery.EventId, delivery.EventType, delivery.Payload, delivery.Status, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.NoError(t, webhookRepository.CreateDelivery(delivery))
+	})
+
+	t.Run("list_due_deliveries", func(t *testing.T) {
+		now := time.Now()
+		id := uuid.New()
+
+		mock.ExpectQuery("SELECT (.+) FROM webhook_deliveries WHERE status = (.+) AND nextAttemptAt <= (.+)").
+			WithArgs(domain.WebhookDeliveryPending, now, 50).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "subscriptionId", "eventId", "eventType", "payload", "status", "attempts", "nextAttemptAt", "lastStatusCode", "lastError", "createdAt", "deliveredAt",
+			}).AddRow(id, uuid.New(), uuid.New(), domain.UserCreatedEvent, []byte(`{}`), domain.WebhookDeliveryPending, 2, "2025-01-02T03:05:05Z", 500, "Unexpected status code 500", "2025-01-02T03:04:05Z", nil))
+
+		deliveries, err := webhookRepository.ListDueDeliveries(now, 50)
+
+		assert.NoError(t, err)
+		assert.Len(t, deliveries, 1)
+		assert.EqualValues(t, 2, deliveries[0].Attempts)
+		assert.EqualValues(t, 500, deliveries[0].LastStatusCode)
+	})
+
+	t.Run("delete_subscription_not_found", func(t *testing.T) {
+		id := uuid.New()
+
+		mock.ExpectQuery("DELETE FROM webhook_subscriptions WHERE id = (.+) RETURNING id").
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, err := webhookRepository.DeleteSubscription(id)
+
+		assert.EqualError(t, err, "sql: no rows in result set")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
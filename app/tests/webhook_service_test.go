@@ -0,0 +1,196 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/PedroPereiraN/go-hexagonal/adapter/output/webhook"
+	"github.com/PedroPereiraN/go-hexagonal/domain"
+	"github.com/PedroPereiraN/go-hexagonal/services"
+	"github.com/PedroPereiraN/go-hexagonal/tests/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func TestWebhookService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repository := mocks.NewMockWebhookRepository(ctrl)
+
+	received := make(chan *http.Request, 1)
+	receivedBody := make(chan []byte, 1)
+	status := http.StatusOK
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		receivedBody <- body
+		w.WriteHeader(status)
+	}))
+	defer receiver.Close()
+
+	wService := service.NewWebhookService(repository, webhook.NewHTTPSender(time.Second))
+
+	subscription := domain.WebhookSubscription{
+		Id: uuid.New(),
+		Url: receiver.URL,
+		EventTypes: []string{domain.UserCreatedEvent},
+		Secret: "a-very-secret-value",
+		Active: true,
+	}
+
+	t.Run("create_generates_secret", func(t *testing.T) {
+		repository.EXPECT().CreateSubscription(gomock.Any()).DoAndReturn(func(dto domain.WebhookSubscription) (uuid.UUID, error) {
+			return dto.Id, nil
+		})
+
+		result, err := wService.CreateSubscription(domain.WebhookSubscription{Url: receiver.URL, EventTypes: []string{domain.WebhookAllEvents}})
+
+		assert.NoError(t, err)
+		assert.True(t, result.Active)
+		assert.Len(t, result.Secret, 64)
+	})
+
+	t.Run("create_rejects_unknown_event", func(t *testing.T) {
+		_, err := wService.CreateSubscription(domain.WebhookSubscription{Url: receiver.URL, EventTypes: []string{"user.exploded"}})
+
+		assert.EqualError(t, err, "Invalid event type: user.exploded")
+	})
+
+	t.Run("publish_queues_matching_subscriptions", func(t *testing.T) {
+		other := domain.WebhookSubscription{Id: uuid.New(), EventTypes: []string{domain.UserDeletedEvent}, Active: true}
+		message := domain.OutboxMessage{Id: uuid.New(), Type: domain.UserCreatedEvent, Payload: []byte(`{"name":"test"}`)}
+
+		repository.EXPECT().ListSubscriptions().Return([]domain.WebhookSubscription{subscription, other}, nil)
+		repository.EXPECT().CreateDelivery(gomock.Any()).DoAndReturn(func(delivery domain.WebhookDelivery) error {
+			assert.EqualValues(t, subscription.Id, delivery.SubscriptionId)
+			assert.EqualValues(t, message.Id, delivery.EventId)
+			assert.EqualValues(t, domain.WebhookDeliveryPending, delivery.Status)
+			assert.Contains(t, string(delivery.Payload), `"data":{"name":"test"}`)
+
+			return nil
+		})
+
+		assert.NoError(t, wService.Publish(message))
+	})
+
+	t.Run("deliver_signed_payload", func(t *testing.T) {
+		delivery := domain.WebhookDelivery{
+			Id: uuid.New(),
+			SubscriptionId: subscription.Id,
+			EventId: uuid.New(),
+			EventType: domain.UserCreatedEvent,
+			Payload: []byte(`{"type":"user.created"}`),
+			Status: domain.WebhookDeliveryPending,
+		}
+
+		repository.EXPECT().ListDueDeliveries(gomock.Any(), gomock.Any()).Return([]domain.WebhookDelivery{delivery}, nil)
+		repository.EXPECT().FindSubscription(subscription.Id).Return(subscription, nil)
+		repository.EXPECT().UpdateDelivery(gomock.Any()).DoAndReturn(func(updated domain.WebhookDelivery) error {
+			assert.EqualValues(t, domain.WebhookDeliverySucceeded, updated.Status)
+			assert.EqualValues(t, 1, updated.Attempts)
+			assert.EqualValues(t, http.StatusOK, updated.LastStatusCode)
+
+			return nil
+		})
+
+		succeeded, err := wService.DeliverPending()
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, succeeded)
+
+		request := <-received
+		body := <-receivedBody
+
+		timestamp, err := strconv.ParseInt(request.Header.Get("X-Webhook-Timestamp"), 10, 64)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, "sha256=" + domain.SignWebhook(subscription.Secret, timestamp, body), request.Header.Get("X-Webhook-Signature"))
+		assert.EqualValues(t, domain.UserCreatedEvent, request.Header.Get("X-Webhook-Event"))
+		assert.EqualValues(t, delivery.EventId.String(), request.Header.Get("X-Webhook-Event-Id"))
+		assert.True(t, json.Valid(body))
+	})
+
+	t.Run("failure_is_retried_with_backoff", func(t *testing.T) {
+		status = http.StatusInternalServerError
+		defer func() { status = http.StatusOK }()
+
+		delivery := domain.WebhookDelivery{Id: uuid.New(), SubscriptionId: subscription.Id, Payload: []byte(`{}`), Attempts: 2, Status: domain.WebhookDeliveryPending}
+
+		repository.EXPECT().ListDueDeliveries(gomock.Any(), gomock.Any()).Return([]domain.WebhookDelivery{delivery}, nil)
+		repository.EXPECT().FindSubscription(subscription.Id).Return(subscription, nil)
+		repository.EXPECT().UpdateDelivery(gomock.Any()).DoAndReturn(func(updated domain.WebhookDelivery) error {
+			assert.EqualValues(t, domain.WebhookDeliveryPending, updated.Status)
+			assert.EqualValues(t, "Unexpected status code 500", updated.LastError)
+			// third attempt, base backoff doubled twice
+			assert.WithinDuration(t, time.Now().Add(4 * service.DefaultWebhookBaseBackoff), updated.NextAttemptAt, time.Second)
+
+			return nil
+		})
+
+		succeeded, err := wService.DeliverPending()
+
+		<-received
+		<-receivedBody
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, succeeded)
+	})
+
+	t.Run("dead_after_max_attempts", func(t *testing.T) {
+		status = http.StatusBadGateway
+		defer func() { status = http.StatusOK }()
+
+		delivery := domain.WebhookDelivery{Id: uuid.New(), SubscriptionId: subscription.Id, Payload: []byte(`{}`), Attempts: 7, Status: domain.WebhookDeliveryPending}
+
+		repository.EXPECT().ListDueDeliveries(gomock.Any(), gomock.Any()).Return([]domain.WebhookDelivery{delivery}, nil)
+		repository.EXPECT().FindSubscription(subscription.Id).Return(subscription, nil)
+		repository.EXPECT().UpdateDelivery(gomock.Any()).DoAndReturn(func(updated domain.WebhookDelivery) error {
+			assert.EqualValues(t, domain.WebhookDeliveryDead, updated.Status)
+			assert.EqualValues(t, 8, updated.Attempts)
+
+			return nil
+		})
+
+		_, err := wService.DeliverPending()
+
+		<-received
+		<-receivedBody
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("redeliver", func(t *testing.T) {
+		delivery := domain.WebhookDelivery{Id: uuid.New(), Status: domain.WebhookDeliveryDead, Attempts: 8}
+
+		repository.EXPECT().FindDelivery(delivery.Id).Return(delivery, nil)
+		repository.EXPECT().UpdateDelivery(gomock.Any()).DoAndReturn(func(updated domain.WebhookDelivery) error {
+			assert.EqualValues(t, domain.WebhookDeliveryPending, updated.Status)
+			assert.EqualValues(t, 0, updated.Attempts)
+
+			return nil
+		})
+
+		result, err := wService.Redeliver(delivery.Id)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, delivery.Id, result)
+	})
+
+	t.Run("redeliver_not_found", func(t *testing.T) {
+		id := uuid.New()
+
+		repository.EXPECT().FindDelivery(id).Return(domain.WebhookDelivery{}, errors.New("sql: no rows in result set"))
+
+		_, err := wService.Redeliver(id)
+
+		assert.EqualError(t, err, "sql: no rows in result set")
+	})
+}